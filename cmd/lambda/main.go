@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
@@ -31,6 +32,13 @@ func initApp() {
 			return
 		}
 		appInst, initErr = app.New(context.Background(), cfg)
+		if initErr != nil {
+			return
+		}
+
+		if err := appInst.NotifyStartup(context.Background()); err != nil {
+			logger.Warn("startup notification failed", slog.String("error", err.Error()))
+		}
 	})
 }
 
@@ -45,7 +53,7 @@ func APIGatewayHandler(ctx context.Context, req awsevents.APIGatewayV2HTTPReques
 		}, nil
 	}
 
-	if appInst.Config.DebugEnabled {
+	if appInst.DebugEnabled() {
 		j, _ := json.Marshal(req)
 		logger.Debug("received api gateway request", slog.String("request", string(j)))
 	}
@@ -55,12 +63,27 @@ func APIGatewayHandler(ctx context.Context, req awsevents.APIGatewayV2HTTPReques
 		headers[strings.ToLower(key)] = value
 	}
 
+	// api gateway base64-encodes non-JSON bodies such as Slack's form posts;
+	// signatures are computed over the decoded bytes
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return awsevents.APIGatewayV2HTTPResponse{
+				StatusCode: 400,
+				Body:       "invalid base64 body",
+			}, nil
+		}
+		body = decoded
+	}
+
 	appReq := app.Request{
 		Type:    app.RequestTypeHTTP,
 		Method:  req.RequestContext.HTTP.Method,
 		Path:    req.RawPath,
 		Headers: headers,
-		Body:    []byte(req.Body),
+		Query:   req.QueryStringParameters,
+		Body:    body,
 	}
 
 	resp := appInst.HandleRequest(ctx, appReq)
@@ -79,7 +102,7 @@ func EventBridgeHandler(ctx context.Context, evt awsevents.CloudWatchEvent) erro
 		return initErr
 	}
 
-	if appInst.Config.DebugEnabled {
+	if appInst.DebugEnabled() {
 		j, _ := json.Marshal(evt)
 		logger.Debug("received eventbridge event", slog.String("event", string(j)))
 	}
@@ -0,0 +1,205 @@
+// Package main replays recorded GitHub webhook payloads through the app in
+// dry-run mode. reports what each event would have done without mutating
+// GitHub or sending notifications.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/app"
+	"github.com/cruxstack/github-ops-app/internal/config"
+	"github.com/joho/godotenv"
+)
+
+// recordedWebhook is the optional wrapper format for payload files. files
+// without a wrapper take their event type from the file name prefix (e.g.,
+// pull_request.1234.json).
+type recordedWebhook struct {
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// replayEntry is the outcome of replaying a single payload file.
+type replayEntry struct {
+	File   string             `json:"file"`
+	Result *app.WebhookResult `json:"result,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+func main() {
+	dir := flag.String("dir", "", "directory of recorded webhook .json files")
+	envFile := flag.String("env", "", "optional .env file to load before config")
+	jsonOutput := flag.Bool("json", false, "print results as json")
+	verbose := flag.Bool("verbose", false, "show application logs")
+	flag.Parse()
+
+	logger := slog.New(config.NewRedactingHandler(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	})))
+
+	if *dir == "" {
+		logger.Error("missing required -dir flag")
+		os.Exit(2)
+	}
+
+	if *envFile != "" {
+		if err := godotenv.Load(*envFile); err != nil {
+			logger.Error("failed to load env file", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.NewConfigWithContext(ctx)
+	if err != nil {
+		logger.Error("config init failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	cfg.DryRun = true
+
+	a, err := app.New(ctx, cfg)
+	if err != nil {
+		logger.Error("app init failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if !*verbose {
+		a.Logger = slog.New(slog.DiscardHandler)
+	}
+
+	files, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		logger.Error("failed to list payload files", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	sort.Strings(files)
+
+	entries := make([]replayEntry, 0, len(files))
+	failed := 0
+	for _, file := range files {
+		entry := replayFile(ctx, a, file)
+		if entry.Error != "" {
+			failed++
+		}
+		entries = append(entries, entry)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			logger.Error("failed to encode results", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+	} else {
+		printSummary(entries)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// replayFile loads a payload file and processes it through the app.
+func replayFile(ctx context.Context, a *app.App, file string) replayEntry {
+	entry := replayEntry{File: filepath.Base(file)}
+
+	eventType, payload, err := loadRecordedWebhook(file)
+	if err != nil {
+		entry.Error = err.Error()
+		return entry
+	}
+
+	result, err := a.ProcessWebhookWithResult(ctx, payload, eventType)
+	entry.Result = result
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	return entry
+}
+
+// loadRecordedWebhook reads a payload file and determines its event type.
+func loadRecordedWebhook(file string) (string, []byte, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "failed to read '%s'", file)
+	}
+
+	var recorded recordedWebhook
+	if err := json.Unmarshal(raw, &recorded); err != nil {
+		return "", nil, errors.Wrapf(err, "failed to parse '%s'", file)
+	}
+	if recorded.EventType != "" && len(recorded.Payload) > 0 {
+		return recorded.EventType, recorded.Payload, nil
+	}
+
+	eventType, _, _ := strings.Cut(filepath.Base(file), ".")
+	return eventType, raw, nil
+}
+
+// printSummary writes a table of replay results and outcome totals.
+func printSummary(entries []replayEntry) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FILE\tEVENT\tACTION\tOUTCOME\tDETAILS")
+
+	totals := make(map[string]int)
+	for _, entry := range entries {
+		event, action, outcome := "-", "-", "error"
+		if entry.Result != nil {
+			event = entry.Result.EventType
+			if entry.Result.Action != "" {
+				action = entry.Result.Action
+			}
+			if entry.Error == "" && entry.Result.Outcome != "" {
+				outcome = entry.Result.Outcome
+			}
+		}
+		totals[outcome]++
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.File, event, action, outcome, describe(entry))
+	}
+	w.Flush()
+
+	outcomes := make([]string, 0, len(totals))
+	for outcome := range totals {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Strings(outcomes)
+
+	fmt.Printf("\n%d events replayed (dry run):", len(entries))
+	for _, outcome := range outcomes {
+		fmt.Printf(" %s=%d", outcome, totals[outcome])
+	}
+	fmt.Println()
+}
+
+// describe summarizes what an event did or would have done.
+func describe(entry replayEntry) string {
+	if entry.Error != "" {
+		return entry.Error
+	}
+	r := entry.Result
+	if r.Reason != "" {
+		return r.Reason
+	}
+
+	var parts []string
+	for _, v := range r.Violations {
+		parts = append(parts, v.Type)
+	}
+	for _, report := range r.SyncReports {
+		parts = append(parts, fmt.Sprintf("%s +%d -%d", report.GitHubTeam, len(report.MembersAdded), len(report.MembersRemoved)))
+	}
+
+	return strings.Join(parts, ", ")
+}
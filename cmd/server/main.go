@@ -36,6 +36,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := appInst.NotifyStartup(ctx); err != nil {
+		logger.Warn("startup notification failed", slog.String("error", err.Error()))
+	}
+
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", httpHandler)
 
@@ -97,11 +101,19 @@ func httpHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	query := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		if len(values) > 0 {
+			query[key] = values[0]
+		}
+	}
+
 	req := app.Request{
 		Type:    app.RequestTypeHTTP,
 		Method:  r.Method,
 		Path:    r.URL.Path,
 		Headers: headers,
+		Query:   query,
 		Body:    body,
 	}
 
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,9 +20,14 @@ func main() {
 	scenarioFile := flag.String("scenarios", "fixtures/scenarios.json", "path to test scenarios file")
 	verbose := flag.Bool("verbose", false, "enable verbose output")
 	scenarioFilter := flag.String("filter", "", "run only scenarios matching this name")
+	jsonOutput := flag.Bool("json", false, "print a machine-readable json summary to stdout")
 	flag.Parse()
 
-	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+	if *jsonOutput {
+		out = os.Stderr
+	}
+
+	logger := slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{
 		Level: slog.LevelInfo,
 	}))
 
@@ -33,7 +39,7 @@ func main() {
 			logger.Warn("failed to load .env file", slog.String("error", err.Error()))
 		}
 	} else if _, err := os.Stat(envExamplePath); err == nil {
-		fmt.Printf("Using .env.test (no .env file found)\n")
+		fmt.Fprintf(out, "Using .env.test (no .env file found)\n")
 		if err := godotenv.Load(envExamplePath); err != nil {
 			logger.Warn("failed to load .env.test file", slog.String("error", err.Error()))
 		}
@@ -54,33 +60,42 @@ func main() {
 		os.Exit(1)
 	}
 
-	passed := 0
-	failed := 0
-	skipped := 0
+	var summary Summary
+	runStart := time.Now()
 
 	for _, scenario := range scenarios {
 		if *scenarioFilter != "" && !strings.Contains(scenario.Name, *scenarioFilter) {
-			skipped++
+			summary.add(scenario.Name, statusSkipped, 0, nil)
 			continue
 		}
 
+		start := time.Now()
 		if err := runScenario(ctx, scenario, *verbose, logger); err != nil {
-			fmt.Printf("✗ FAILED: %v\n\n", err)
-			failed++
+			fmt.Fprintf(out, "✗ FAILED: %v\n\n", err)
+			summary.add(scenario.Name, statusFailed, time.Since(start), err)
 		} else {
-			passed++
+			summary.add(scenario.Name, statusPassed, time.Since(start), nil)
 		}
 	}
+	summary.DurationMS = time.Since(runStart).Milliseconds()
+	passed, failed, skipped := summary.Passed, summary.Failed, summary.Skipped
 
-	fmt.Printf("\n")
+	fmt.Fprintf(out, "\n")
 	separator := strings.Repeat("═", 60)
-	fmt.Printf("%s\n", separator)
+	fmt.Fprintf(out, "%s\n", separator)
 	if failed > 0 {
-		fmt.Printf("  Test Results: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
+		fmt.Fprintf(out, "  Test Results: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
 	} else {
-		fmt.Printf("  Test Results: ✓ All %d tests passed, %d skipped\n", passed, skipped)
+		fmt.Fprintf(out, "  Test Results: ✓ All %d tests passed, %d skipped\n", passed, skipped)
+	}
+	fmt.Fprintf(out, "%s\n", separator)
+
+	if *jsonOutput {
+		if err := summary.write(os.Stdout); err != nil {
+			logger.Error("failed to write json summary", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
 	}
-	fmt.Printf("%s\n", separator)
 
 	if failed > 0 {
 		os.Exit(1)
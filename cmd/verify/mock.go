@@ -80,7 +80,7 @@ func (ms *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if ms.verbose {
 		serviceName := fmt.Sprintf("%-6s", ms.name)
-		fmt.Printf("  → %s %-4s %s\n", serviceName, r.Method, r.URL.Path)
+		fmt.Fprintf(out, "  → %s %-4s %s\n", serviceName, r.Method, r.URL.Path)
 	}
 
 	key := fmt.Sprintf("%s:%s", r.Method, r.URL.Path)
@@ -116,7 +116,7 @@ func (ms *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	if ms.verbose {
 		serviceName := fmt.Sprintf("%-6s", ms.name)
-		fmt.Printf("  ✗ %s No mock response for: %s %s\n", serviceName, r.Method, r.URL.Path)
+		fmt.Fprintf(out, "  ✗ %s No mock response for: %s %s\n", serviceName, r.Method, r.URL.Path)
 	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusNotFound)
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// out receives human-readable progress output. it points at stderr in json
+// mode so stdout carries only the machine-readable summary.
+var out io.Writer = os.Stdout
+
+// ScenarioResult records the outcome of a single scenario run.
+type ScenarioResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	DurationMS int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Summary is the machine-readable report emitted with the -json flag.
+type Summary struct {
+	Passed     int              `json:"passed"`
+	Failed     int              `json:"failed"`
+	Skipped    int              `json:"skipped"`
+	Total      int              `json:"total"`
+	DurationMS int64            `json:"duration_ms"`
+	Scenarios  []ScenarioResult `json:"scenarios"`
+}
+
+const (
+	statusPassed  = "passed"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// add appends a scenario result and updates the aggregate counts.
+func (s *Summary) add(name, status string, duration time.Duration, err error) {
+	res := ScenarioResult{
+		Name:       name,
+		Status:     status,
+		DurationMS: duration.Milliseconds(),
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	s.Scenarios = append(s.Scenarios, res)
+	s.Total++
+
+	switch status {
+	case statusPassed:
+		s.Passed++
+	case statusFailed:
+		s.Failed++
+	case statusSkipped:
+		s.Skipped++
+	}
+}
+
+// write encodes the summary as indented json.
+func (s *Summary) write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
@@ -41,9 +41,9 @@ type ExpectedCall struct {
 func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logger *slog.Logger) error {
 	startTime := time.Now()
 
-	fmt.Printf("\n▶ Running: %s\n", scenario.Name)
+	fmt.Fprintf(out, "\n▶ Running: %s\n", scenario.Name)
 	if scenario.Description != "" {
-		fmt.Printf("  %s\n", scenario.Description)
+		fmt.Fprintf(out, "  %s\n", scenario.Description)
 	}
 
 	githubResponses := []MockResponse{}
@@ -158,7 +158,15 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 	}
 
 	for key, value := range scenario.ConfigOverrides {
+		prev, had := os.LookupEnv(key)
 		os.Setenv(key, value)
+		defer func() {
+			if had {
+				os.Setenv(key, prev)
+			} else {
+				os.Unsetenv(key)
+			}
+		}()
 	}
 
 	cfg, err := config.NewConfig()
@@ -172,10 +180,10 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 	}
 
 	if verbose {
-		fmt.Printf("\n  Application Output:\n")
+		fmt.Fprintf(out, "\n  Application Output:\n")
 	}
 
-	appLogger := slog.New(&testHandler{prefix: "  ", verbose: verbose, w: os.Stdout})
+	appLogger := slog.New(&testHandler{prefix: "  ", verbose: verbose, w: out})
 	a.Logger = appLogger
 
 	var req app.Request
@@ -219,7 +227,7 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 			return fmt.Errorf("expected error but processing succeeded")
 		}
 		if verbose {
-			fmt.Printf("  ✓ Expected error occurred: %v\n", processErr)
+			fmt.Fprintf(out, "  ✓ Expected error occurred: %v\n", processErr)
 		}
 	} else {
 		if processErr != nil {
@@ -241,29 +249,29 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 	totalCalls := len(githubReqs) + len(oktaReqs) + len(slackReqs)
 
 	if verbose {
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "\n")
 	}
 
 	if err := validateExpectedCalls(scenario.ExpectedCalls, allReqs); err != nil {
-		fmt.Printf("\n  Validation:\n")
-		fmt.Printf("  ✗ FAILED: %v\n", err)
-		fmt.Printf("\n  All captured requests:\n")
+		fmt.Fprintf(out, "\n  Validation:\n")
+		fmt.Fprintf(out, "  ✗ FAILED: %v\n", err)
+		fmt.Fprintf(out, "\n  All captured requests:\n")
 		if len(githubReqs) > 0 {
-			fmt.Printf("    GitHub (%d):\n", len(githubReqs))
+			fmt.Fprintf(out, "    GitHub (%d):\n", len(githubReqs))
 			for i, req := range githubReqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
 		if len(oktaReqs) > 0 {
-			fmt.Printf("    Okta (%d):\n", len(oktaReqs))
+			fmt.Fprintf(out, "    Okta (%d):\n", len(oktaReqs))
 			for i, req := range oktaReqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
 		if len(slackReqs) > 0 {
-			fmt.Printf("    Slack (%d):\n", len(slackReqs))
+			fmt.Fprintf(out, "    Slack (%d):\n", len(slackReqs))
 			for i, req := range slackReqs {
-				fmt.Printf("      [%d] %s %s\n", i+1, req.Method, req.Path)
+				fmt.Fprintf(out, "      [%d] %s %s\n", i+1, req.Method, req.Path)
 			}
 		}
 		return err
@@ -272,12 +280,12 @@ func runScenario(ctx context.Context, scenario TestScenario, verbose bool, logge
 	duration := time.Since(startTime)
 
 	if verbose {
-		fmt.Printf("  Validation:\n")
-		fmt.Printf("  ✓ All expected calls verified (%d total)\n", totalCalls)
-		fmt.Printf("\n")
+		fmt.Fprintf(out, "  Validation:\n")
+		fmt.Fprintf(out, "  ✓ All expected calls verified (%d total)\n", totalCalls)
+		fmt.Fprintf(out, "\n")
 	}
 
-	fmt.Printf("✓ PASSED (Duration: %.2fs)\n", duration.Seconds())
+	fmt.Fprintf(out, "✓ PASSED (Duration: %.2fs)\n", duration.Seconds())
 	return nil
 }
 
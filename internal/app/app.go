@@ -6,13 +6,21 @@ import (
 	"context"
 	"encoding/json"
 	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
 
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/cockroachdb/errors"
 	"github.com/cruxstack/github-ops-app/internal/config"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/client"
+	"github.com/cruxstack/github-ops-app/internal/lock"
+	"github.com/cruxstack/github-ops-app/internal/metrics"
 	"github.com/cruxstack/github-ops-app/internal/notifiers"
 	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/store"
 )
 
 // App is the main application instance containing all clients and
@@ -22,17 +30,73 @@ type App struct {
 	Logger       *slog.Logger
 	GitHubClient *client.Client
 	OktaClient   *okta.Client
-	Notifier     *notifiers.SlackNotifier
+	// OktaConnections are the group sources of additional Okta orgs from
+	// APP_OKTA_CONNECTIONS, keyed by connection name.
+	OktaConnections map[string]okta.GroupSource
+	// Notifier fans out notifications to all configured sinks.
+	Notifier notifiers.Notifier
+	Locker   lock.Locker
+	// OrphanHistory stores orphaned user detection results for trend views.
+	OrphanHistory store.OrphanedUsersHistory
+	// LastSync stores the previous okta sync changes for trend comparison.
+	LastSync store.LastSyncStore
+	// Store is the shared backend selected by APP_STORE_BACKEND for state
+	// that must outlive a request.
+	Store store.Store
+	// Metrics records webhook processing latency and outcomes.
+	Metrics metrics.Recorder
+	// PagerDuty raises incidents for critical okta sync failures. nil when
+	// APP_PAGERDUTY_ROUTING_KEY is not set.
+	PagerDuty *notifiers.PagerDutyNotifier
+
+	// configMu guards the Config fields that can change at runtime, i.e.
+	// DebugEnabled.
+	configMu sync.RWMutex
+
+	// incidentsMu serializes updates to the okta sync incident state.
+	incidentsMu sync.Mutex
+
+	// branchDeletions remembers reported branch deletions. it is created on
+	// first use unless configureStore shares it through the store.
+	branchDeletionMu sync.Mutex
+	branchDeletions  dedupSet
+
+	// deliveries remembers recent webhook delivery IDs so retried deliveries
+	// are not processed twice. nil disables deduplication.
+	deliveries dedupSet
+
+	// protectionCache is shared by all installation clients so compliance
+	// checks for the same branch reuse protection lookups.
+	protectionCache *client.ProtectionCache
+
+	// slackActions maps Slack interactive action IDs to the handlers
+	// registered with HandleSlackAction.
+	slackActions map[string]SlackActionHandler
 }
 
 // New creates a new App instance with configured clients.
 // Initializes GitHub, Okta, and Slack clients based on config.
 func New(ctx context.Context, cfg *config.Config) (*App, error) {
 	logger := config.NewLogger()
+	for _, warning := range cfg.Diagnose() {
+		logger.Warn("config warning", slog.String("warning", warning))
+	}
 
 	app := &App{
 		Config: cfg,
 		Logger: logger,
+		Locker: lock.NewMemoryLocker(),
+
+		OrphanHistory: store.NewMemoryOrphanedUsersHistory(cfg.OktaOrphanedHistorySize),
+		LastSync:      store.NewMemoryLastSyncStore(),
+		Metrics:       metrics.NewMemoryRecorder(),
+
+		protectionCache: client.NewProtectionCache(cfg.PRProtectionCacheTTL),
+		deliveries:      newDedupCache(cfg.WebhookDedupSize, cfg.WebhookDedupTTL),
+	}
+
+	if err := app.configureStore(ctx); err != nil {
+		return nil, err
 	}
 
 	if cfg.IsGitHubConfigured() {
@@ -46,82 +110,283 @@ func New(ctx context.Context, cfg *config.Config) (*App, error) {
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create github app client")
 		}
+		ghClient.SetDryRun(cfg.DryRun)
+		ghClient.SetMaxRPS(cfg.GitHubMaxRPS)
+		ghClient.SetRateLimitRetry(cfg.GitHubRateLimitMaxRetries, cfg.GitHubRateLimitMaxBackoff)
+		ghClient.SetProtectionCache(app.protectionCache)
+		ghClient.SetLogger(logger)
 		app.GitHubClient = ghClient
 	}
 
 	if cfg.IsOktaSyncEnabled() {
 		oktaClient, err := okta.NewClientWithContext(ctx, &okta.ClientConfig{
-			Domain:          cfg.OktaDomain,
-			ClientID:        cfg.OktaClientID,
-			PrivateKey:      cfg.OktaPrivateKey,
-			PrivateKeyID:    cfg.OktaPrivateKeyID,
-			Scopes:          cfg.OktaScopes,
-			GitHubUserField: cfg.OktaGitHubUserField,
-			BaseURL:         cfg.OktaBaseURL,
+			Domain:           cfg.OktaDomain,
+			ClientID:         cfg.OktaClientID,
+			PrivateKey:       cfg.OktaPrivateKey,
+			PrivateKeyID:     cfg.OktaPrivateKeyID,
+			Scopes:           cfg.OktaScopes,
+			GitHubUserFields: cfg.OktaGitHubUserFields,
+			BaseURL:          cfg.OktaBaseURL,
 		})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create okta client")
 		}
+		oktaClient.SetLogger(logger)
 		app.OktaClient = oktaClient
+
+		for name, conn := range cfg.OktaConnections {
+			connClient, err := okta.NewClientWithContext(ctx, &okta.ClientConfig{
+				Domain:           conn.Domain,
+				ClientID:         conn.ClientID,
+				PrivateKey:       []byte(conn.PrivateKey),
+				PrivateKeyID:     conn.PrivateKeyID,
+				Scopes:           conn.Scopes,
+				GitHubUserFields: cfg.OktaGitHubUserFields,
+				BaseURL:          conn.BaseURL,
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to create okta client for connection '%s'", name)
+			}
+			connClient.SetLogger(logger.With(slog.String("okta_connection", name)))
+			if app.OktaConnections == nil {
+				app.OktaConnections = make(map[string]okta.GroupSource, len(cfg.OktaConnections))
+			}
+			app.OktaConnections[name] = connClient
+		}
 	}
 
+	var sinks []notifiers.Sink
 	if cfg.SlackEnabled {
 		channels := notifiers.SlackChannels{
 			Default:       cfg.SlackChannel,
 			PRBypass:      cfg.SlackChannelPRBypass,
 			OktaSync:      cfg.SlackChannelOktaSync,
 			OrphanedUsers: cfg.SlackChannelOrphanedUsers,
+			Startup:       cfg.SlackChannelStartup,
+			PRBypassRepos: cfg.SlackChannelPRBypassRepos,
 		}
 		messages := notifiers.SlackMessages{
 			PRBypassFooterNote: cfg.SlackPRBypassFooterNote,
+			Remediations:       cfg.SlackPRBypassRemediations,
+			OrphanedUsersLimit: cfg.SlackOrphanedUsersLimit,
 		}
-		app.Notifier = notifiers.NewSlackNotifierWithAPIURL(cfg.SlackToken, channels, messages, cfg.SlackAPIURL)
+		slackNotifier := notifiers.NewSlackNotifierWithAPIURL(cfg.SlackToken, channels, messages, cfg.SlackAPIURL)
+		slackNotifier.SetGitHubBaseURL(cfg.GitHubBaseURL)
+		slackNotifier.SetLogger(logger)
+		slackNotifier.SetThreadSync(cfg.SlackThreadSync)
+		slackNotifier.SetThreadSyncDaily(cfg.SlackThreadSyncDaily)
+		slackNotifier.SetRetry(cfg.SlackMaxAttempts, cfg.SlackRetryTimeout)
+		sinks = append(sinks, notifiers.Sink{
+			Name:     "slack",
+			Notifier: slackNotifier,
+		})
+	}
+
+	if len(sinks) > 0 {
+		app.Notifier = notifiers.NewMultiNotifier(logger, sinks...)
+	}
+
+	if cfg.PagerDutyRoutingKey != "" {
+		app.PagerDuty = notifiers.NewPagerDutyNotifierWithURL(cfg.PagerDutyRoutingKey, cfg.GitHubOrg, cfg.PagerDutyEventsURL)
 	}
 
 	return app, nil
 }
 
+// configureStore sets up the backend selected by APP_STORE_BACKEND. the
+// memory backend keeps the in-process lock and history implementations; a
+// durable backend moves them into the shared store so every instance sees
+// the same state.
+func (a *App) configureStore(ctx context.Context) error {
+	if a.Config.StoreBackend != store.BackendDynamoDB {
+		a.Store = store.NewMemoryStore()
+		return nil
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load aws config for dynamodb")
+	}
+	a.Store = store.NewDynamoDBStore(dynamodb.NewFromConfig(awsCfg), a.Config.StoreDynamoDBTable)
+	a.Locker = lock.NewStoreLocker(a.Store, lock.DefaultStoreLockTTL)
+	a.OrphanHistory = store.NewPersistentOrphanedUsersHistory(a.Store, a.Config.OktaOrphanedHistorySize)
+	a.LastSync = store.NewPersistentLastSyncStore(a.Store)
+	if a.Config.WebhookDedupSize > 0 {
+		a.deliveries = &storeDedup{store: a.Store, prefix: webhookDeliveryKeyPrefix, ttl: a.Config.WebhookDedupTTL}
+	}
+	a.branchDeletions = &storeDedup{store: a.Store, prefix: branchDeletionKeyPrefix, ttl: branchDeletionDedupWindow}
+	return nil
+}
+
 // ScheduledEvent represents a generic scheduled event.
 type ScheduledEvent struct {
 	Action string          `json:"action"`
 	Data   json.RawMessage `json:"data,omitempty"`
 }
 
+// ScheduledResult summarizes what processing a scheduled event did.
+type ScheduledResult struct {
+	Action      string             `json:"action"`
+	DryRun      bool               `json:"dry_run"`
+	SyncReports []*okta.SyncReport `json:"sync_reports,omitempty"`
+	// OrphanedUsers is the report of orphaned-users-report.
+	OrphanedUsers *okta.OrphanedUsersReport `json:"orphaned_users_report,omitempty"`
+	// MissingUsernames is the report of missing-github-username.
+	MissingUsernames *okta.MissingUsernamesReport `json:"missing_github_username_report,omitempty"`
+	// RuleDiagnostics is the report of diagnose-rules.
+	RuleDiagnostics *okta.RuleDiagnosticsReport `json:"rule_diagnostics,omitempty"`
+}
+
 // ProcessScheduledEvent handles scheduled events (e.g., cron jobs).
 // Routes to appropriate handlers based on event action.
 func (a *App) ProcessScheduledEvent(ctx context.Context, evt ScheduledEvent) error {
-	if a.Config.DebugEnabled {
+	_, err := a.ProcessScheduledEventWithResult(ctx, evt)
+	return err
+}
+
+// ProcessScheduledEventWithResult handles scheduled events and returns a
+// summary of the outcome. okta-sync-dryrun includes the planned changes in
+// SyncReports, orphaned-users-report sets OrphanedUsers,
+// missing-github-username sets MissingUsernames, and diagnose-rules sets
+// RuleDiagnostics. the result is non-nil even when an error occurs.
+func (a *App) ProcessScheduledEventWithResult(ctx context.Context, evt ScheduledEvent) (*ScheduledResult, error) {
+	if a.DebugEnabled() {
 		j, _ := json.Marshal(evt)
 		a.Logger.Debug("received scheduled event", slog.String("event", string(j)))
 	}
 
+	result := &ScheduledResult{
+		Action: evt.Action,
+		DryRun: a.Config.DryRun,
+	}
+
+	if err := a.checkScheduledActionConfigured(evt.Action); err != nil {
+		return result, err
+	}
+
+	var err error
 	switch evt.Action {
 	case "okta-sync":
-		return a.handleOktaSync(ctx)
+		_, err = a.handleOktaSync(ctx, evt.Data)
+	case "okta-sync-dryrun":
+		result.DryRun = true
+		var syncResult *okta.SyncResult
+		syncResult, err = a.handleOktaSyncDryRun(ctx)
+		if syncResult != nil {
+			result.SyncReports = syncResult.Reports
+		}
+	case "orphaned-users-report":
+		result.OrphanedUsers, err = a.handleOrphanedUsersReport(ctx)
+	case "missing-github-username":
+		result.MissingUsernames, err = a.handleMissingUsernamesReport(ctx)
+	case "diagnose-rules":
+		result.RuleDiagnostics, err = a.handleRuleDiagnostics(ctx)
 	case "slack-test":
-		return a.handleSlackTest(ctx)
+		err = a.handleSlackTest(ctx)
+	case "audit-branch-protection":
+		err = a.handleBranchProtectionAudit(ctx)
 	default:
-		return errors.Newf("unknown scheduled action: %s", evt.Action)
+		err = errors.Newf("unknown scheduled action: %s", evt.Action)
 	}
+
+	return result, err
+}
+
+// checkScheduledActionConfigured returns an error marked with
+// ErrNotConfigured if the feature a scheduled action needs is not
+// configured, so partial deployments can skip the action rather than fail.
+func (a *App) checkScheduledActionConfigured(action string) error {
+	switch action {
+	case "okta-sync", "okta-sync-dryrun", "orphaned-users-report", "missing-github-username", "diagnose-rules":
+		if !a.Config.IsOktaSyncEnabled() {
+			return notConfigured("okta sync")
+		}
+	case "audit-branch-protection":
+		if !a.Config.IsGitHubConfigured() {
+			return notConfigured("github")
+		}
+	case "slack-test":
+		if a.Notifier == nil {
+			return notConfigured("slack")
+		}
+	}
+	return nil
+}
+
+// notConfigured returns an error for an unconfigured feature that matches
+// ErrNotConfigured.
+func notConfigured(feature string) error {
+	return errors.Mark(errors.Newf("%s is not configured", feature), internalerrors.ErrNotConfigured)
+}
+
+// WebhookResult outcomes describe the decision made for a webhook.
+const (
+	WebhookOutcomeSkipped       = "skipped"
+	WebhookOutcomeCompliant     = "compliant"
+	WebhookOutcomeViolations    = "violations"
+	WebhookOutcomeBypassed      = "bypassed"
+	WebhookOutcomeBypassAllowed = "bypass_allowed"
+	WebhookOutcomeExempt        = "exempt"
+	WebhookOutcomeOktaSync      = "okta_sync"
+	WebhookOutcomeOktaTeamSync  = "okta_team_sync"
+	WebhookOutcomeBranchDeleted = "branch_deleted"
+	WebhookOutcomeDirectPush    = "direct_push"
+)
+
+// WebhookResult summarizes what processing a webhook did, or would have done
+// when dry-run mode is enabled.
+type WebhookResult struct {
+	EventType   string                       `json:"event_type"`
+	Action      string                       `json:"action,omitempty"`
+	Outcome     string                       `json:"outcome,omitempty"`
+	Reason      string                       `json:"reason,omitempty"`
+	DryRun      bool                         `json:"dry_run"`
+	Violations  []client.ComplianceViolation `json:"violations,omitempty"`
+	SyncReports []*okta.SyncReport           `json:"sync_reports,omitempty"`
+}
+
+// skip marks the result as skipped with the given reason.
+func (r *WebhookResult) skip(reason string) {
+	r.Outcome = WebhookOutcomeSkipped
+	r.Reason = reason
 }
 
 // ProcessWebhook handles incoming GitHub webhook events.
-// Supports pull_request, team, and membership events.
+// Supports pull_request, team, membership, push, and delete events.
 func (a *App) ProcessWebhook(ctx context.Context, payload []byte, eventType string) error {
-	if a.Config.DebugEnabled {
+	_, err := a.ProcessWebhookWithResult(ctx, payload, eventType)
+	return err
+}
+
+// ProcessWebhookWithResult handles incoming GitHub webhook events and returns
+// a summary of the outcome. the result is non-nil even when an error occurs.
+func (a *App) ProcessWebhookWithResult(ctx context.Context, payload []byte, eventType string) (*WebhookResult, error) {
+	if a.DebugEnabled() {
 		a.Logger.Debug("received webhook", slog.String("event_type", eventType))
 	}
 
+	result := &WebhookResult{
+		EventType: eventType,
+		DryRun:    a.Config.DryRun,
+	}
+
+	var err error
 	switch eventType {
 	case "pull_request":
-		return a.handlePullRequestWebhook(ctx, payload)
+		err = a.handlePullRequestWebhook(ctx, payload, result)
 	case "team":
-		return a.handleTeamWebhook(ctx, payload)
+		err = a.handleTeamWebhook(ctx, payload, result)
 	case "membership":
-		return a.handleMembershipWebhook(ctx, payload)
+		err = a.handleMembershipWebhook(ctx, payload, result)
+	case "push":
+		err = a.handlePushWebhook(ctx, payload, result)
+	case "delete":
+		err = a.handleDeleteWebhook(ctx, payload, result)
 	default:
-		return errors.Wrapf(internalerrors.ErrInvalidEventType, "%s", eventType)
+		err = errors.Wrapf(internalerrors.ErrInvalidEventType, "%s", eventType)
 	}
+
+	return result, err
 }
 
 // StatusResponse contains application status and feature flags.
@@ -131,15 +396,208 @@ type StatusResponse struct {
 	OktaSyncEnabled   bool   `json:"okta_sync_enabled"`
 	PRComplianceCheck bool   `json:"pr_compliance_check"`
 	SlackEnabled      bool   `json:"slack_enabled"`
+	ConfigFingerprint string `json:"config_fingerprint"`
+	// SyncSchedule echoes APP_SYNC_SCHEDULE and Warnings lists the results
+	// of config.Diagnose.
+	SyncSchedule string   `json:"sync_schedule,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+	// InFlight is the number of requests being processed, including the
+	// status request itself. omitted when metrics are disabled.
+	InFlight *int64 `json:"in_flight,omitempty"`
+}
+
+// DebugEnabled reports whether debug logging is enabled. it can be changed
+// at runtime with SetDebugEnabled.
+func (a *App) DebugEnabled() bool {
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return a.Config.DebugEnabled
+}
+
+// SetDebugEnabled turns debug logging on or off without a restart. turning
+// it off restores the configured log level, or info if that was debug.
+func (a *App) SetDebugEnabled(enabled bool) {
+	a.configMu.Lock()
+	defer a.configMu.Unlock()
+
+	a.Config.DebugEnabled = enabled
+	level := max(a.Config.LogLevel, slog.LevelInfo)
+	if enabled {
+		level = slog.LevelDebug
+	}
+	config.SetLogLevel(level)
 }
 
 // GetStatus returns current application status and enabled features.
 func (a *App) GetStatus() StatusResponse {
-	return StatusResponse{
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+
+	status := StatusResponse{
 		Status:            "ok",
 		GitHubConfigured:  a.Config.IsGitHubConfigured(),
 		OktaSyncEnabled:   a.Config.IsOktaSyncEnabled(),
 		PRComplianceCheck: a.Config.IsPRComplianceEnabled(),
 		SlackEnabled:      a.Config.SlackEnabled,
+		ConfigFingerprint: a.Config.Fingerprint(),
+		SyncSchedule:      a.Config.SyncSchedule,
+		Warnings:          a.Config.Diagnose(),
+	}
+	if a.Metrics != nil {
+		inFlight := a.Metrics.Snapshot().InFlight
+		status.InFlight = &inFlight
+	}
+	return status
+}
+
+// cache types accepted by ClearCaches.
+const (
+	CacheTypeSlug       = "slug"
+	CacheTypeTeams      = "teams"
+	CacheTypeProtection = "protection"
+	CacheTypeDedup      = "dedup"
+	CacheTypeAll        = "all"
+)
+
+// ClearCaches flushes in-memory caches of the given type so the next lookup
+// fetches fresh data. slug drops the app slug and bot user ID, teams drops
+// cached team memberships, protection drops branch protection and ruleset
+// lookups, and dedup resets the branch deletion dedup window, okta sync
+// throttle, and webhook delivery IDs. all clears every type. returns the
+// cleared types.
+func (a *App) ClearCaches(cacheType string) ([]string, error) {
+	var types []string
+	switch cacheType {
+	case CacheTypeAll:
+		types = []string{CacheTypeSlug, CacheTypeTeams, CacheTypeProtection, CacheTypeDedup}
+	case CacheTypeSlug, CacheTypeTeams, CacheTypeProtection, CacheTypeDedup:
+		types = []string{cacheType}
+	default:
+		return nil, errors.Newf("invalid cache type '%s': must be '%s', '%s', '%s', '%s', or '%s'",
+			cacheType, CacheTypeSlug, CacheTypeTeams, CacheTypeProtection, CacheTypeDedup, CacheTypeAll)
+	}
+
+	for _, t := range types {
+		switch t {
+		case CacheTypeSlug:
+			if a.GitHubClient != nil {
+				a.GitHubClient.ClearAppIdentityCache()
+			}
+		case CacheTypeTeams:
+			if a.GitHubClient != nil {
+				a.GitHubClient.ClearTeamMembersCache()
+			}
+		case CacheTypeProtection:
+			a.protectionCache.Clear()
+		case CacheTypeDedup:
+			a.branchDeletionMu.Lock()
+			if a.branchDeletions != nil {
+				a.branchDeletions.clear()
+			}
+			a.branchDeletionMu.Unlock()
+
+			if a.Store != nil {
+				if err := a.Store.Delete(context.Background(), lastOktaSyncAtKey); err != nil {
+					a.Logger.Warn("failed to clear okta sync time", slog.String("error", err.Error()))
+				}
+			}
+
+			if a.deliveries != nil {
+				a.deliveries.clear()
+			}
+		}
+	}
+
+	a.Logger.Info("cleared caches", slog.Any("types", types))
+	return types, nil
+}
+
+// Version is the app version reported in startup notifications. set at
+// build time with -ldflags "-X .../internal/app.Version=v1.2.3"; falls back
+// to the module version or vcs revision from the build info.
+var Version string
+
+// buildVersion returns the app version, or "dev" if it cannot be determined.
+func buildVersion() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		return v
+	}
+
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+// startupNotificationKeyPrefix namespaces the store keys that record which
+// versions have posted a startup notification.
+const startupNotificationKeyPrefix = "startup-notification/"
+
+// NotifyStartup posts a startup notification with the app version,
+// environment, and the GetStatus feature summary when APP_NOTIFY_ON_START is
+// enabled. confirms notifier connectivity right after a deploy. each version
+// and environment notifies once per store, so Lambda cold starts sharing a
+// dynamodb store do not repeat it.
+func (a *App) NotifyStartup(ctx context.Context) error {
+	if !a.Config.NotifyOnStart {
+		return nil
+	}
+	if a.Notifier == nil {
+		return errors.New("startup notification enabled but no notifier is configured")
+	}
+
+	version := buildVersion()
+	if a.Store != nil {
+		key := startupNotificationKeyPrefix + a.Config.Environment + "/" + version
+		first, err := a.Store.PutIfAbsent(ctx, key, []byte(time.Now().UTC().Format(time.RFC3339)), 0)
+		if err != nil {
+			a.Logger.Warn("failed to record startup notification, sending anyway",
+				slog.String("error", err.Error()))
+		} else if !first {
+			a.Logger.Debug("startup notification already sent for this version",
+				slog.String("version", version))
+			return nil
+		}
+	}
+
+	status := a.GetStatus()
+	info := notifiers.StartupInfo{
+		Version:     version,
+		Environment: a.Config.Environment,
+		DryRun:      a.Config.DryRun,
+		Features: []notifiers.FeatureStatus{
+			{Name: "GitHub App", Enabled: status.GitHubConfigured},
+			{Name: "Okta sync", Enabled: status.OktaSyncEnabled},
+			{Name: "PR compliance", Enabled: status.PRComplianceCheck},
+			{Name: "Slack", Enabled: status.SlackEnabled},
+		},
+	}
+
+	if err := a.Notifier.NotifyStartup(ctx, info); err != nil {
+		return errors.Wrap(err, "failed to send startup notification")
 	}
+	a.Logger.Info("sent startup notification", slog.String("version", info.Version))
+	return nil
 }
@@ -2,13 +2,42 @@ package app
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash"
 	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/cockroachdb/errors"
 	"github.com/cruxstack/github-ops-app/internal/config"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/client"
+	"github.com/cruxstack/github-ops-app/internal/github/webhooks"
+	"github.com/cruxstack/github-ops-app/internal/lock"
+	"github.com/cruxstack/github-ops-app/internal/metrics"
+	"github.com/cruxstack/github-ops-app/internal/notifiers"
 	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/store"
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/google/go-github/v79/github"
+	"github.com/slack-go/slack"
 )
 
 func TestHandleSlackTest_NotConfigured(t *testing.T) {
@@ -128,6 +157,19 @@ func TestFakeOrphanedUsersReport(t *testing.T) {
 	}
 }
 
+func TestFakeMissingUsernamesReport(t *testing.T) {
+	report := fakeMissingUsernamesReport()
+
+	if report == nil || len(report.Users) != 2 {
+		t.Fatalf("expected 2 users, got %+v", report)
+	}
+	for _, user := range report.Users {
+		if user.Email == "" || len(user.Groups) == 0 {
+			t.Errorf("expected email and groups, got %+v", user)
+		}
+	}
+}
+
 func TestProcessScheduledEvent_SlackTest(t *testing.T) {
 	app := &App{
 		Config:   &config.Config{},
@@ -158,6 +200,41 @@ func TestProcessScheduledEvent_UnknownAction(t *testing.T) {
 	}
 }
 
+func TestHandleScheduledRequest_NotConfigured(t *testing.T) {
+	app := &App{
+		Config: &config.Config{},
+		Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+	}
+
+	for _, action := range []string{"okta-sync", "okta-sync-dryrun", "orphaned-users-report", "diagnose-rules", "audit-branch-protection", "slack-test"} {
+		t.Run(action, func(t *testing.T) {
+			err := app.ProcessScheduledEvent(context.Background(), ScheduledEvent{Action: action})
+			if !errors.Is(err, internalerrors.ErrNotConfigured) {
+				t.Errorf("expected not configured error, got %v", err)
+			}
+
+			resp := app.HandleRequest(context.Background(), Request{
+				Type:            RequestTypeScheduled,
+				ScheduledAction: action,
+			})
+			if resp.StatusCode != 200 {
+				t.Errorf("expected status 200, got %d: %s", resp.StatusCode, resp.Body)
+			}
+			if !strings.Contains(string(resp.Body), `"status":"skipped"`) {
+				t.Errorf("expected skipped status, got %s", resp.Body)
+			}
+		})
+	}
+
+	resp := app.HandleRequest(context.Background(), Request{
+		Type:            RequestTypeScheduled,
+		ScheduledAction: "unknown-action",
+	})
+	if resp.StatusCode != 500 {
+		t.Errorf("expected status 500 for unknown action, got %d", resp.StatusCode)
+	}
+}
+
 // verify fake data types match expected interfaces
 func TestFakeDataTypes(t *testing.T) {
 	// ensure fake PR result is compatible with notifier
@@ -172,10 +249,12 @@ func TestFakeDataTypes(t *testing.T) {
 
 func TestCheckAdminAuth(t *testing.T) {
 	tests := []struct {
-		name        string
-		adminToken  string
-		authHeader  string
-		expectError bool
+		name          string
+		adminToken    string
+		readOnlyToken string
+		level         authLevel
+		authHeader    string
+		expectError   bool
 	}{
 		{
 			name:        "no token configured, no header",
@@ -213,12 +292,36 @@ func TestCheckAdminAuth(t *testing.T) {
 			authHeader:  "bearer secret-token",
 			expectError: false,
 		},
+		{
+			name:          "read-only token on read-only route",
+			adminToken:    "secret-token",
+			readOnlyToken: "read-token",
+			level:         authReadOnly,
+			authHeader:    "Bearer read-token",
+			expectError:   false,
+		},
+		{
+			name:          "read-only token on admin route",
+			adminToken:    "secret-token",
+			readOnlyToken: "read-token",
+			level:         authAdmin,
+			authHeader:    "Bearer read-token",
+			expectError:   true,
+		},
+		{
+			name:          "admin token on admin route",
+			adminToken:    "secret-token",
+			readOnlyToken: "read-token",
+			level:         authAdmin,
+			authHeader:    "Bearer secret-token",
+			expectError:   false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			app := &App{
-				Config: &config.Config{AdminToken: tt.adminToken},
+				Config: &config.Config{AdminToken: tt.adminToken, ReadOnlyToken: tt.readOnlyToken},
 				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
 			}
 
@@ -228,7 +331,7 @@ func TestCheckAdminAuth(t *testing.T) {
 			}
 
 			req := Request{Headers: headers}
-			resp := app.checkAdminAuth(req)
+			resp := app.checkAdminAuth(req, tt.level)
 
 			if tt.expectError && resp == nil {
 				t.Error("expected error response, got nil")
@@ -300,12 +403,44 @@ func TestHandleRequest_AdminAuthOnProtectedEndpoints(t *testing.T) {
 			authHeader:     "",
 			expectedStatus: 401,
 		},
+		{
+			name:           "status endpoint, read-only token",
+			path:           "/server/status",
+			method:         "GET",
+			adminToken:     "secret",
+			authHeader:     "Bearer read-only",
+			expectedStatus: 200,
+		},
+		{
+			name:           "scheduled endpoint, read-only token",
+			path:           "/scheduled/slack-test",
+			method:         "POST",
+			adminToken:     "secret",
+			authHeader:     "Bearer read-only",
+			expectedStatus: 401,
+		},
+		{
+			name:           "cache clear endpoint, read-only token",
+			path:           "/server/cache/clear",
+			method:         "POST",
+			adminToken:     "secret",
+			authHeader:     "Bearer read-only",
+			expectedStatus: 401,
+		},
+		{
+			name:           "cache clear endpoint, token required, correct",
+			path:           "/server/cache/clear",
+			method:         "POST",
+			adminToken:     "secret",
+			authHeader:     "Bearer secret",
+			expectedStatus: 200,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			app := &App{
-				Config: &config.Config{AdminToken: tt.adminToken},
+				Config: &config.Config{AdminToken: tt.adminToken, ReadOnlyToken: "read-only"},
 				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
 			}
 
@@ -329,3 +464,1889 @@ func TestHandleRequest_AdminAuthOnProtectedEndpoints(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleWebhookRequest_LegacySignatures(t *testing.T) {
+	const secret = "webhook-secret"
+	payload := []byte(`{"ref": "refs/tags/v1.0.0", "repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}}`)
+
+	sign := func(h func() hash.Hash, prefix string) string {
+		mac := hmac.New(h, []byte(secret))
+		mac.Write(payload)
+		return prefix + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name           string
+		allowLegacy    bool
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			name:           "sha-256 signature accepted",
+			headers:        map[string]string{"x-hub-signature-256": sign(sha256.New, "sha256=")},
+			expectedStatus: 200,
+		},
+		{
+			name:           "sha-1 signature rejected by default",
+			headers:        map[string]string{"x-hub-signature": sign(sha1.New, "sha1=")},
+			expectedStatus: 401,
+		},
+		{
+			name:           "sha-1 signature accepted when allowed",
+			allowLegacy:    true,
+			headers:        map[string]string{"x-hub-signature": sign(sha1.New, "sha1=")},
+			expectedStatus: 200,
+		},
+		{
+			name:           "invalid sha-1 signature rejected when allowed",
+			allowLegacy:    true,
+			headers:        map[string]string{"x-hub-signature": "sha1=0000"},
+			expectedStatus: 401,
+		},
+		{
+			name:        "sha-256 preferred when both are sent",
+			allowLegacy: true,
+			headers: map[string]string{
+				"x-hub-signature-256": "sha256=0000",
+				"x-hub-signature":     sign(sha1.New, "sha1="),
+			},
+			expectedStatus: 401,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				Config: &config.Config{
+					GitHubWebhookSecret:   secret,
+					AllowLegacySignatures: tt.allowLegacy,
+				},
+				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			}
+
+			headers := map[string]string{"x-github-event": "push"}
+			maps.Copy(headers, tt.headers)
+
+			resp := app.HandleRequest(context.Background(), Request{
+				Type:    RequestTypeHTTP,
+				Method:  "POST",
+				Path:    "/webhooks",
+				Headers: headers,
+				Body:    payload,
+			})
+			if resp.StatusCode != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestClearCaches(t *testing.T) {
+	app := &App{
+		Config:          &config.Config{},
+		Logger:          slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		Store:           store.NewMemoryStore(),
+		protectionCache: client.NewProtectionCache(time.Minute),
+	}
+
+	seed := func() {
+		app.recordBranchDeletion(context.Background(), "acme/repo", "main")
+		app.markOktaSynced(context.Background())
+	}
+
+	seed()
+	resp := app.HandleRequest(context.Background(), Request{
+		Type:   RequestTypeHTTP,
+		Method: "POST",
+		Path:   "/server/cache/clear",
+		Query:  map[string]string{"type": CacheTypeProtection},
+	})
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	if !strings.Contains(string(resp.Body), `"cleared":["protection"]`) {
+		t.Errorf("expected only protection cache cleared, got %s", resp.Body)
+	}
+	if app.recordBranchDeletion(context.Background(), "acme/repo", "main") {
+		t.Error("expected dedup state to survive a protection-only clear")
+	}
+	if !app.oktaSyncedWithin(context.Background(), time.Hour) {
+		t.Error("expected okta sync throttle to survive a protection-only clear")
+	}
+
+	cleared, err := app.ClearCaches(CacheTypeAll)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cleared) != 4 {
+		t.Errorf("expected all 4 cache types cleared, got %v", cleared)
+	}
+	if !app.recordBranchDeletion(context.Background(), "acme/repo", "main") {
+		t.Error("expected branch deletion dedup to be reset")
+	}
+	if app.oktaSyncedWithin(context.Background(), time.Hour) {
+		t.Error("expected okta sync throttle to be reset")
+	}
+
+	resp = app.HandleRequest(context.Background(), Request{
+		Type:   RequestTypeHTTP,
+		Method: "POST",
+		Path:   "/server/cache/clear",
+		Query:  map[string]string{"type": "bogus"},
+	})
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400 for unknown cache type, got %d", resp.StatusCode)
+	}
+}
+
+// healthNotifier is a notifier whose health probe returns err, or blocks
+// until the probe context is done when hang is set.
+type healthNotifier struct {
+	notifiers.Notifier
+	err  error
+	hang bool
+}
+
+func (n *healthNotifier) CheckHealth(ctx context.Context) error {
+	if n.hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return n.err
+}
+
+func TestHandleHealthzRequest(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	tests := []struct {
+		name           string
+		sinks          []notifiers.Sink
+		expectedStatus int
+		expectedOK     map[string]bool
+	}{
+		{
+			name:           "no services configured",
+			expectedStatus: 200,
+			expectedOK:     map[string]bool{},
+		},
+		{
+			name: "all probes pass",
+			sinks: []notifiers.Sink{
+				{Name: "slack", Notifier: &healthNotifier{}},
+			},
+			expectedStatus: 200,
+			expectedOK:     map[string]bool{"slack": true},
+		},
+		{
+			name: "failing probe",
+			sinks: []notifiers.Sink{
+				{Name: "slack", Notifier: &healthNotifier{err: errors.New("invalid_auth")}},
+				{Name: "other", Notifier: &healthNotifier{}},
+			},
+			expectedStatus: 503,
+			expectedOK:     map[string]bool{"slack": false, "other": true},
+		},
+		{
+			name: "hung probe times out",
+			sinks: []notifiers.Sink{
+				{Name: "slack", Notifier: &healthNotifier{hang: true}},
+				{Name: "other", Notifier: &healthNotifier{}},
+			},
+			expectedStatus: 503,
+			expectedOK:     map[string]bool{"slack": false, "other": true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				Config: &config.Config{HealthCheckTimeout: 50 * time.Millisecond},
+				Logger: logger,
+			}
+			if len(tt.sinks) > 0 {
+				app.Notifier = notifiers.NewMultiNotifier(logger, tt.sinks...)
+			}
+
+			resp := app.HandleRequest(context.Background(), Request{
+				Type:   RequestTypeHTTP,
+				Method: "GET",
+				Path:   "/server/healthz",
+			})
+			if resp.StatusCode != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, resp.StatusCode, resp.Body)
+			}
+
+			var health HealthResponse
+			if err := json.Unmarshal(resp.Body, &health); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if health.Healthy != (tt.expectedStatus == 200) {
+				t.Errorf("expected healthy %v, got %v", tt.expectedStatus == 200, health.Healthy)
+			}
+			if len(health.Services) != len(tt.expectedOK) {
+				t.Errorf("expected %d services, got %v", len(tt.expectedOK), health.Services)
+			}
+			for name, ok := range tt.expectedOK {
+				got := health.Services[name]
+				if got.OK != ok {
+					t.Errorf("expected %s ok=%v, got %+v", name, ok, got)
+				}
+				if !ok && got.Error == "" {
+					t.Errorf("expected %s error to be reported", name)
+				}
+			}
+		})
+	}
+}
+
+func TestShouldIgnoreWebhookChange(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender *github.User
+		want   bool
+	}{
+		{"other bot sender", &github.User{Login: github.Ptr("renovate[bot]"), ID: github.Ptr(int64(5)), Type: github.Ptr("Bot")}, true},
+		{"app bot matched by id after rename", &github.User{Login: github.Ptr("old-ops-app[bot]"), ID: github.Ptr(int64(99))}, true},
+		{"app bot matched by slug", &github.User{Login: github.Ptr("ops-app[bot]")}, true},
+		{"human sender", &github.User{Login: github.Ptr("alice"), ID: github.Ptr(int64(7)), Type: github.Ptr("User")}, false},
+		{"missing sender", nil, false},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"slug":"ops-app"}`)
+	})
+	mux.HandleFunc("GET /users/{login}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"login":"ops-app[bot]","id":99,"type":"Bot"}`)
+	})
+	app := &App{
+		Config:       &config.Config{},
+		Logger:       slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		GitHubClient: newTestGitHubClient(t, mux),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &webhooks.MembershipEvent{Sender: tt.sender}
+			if got := app.shouldIgnoreWebhookChange(context.Background(), event); got != tt.want {
+				t.Errorf("shouldIgnoreWebhookChange() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleMembershipWebhook_ActionPolicies(t *testing.T) {
+	payload := []byte(`{
+		"action": "removed",
+		"scope": "team",
+		"member": {"login": "alice"},
+		"team": {"slug": "engineering"},
+		"sender": {"login": "bob", "type": "User"}
+	}`)
+
+	tests := []struct {
+		name        string
+		policies    map[string]string
+		lastSyncAge time.Duration
+		expectError bool
+	}{
+		{
+			name:        "default policy triggers full sync",
+			policies:    nil,
+			expectError: true, // clients not initialized
+		},
+		{
+			name:        "ignore policy skips sync",
+			policies:    map[string]string{"removed": config.MembershipPolicyIgnore},
+			expectError: false,
+		},
+		{
+			name:        "team-sync policy triggers targeted sync",
+			policies:    map[string]string{"removed": config.MembershipPolicyTeamSync},
+			expectError: true, // clients not initialized
+		},
+		{
+			name:        "throttle policy skips when synced recently",
+			policies:    map[string]string{"removed": config.MembershipPolicyThrottle},
+			lastSyncAge: 30 * time.Second,
+			expectError: false,
+		},
+		{
+			name:        "throttle policy syncs when never synced",
+			policies:    map[string]string{"removed": config.MembershipPolicyThrottle},
+			expectError: true, // clients not initialized
+		},
+		{
+			name:        "throttle policy syncs once the window has passed",
+			policies:    map[string]string{"removed": config.MembershipPolicyThrottle},
+			lastSyncAge: 2 * time.Minute,
+			expectError: true, // clients not initialized
+		},
+		{
+			name:        "policy for other action does not apply",
+			policies:    map[string]string{"added": config.MembershipPolicyIgnore},
+			expectError: true, // clients not initialized
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				Config: &config.Config{
+					OktaDomain:                   "example.okta.com",
+					OktaClientID:                 "client-id",
+					OktaPrivateKey:               []byte("key"),
+					OktaSyncRules:                []types.SyncRule{{Name: "eng"}},
+					OktaMembershipActionPolicies: tt.policies,
+					OktaMembershipThrottleWindow: time.Minute,
+				},
+				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+				Store:  store.NewMemoryStore(),
+			}
+			if tt.lastSyncAge > 0 {
+				at := time.Now().Add(-tt.lastSyncAge).UTC().Format(time.RFC3339Nano)
+				_ = app.Store.Set(context.Background(), lastOktaSyncAtKey, []byte(at), 0)
+			}
+
+			err := app.ProcessWebhook(context.Background(), payload, "membership")
+
+			if tt.expectError && err == nil {
+				t.Error("expected sync to be attempted")
+			}
+			if !tt.expectError && err != nil {
+				t.Errorf("expected sync to be skipped, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestOktaSyncedWithin_SharedStore(t *testing.T) {
+	ctx := context.Background()
+	shared := store.NewMemoryStore()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	first := &App{Config: &config.Config{}, Logger: logger, Store: shared}
+	second := &App{Config: &config.Config{}, Logger: logger, Store: shared}
+
+	if second.oktaSyncedWithin(ctx, time.Minute) {
+		t.Fatal("expected no recent sync before any sync completed")
+	}
+	first.markOktaSynced(ctx)
+	if !second.oktaSyncedWithin(ctx, time.Minute) {
+		t.Error("expected a sync completed by another instance to throttle")
+	}
+	if second.oktaSyncedWithin(ctx, 0) {
+		t.Error("expected a zero window never to throttle")
+	}
+
+	_ = shared.Set(ctx, lastOktaSyncAtKey, []byte("not a time"), 0)
+	if second.oktaSyncedWithin(ctx, time.Minute) {
+		t.Error("expected an unreadable sync time not to throttle")
+	}
+}
+
+func TestHandleMetricsRequest_AdminOnly(t *testing.T) {
+	app := &App{
+		Config:  &config.Config{AdminToken: "admin-token", ReadOnlyToken: "read-token"},
+		Logger:  slog.New(slog.DiscardHandler),
+		Metrics: metrics.NewMemoryRecorder(),
+	}
+
+	for token, want := range map[string]int{"read-token": 401, "admin-token": 200} {
+		resp := app.HandleRequest(context.Background(), Request{
+			Type:    RequestTypeHTTP,
+			Method:  "GET",
+			Path:    "/server/metrics",
+			Headers: map[string]string{"authorization": "Bearer " + token},
+		})
+		if resp.StatusCode != want {
+			t.Errorf("expected status %d for %s, got %d", want, token, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandleWebhookRequest_Metrics(t *testing.T) {
+	app := &App{
+		Config: &config.Config{
+			PRComplianceEnabled: true,
+			PRMonitoredBranches: []string{"main"},
+		},
+		Logger:  slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		Metrics: metrics.NewMemoryRecorder(),
+	}
+
+	send := func(eventType, payload string) {
+		app.HandleRequest(context.Background(), Request{
+			Type:   RequestTypeHTTP,
+			Method: "POST",
+			Path:   "/webhooks",
+			Headers: map[string]string{
+				"x-github-event":    eventType,
+				"x-github-delivery": "delivery-1",
+			},
+			Body: []byte(payload),
+		})
+	}
+
+	send("pull_request", `{
+		"action": "closed",
+		"number": 1,
+		"pull_request": {"number": 1, "merged": false, "base": {"ref": "main"}},
+		"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}
+	}`)
+	send("bogus", `{}`)
+
+	resp := app.HandleRequest(context.Background(), Request{
+		Type:   RequestTypeHTTP,
+		Method: "GET",
+		Path:   "/server/metrics",
+	})
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	var snapshot metrics.Snapshot
+	if err := json.Unmarshal(resp.Body, &snapshot); err != nil {
+		t.Fatalf("failed to decode metrics: %v", err)
+	}
+
+	got := map[string]string{}
+	for _, stats := range snapshot.Webhooks {
+		got[stats.EventType] = stats.Outcome
+		if stats.Count != 1 {
+			t.Errorf("expected 1 %s delivery, got %d", stats.EventType, stats.Count)
+		}
+	}
+	want := map[string]string{
+		"pull_request": metrics.OutcomeSkipped,
+		"bogus":        metrics.OutcomeError,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("expected outcomes %v, got %v", want, got)
+	}
+
+	// only the metrics request itself is in flight
+	if snapshot.InFlight != 1 {
+		t.Errorf("expected 1 request in flight, got %d", snapshot.InFlight)
+	}
+	if got := app.Metrics.Snapshot().InFlight; got != 0 {
+		t.Errorf("expected no requests in flight after returning, got %d", got)
+	}
+}
+
+func TestSelectSyncRules(t *testing.T) {
+	rules := []types.SyncRule{
+		{Name: "engineering-team", OktaGroupName: "Engineering"},
+		{Name: "ops", OktaGroupName: "Ops"},
+		{Name: "security", OktaGroupName: "Security"},
+	}
+	names := func(rules []types.SyncRule) []string {
+		var names []string
+		for _, rule := range rules {
+			names = append(names, rule.GetName())
+		}
+		return names
+	}
+
+	for _, data := range []string{"", "null", "{}", `{"rules":[]}`} {
+		selected, partial, err := selectSyncRules(rules, json.RawMessage(data))
+		if err != nil || partial || len(selected) != len(rules) {
+			t.Errorf("%q: expected all rules, got %v, %t, %v", data, names(selected), partial, err)
+		}
+	}
+
+	// configured order is kept and names match case-insensitively
+	selected, partial, err := selectSyncRules(rules, json.RawMessage(`{"rules":["Security","engineering-team"]}`))
+	if err != nil || !partial {
+		t.Fatalf("expected partial selection, got %t, %v", partial, err)
+	}
+	if got, want := names(selected), []string{"engineering-team", "security"}; !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	for _, data := range []string{`{"rules":["missing"]}`, `{"rules":"ops"}`, `not json`} {
+		if _, _, err := selectSyncRules(rules, json.RawMessage(data)); err == nil {
+			t.Errorf("%q: expected error", data)
+		}
+	}
+}
+
+func TestObserveOktaSync(t *testing.T) {
+	app := &App{Metrics: metrics.NewMemoryRecorder()}
+
+	app.observeOktaSync(&okta.SyncResult{
+		Reports: []*okta.SyncReport{
+			{Rule: "eng", MembersAdded: []string{"alice", "bob"}},
+			{Rule: "eng", MembersRemoved: []string{"carol"}, Errors: []string{"boom"}},
+			{Rule: "ops", MembersAdded: []string{"dave"}},
+		},
+		Skipped: []okta.SkipCount{{Reason: okta.SkipReasonNoGitHubUsername, Count: 4}},
+	}, 2)
+	app.observeOktaSync(nil, 0)
+
+	stats := app.Metrics.Snapshot().OktaSync
+	if stats.Runs != 2 || stats.FailedRuns != 1 {
+		t.Errorf("expected 2 runs with 1 failed, got %+v", stats)
+	}
+	if stats.MembersAdded != 3 || stats.MembersRemoved != 1 || stats.OrphanedUsers != 2 || stats.RulesFailed != 1 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if len(stats.Rules) != 2 || stats.Rules[0].Failed != 1 || stats.Rules[1].Succeeded != 1 {
+		t.Errorf("expected eng failed and ops succeeded, got %+v", stats.Rules)
+	}
+	if stats.LastMembersSkipped[okta.SkipReasonNoGitHubUsername] != 4 {
+		t.Errorf("expected 4 users skipped without a username, got %v", stats.LastMembersSkipped)
+	}
+}
+
+func TestProcessWebhookWithResult(t *testing.T) {
+	membershipPayload := []byte(`{
+		"action": "removed",
+		"scope": "team",
+		"member": {"login": "alice"},
+		"team": {"slug": "engineering"},
+		"sender": {"login": "bob", "type": "User"}
+	}`)
+	unmergedPRPayload := []byte(`{
+		"action": "closed",
+		"number": 1,
+		"pull_request": {"number": 1, "merged": false, "base": {"ref": "main"}},
+		"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}
+	}`)
+
+	tests := []struct {
+		name          string
+		eventType     string
+		payload       []byte
+		policies      map[string]string
+		expectOutcome string
+		expectError   bool
+	}{
+		{
+			name:          "unmerged pr is skipped",
+			eventType:     "pull_request",
+			payload:       unmergedPRPayload,
+			expectOutcome: WebhookOutcomeSkipped,
+		},
+		{
+			name:          "ignored membership action is skipped",
+			eventType:     "membership",
+			payload:       membershipPayload,
+			policies:      map[string]string{"removed": config.MembershipPolicyIgnore},
+			expectOutcome: WebhookOutcomeSkipped,
+		},
+		{
+			name:          "membership change triggers sync",
+			eventType:     "membership",
+			payload:       membershipPayload,
+			expectOutcome: WebhookOutcomeOktaSync,
+			expectError:   true, // clients not initialized
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				Config: &config.Config{
+					DryRun:                       true,
+					PRComplianceEnabled:          true,
+					PRMonitoredBranches:          []string{"main"},
+					OktaDomain:                   "example.okta.com",
+					OktaClientID:                 "client-id",
+					OktaPrivateKey:               []byte("key"),
+					OktaSyncRules:                []types.SyncRule{{Name: "eng"}},
+					OktaMembershipActionPolicies: tt.policies,
+				},
+				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			}
+
+			result, err := app.ProcessWebhookWithResult(context.Background(), tt.payload, tt.eventType)
+			if tt.expectError != (err != nil) {
+				t.Errorf("expected error=%v, got: %v", tt.expectError, err)
+			}
+			if result == nil {
+				t.Fatal("expected non-nil result")
+			}
+			if result.Outcome != tt.expectOutcome {
+				t.Errorf("expected outcome %q, got %q", tt.expectOutcome, result.Outcome)
+			}
+			if !result.DryRun {
+				t.Error("expected result to be marked as dry run")
+			}
+			if result.Outcome == WebhookOutcomeSkipped && result.Reason == "" {
+				t.Error("expected skip reason")
+			}
+		})
+	}
+}
+
+func TestHandlePullRequestWebhook_Actions(t *testing.T) {
+	prPayload := func(action string, merged bool, changes string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"action": %q,
+			"number": 1,
+			"pull_request": {"number": 1, "merged": %t, "base": {"ref": "main"}},
+			%s
+			"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}
+		}`, action, merged, changes))
+	}
+	baseChange := `"changes": {"base": {"ref": {"from": "develop"}, "sha": {"from": "abc123"}}},`
+
+	tests := []struct {
+		name         string
+		actions      []string
+		payload      []byte
+		expectReason string
+	}{
+		{
+			name:         "reopened ignored by default",
+			payload:      prPayload("reopened", false, ""),
+			expectReason: "pr action not monitored",
+		},
+		{
+			name:         "unmerged closed pr is skipped",
+			payload:      prPayload("closed", false, ""),
+			expectReason: "pr not merged",
+		},
+		{
+			name:         "edited without base change is skipped",
+			actions:      []string{config.PRActionClosed, config.PRActionEdited},
+			payload:      prPayload("edited", true, ""),
+			expectReason: "pr base branch unchanged",
+		},
+		{
+			name:         "retargeted unmerged pr is skipped",
+			actions:      []string{config.PRActionClosed, config.PRActionEdited},
+			payload:      prPayload("edited", false, baseChange),
+			expectReason: "pr not merged",
+		},
+		{
+			name:         "reopened unmerged pr is skipped",
+			actions:      []string{config.PRActionReopened},
+			payload:      prPayload("reopened", false, ""),
+			expectReason: "pr not merged",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{
+				Config: &config.Config{
+					PRComplianceEnabled: true,
+					PRMonitoredBranches: []string{"main"},
+					PRComplianceActions: tt.actions,
+				},
+				Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			}
+
+			result, err := app.ProcessWebhookWithResult(context.Background(), tt.payload, "pull_request")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Outcome != WebhookOutcomeSkipped {
+				t.Errorf("expected outcome %q, got %q", WebhookOutcomeSkipped, result.Outcome)
+			}
+			if result.Reason != tt.expectReason {
+				t.Errorf("expected reason %q, got %q", tt.expectReason, result.Reason)
+			}
+		})
+	}
+}
+
+func TestAuditExclusionReason(t *testing.T) {
+	repo := func(fullName string, archived, fork bool) *github.Repository {
+		return &github.Repository{FullName: &fullName, Archived: &archived, Fork: &fork}
+	}
+
+	tests := []struct {
+		name   string
+		cfg    config.Config
+		repo   *github.Repository
+		expect string
+	}{
+		{
+			name:   "regular repo is audited",
+			repo:   repo("acme/api", false, false),
+			expect: "",
+		},
+		{
+			name:   "archived repo is excluded by default",
+			repo:   repo("acme/old", true, false),
+			expect: auditExcludedArchived,
+		},
+		{
+			name:   "archived repo is audited when included",
+			cfg:    config.Config{AuditIncludeArchived: true},
+			repo:   repo("acme/old", true, false),
+			expect: "",
+		},
+		{
+			name:   "fork is excluded by default",
+			repo:   repo("acme/fork", false, true),
+			expect: auditExcludedFork,
+		},
+		{
+			name:   "ignored repo glob is excluded",
+			cfg:    config.Config{AuditIgnoreRepos: []string{"acme/sandbox-*", "acme/template"}},
+			repo:   repo("acme/Sandbox-demo", false, false),
+			expect: auditExcludedIgnored,
+		},
+		{
+			name:   "owner wildcard is excluded",
+			cfg:    config.Config{AuditIgnoreRepos: []string{"other/*"}},
+			repo:   repo("other/anything", false, false),
+			expect: auditExcludedIgnored,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{Config: &tt.cfg}
+			if got := app.auditExclusionReason(tt.repo); got != tt.expect {
+				t.Errorf("expected %q, got %q", tt.expect, got)
+			}
+		})
+	}
+}
+
+func TestAcquireOktaSyncLock(t *testing.T) {
+	newApp := func(mode string) *App {
+		return &App{
+			Config: &config.Config{OktaSyncConcurrency: mode},
+			Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			Locker: lock.NewMemoryLocker(),
+		}
+	}
+
+	t.Run("coalesce skips while sync is running", func(t *testing.T) {
+		app := newApp(config.SyncConcurrencyCoalesce)
+
+		release, acquired, err := app.acquireOktaSyncLock(context.Background())
+		if err != nil || !acquired {
+			t.Fatalf("expected lock to be acquired, got acquired=%v err=%v", acquired, err)
+		}
+		defer release()
+
+		_, acquired, err = app.acquireOktaSyncLock(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if acquired {
+			t.Error("expected concurrent trigger to be coalesced")
+		}
+	})
+
+	t.Run("wait blocks while sync is running", func(t *testing.T) {
+		app := newApp(config.SyncConcurrencyWait)
+
+		release, _, err := app.acquireOktaSyncLock(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		if _, _, err := app.acquireOktaSyncLock(ctx); err == nil {
+			t.Error("expected waiting trigger to fail once context is done")
+		}
+	})
+
+	t.Run("no locker configured", func(t *testing.T) {
+		app := &App{Config: &config.Config{}}
+
+		release, acquired, err := app.acquireOktaSyncLock(context.Background())
+		if err != nil || !acquired {
+			t.Fatalf("expected lock to be acquired, got acquired=%v err=%v", acquired, err)
+		}
+		release()
+	})
+}
+
+func TestHandleOrphanedHistoryRequest(t *testing.T) {
+	history := store.NewMemoryOrphanedUsersHistory(10)
+	app := &App{
+		Config:        &config.Config{AdminToken: "secret"},
+		Logger:        slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		OrphanHistory: history,
+	}
+
+	app.recordOrphanedUsers(context.Background(), &okta.OrphanedUsersReport{})
+	app.recordOrphanedUsers(context.Background(), fakeOrphanedUsersReport())
+
+	req := Request{
+		Type:    RequestTypeHTTP,
+		Method:  "GET",
+		Path:    "/okta/orphaned/history",
+		Headers: map[string]string{},
+	}
+
+	if resp := app.HandleRequest(context.Background(), req); resp.StatusCode != 401 {
+		t.Errorf("expected status 401 without token, got %d", resp.StatusCode)
+	}
+
+	req.Headers["authorization"] = "Bearer secret"
+	resp := app.HandleRequest(context.Background(), req)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Snapshots []store.OrphanedUsersSnapshot `json:"snapshots"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(body.Snapshots) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(body.Snapshots))
+	}
+	if body.Snapshots[0].Count != 3 {
+		t.Errorf("expected newest snapshot count 3, got %d", body.Snapshots[0].Count)
+	}
+	if body.Snapshots[1].Count != 0 || body.Snapshots[1].Users == nil {
+		t.Errorf("expected oldest snapshot to be empty with non-nil users, got %+v", body.Snapshots[1])
+	}
+}
+
+func TestCompareWithLastSync(t *testing.T) {
+	ctx := context.Background()
+	app := &App{
+		Config:   &config.Config{},
+		Logger:   slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		LastSync: store.NewMemoryLastSyncStore(),
+	}
+
+	first := &okta.SyncResult{
+		Reports: []*okta.SyncReport{
+			{GitHubTeam: "eng", MembersAdded: []string{"alice"}},
+			{GitHubTeam: "ops", MembersRemoved: []string{"bob"}},
+		},
+		Skipped: []okta.SkipCount{{Reason: okta.SkipReasonNoGitHubUsername, Count: 2}},
+	}
+	app.compareWithLastSync(ctx, first, false)
+	if first.Reports[0].SincePrevious != nil {
+		t.Errorf("expected no trend on first sync, got %+v", first.Reports[0].SincePrevious)
+	}
+	if first.Skipped[0].Previous != nil {
+		t.Errorf("expected no previous skip count on first sync, got %d", *first.Skipped[0].Previous)
+	}
+
+	second := &okta.SyncResult{
+		Reports: []*okta.SyncReport{
+			{GitHubTeam: "eng", MembersAdded: []string{"carol"}, MembersRemoved: []string{"alice"}},
+		},
+		Skipped: []okta.SkipCount{{Reason: okta.SkipReasonNoGitHubUsername, Count: 1}},
+	}
+	app.compareWithLastSync(ctx, second, true)
+	if second.Skipped[0].Previous != nil {
+		t.Error("expected team sync skip counts not to be compared")
+	}
+
+	trend := second.Reports[0].SincePrevious
+	if trend == nil {
+		t.Fatal("expected trend on second sync")
+	}
+	if len(trend.Churned) != 1 || trend.Churned[0] != "alice" {
+		t.Errorf("expected alice to be churned, got %v", trend.Churned)
+	}
+	if len(trend.NewlyAdded) != 1 || trend.NewlyAdded[0] != "carol" {
+		t.Errorf("expected carol to be newly added, got %v", trend.NewlyAdded)
+	}
+
+	snapshot, err := app.LastSync.Get(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := snapshot.Teams["ops"]; !ok {
+		t.Errorf("expected team sync to keep unsynced teams, got %+v", snapshot.Teams)
+	}
+
+	dryRunRule := &okta.SyncResult{
+		Reports: []*okta.SyncReport{
+			{GitHubTeam: "eng", MembersRemoved: []string{"carol"}, DryRun: true},
+			{GitHubTeam: "ops", MembersAdded: []string{"bob"}},
+		},
+		Skipped: []okta.SkipCount{{Reason: okta.SkipReasonNoGitHubUsername, Count: 5}},
+	}
+	app.compareWithLastSync(ctx, dryRunRule, false)
+	if previous := dryRunRule.Skipped[0].Previous; previous == nil || *previous != 2 {
+		t.Errorf("expected previous skip count from the last full sync, got %v", previous)
+	}
+	snapshot, _ = app.LastSync.Get(ctx)
+	if eng := snapshot.Teams["eng"]; len(eng.Added) != 1 || eng.Added[0] != "carol" {
+		t.Errorf("expected dry-run rule to keep previous baseline, got %+v", eng)
+	}
+	if ops := snapshot.Teams["ops"]; len(ops.Added) != 1 || ops.Added[0] != "bob" {
+		t.Errorf("expected enforced rule to update baseline, got %+v", ops)
+	}
+
+	app.Config.DryRun = true
+	app.compareWithLastSync(ctx, &okta.SyncResult{Reports: []*okta.SyncReport{{GitHubTeam: "eng", DryRun: true}}}, false)
+	snapshot, _ = app.LastSync.Get(ctx)
+	if len(snapshot.Teams) != 2 {
+		t.Errorf("expected dry run to leave snapshot unchanged, got %+v", snapshot.Teams)
+	}
+}
+
+// startupNotifier records startup notifications.
+type startupNotifier struct {
+	notifiers.Notifier
+	infos []notifiers.StartupInfo
+}
+
+func (n *startupNotifier) NotifyStartup(_ context.Context, info notifiers.StartupInfo) error {
+	n.infos = append(n.infos, info)
+	return nil
+}
+
+func TestNotifyStartup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	t.Run("disabled sends nothing", func(t *testing.T) {
+		n := &startupNotifier{}
+		app := &App{Config: &config.Config{}, Logger: logger, Notifier: n}
+		if err := app.NotifyStartup(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(n.infos) != 0 {
+			t.Errorf("expected no notifications, got %d", len(n.infos))
+		}
+	})
+
+	t.Run("sent once per version through the store", func(t *testing.T) {
+		n := &startupNotifier{}
+		shared := store.NewMemoryStore()
+		cfg := &config.Config{NotifyOnStart: true, Environment: "prod"}
+
+		// each cold start builds a new app sharing the store
+		for range 3 {
+			app := &App{Config: cfg, Logger: logger, Notifier: n, Store: shared}
+			if err := app.NotifyStartup(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if len(n.infos) != 1 {
+			t.Errorf("expected 1 notification across cold starts, got %d", len(n.infos))
+		}
+
+		app := &App{Config: &config.Config{NotifyOnStart: true, Environment: "staging"}, Logger: logger, Notifier: n, Store: shared}
+		if err := app.NotifyStartup(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(n.infos) != 2 {
+			t.Errorf("expected another environment to notify, got %d", len(n.infos))
+		}
+	})
+
+	t.Run("enabled without notifier fails", func(t *testing.T) {
+		app := &App{Config: &config.Config{NotifyOnStart: true}, Logger: logger}
+		if err := app.NotifyStartup(context.Background()); err == nil {
+			t.Error("expected error when no notifier is configured")
+		}
+	})
+
+	t.Run("enabled sends status summary", func(t *testing.T) {
+		n := &startupNotifier{}
+		app := &App{
+			Config: &config.Config{
+				NotifyOnStart: true,
+				Environment:   "staging",
+				SlackEnabled:  true,
+			},
+			Logger:   logger,
+			Notifier: n,
+		}
+		if err := app.NotifyStartup(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(n.infos) != 1 {
+			t.Fatalf("expected 1 notification, got %d", len(n.infos))
+		}
+
+		info := n.infos[0]
+		if info.Environment != "staging" {
+			t.Errorf("Environment = %q, want %q", info.Environment, "staging")
+		}
+		if info.Version == "" {
+			t.Error("expected version to be set")
+		}
+		for _, feature := range info.Features {
+			if want := feature.Name == "Slack"; feature.Enabled != want {
+				t.Errorf("feature %q enabled = %v, want %v", feature.Name, feature.Enabled, want)
+			}
+		}
+	})
+}
+
+// oktaSyncNotifier records the disabled rules of okta sync notifications.
+type oktaSyncNotifier struct {
+	notifiers.Notifier
+	calls         int
+	disabledRules []string
+}
+
+func (n *oktaSyncNotifier) NotifyOktaSync(_ context.Context, result *okta.SyncResult, _ string) error {
+	n.calls++
+	n.disabledRules = result.DisabledRules
+	return nil
+}
+
+func TestNotifyOktaSync_DisabledRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		reports []*okta.SyncReport
+		want    []string
+	}{
+		{name: "enabled", enabled: true, reports: []*okta.SyncReport{{Rule: "eng"}}, want: []string{"legacy"}},
+		{name: "enabled without reports", enabled: true, want: []string{"legacy"}},
+		{name: "disabled", reports: []*okta.SyncReport{{Rule: "eng"}}},
+		{name: "disabled without reports"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &oktaSyncNotifier{}
+			app := &App{
+				Config:   &config.Config{OktaSyncNotifyDisabledRules: tt.enabled},
+				Logger:   slog.New(slog.NewTextHandler(os.Stderr, nil)),
+				Notifier: n,
+			}
+
+			app.notifyOktaSync(context.Background(), &okta.SyncResult{
+				Reports:       tt.reports,
+				DisabledRules: []string{"legacy"},
+			})
+
+			if n.calls != 1 {
+				t.Fatalf("expected 1 notification, got %d", n.calls)
+			}
+			if !slices.Equal(n.disabledRules, tt.want) {
+				t.Errorf("expected disabled rules %v, got %v", tt.want, n.disabledRules)
+			}
+		})
+	}
+}
+
+// branchDeletionNotifier records branch deletion alerts.
+type branchDeletionNotifier struct {
+	notifiers.Notifier
+	deletions []notifiers.BranchDeletion
+}
+
+func (n *branchDeletionNotifier) NotifyBranchDeletion(_ context.Context, deletion notifiers.BranchDeletion) error {
+	n.deletions = append(n.deletions, deletion)
+	return nil
+}
+
+func TestBranchDeletionWebhooks(t *testing.T) {
+	pushPayload := func(ref string, deleted bool) []byte {
+		return []byte(fmt.Sprintf(`{
+			"ref": %q,
+			"before": "abc123",
+			"deleted": %t,
+			"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}},
+			"sender": {"login": "mallory"}
+		}`, ref, deleted))
+	}
+	deletePayload := func(ref, refType string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"ref": %q,
+			"ref_type": %q,
+			"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}},
+			"sender": {"login": "mallory"}
+		}`, ref, refType))
+	}
+
+	n := &branchDeletionNotifier{}
+	app := &App{
+		Config: &config.Config{
+			GitHubOrg:            "acme",
+			GitHubAppID:          1,
+			GitHubAppPrivateKey:  []byte("key"),
+			GitHubInstallationID: 1,
+			PRComplianceEnabled:  true,
+			PRMonitoredBranches:  []string{"main"},
+		},
+		Logger:   slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		Notifier: n,
+	}
+
+	tests := []struct {
+		name          string
+		eventType     string
+		payload       []byte
+		expectOutcome string
+		expectReason  string
+	}{
+		{
+			name:          "regular push to unmonitored branch is skipped",
+			eventType:     "push",
+			payload:       pushPayload("refs/heads/feature", false),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "branch not monitored",
+		},
+		{
+			name:          "unmonitored branch deletion is skipped",
+			eventType:     "push",
+			payload:       pushPayload("refs/heads/feature", true),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "branch not monitored",
+		},
+		{
+			name:          "tag deletion is skipped",
+			eventType:     "delete",
+			payload:       deletePayload("v1.0.0", "tag"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "not a branch deletion",
+		},
+		{
+			name:          "monitored branch deletion alerts",
+			eventType:     "push",
+			payload:       pushPayload("refs/heads/main", true),
+			expectOutcome: WebhookOutcomeBranchDeleted,
+		},
+		{
+			name:          "delete event for same branch is deduplicated",
+			eventType:     "delete",
+			payload:       deletePayload("main", "branch"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "branch deletion already reported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := app.ProcessWebhookWithResult(context.Background(), tt.payload, tt.eventType)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Outcome != tt.expectOutcome {
+				t.Errorf("expected outcome %q, got %q", tt.expectOutcome, result.Outcome)
+			}
+			if result.Reason != tt.expectReason {
+				t.Errorf("expected reason %q, got %q", tt.expectReason, result.Reason)
+			}
+		})
+	}
+
+	if len(n.deletions) != 1 {
+		t.Fatalf("expected 1 branch deletion alert, got %d", len(n.deletions))
+	}
+	deletion := n.deletions[0]
+	if deletion.Repo != "acme/repo" || deletion.Branch != "main" || deletion.DeletedBy != "mallory" || deletion.LastCommit != "abc123" {
+		t.Errorf("unexpected deletion alert: %+v", deletion)
+	}
+	if deletion.DeletedAt.IsZero() {
+		t.Error("expected deletion time to be set")
+	}
+}
+
+// directPushNotifier records direct push alerts.
+type directPushNotifier struct {
+	notifiers.Notifier
+	pushes []notifiers.DirectPush
+}
+
+func (n *directPushNotifier) NotifyDirectPush(_ context.Context, push notifiers.DirectPush) error {
+	n.pushes = append(n.pushes, push)
+	return nil
+}
+
+func TestDirectPushWebhook(t *testing.T) {
+	pushPayload := func(ref string, forced, created bool, senderType string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"ref": %q,
+			"before": "abc1234567",
+			"after": "def7654321",
+			"forced": %t,
+			"created": %t,
+			"compare": "https://github.com/acme/repo/compare/abc1234567...def7654321",
+			"commits": [{"id": "def7654321", "message": "hotfix\n\ndetails"}],
+			"head_commit": {"id": "def7654321", "message": "hotfix\n\ndetails"},
+			"repository": {"name": "repo", "full_name": "acme/repo", "default_branch": "main", "owner": {"login": "acme"}},
+			"sender": {"login": "mallory", "type": %q}
+		}`, ref, forced, created, senderType))
+	}
+
+	n := &directPushNotifier{}
+	app := &App{
+		Config: &config.Config{
+			GitHubOrg:            "acme",
+			GitHubAppID:          1,
+			GitHubAppPrivateKey:  []byte("key"),
+			GitHubInstallationID: 1,
+			PRComplianceEnabled:  true,
+			PRMonitoredBranches:  []string{"main", "hotfix/1"},
+			PRPushExemptions: []config.PushExemption{
+				{Branch: "hotfix/*", Users: []string{"Mallory"}, AllowForcePush: true},
+			},
+		},
+		Logger:   slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		Notifier: n,
+	}
+
+	tests := []struct {
+		name          string
+		payload       []byte
+		expectOutcome string
+		expectReason  string
+		expectAction  string
+	}{
+		{
+			name:          "tag push is skipped",
+			payload:       pushPayload("refs/tags/v1.0.0", false, false, "User"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "not a branch push",
+		},
+		{
+			name:          "branch creation is skipped",
+			payload:       pushPayload("refs/heads/main", false, true, "User"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "branch created",
+			expectAction:  "pushed",
+		},
+		{
+			name:          "bot push is skipped",
+			payload:       pushPayload("refs/heads/main", true, false, "Bot"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "push made by bot or app",
+			expectAction:  "force_pushed",
+		},
+		{
+			name:          "exempt push to non-default branch is skipped",
+			payload:       pushPayload("refs/heads/hotfix/1", true, false, "User"),
+			expectOutcome: WebhookOutcomeSkipped,
+			expectReason:  "push exempt by policy",
+			expectAction:  "force_pushed",
+		},
+		{
+			name:          "force push to default branch alerts despite exemption",
+			payload:       pushPayload("refs/heads/main", true, false, "User"),
+			expectOutcome: WebhookOutcomeDirectPush,
+			expectAction:  "force_pushed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := app.ProcessWebhookWithResult(context.Background(), tt.payload, "push")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Outcome != tt.expectOutcome {
+				t.Errorf("expected outcome %q, got %q", tt.expectOutcome, result.Outcome)
+			}
+			if result.Reason != tt.expectReason {
+				t.Errorf("expected reason %q, got %q", tt.expectReason, result.Reason)
+			}
+			if result.Action != tt.expectAction {
+				t.Errorf("expected action %q, got %q", tt.expectAction, result.Action)
+			}
+		})
+	}
+
+	if len(n.pushes) != 1 {
+		t.Fatalf("expected 1 direct push alert, got %d", len(n.pushes))
+	}
+	push := n.pushes[0]
+	if !push.Forced || push.Repo != "acme/repo" || push.Branch != "main" || push.PushedBy != "mallory" {
+		t.Errorf("unexpected direct push alert: %+v", push)
+	}
+	if push.Commits != 1 || push.HeadCommitMessage != "hotfix" {
+		t.Errorf("expected commit details, got %d commits and message %q", push.Commits, push.HeadCommitMessage)
+	}
+}
+
+func TestDedupCache(t *testing.T) {
+	ctx := context.Background()
+	c := newDedupCache(2, time.Minute)
+	now := time.Now()
+	claim := func(c dedupSet, key string, at time.Time) bool {
+		t.Helper()
+		claimed, err := c.claim(ctx, key, at)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return claimed
+	}
+
+	if !claim(c, "a", now) || claim(c, "a", now.Add(time.Second)) {
+		t.Fatal("expected first claim to succeed and repeat to be rejected")
+	}
+	if !claim(c, "a", now.Add(2*time.Minute)) {
+		t.Error("expected claim after ttl to succeed")
+	}
+
+	// "a" is most recent, so "b" is evicted when "c" is added
+	claim(c, "b", now)
+	claim(c, "a", now.Add(2*time.Minute))
+	claim(c, "c", now)
+	if !claim(c, "b", now) {
+		t.Error("expected evicted delivery to be claimable")
+	}
+
+	if err := c.release(ctx, "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !claim(c, "b", now) {
+		t.Error("expected released delivery to be claimable")
+	}
+	if !claim(c, "", now) || !claim(c, "", now) {
+		t.Error("expected deliveries without an id to always be claimed")
+	}
+
+	var disabled *dedupCache
+	if !claim(disabled, "a", now) || !claim(disabled, "a", now) {
+		t.Error("expected nil cache to disable deduplication")
+	}
+}
+
+func TestStoreDedup_SharedAcrossApps(t *testing.T) {
+	ctx := context.Background()
+	shared := store.NewMemoryStore()
+	newApp := func() *App {
+		return &App{
+			Config: &config.Config{},
+			Logger: slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			deliveries: &storeDedup{
+				store: shared, prefix: webhookDeliveryKeyPrefix, ttl: time.Hour,
+			},
+			branchDeletions: &storeDedup{
+				store: shared, prefix: branchDeletionKeyPrefix, ttl: branchDeletionDedupWindow,
+			},
+		}
+	}
+	first, second := newApp(), newApp()
+	now := time.Now()
+
+	if !first.claimDelivery(ctx, "delivery-1", now) {
+		t.Fatal("expected first claim to succeed")
+	}
+	if second.claimDelivery(ctx, "delivery-1", now) {
+		t.Error("expected a delivery claimed by another instance to be rejected")
+	}
+	first.releaseDelivery(ctx, "delivery-1")
+	if !second.claimDelivery(ctx, "delivery-1", now) {
+		t.Error("expected a released delivery to be claimable by another instance")
+	}
+
+	if !first.recordBranchDeletion(ctx, "acme/repo", "main") {
+		t.Fatal("expected first branch deletion to be recorded")
+	}
+	if second.recordBranchDeletion(ctx, "acme/repo", "main") {
+		t.Error("expected a branch deletion reported by another instance to be skipped")
+	}
+
+	// clearing one instance's caches leaves shared entries to expire
+	if _, err := first.ClearCaches(CacheTypeDedup); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.claimDelivery(ctx, "delivery-1", now) {
+		t.Error("expected shared delivery claims to survive a cache clear")
+	}
+}
+
+func TestHandleWebhookRequest_DuplicateDelivery(t *testing.T) {
+	recorder := metrics.NewMemoryRecorder()
+	app := &App{
+		Config: &config.Config{
+			PRComplianceEnabled: true,
+			PRMonitoredBranches: []string{"main"},
+		},
+		Logger:     slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		Metrics:    recorder,
+		deliveries: newDedupCache(10, time.Hour),
+	}
+
+	send := func(eventType, deliveryID, payload string) Response {
+		return app.HandleRequest(context.Background(), Request{
+			Type:   RequestTypeHTTP,
+			Method: "POST",
+			Path:   "/webhooks",
+			Headers: map[string]string{
+				"x-github-event":    eventType,
+				"x-github-delivery": deliveryID,
+			},
+			Body: []byte(payload),
+		})
+	}
+
+	prPayload := `{
+		"action": "closed",
+		"number": 1,
+		"pull_request": {"number": 1, "merged": false, "base": {"ref": "main"}},
+		"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}
+	}`
+	for range 2 {
+		if resp := send("pull_request", "delivery-1", prPayload); resp.StatusCode != 200 {
+			t.Fatalf("expected status 200, got %d", resp.StatusCode)
+		}
+	}
+
+	// failed deliveries are released so a redelivery is processed again
+	for range 2 {
+		if resp := send("bogus", "delivery-2", `{}`); resp.StatusCode != 500 {
+			t.Errorf("expected status 500 for reprocessed failure, got %d", resp.StatusCode)
+		}
+	}
+
+	counts := map[string]int64{}
+	for _, stats := range recorder.Snapshot().Webhooks {
+		counts[stats.EventType] += stats.Count
+	}
+	if counts["pull_request"] != 1 || counts["bogus"] != 2 {
+		t.Errorf("expected 1 pull_request and 2 bogus deliveries processed, got %v", counts)
+	}
+}
+
+func TestHandleWebhookRequest_SuccessResponse(t *testing.T) {
+	prPayload := `{
+		"action": "closed",
+		"number": 1,
+		"pull_request": {"number": 1, "merged": false, "base": {"ref": "main"}},
+		"repository": {"name": "repo", "full_name": "acme/repo", "owner": {"login": "acme"}}
+	}`
+	send := func(cfg *config.Config, deliveryID string) Response {
+		cfg.PRComplianceEnabled = true
+		cfg.PRMonitoredBranches = []string{"main"}
+		app := &App{
+			Config:     cfg,
+			Logger:     slog.New(slog.NewTextHandler(os.Stderr, nil)),
+			deliveries: newDedupCache(10, time.Hour),
+		}
+		return app.HandleRequest(context.Background(), Request{
+			Type:   RequestTypeHTTP,
+			Method: "POST",
+			Path:   "/webhooks",
+			Headers: map[string]string{
+				"x-github-event":    "pull_request",
+				"x-github-delivery": deliveryID,
+			},
+			Body: []byte(prPayload),
+		})
+	}
+
+	resp := send(&config.Config{}, "delivery-1")
+	if resp.StatusCode != 200 || string(resp.Body) != "ok" {
+		t.Errorf("expected default 200 ok, got %d %q", resp.StatusCode, resp.Body)
+	}
+
+	resp = send(&config.Config{WebhookSuccessStatus: 202, WebhookSuccessBody: "accepted"}, "delivery-1")
+	if resp.StatusCode != 202 || string(resp.Body) != "accepted" {
+		t.Errorf("expected 202 accepted, got %d %q", resp.StatusCode, resp.Body)
+	}
+
+	resp = send(&config.Config{WebhookSuccessStatus: 204, WebhookSuccessJSON: true}, "delivery-1")
+	if resp.StatusCode != 204 || len(resp.Body) != 0 {
+		t.Errorf("expected empty 204, got %d %q", resp.StatusCode, resp.Body)
+	}
+
+	resp = send(&config.Config{WebhookSuccessJSON: true}, "delivery-1")
+	if resp.StatusCode != 200 || resp.ContentType != "application/json" {
+		t.Fatalf("expected json 200, got %d %s", resp.StatusCode, resp.ContentType)
+	}
+	var ack struct {
+		Status     string         `json:"status"`
+		DeliveryID string         `json:"delivery_id"`
+		Result     *WebhookResult `json:"result"`
+	}
+	if err := json.Unmarshal(resp.Body, &ack); err != nil {
+		t.Fatalf("failed to decode ack: %v", err)
+	}
+	if ack.Status != "ok" || ack.DeliveryID != "delivery-1" || ack.Result == nil || ack.Result.Outcome != WebhookOutcomeSkipped {
+		t.Errorf("unexpected ack: %s", resp.Body)
+	}
+}
+
+func TestReportOktaSyncIncidents(t *testing.T) {
+	var events []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			EventAction string `json:"event_action"`
+			DedupKey    string `json:"dedup_key"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		events = append(events, event.EventAction+" "+strings.TrimPrefix(event.DedupKey, "github-ops-app/acme/"))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	app := &App{
+		Config:    &config.Config{GitHubOrg: "acme", PagerDutySafetyThresholdRuns: 2},
+		Logger:    slog.New(slog.NewTextHandler(os.Stderr, nil)),
+		PagerDuty: notifiers.NewPagerDutyNotifierWithURL("routing-key", "acme", srv.URL),
+		Store:     store.NewMemoryStore(),
+	}
+	ctx := context.Background()
+	blocked := &okta.SyncResult{Reports: []*okta.SyncReport{{GitHubTeam: "eng", SafetyThresholdBlocked: true}}}
+	clean := &okta.SyncResult{Reports: []*okta.SyncReport{{GitHubTeam: "eng"}}}
+
+	app.reportOktaSyncIncidents(ctx, clean, nil)
+	app.reportOktaSyncIncidents(ctx, nil, errors.New("all sync rules failed: 1 errors"))
+	app.reportOktaSyncIncidents(ctx, blocked, nil)
+
+	// the consecutive run count survives a restart through the store
+	app = &App{Config: app.Config, Logger: app.Logger, PagerDuty: app.PagerDuty, Store: app.Store}
+	app.reportOktaSyncIncidents(ctx, blocked, nil)
+	app.reportOktaSyncIncidents(ctx, clean, nil)
+	app.reportOktaSyncIncidents(ctx, clean, nil)
+
+	// only incidents that were triggered are resolved
+	want := []string{
+		"trigger okta-sync-failed",
+		"resolve okta-sync-failed",
+		"trigger okta-sync-safety-threshold",
+		"resolve okta-sync-safety-threshold",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+}
+
+func TestHandleConfigRequest_Patch(t *testing.T) {
+	app := &App{
+		Config: &config.Config{AdminToken: "secret", ReadOnlyToken: "read-only", LogLevel: slog.LevelWarn},
+		Logger: config.NewLogger(),
+	}
+	t.Cleanup(func() { config.SetLogLevel(slog.LevelInfo) })
+
+	patch := func(token, body string) Response {
+		return app.HandleRequest(context.Background(), Request{
+			Type:    RequestTypeHTTP,
+			Method:  "PATCH",
+			Path:    "/server/config",
+			Headers: map[string]string{"authorization": "Bearer " + token},
+			Body:    []byte(body),
+		})
+	}
+
+	if resp := patch("read-only", `{"debug_enabled": true}`); resp.StatusCode != 401 {
+		t.Errorf("expected 401 for read-only token, got %d", resp.StatusCode)
+	}
+	for _, body := range []string{`{"debug_enabled": true, "dry_run": true}`, `{"debug_enabled": "yes"}`, `{}`, `[]`} {
+		if resp := patch("secret", body); resp.StatusCode != 400 {
+			t.Errorf("expected 400 for body %s, got %d", body, resp.StatusCode)
+		}
+	}
+	if app.DebugEnabled() {
+		t.Fatal("expected rejected patches to leave debug logging off")
+	}
+
+	resp := patch("secret", `{"debug_enabled": true}`)
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	var redacted config.RedactedConfig
+	if err := json.Unmarshal(resp.Body, &redacted); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !redacted.DebugEnabled || !app.DebugEnabled() {
+		t.Error("expected debug logging enabled")
+	}
+	if !app.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("expected logger to emit debug records")
+	}
+
+	if resp := patch("secret", `{"debug_enabled": false}`); resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if app.Logger.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("expected configured warn level to be restored")
+	}
+}
+
+func TestHandleSlackInteractionRequest(t *testing.T) {
+	const secret = "signing-secret"
+	app := &App{
+		Config: &config.Config{SlackSigningSecret: secret},
+		Logger: slog.New(slog.DiscardHandler),
+	}
+
+	var clicked []string
+	app.HandleSlackAction("approve", func(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error {
+		clicked = append(clicked, callback.User.ID+":"+action.Value)
+		return nil
+	})
+
+	payload := `{"type":"block_actions","user":{"id":"U1"},"actions":[{"block_id":"b1","action_id":"approve","value":"req-1"},{"block_id":"b1","action_id":"unknown"}]}`
+	body := "payload=" + url.QueryEscape(payload)
+
+	send := func(body string, sent time.Time, sign bool) Response {
+		timestamp := fmt.Sprint(sent.Unix())
+		headers := map[string]string{"x-slack-request-timestamp": timestamp}
+		if sign {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write([]byte("v0:" + timestamp + ":" + body))
+			headers["x-slack-signature"] = "v0=" + hex.EncodeToString(mac.Sum(nil))
+		}
+		return app.HandleRequest(context.Background(), Request{
+			Type:    RequestTypeHTTP,
+			Method:  "POST",
+			Path:    "/slack/interactions",
+			Headers: headers,
+			Body:    []byte(body),
+		})
+	}
+
+	if resp := send(body, time.Now(), false); resp.StatusCode != 401 {
+		t.Errorf("expected 401 for unsigned request, got %d", resp.StatusCode)
+	}
+	if resp := send(body, time.Now().Add(-10*time.Minute), true); resp.StatusCode != 401 {
+		t.Errorf("expected 401 for replayed request, got %d", resp.StatusCode)
+	}
+	if resp := send("payload=not-json", time.Now(), true); resp.StatusCode != 400 {
+		t.Errorf("expected 400 for invalid payload, got %d", resp.StatusCode)
+	}
+	if len(clicked) != 0 {
+		t.Fatalf("expected no actions before a valid request, got %v", clicked)
+	}
+
+	if resp := send(body, time.Now(), true); resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+	if !slices.Equal(clicked, []string{"U1:req-1"}) {
+		t.Errorf("expected approve action dispatched once, got %v", clicked)
+	}
+
+	app.Config.SlackSigningSecret = ""
+	if resp := send(body, time.Now(), true); resp.StatusCode != 404 {
+		t.Errorf("expected 404 without a signing secret, got %d", resp.StatusCode)
+	}
+}
+
+// testPrivateKeyPEM returns a PEM-encoded RSA key for test clients.
+func testPrivateKeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// newTestGitHubClient returns a GitHub client for org "acme" backed by mux.
+// the installation token endpoint is added to mux.
+func newTestGitHubClient(t *testing.T, mux *http.ServeMux) *client.Client {
+	t.Helper()
+	mux.HandleFunc("POST /app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token":"test-token","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewAppClientWithBaseURL(1, 1, testPrivateKeyPEM(t), "acme", srv.URL+"/")
+	if err != nil {
+		t.Fatalf("failed to create github client: %v", err)
+	}
+	return c
+}
+
+// newTestOktaClient returns an Okta client backed by mux over TLS. the OAuth
+// token endpoint is added to mux.
+func newTestOktaClient(t *testing.T, mux *http.ServeMux) *okta.Client {
+	t.Helper()
+	mux.HandleFunc("POST /oauth2/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"token_type":"Bearer","expires_in":3600,"access_token":"test-token","scope":"okta.groups.read okta.users.read"}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	t.Cleanup(srv.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	ctx := context.WithValue(context.Background(), "okta_tls_cert_pool", pool)
+
+	c, err := okta.NewClientWithContext(ctx, &okta.ClientConfig{
+		ClientID:         "client-id",
+		PrivateKey:       testPrivateKeyPEM(t),
+		GitHubUserFields: []string{"githubUsername"},
+		BaseURL:          srv.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create okta client: %v", err)
+	}
+	return c
+}
+
+// newOktaGroupMux serves a single Okta group "eng" with id g1 whose members
+// are users, given as JSON objects.
+func newOktaGroupMux(users string) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/groups", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":"g1","type":"OKTA_GROUP","profile":{"name":"eng"}}]`)
+	})
+	mux.HandleFunc("GET /api/v1/groups/g1/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, users)
+	})
+	return mux
+}
+
+// newOktaTestApp returns an app with okta sync configured for a single rule
+// mapping Okta group "eng" to team "eng", with clients backed by the muxes.
+// the admin token is "secret".
+func newOktaTestApp(t *testing.T, oktaMux, githubMux *http.ServeMux) *App {
+	t.Helper()
+	return &App{
+		Config: &config.Config{
+			AdminToken:     "secret",
+			OktaDomain:     "example.okta.com",
+			OktaClientID:   "client-id",
+			OktaPrivateKey: []byte("key"),
+			OktaSyncRules:  []okta.SyncRule{{Name: "eng", OktaGroupName: "eng", GitHubTeamName: "eng"}},
+		},
+		Logger:       slog.New(slog.DiscardHandler),
+		OktaClient:   newTestOktaClient(t, oktaMux),
+		GitHubClient: newTestGitHubClient(t, githubMux),
+	}
+}
+
+// postScheduled triggers a scheduled action over HTTP with the admin token.
+func postScheduled(app *App, action string) Response {
+	return app.HandleRequest(context.Background(), Request{
+		Type:    RequestTypeHTTP,
+		Method:  "POST",
+		Path:    "/scheduled/" + action,
+		Headers: map[string]string{"authorization": "Bearer secret"},
+	})
+}
+
+func TestHandleScheduledHTTPRequest_MissingUsernamesReport(t *testing.T) {
+	oktaMux := newOktaGroupMux(`[
+		{"id":"u1","status":"ACTIVE","profile":{"email":"alice@example.com","githubUsername":"alice"}},
+		{"id":"u2","status":"ACTIVE","profile":{"email":"bob@example.com"}}
+	]`)
+	app := newOktaTestApp(t, oktaMux, http.NewServeMux())
+
+	resp := postScheduled(app, "missing-github-username")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	var body struct {
+		Report *okta.MissingUsernamesReport `json:"missing_github_username_report"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Report == nil {
+		t.Fatalf("expected missing_github_username_report in response, got %s", resp.Body)
+	}
+	if len(body.Report.Users) != 1 || body.Report.Users[0].Email != "bob@example.com" {
+		t.Errorf("expected bob reported, got %+v", body.Report.Users)
+	}
+}
+
+func TestHandleScheduledHTTPRequest_RuleDiagnostics(t *testing.T) {
+	oktaMux := newOktaGroupMux(`[{"id":"u1","status":"ACTIVE","profile":{"githubUsername":"alice"}}]`)
+	githubMux := http.NewServeMux()
+	githubMux.HandleFunc("GET /orgs/acme/teams/eng", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"name":"eng","slug":"eng"}`)
+	})
+	githubMux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"alice"}]`)
+	})
+	app := newOktaTestApp(t, oktaMux, githubMux)
+
+	resp := postScheduled(app, "diagnose-rules")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	var body struct {
+		Report *okta.RuleDiagnosticsReport `json:"rule_diagnostics"`
+	}
+	if err := json.Unmarshal(resp.Body, &body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Report == nil {
+		t.Fatalf("expected rule_diagnostics in response, got %s", resp.Body)
+	}
+	if len(body.Report.Rules) != 1 || body.Report.Rules[0].Status != okta.RuleStatusInSync {
+		t.Errorf("expected rule eng in sync, got %+v", body.Report.Rules)
+	}
+}
+
+func TestHandleScheduledHTTPRequest_DryRunMakesNoChanges(t *testing.T) {
+	oktaMux := newOktaGroupMux(`[{"id":"u1","status":"ACTIVE","profile":{"githubUsername":"alice"}}]`)
+
+	var mu sync.Mutex
+	var mutations []string
+	var listed bool
+	githubMux := http.NewServeMux()
+	githubMux.HandleFunc("GET /orgs/acme/teams/eng", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"name":"eng","slug":"eng"}`)
+	})
+	githubMux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		listed = true
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"bob"}]`)
+	})
+	githubMux.HandleFunc("GET /orgs/acme/memberships/{user}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"state":"active","role":"member"}`)
+	})
+	githubMux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			mutations = append(mutations, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+		}
+		http.NotFound(w, r)
+	})
+
+	app := newOktaTestApp(t, oktaMux, githubMux)
+	app.Config.DryRun = true
+	app.Config.OktaSyncSafetyThreshold = 1
+
+	resp := postScheduled(app, "okta-sync")
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !listed {
+		t.Fatal("expected the sync to read the team's members")
+	}
+	if len(mutations) != 0 {
+		t.Errorf("expected no mutating github calls, got %v", mutations)
+	}
+}
+
+func TestIsRulesetMonitoredBranch_LookupFailure(t *testing.T) {
+	githubMux := http.NewServeMux()
+	githubMux.HandleFunc("GET /repos/acme/repo/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+	})
+	ghClient := newTestGitHubClient(t, githubMux)
+	app := &App{
+		Config: &config.Config{PRComplianceEnabled: true, PRMonitorRulesetBranches: true},
+		Logger: slog.New(slog.DiscardHandler),
+	}
+
+	tests := []struct {
+		branch string
+		want   bool
+	}{
+		{branch: "trunk", want: true},
+		{branch: "refs/heads/trunk", want: true},
+		{branch: "release/1.0"},
+	}
+	for _, tt := range tests {
+		if got := app.isRulesetMonitoredBranch(context.Background(), ghClient, tt.branch, "acme", "repo", "trunk"); got != tt.want {
+			t.Errorf("isRulesetMonitoredBranch(%q) = %v, want %v", tt.branch, got, tt.want)
+		}
+	}
+}
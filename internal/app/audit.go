@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+	"github.com/google/go-github/v79/github"
+)
+
+// audit exclusion reasons explain why a repository was not audited.
+const (
+	auditExcludedArchived = "archived"
+	auditExcludedFork     = "fork"
+	auditExcludedIgnored  = "ignored"
+)
+
+// BranchProtectionAuditReport summarizes a branch protection audit sweep.
+// excluded counts are reported so the audit scope is transparent.
+type BranchProtectionAuditReport struct {
+	ReposScanned     int      `json:"repos_scanned"`
+	ReposExcluded    int      `json:"repos_excluded"`
+	ExcludedArchived int      `json:"excluded_archived"`
+	ExcludedForks    int      `json:"excluded_forks"`
+	ExcludedIgnored  int      `json:"excluded_ignored"`
+	Unprotected      []string `json:"unprotected"`
+	Errors           []string `json:"errors"`
+}
+
+// handleBranchProtectionAudit sweeps org repositories for default branches
+// without branch protection and logs the findings.
+func (a *App) handleBranchProtectionAudit(ctx context.Context) error {
+	report, err := a.auditBranchProtection(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range report.Unprotected {
+		a.Logger.Warn("default branch is not protected", slog.String("repo", repo))
+	}
+	for _, errMsg := range report.Errors {
+		a.Logger.Warn("branch protection audit error", slog.String("error", errMsg))
+	}
+
+	a.Logger.Info("branch protection audit completed",
+		slog.Int("repos_scanned", report.ReposScanned),
+		slog.Int("repos_excluded", report.ReposExcluded),
+		slog.Int("excluded_archived", report.ExcludedArchived),
+		slog.Int("excluded_forks", report.ExcludedForks),
+		slog.Int("excluded_ignored", report.ExcludedIgnored),
+		slog.Int("unprotected", len(report.Unprotected)))
+
+	return nil
+}
+
+// auditBranchProtection checks the default branch of every non-excluded org
+// repository for branch protection or ruleset pull request rules.
+func (a *App) auditBranchProtection(ctx context.Context) (*BranchProtectionAuditReport, error) {
+	if a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "github client")
+	}
+
+	repos, err := a.GitHubClient.ListOrgRepos(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list repositories for audit")
+	}
+
+	report := &BranchProtectionAuditReport{
+		Unprotected: []string{},
+		Errors:      []string{},
+	}
+
+	for _, repo := range repos {
+		switch a.auditExclusionReason(repo) {
+		case auditExcludedArchived:
+			report.ExcludedArchived++
+			report.ReposExcluded++
+			continue
+		case auditExcludedFork:
+			report.ExcludedForks++
+			report.ReposExcluded++
+			continue
+		case auditExcludedIgnored:
+			report.ExcludedIgnored++
+			report.ReposExcluded++
+			continue
+		}
+
+		report.ReposScanned++
+
+		branch := repo.GetDefaultBranch()
+		if branch == "" {
+			continue
+		}
+
+		owner := repo.GetOwner().GetLogin()
+		protected, err := a.GitHubClient.HasBranchProtection(ctx, owner, repo.GetName(), branch)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+			continue
+		}
+		if !protected {
+			report.Unprotected = append(report.Unprotected, fmt.Sprintf("%s@%s", repo.GetFullName(), branch))
+		}
+	}
+
+	return report, nil
+}
+
+// auditExclusionReason returns why a repository is excluded from the audit,
+// or an empty string if it should be audited. archived and forked repos are
+// skipped unless explicitly included.
+func (a *App) auditExclusionReason(repo *github.Repository) string {
+	if repo.GetArchived() && !a.Config.AuditIncludeArchived {
+		return auditExcludedArchived
+	}
+	if repo.GetFork() && !a.Config.AuditIncludeForks {
+		return auditExcludedFork
+	}
+	if a.Config.IsAuditIgnoredRepo(repo.GetFullName()) {
+		return auditExcludedIgnored
+	}
+	return ""
+}
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cruxstack/github-ops-app/internal/store"
+)
+
+// store key prefixes of shared dedup entries.
+const (
+	webhookDeliveryKeyPrefix = "webhook-delivery/"
+	branchDeletionKeyPrefix  = "branch-deletion/"
+)
+
+// dedupSet remembers recently seen keys, such as webhook delivery IDs, so
+// repeated work is skipped.
+type dedupSet interface {
+	// claim records key and returns false if it was already claimed within
+	// the ttl. empty keys are always claimed.
+	claim(ctx context.Context, key string, now time.Time) (bool, error)
+	// release forgets key so it can be claimed again.
+	release(ctx context.Context, key string) error
+	// clear forgets the keys held by this process.
+	clear()
+}
+
+// dedupCache is a bounded LRU of recently seen keys. it is per process, so
+// in Lambda it only catches repeats that reach the same warm container.
+type dedupCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+// dedupEntry is a key and when it was first claimed.
+type dedupEntry struct {
+	key string
+	at  time.Time
+}
+
+// newDedupCache creates a cache holding up to size keys for ttl. returns
+// nil, which disables deduplication, if size is not positive.
+func newDedupCache(size int, ttl time.Duration) *dedupCache {
+	if size <= 0 {
+		return nil
+	}
+	return &dedupCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// claim records a key and returns false if it was already claimed within the
+// ttl. never returns an error.
+func (c *dedupCache) claim(_ context.Context, key string, now time.Time) (bool, error) {
+	if c == nil || key == "" {
+		return true, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*dedupEntry)
+		if now.Sub(entry.at) < c.ttl {
+			c.order.MoveToFront(elem)
+			return false, nil
+		}
+		entry.at = now
+		c.order.MoveToFront(elem)
+		return true, nil
+	}
+
+	c.entries[key] = c.order.PushFront(&dedupEntry{key: key, at: now})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*dedupEntry).key)
+	}
+	return true, nil
+}
+
+// release forgets a key so a redelivery of a webhook that failed is
+// processed again.
+func (c *dedupCache) release(_ context.Context, key string) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+	}
+	return nil
+}
+
+// clear forgets all keys.
+func (c *dedupCache) clear() {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
+
+// storeDedup keeps claimed keys in a shared store so every instance of the
+// app, such as concurrent Lambda containers, sees the same claims.
+type storeDedup struct {
+	store  store.Store
+	prefix string
+	ttl    time.Duration
+}
+
+// claim stores the key if it is absent. the entry expires after the ttl.
+func (d *storeDedup) claim(ctx context.Context, key string, now time.Time) (bool, error) {
+	if key == "" {
+		return true, nil
+	}
+	return d.store.PutIfAbsent(ctx, d.prefix+key, []byte(now.UTC().Format(time.RFC3339)), d.ttl)
+}
+
+// release deletes the key from the store.
+func (d *storeDedup) release(ctx context.Context, key string) error {
+	if key == "" {
+		return nil
+	}
+	return d.store.Delete(ctx, d.prefix+key)
+}
+
+// clear does nothing. shared entries are left to expire so clearing one
+// instance's caches does not reprocess work another instance handled.
+func (d *storeDedup) clear() {}
@@ -1,42 +1,96 @@
 package app
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/config"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/client"
 	"github.com/cruxstack/github-ops-app/internal/github/webhooks"
+	"github.com/cruxstack/github-ops-app/internal/metrics"
+	"github.com/cruxstack/github-ops-app/internal/notifiers"
 	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/store"
+	"github.com/cruxstack/github-ops-app/internal/types"
 )
 
+// oktaSyncData is the optional payload of an okta-sync scheduled event.
+type oktaSyncData struct {
+	// Rules restricts the sync to the named rules. empty syncs all rules.
+	Rules []string `json:"rules"`
+}
+
 // handleOktaSync executes Okta group synchronization to GitHub teams.
-// sends Slack notification with sync results if configured.
-func (a *App) handleOktaSync(ctx context.Context) error {
+// sends Slack notification with sync results if configured. data may name
+// a subset of rules to sync, e.g. {"rules": ["engineering-team"]}; such a
+// partial sync skips orphaned user detection and is not recorded as a full
+// sync in the metrics or throttle window. returns nil result when the sync
+// was skipped.
+func (a *App) handleOktaSync(ctx context.Context, data json.RawMessage) (*okta.SyncResult, error) {
 	if !a.Config.IsOktaSyncEnabled() {
 		a.Logger.Info("okta sync is not enabled, skipping")
-		return nil
+		return nil, nil
 	}
 
 	if a.OktaClient == nil || a.GitHubClient == nil {
-		return errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
 	}
 
-	syncer := okta.NewSyncer(a.OktaClient, a.GitHubClient, a.Config.OktaSyncRules, a.Config.OktaSyncSafetyThreshold, a.Logger)
-	syncResult, err := syncer.Sync(ctx)
+	rules, partial, err := selectSyncRules(a.Config.OktaSyncRules, data)
 	if err != nil {
-		return errors.Wrap(err, "okta sync failed")
+		return nil, err
 	}
 
-	a.Logger.Info("okta sync completed", slog.Int("report_count", len(syncResult.Reports)))
+	release, acquired, err := a.acquireOktaSyncLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		a.Logger.Info("okta sync already in progress, coalescing trigger")
+		return nil, nil
+	}
+	defer release()
 
-	if a.Notifier != nil {
-		if err := a.Notifier.NotifyOktaSync(ctx, syncResult.Reports, a.Config.GitHubOrg); err != nil {
-			a.Logger.Warn("failed to send slack notification", slog.String("error", err.Error()))
+	// group the notifications of this run into one slack thread
+	ctx = notifiers.WithSyncThread(ctx)
+
+	syncer := okta.NewSyncer(a.OktaClient, a.GitHubClient, rules, a.oktaSyncOptions(), a.Logger)
+	syncer.SetConnections(a.OktaConnections)
+	syncResult, err := syncer.Sync(ctx)
+	if err != nil {
+		if !partial {
+			a.observeOktaSync(nil, 0)
+			a.reportOktaSyncIncidents(ctx, nil, err)
 		}
+		return nil, errors.Wrap(err, "okta sync failed")
 	}
 
+	if partial {
+		a.Logger.Info("okta sync of selected rules completed",
+			slog.Int("rule_count", len(rules)),
+			slog.Int("report_count", len(syncResult.Reports)))
+		a.compareWithLastSync(ctx, syncResult, true)
+		a.notifyOktaSync(ctx, syncResult)
+		return syncResult, nil
+	}
+	a.markOktaSynced(ctx)
+
+	a.Logger.Info("okta sync completed", slog.Int("report_count", len(syncResult.Reports)))
+
+	a.compareWithLastSync(ctx, syncResult, false)
+	a.notifyOktaSync(ctx, syncResult)
+	a.reportOktaSyncIncidents(ctx, syncResult, nil)
+
+	orphanedCount := 0
 	if a.Config.OktaOrphanedUserNotifications {
 		syncedTeams := make([]string, 0, len(syncResult.Reports))
 		for _, report := range syncResult.Reports {
@@ -44,12 +98,17 @@ func (a *App) handleOktaSync(ctx context.Context) error {
 		}
 
 		orphanedReport, err := syncer.DetectOrphanedUsers(ctx, syncedTeams)
+		if orphanedReport != nil {
+			a.recordOrphanedUsers(ctx, orphanedReport)
+		}
+
 		if err != nil {
 			a.Logger.Warn("failed to detect orphaned users", slog.String("error", err.Error()))
 		} else if orphanedReport != nil && len(orphanedReport.OrphanedUsers) > 0 {
-			a.Logger.Info("orphaned users detected", slog.Int("count", len(orphanedReport.OrphanedUsers)))
+			orphanedCount = len(orphanedReport.OrphanedUsers)
+			a.Logger.Info("orphaned users detected", slog.Int("count", orphanedCount))
 
-			if a.Notifier != nil {
+			if a.Notifier != nil && !a.skipForDryRun("orphaned users notification") {
 				if err := a.Notifier.NotifyOrphanedUsers(ctx, orphanedReport); err != nil {
 					a.Logger.Warn("failed to send orphaned users notification", slog.String("error", err.Error()))
 				}
@@ -57,95 +116,1087 @@ func (a *App) handleOktaSync(ctx context.Context) error {
 		}
 	}
 
-	return nil
+	a.observeOktaSync(syncResult, orphanedCount)
+
+	return syncResult, nil
+}
+
+// selectSyncRules returns the rules named by an okta-sync event's data, in
+// their configured order, and true when the data restricts the sync to
+// them. names match case-insensitively. empty data or an empty rule list
+// selects every rule. an unknown name is an error so a typo in a schedule
+// does not silently sync nothing.
+func selectSyncRules(rules []types.SyncRule, data json.RawMessage) ([]types.SyncRule, bool, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return rules, false, nil
+	}
+
+	var parsed oktaSyncData
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, false, errors.Wrap(err, "invalid okta-sync data")
+	}
+	if len(parsed.Rules) == 0 {
+		return rules, false, nil
+	}
+
+	requested := make(map[string]bool, len(parsed.Rules))
+	for _, name := range parsed.Rules {
+		known := slices.ContainsFunc(rules, func(rule types.SyncRule) bool {
+			return strings.EqualFold(rule.GetName(), name)
+		})
+		if !known {
+			return nil, false, errors.Newf("unknown sync rule '%s' in okta-sync data", name)
+		}
+		requested[strings.ToLower(name)] = true
+	}
+
+	var selected []types.SyncRule
+	for _, rule := range rules {
+		if requested[strings.ToLower(rule.GetName())] {
+			selected = append(selected, rule)
+		}
+	}
+	return selected, true, nil
+}
+
+// observeOktaSync records a full Okta sync run in the app metrics. a nil
+// syncResult records a run that failed before producing reports. a rule
+// fails if any of its reports has errors.
+func (a *App) observeOktaSync(syncResult *okta.SyncResult, orphanedUsers int) {
+	if a.Metrics == nil {
+		return
+	}
+	if syncResult == nil {
+		a.Metrics.ObserveOktaSync(metrics.OktaSyncRun{Failed: true})
+		return
+	}
+
+	run := metrics.OktaSyncRun{
+		OrphanedUsers:  orphanedUsers,
+		Rules:          make(map[string]bool),
+		MembersSkipped: make(map[string]int, len(syncResult.Skipped)),
+	}
+	for _, skipped := range syncResult.Skipped {
+		run.MembersSkipped[skipped.Reason] = skipped.Count
+	}
+	for _, report := range syncResult.Reports {
+		run.MembersAdded += len(report.MembersAdded)
+		run.MembersRemoved += len(report.MembersRemoved)
+		if _, seen := run.Rules[report.Rule]; !seen {
+			run.Rules[report.Rule] = true
+		}
+		if report.HasErrors() {
+			run.Rules[report.Rule] = false
+		}
+	}
+	a.Metrics.ObserveOktaSync(run)
+}
+
+// handleTeamOktaSync resyncs only the rules that manage a single GitHub
+// team. orphaned user detection is skipped since other teams are not synced.
+func (a *App) handleTeamOktaSync(ctx context.Context, teamSlug string) (*okta.SyncResult, error) {
+	if a.OktaClient == nil || a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+	}
+
+	release, acquired, err := a.acquireOktaSyncLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		a.Logger.Info("okta sync already in progress, coalescing team sync trigger",
+			slog.String("team", teamSlug))
+		return nil, nil
+	}
+	defer release()
+
+	ctx = notifiers.WithSyncThread(ctx)
+
+	syncer := a.newOktaSyncer()
+	syncResult, err := syncer.SyncTeam(ctx, teamSlug)
+	if err != nil {
+		return nil, errors.Wrapf(err, "okta sync for team '%s' failed", teamSlug)
+	}
+
+	if len(syncResult.Reports) == 0 {
+		if a.DebugEnabled() {
+			a.Logger.Debug("team not managed by any sync rule, skipping", slog.String("team", teamSlug))
+		}
+		return syncResult, nil
+	}
+
+	a.Logger.Info("okta team sync completed",
+		slog.String("team", teamSlug),
+		slog.Int("report_count", len(syncResult.Reports)))
+
+	a.compareWithLastSync(ctx, syncResult, true)
+	a.notifyOktaSync(ctx, syncResult)
+
+	return syncResult, nil
+}
+
+// handleOktaSyncDryRun runs a full okta sync that reports planned team
+// changes without applying any of them, regardless of per-rule dry_run
+// settings. the last sync baseline and throttle state are left untouched and
+// orphaned user detection is skipped.
+func (a *App) handleOktaSyncDryRun(ctx context.Context) (*okta.SyncResult, error) {
+	if a.OktaClient == nil || a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+	}
+
+	opts := a.oktaSyncOptions()
+	opts.DryRun = true
+	syncer := okta.NewSyncer(a.OktaClient, a.GitHubClient, a.Config.OktaSyncRules, opts, a.Logger)
+	syncer.SetConnections(a.OktaConnections)
+
+	syncResult, err := syncer.Sync(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "okta sync dry run failed")
+	}
+
+	a.Logger.Info("okta sync dry run completed", slog.Int("report_count", len(syncResult.Reports)))
+
+	a.notifyOktaSync(ctx, syncResult)
+
+	return syncResult, nil
+}
+
+// handleOrphanedUsersReport detects orphaned users on demand without
+// syncing or notifying. the synced teams are computed from the rules, so it
+// works before any sync has run. the orphaned user history is not updated.
+func (a *App) handleOrphanedUsersReport(ctx context.Context) (*okta.OrphanedUsersReport, error) {
+	if a.OktaClient == nil || a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+	}
+
+	syncer := a.newOktaSyncer()
+	syncedTeams, err := syncer.RuleTeams()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve sync rule teams")
+	}
+
+	report, err := syncer.DetectOrphanedUsers(ctx, syncedTeams)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect orphaned users")
+	}
+
+	a.Logger.Info("orphaned users report completed",
+		slog.Int("team_count", len(syncedTeams)),
+		slog.Int("orphaned_count", len(report.OrphanedUsers)))
+	return report, nil
+}
+
+// handleMissingUsernamesReport lists active Okta users in the groups of all
+// enabled rules whose profile has no GitHub username, and posts the list to
+// Slack when APP_OKTA_NOTIFY_MISSING_USERNAMES is enabled.
+func (a *App) handleMissingUsernamesReport(ctx context.Context) (*okta.MissingUsernamesReport, error) {
+	if a.OktaClient == nil || a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+	}
+
+	report, err := a.newOktaSyncer().DetectMissingUsernames(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect users without a github username")
+	}
+
+	a.Logger.Info("missing github username report completed",
+		slog.Int("user_count", len(report.Users)),
+		slog.Int("error_count", len(report.Errors)))
+
+	if a.Config.OktaNotifyMissingUsernames && a.Notifier != nil && len(report.Users) > 0 {
+		if err := a.Notifier.NotifyMissingUsernames(ctx, report); err != nil {
+			a.Logger.Warn("failed to send missing github username notification", slog.String("error", err.Error()))
+		}
+	}
+
+	return report, nil
+}
+
+// handleRuleDiagnostics checks the GitHub team of every enabled rule without
+// syncing. rules whose team no longer exists are logged as warnings so a team
+// deleted by hand is not recreated by the next sync unnoticed.
+func (a *App) handleRuleDiagnostics(ctx context.Context) (*okta.RuleDiagnosticsReport, error) {
+	if a.OktaClient == nil || a.GitHubClient == nil {
+		return nil, errors.Wrap(internalerrors.ErrClientNotInit, "okta or github client")
+	}
+
+	report, err := a.newOktaSyncer().DiagnoseRules(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to diagnose sync rules")
+	}
+
+	for _, diagnosis := range report.Rules {
+		switch diagnosis.Status {
+		case okta.RuleStatusWouldCreate, okta.RuleStatusTeamNotFound:
+			a.Logger.Warn("sync rule targets a github team that does not exist",
+				slog.String("rule", diagnosis.Rule),
+				slog.String("team", diagnosis.GitHubTeam),
+				slog.String("status", diagnosis.Status))
+		case okta.RuleStatusError:
+			a.Logger.Warn("failed to diagnose sync rule",
+				slog.String("rule", diagnosis.Rule),
+				slog.String("error", diagnosis.Error))
+		}
+	}
+
+	a.Logger.Info("sync rule diagnostics completed",
+		slog.Int("in_sync_count", report.Count(okta.RuleStatusInSync)),
+		slog.Int("out_of_sync_count", report.Count(okta.RuleStatusOutOfSync)),
+		slog.Int("would_create_count", report.Count(okta.RuleStatusWouldCreate)),
+		slog.Int("team_not_found_count", report.Count(okta.RuleStatusTeamNotFound)),
+		slog.Int("error_count", report.Count(okta.RuleStatusError)))
+	return report, nil
+}
+
+// newOktaSyncer creates a syncer using the configured rules and options.
+func (a *App) newOktaSyncer() *okta.Syncer {
+	syncer := okta.NewSyncer(a.OktaClient, a.GitHubClient, a.Config.OktaSyncRules, a.oktaSyncOptions(), a.Logger)
+	syncer.SetConnections(a.OktaConnections)
+	return syncer
+}
+
+// oktaSyncOptions returns the syncer options from the app configuration.
+func (a *App) oktaSyncOptions() okta.SyncOptions {
+	return okta.SyncOptions{
+		SafetyThreshold: a.Config.OktaSyncSafetyThreshold,
+		OrgMembersOnly:  a.Config.OktaSyncOrgMembersOnly,
+		ADUseCommonName: a.Config.OktaADGroupUseCN,
+		Parallelism:     a.Config.OktaSyncParallelism,
+		DryRun:          a.Config.DryRun,
+
+		ResolveUsernamesViaSCIM: a.Config.OktaResolveUsernamesViaSCIM,
+		VerifyMembership:        a.Config.OktaSyncVerifyMembership,
+		PendingInvitesAsMembers: a.Config.OktaSyncPendingInvites,
+		RemoveMembersFirst:      a.Config.OktaSyncMemberOrder == config.MemberOrderRemoveFirst,
+		OrphanExemptTeams:       a.Config.OrphanedUserExemptTeams,
+		OrphanExemptLogins:      a.Config.OrphanedUserExemptLogins,
+	}
+}
+
+// oktaSyncLockKey is the lock name shared by full and team-scoped syncs.
+const oktaSyncLockKey = "okta-sync"
+
+// acquireOktaSyncLock prevents overlapping okta syncs. waits for a running
+// sync or reports it as not acquired, depending on the configured
+// concurrency mode. no locking is done when no locker is configured.
+func (a *App) acquireOktaSyncLock(ctx context.Context) (func(), bool, error) {
+	if a.Locker == nil {
+		return func() {}, true, nil
+	}
+
+	if a.Config.OktaSyncConcurrency == config.SyncConcurrencyCoalesce {
+		release, acquired, err := a.Locker.TryLock(ctx, oktaSyncLockKey)
+		if err != nil {
+			return nil, false, errors.Wrap(err, "failed to acquire okta sync lock")
+		}
+		return release, acquired, nil
+	}
+
+	release, err := a.Locker.Lock(ctx, oktaSyncLockKey)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "failed to acquire okta sync lock")
+	}
+	return release, true, nil
+}
+
+// recordOrphanedUsers stores an orphaned user detection result in the
+// history store if configured. failures are logged only.
+func (a *App) recordOrphanedUsers(ctx context.Context, report *okta.OrphanedUsersReport) {
+	if a.OrphanHistory == nil {
+		return
+	}
+
+	snapshot := store.OrphanedUsersSnapshot{
+		Timestamp: time.Now().UTC(),
+		Count:     len(report.OrphanedUsers),
+		Users:     report.OrphanedUsers,
+	}
+	if snapshot.Users == nil {
+		snapshot.Users = []string{}
+	}
+
+	if err := a.OrphanHistory.Record(ctx, snapshot); err != nil {
+		a.Logger.Warn("failed to record orphaned users history", slog.String("error", err.Error()))
+	}
+}
+
+// compareWithLastSync annotates each report with the trend since the
+// previous sync of its team, then stores this run's changes for the next
+// comparison. partial keeps stored teams that were not synced this run.
+// failures are logged only.
+func (a *App) compareWithLastSync(ctx context.Context, syncResult *okta.SyncResult, partial bool) {
+	if a.LastSync == nil {
+		return
+	}
+
+	previous, err := a.LastSync.Get(ctx)
+	if err != nil {
+		a.Logger.Warn("failed to load last sync snapshot", slog.String("error", err.Error()))
+		return
+	}
+
+	snapshot := store.SyncSnapshot{
+		Timestamp: time.Now().UTC(),
+		Teams:     map[string]store.TeamSyncChanges{},
+	}
+	if partial && previous != nil {
+		maps.Copy(snapshot.Teams, previous.Teams)
+	}
+
+	// skip counts of a partial sync cover only some rules, so they are
+	// neither compared nor kept as the baseline
+	if partial {
+		if previous != nil {
+			snapshot.Skipped = previous.Skipped
+		}
+	} else {
+		snapshot.Skipped = make(map[string]int, len(syncResult.Skipped))
+		for i, skipped := range syncResult.Skipped {
+			snapshot.Skipped[skipped.Reason] = skipped.Count
+			if previous == nil {
+				continue
+			}
+			if previousCount, ok := previous.Skipped[skipped.Reason]; ok {
+				syncResult.Skipped[i].Previous = &previousCount
+			}
+		}
+	}
+
+	applied := false
+	for _, report := range syncResult.Reports {
+		if report.GitHubTeam == "" {
+			continue
+		}
+		previousChanges, hasPrevious := store.TeamSyncChanges{}, false
+		if previous != nil {
+			previousChanges, hasPrevious = previous.Teams[report.GitHubTeam]
+		}
+		if hasPrevious {
+			report.CompareWithPrevious(previousChanges.Added, previousChanges.Removed)
+		}
+
+		// dry-run changes were not applied so must not become the baseline
+		if report.DryRun {
+			if hasPrevious {
+				snapshot.Teams[report.GitHubTeam] = previousChanges
+			}
+			continue
+		}
+		applied = true
+		snapshot.Teams[report.GitHubTeam] = store.TeamSyncChanges{
+			Added:   report.MembersAdded,
+			Removed: report.MembersRemoved,
+		}
+	}
+
+	if !applied && a.skipForDryRun("last sync snapshot update") {
+		return
+	}
+	if err := a.LastSync.Put(ctx, snapshot); err != nil {
+		a.Logger.Warn("failed to store last sync snapshot", slog.String("error", err.Error()))
+	}
+}
+
+// notifyOktaSync sends the sync report to Slack if configured.
+func (a *App) notifyOktaSync(ctx context.Context, syncResult *okta.SyncResult) {
+	notified := *syncResult
+	notified.DisabledRules = nil
+	if a.Config.OktaSyncNotifyDisabledRules && len(syncResult.DisabledRules) > 0 {
+		notified.DisabledRules = syncResult.DisabledRules
+		a.Logger.Info("okta sync rules skipped because they are disabled",
+			slog.String("rules", strings.Join(notified.DisabledRules, ",")))
+	}
+
+	a.logAPIQuotas(syncResult.APIQuotas)
+
+	if a.Notifier != nil && !a.skipForDryRun("okta sync notification") {
+		if err := a.Notifier.NotifyOktaSync(ctx, &notified, a.Config.GitHubOrg); err != nil {
+			a.Logger.Warn("failed to send slack notification", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// logAPIQuotas logs the API rate limits remaining after a sync, warning when
+// a quota is running low so schedules can be adjusted.
+func (a *App) logAPIQuotas(quotas []types.APIQuota) {
+	for _, q := range quotas {
+		attrs := []any{
+			slog.String("service", q.Service),
+			slog.Int("remaining", q.Remaining),
+			slog.Int("limit", q.Limit),
+			slog.Duration("resets_in", q.ResetsIn()),
+		}
+		if q.IsLow() {
+			a.Logger.Warn("okta sync api quota is running low", attrs...)
+		} else {
+			a.Logger.Info("okta sync api quota", attrs...)
+		}
+	}
+}
+
+// skipForDryRun logs and returns true when dry-run mode is enabled so the
+// caller skips a side effect such as a notification or comment.
+func (a *App) skipForDryRun(action string, attrs ...any) bool {
+	if !a.Config.DryRun {
+		return false
+	}
+	a.Logger.Info("dry run: skipping "+action, attrs...)
+	return true
+}
+
+// oktaSyncIncidentsKey is the Store key holding the PagerDuty incident
+// state of full okta syncs.
+const oktaSyncIncidentsKey = "okta-sync/incidents"
+
+// oktaSyncIncidents is the PagerDuty incident state carried between full
+// okta syncs. it lives in the Store so consecutive runs are counted across
+// Lambda cold starts and instances.
+type oktaSyncIncidents struct {
+	// SyncFailedOpen is true while a sync failure incident is triggered.
+	SyncFailedOpen bool `json:"sync_failed_open"`
+	// SafetyBlockedRuns counts consecutive full okta syncs on which the
+	// safety threshold blocked removals.
+	SafetyBlockedRuns int `json:"safety_blocked_runs"`
+	// SafetyBlockedOpen is true while a safety threshold incident is
+	// triggered.
+	SafetyBlockedOpen bool `json:"safety_blocked_open"`
+}
+
+// reportOktaSyncIncidents triggers or resolves the PagerDuty incidents of a
+// full okta sync. a failed sync triggers an incident that the next
+// successful sync resolves. the safety threshold incident is triggered once
+// removals have been blocked on APP_PAGERDUTY_SAFETY_THRESHOLD_RUNS
+// consecutive syncs and resolved by a sync that blocks none. incidents are
+// only resolved if they were triggered.
+func (a *App) reportOktaSyncIncidents(ctx context.Context, syncResult *okta.SyncResult, syncErr error) {
+	if a.PagerDuty == nil {
+		return
+	}
+
+	a.incidentsMu.Lock()
+	defer a.incidentsMu.Unlock()
+
+	state := a.loadOktaSyncIncidents(ctx)
+	defer a.saveOktaSyncIncidents(ctx, state)
+
+	if syncErr != nil {
+		if a.triggerIncident(ctx, notifiers.Incident{
+			Type:    notifiers.IncidentOktaSyncFailed,
+			Summary: "okta sync failed for " + a.Config.GitHubOrg,
+			Details: map[string]any{"error": syncErr.Error()},
+		}) {
+			state.SyncFailedOpen = true
+		}
+		return
+	}
+	if state.SyncFailedOpen && a.resolveIncident(ctx, notifiers.IncidentOktaSyncFailed) {
+		state.SyncFailedOpen = false
+	}
+
+	var blockedTeams []string
+	for _, report := range syncResult.Reports {
+		if report.SafetyThresholdBlocked {
+			blockedTeams = append(blockedTeams, report.GitHubTeam)
+		}
+	}
+
+	if len(blockedTeams) == 0 {
+		state.SafetyBlockedRuns = 0
+		if state.SafetyBlockedOpen && a.resolveIncident(ctx, notifiers.IncidentSafetyThresholdBlocked) {
+			state.SafetyBlockedOpen = false
+		}
+		return
+	}
+
+	state.SafetyBlockedRuns++
+	runs := state.SafetyBlockedRuns
+	threshold := a.Config.PagerDutySafetyThresholdRuns
+	if threshold > 0 && runs >= threshold {
+		if a.triggerIncident(ctx, notifiers.Incident{
+			Type: notifiers.IncidentSafetyThresholdBlocked,
+			Summary: fmt.Sprintf("okta sync safety threshold blocked removals on %d consecutive syncs for %s",
+				runs, a.Config.GitHubOrg),
+			Details: map[string]any{
+				"consecutive_runs": runs,
+				"blocked_teams":    blockedTeams,
+				"safety_threshold": a.Config.OktaSyncSafetyThreshold,
+			},
+		}) {
+			state.SafetyBlockedOpen = true
+		}
+	}
+}
+
+// loadOktaSyncIncidents reads the incident state from the store. a missing
+// or unreadable state is logged and treated as no open incidents.
+func (a *App) loadOktaSyncIncidents(ctx context.Context) *oktaSyncIncidents {
+	state := &oktaSyncIncidents{}
+	data, found, err := a.Store.Get(ctx, oktaSyncIncidentsKey)
+	if err != nil {
+		a.Logger.Warn("failed to load okta sync incident state", slog.String("error", err.Error()))
+		return state
+	}
+	if !found {
+		return state
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		a.Logger.Warn("failed to decode okta sync incident state", slog.String("error", err.Error()))
+		return &oktaSyncIncidents{}
+	}
+	return state
+}
+
+// saveOktaSyncIncidents writes the incident state to the store, logging
+// failures.
+func (a *App) saveOktaSyncIncidents(ctx context.Context, state *oktaSyncIncidents) {
+	data, err := json.Marshal(state)
+	if err == nil {
+		err = a.Store.Set(ctx, oktaSyncIncidentsKey, data, 0)
+	}
+	if err != nil {
+		a.Logger.Warn("failed to save okta sync incident state", slog.String("error", err.Error()))
+	}
+}
+
+// triggerIncident raises incident with PagerDuty, logging failures. returns
+// true if the incident was triggered.
+func (a *App) triggerIncident(ctx context.Context, incident notifiers.Incident) bool {
+	if a.skipForDryRun("pagerduty incident", slog.String("type", incident.Type)) {
+		return false
+	}
+	if err := a.PagerDuty.Trigger(ctx, incident); err != nil {
+		a.Logger.Warn("failed to trigger pagerduty incident",
+			slog.String("type", incident.Type),
+			slog.String("error", err.Error()))
+		return false
+	}
+	a.Logger.Info("triggered pagerduty incident", slog.String("type", incident.Type))
+	return true
+}
+
+// resolveIncident resolves the PagerDuty incident of incidentType, logging
+// failures. returns true if the incident was resolved.
+func (a *App) resolveIncident(ctx context.Context, incidentType string) bool {
+	if a.skipForDryRun("pagerduty resolve", slog.String("type", incidentType)) {
+		return false
+	}
+	if err := a.PagerDuty.Resolve(ctx, incidentType); err != nil {
+		a.Logger.Warn("failed to resolve pagerduty incident",
+			slog.String("type", incidentType),
+			slog.String("error", err.Error()))
+		return false
+	}
+	return true
+}
+
+// lastOktaSyncAtKey is the Store key holding the completion time of the last
+// full okta sync.
+const lastOktaSyncAtKey = "okta-sync/last-synced-at"
+
+// markOktaSynced records the completion time of a full okta sync in the
+// store, logging failures.
+func (a *App) markOktaSynced(ctx context.Context) {
+	if a.Store == nil {
+		return
+	}
+	at := []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	if err := a.Store.Set(ctx, lastOktaSyncAtKey, at, 0); err != nil {
+		a.Logger.Warn("failed to record okta sync time", slog.String("error", err.Error()))
+	}
+}
+
+// oktaSyncedWithin returns true if a full okta sync completed within the
+// given window. an unreadable sync time is logged and treated as no recent
+// sync, so the caller syncs.
+func (a *App) oktaSyncedWithin(ctx context.Context, window time.Duration) bool {
+	if a.Store == nil {
+		return false
+	}
+	data, found, err := a.Store.Get(ctx, lastOktaSyncAtKey)
+	if err != nil {
+		a.Logger.Warn("failed to read okta sync time", slog.String("error", err.Error()))
+		return false
+	}
+	if !found {
+		return false
+	}
+	at, err := time.Parse(time.RFC3339Nano, string(data))
+	if err != nil {
+		a.Logger.Warn("failed to parse okta sync time", slog.String("error", err.Error()))
+		return false
+	}
+	return time.Since(at) < window
 }
 
 // handlePullRequestWebhook processes GitHub pull request webhook events.
-// checks merged PRs for branch protection compliance violations.
-func (a *App) handlePullRequestWebhook(ctx context.Context, payload []byte) error {
+// checks merged PRs for branch protection compliance violations. reopened
+// and base-changing edited events re-evaluate merged PRs when enabled via
+// APP_PR_COMPLIANCE_ACTIONS.
+func (a *App) handlePullRequestWebhook(ctx context.Context, payload []byte, whResult *WebhookResult) error {
 	prEvent, err := webhooks.ParsePullRequestEvent(payload)
 	if err != nil {
 		return err
 	}
+	whResult.Action = prEvent.Action
 
-	if !prEvent.IsMerged() {
-		if a.Config.DebugEnabled {
-			a.Logger.Debug("pr not merged, skipping", slog.Int("pr_number", prEvent.Number))
+	if !a.Config.ShouldHandlePRAction(prEvent.Action) {
+		whResult.skip("pr action not monitored")
+		if a.DebugEnabled() {
+			a.Logger.Debug("pr action not monitored, skipping",
+				slog.Int("pr_number", prEvent.Number),
+				slog.String("action", prEvent.Action))
 		}
 		return nil
 	}
 
-	baseBranch := prEvent.GetBaseBranch()
-	if !a.Config.ShouldMonitorBranch(baseBranch) {
-		if a.Config.DebugEnabled {
-			a.Logger.Debug("branch not monitored, skipping", slog.String("branch", baseBranch))
+	if prEvent.Action == config.PRActionEdited && !prEvent.BaseChanged() {
+		whResult.skip("pr base branch unchanged")
+		if a.DebugEnabled() {
+			a.Logger.Debug("pr edited without base branch change, skipping", slog.Int("pr_number", prEvent.Number))
+		}
+		return nil
+	}
+
+	if !prEvent.WasMerged() {
+		whResult.skip("pr not merged")
+		if a.DebugEnabled() {
+			a.Logger.Debug("pr not merged, skipping", slog.Int("pr_number", prEvent.Number))
 		}
 		return nil
 	}
 
-	ghClient := a.GitHubClient
+	if prEvent.Action != config.PRActionClosed {
+		a.Logger.Info("re-evaluating merged pr compliance",
+			slog.Int("pr_number", prEvent.Number),
+			slog.String("action", prEvent.Action),
+			slog.String("previous_base", prEvent.GetPreviousBaseBranch()))
+	}
 
-	if prEvent.GetInstallationID() != 0 && prEvent.GetInstallationID() != a.Config.GitHubInstallationID {
-		installClient, err := client.NewAppClientWithBaseURL(
-			a.Config.GitHubAppID,
-			prEvent.GetInstallationID(),
-			a.Config.GitHubAppPrivateKey,
-			a.Config.GitHubOrg,
-			a.Config.GitHubBaseURL,
-		)
-		if err != nil {
-			return errors.Wrapf(err, "failed to create client for installation %d", prEvent.GetInstallationID())
+	baseBranch := prEvent.GetBaseBranch()
+	repoFullName := prEvent.GetRepoFullName()
+	monitored := a.Config.ShouldMonitorRepoBranch(repoFullName, baseBranch)
+	if !monitored && !(a.Config.PRMonitorRulesetBranches && a.Config.IsPRComplianceEnabled()) {
+		whResult.skip("branch not monitored")
+		if a.DebugEnabled() {
+			a.Logger.Debug("branch not monitored, skipping", slog.String("branch", baseBranch))
 		}
-		ghClient = installClient
+		return nil
 	}
 
-	if ghClient == nil {
-		return errors.Wrap(internalerrors.ErrClientNotInit, "github client")
+	ghClient, err := a.githubClientForInstallation(prEvent.GetInstallationID())
+	if err != nil {
+		return err
 	}
 
 	owner := prEvent.GetRepoOwner()
 	repo := prEvent.GetRepoName()
 
-	result, err := ghClient.CheckPRCompliance(ctx, owner, repo, prEvent.Number)
+	if !monitored {
+		if !a.isRulesetMonitoredBranch(ctx, ghClient, baseBranch, owner, repo, prEvent.GetDefaultBranch()) {
+			whResult.skip("branch not monitored")
+			if a.DebugEnabled() {
+				a.Logger.Debug("branch not protected by any ruleset, skipping", slog.String("branch", baseBranch))
+			}
+			return nil
+		}
+	}
+
+	policy := a.Config.PRPolicyFor(repoFullName)
+	opts := client.PRComplianceOptions{
+		MinApprovals:         policy.MinApprovalsForBranch(baseBranch),
+		RequiredTeam:         policy.RequiredTeam,
+		BypassAllowlist:      policy.BypassAllowlist,
+		SuppressedViolations: policy.SuppressedViolations,
+		ExemptLabel:          policy.ExemptLabel,
+		BypassPermissions:    a.Config.PRBypassPermissions,
+
+		ExemptLabelApprovers:     policy.ExemptLabelApprovers,
+		ExemptLabelApproverTeams: policy.ExemptLabelApproverTeams,
+	}
+
+	result, err := ghClient.CheckPRCompliance(ctx, owner, repo, prEvent.Number, opts)
 	if err != nil {
 		return errors.Wrapf(err, "failed to check pr #%d compliance", prEvent.Number)
 	}
 
+	whResult.Violations = result.Violations
+	switch {
+	case result.WasBypassed():
+		whResult.Outcome = WebhookOutcomeBypassed
+	case result.BypassAllowlisted && result.HasViolations():
+		whResult.Outcome = WebhookOutcomeBypassAllowed
+		a.Logger.Info("pr merged with violations by allowlisted user, not reporting",
+			slog.Int("pr_number", prEvent.Number),
+			slog.String("merged_by", result.MergedByLogin()))
+	case result.Exempted && result.HasViolations():
+		whResult.Outcome = WebhookOutcomeExempt
+		a.Logger.Info("pr merged with violations under compliance exemption, not reporting",
+			slog.Int("pr_number", prEvent.Number),
+			slog.String("repo", repoFullName),
+			slog.String("label", policy.ExemptLabel),
+			slog.String("labeled_by", result.ExemptedBy),
+			slog.String("merged_by", result.MergedByLogin()),
+			slog.Any("violations", result.Violations))
+	case result.HasViolations():
+		whResult.Outcome = WebhookOutcomeViolations
+	default:
+		whResult.Outcome = WebhookOutcomeCompliant
+	}
+
 	if result.WasBypassed() {
 		a.Logger.Info("pr bypassed branch protection",
 			slog.Int("pr_number", prEvent.Number),
 			slog.String("branch", baseBranch))
 
-		if a.Notifier != nil {
-			repoFullName := prEvent.GetRepoFullName()
+		if a.Notifier != nil && !a.skipForDryRun("pr bypass notification", slog.Int("pr_number", prEvent.Number)) {
 			if err := a.Notifier.NotifyPRBypass(ctx, result, repoFullName); err != nil {
 				a.Logger.Warn("failed to send slack notification", slog.String("error", err.Error()))
 			}
 		}
-	} else if a.Config.DebugEnabled {
+
+		if a.Config.PRCommentOnBypass && !a.skipForDryRun("pr bypass comment", slog.Int("pr_number", prEvent.Number)) {
+			posted, err := ghClient.CommentOnBypass(ctx, owner, repo, result)
+			if err != nil {
+				a.Logger.Warn("failed to comment on bypassed pr",
+					slog.Int("pr_number", prEvent.Number),
+					slog.String("error", err.Error()))
+			} else if !posted && a.DebugEnabled() {
+				a.Logger.Debug("bypass comment already exists, skipping", slog.Int("pr_number", prEvent.Number))
+			}
+		}
+
+		if a.Config.PRComplianceCheckRun && !a.skipForDryRun("pr bypass check run", slog.Int("pr_number", prEvent.Number)) {
+			if err := ghClient.CreateComplianceCheckRun(ctx, owner, repo, result); err != nil {
+				a.Logger.Warn("failed to create check run for bypassed pr",
+					slog.Int("pr_number", prEvent.Number),
+					slog.String("error", err.Error()))
+			}
+		}
+	} else if a.DebugEnabled() {
 		a.Logger.Debug("pr complied with branch protection", slog.Int("pr_number", prEvent.Number))
 	}
 
 	return nil
 }
 
+// githubClientForInstallation returns the GitHub client for a webhook's
+// installation, creating one when it differs from the configured
+// installation.
+func (a *App) githubClientForInstallation(installationID int64) (*client.Client, error) {
+	if installationID == 0 || installationID == a.Config.GitHubInstallationID {
+		if a.GitHubClient == nil {
+			return nil, errors.Wrap(internalerrors.ErrClientNotInit, "github client")
+		}
+		return a.GitHubClient, nil
+	}
+
+	installClient, err := client.NewAppClientWithBaseURL(
+		a.Config.GitHubAppID,
+		installationID,
+		a.Config.GitHubAppPrivateKey,
+		a.Config.GitHubOrg,
+		a.Config.GitHubBaseURL,
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create client for installation %d", installationID)
+	}
+	installClient.SetDryRun(a.Config.DryRun)
+	installClient.SetMaxRPS(a.Config.GitHubMaxRPS)
+	installClient.SetRateLimitRetry(a.Config.GitHubRateLimitMaxRetries, a.Config.GitHubRateLimitMaxBackoff)
+	installClient.SetProtectionCache(a.protectionCache)
+	installClient.SetLogger(a.Logger)
+	installClient.ShareAppIdentity(a.GitHubClient)
+	return installClient, nil
+}
+
+// branchDeletionDedupWindow is how long a reported branch deletion
+// suppresses further alerts for the same branch. GitHub sends both a push
+// and a delete event for one deletion.
+const branchDeletionDedupWindow = 5 * time.Minute
+
+// branchDeletionDedupSize is how many recent branch deletions are kept in
+// memory for deduplication.
+const branchDeletionDedupSize = 1000
+
+// handlePushWebhook processes GitHub push webhook events. alerts when a
+// monitored branch is deleted or receives commits that were not merged
+// through a pull request.
+func (a *App) handlePushWebhook(ctx context.Context, payload []byte, whResult *WebhookResult) error {
+	pushEvent, err := webhooks.ParsePushEvent(payload)
+	if err != nil {
+		return err
+	}
+
+	if !strings.HasPrefix(pushEvent.Ref, "refs/heads/") {
+		whResult.skip("not a branch push")
+		return nil
+	}
+
+	if !pushEvent.IsBranchDeletion() {
+		return a.handleDirectPush(ctx, pushEvent, whResult)
+	}
+	whResult.Action = "deleted"
+
+	deletedAt := time.Now().UTC()
+	if pushedAt := pushEvent.Repository.GetPushedAt(); !pushedAt.IsZero() {
+		deletedAt = pushedAt.UTC()
+	}
+
+	deletion := notifiers.BranchDeletion{
+		Repo:       pushEvent.GetRepoFullName(),
+		Branch:     pushEvent.GetBranch(),
+		DeletedBy:  pushEvent.GetSenderLogin(),
+		DeletedAt:  deletedAt,
+		LastCommit: pushEvent.Before,
+	}
+	return a.handleBranchDeletion(ctx, deletion, pushEvent.GetRepoOwner(), pushEvent.GetRepoName(),
+		pushEvent.GetDefaultBranch(), pushEvent.GetInstallationID(), whResult)
+}
+
+// handleDeleteWebhook processes GitHub delete webhook events. alerts when a
+// monitored branch is deleted.
+func (a *App) handleDeleteWebhook(ctx context.Context, payload []byte, whResult *WebhookResult) error {
+	deleteEvent, err := webhooks.ParseDeleteEvent(payload)
+	if err != nil {
+		return err
+	}
+
+	if !deleteEvent.IsBranchDeletion() {
+		whResult.skip("not a branch deletion")
+		return nil
+	}
+	whResult.Action = "deleted"
+
+	deletion := notifiers.BranchDeletion{
+		Repo:      deleteEvent.GetRepoFullName(),
+		Branch:    deleteEvent.GetBranch(),
+		DeletedBy: deleteEvent.GetSenderLogin(),
+		DeletedAt: time.Now().UTC(),
+	}
+	return a.handleBranchDeletion(ctx, deletion, deleteEvent.GetRepoOwner(), deleteEvent.GetRepoName(),
+		deleteEvent.GetDefaultBranch(), deleteEvent.GetInstallationID(), whResult)
+}
+
+// handleBranchDeletion raises a high-severity alert when the deleted branch
+// is monitored for PR compliance. deleting and recreating a protected branch
+// resets its history without any PR being merged.
+func (a *App) handleBranchDeletion(ctx context.Context, deletion notifiers.BranchDeletion, owner, repo, defaultBranch string, installationID int64, whResult *WebhookResult) error {
+	monitored, err := a.isMonitoredBranch(ctx, deletion.Branch, owner, repo, defaultBranch, installationID)
+	if err != nil {
+		return err
+	}
+	if !monitored {
+		whResult.skip("branch not monitored")
+		if a.DebugEnabled() {
+			a.Logger.Debug("deleted branch not monitored, skipping", slog.String("branch", deletion.Branch))
+		}
+		return nil
+	}
+
+	if !a.recordBranchDeletion(ctx, deletion.Repo, deletion.Branch) {
+		whResult.skip("branch deletion already reported")
+		return nil
+	}
+
+	whResult.Outcome = WebhookOutcomeBranchDeleted
+	a.Logger.Warn("protected branch deleted",
+		slog.String("repo", deletion.Repo),
+		slog.String("branch", deletion.Branch),
+		slog.String("deleted_by", deletion.DeletedBy),
+		slog.Time("deleted_at", deletion.DeletedAt))
+
+	if a.Notifier != nil && !a.skipForDryRun("branch deletion notification", slog.String("branch", deletion.Branch)) {
+		if err := a.Notifier.NotifyBranchDeletion(ctx, deletion); err != nil {
+			a.Logger.Warn("failed to send branch deletion notification", slog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// handleDirectPush alerts when commits land on a monitored branch without
+// being merged through a PR. pushes whose head commit is the merge commit of
+// a merged PR are skipped, as are pushes made by bots or the app itself and
+// pushes to non-default branches matching a policy push exemption. force
+// pushes never come from PR merges and are reported unless an exemption
+// allows them.
+func (a *App) handleDirectPush(ctx context.Context, pushEvent *webhooks.PushEvent, whResult *WebhookResult) error {
+	whResult.Action = "pushed"
+	if pushEvent.Forced {
+		whResult.Action = "force_pushed"
+	}
+
+	if pushEvent.Created {
+		whResult.skip("branch created")
+		return nil
+	}
+
+	branch := pushEvent.GetBranch()
+	owner := pushEvent.GetRepoOwner()
+	repo := pushEvent.GetRepoName()
+	installationID := pushEvent.GetInstallationID()
+
+	monitored, err := a.isMonitoredBranch(ctx, branch, owner, repo, pushEvent.GetDefaultBranch(), installationID)
+	if err != nil {
+		return err
+	}
+	if !monitored {
+		whResult.skip("branch not monitored")
+		if a.DebugEnabled() {
+			a.Logger.Debug("pushed branch not monitored, skipping", slog.String("branch", branch))
+		}
+		return nil
+	}
+
+	if a.shouldIgnoreWebhookChange(ctx, pushEvent) {
+		whResult.skip("push made by bot or app")
+		if a.DebugEnabled() {
+			a.Logger.Debug("ignoring push from bot or app", slog.String("sender", pushEvent.GetSenderLogin()))
+		}
+		return nil
+	}
+
+	policy := a.Config.PRPolicyFor(pushEvent.GetRepoFullName())
+	if policy.IsPushExempt(branch, pushEvent.GetDefaultBranch(), pushEvent.GetSenderLogin(), pushEvent.Forced) {
+		whResult.skip("push exempt by policy")
+		if a.DebugEnabled() {
+			a.Logger.Debug("push matches policy exemption, skipping",
+				slog.String("branch", branch),
+				slog.String("sender", pushEvent.GetSenderLogin()))
+		}
+		return nil
+	}
+
+	if !pushEvent.Forced {
+		ghClient, err := a.githubClientForInstallation(installationID)
+		if err != nil {
+			return err
+		}
+		prNumber, err := ghClient.FindMergedPRForCommit(ctx, owner, repo, pushEvent.After)
+		if err != nil {
+			return errors.Wrapf(err, "failed to check pr association for push to %s@%s", pushEvent.GetRepoFullName(), branch)
+		}
+		if prNumber != 0 {
+			whResult.skip("push from merged pr")
+			if a.DebugEnabled() {
+				a.Logger.Debug("push is a pr merge, skipping", slog.Int("pr_number", prNumber))
+			}
+			return nil
+		}
+	}
+
+	pushedAt := time.Now().UTC()
+	if at := pushEvent.Repository.GetPushedAt(); !at.IsZero() {
+		pushedAt = at.UTC()
+	}
+
+	push := notifiers.DirectPush{
+		Repo:              pushEvent.GetRepoFullName(),
+		Branch:            branch,
+		PushedBy:          pushEvent.GetSenderLogin(),
+		PushedAt:          pushedAt,
+		Forced:            pushEvent.Forced,
+		Before:            pushEvent.Before,
+		After:             pushEvent.After,
+		Commits:           len(pushEvent.Commits),
+		HeadCommitMessage: pushEvent.GetHeadCommitMessage(),
+		CompareURL:        pushEvent.Compare,
+	}
+
+	whResult.Outcome = WebhookOutcomeDirectPush
+	a.Logger.Warn("direct push to protected branch",
+		slog.String("repo", push.Repo),
+		slog.String("branch", push.Branch),
+		slog.String("pushed_by", push.PushedBy),
+		slog.Bool("forced", push.Forced),
+		slog.String("after", push.After))
+
+	if a.Notifier != nil && !a.skipForDryRun("direct push notification", slog.String("branch", branch)) {
+		if err := a.Notifier.NotifyDirectPush(ctx, push); err != nil {
+			a.Logger.Warn("failed to send direct push notification", slog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// isMonitoredBranch returns true if the branch is monitored for PR
+// compliance, resolving ruleset ref_name conditions through the API only
+// when the branch is not in the static list.
+func (a *App) isMonitoredBranch(ctx context.Context, branch, owner, repo, defaultBranch string, installationID int64) (bool, error) {
+	repoFullName := owner + "/" + repo
+	if a.Config.ShouldMonitorRepoBranch(repoFullName, branch) {
+		return true, nil
+	}
+	if !a.Config.PRMonitorRulesetBranches || !a.Config.IsPRComplianceEnabled() {
+		return false, nil
+	}
+
+	ghClient, err := a.githubClientForInstallation(installationID)
+	if err != nil {
+		return false, err
+	}
+	return a.isRulesetMonitoredBranch(ctx, ghClient, branch, owner, repo, defaultBranch), nil
+}
+
+// isRulesetMonitoredBranch returns true if a ruleset ref_name condition
+// targets the branch. ruleset monitoring is optional, so a failed lookup is
+// logged and only the default branch is treated as monitored.
+func (a *App) isRulesetMonitoredBranch(ctx context.Context, ghClient *client.Client, branch, owner, repo, defaultBranch string) bool {
+	repoFullName := owner + "/" + repo
+	conditions, err := ghClient.GetRulesetRefConditions(ctx, owner, repo, defaultBranch)
+	if err != nil {
+		a.Logger.Warn("failed to resolve ruleset protected branches, monitoring the default branch only",
+			slog.String("repo", repoFullName),
+			slog.String("branch", branch),
+			slog.String("error", err.Error()))
+		return strings.TrimPrefix(branch, "refs/heads/") == defaultBranch
+	}
+	return a.Config.ShouldMonitorRepoBranch(repoFullName, branch, conditions...)
+}
+
+// recordBranchDeletion returns true if the deletion has not been reported
+// within the dedup window, and records it. a store error is logged and the
+// deletion is reported, since a duplicate alert beats a missed one.
+func (a *App) recordBranchDeletion(ctx context.Context, repo, branch string) bool {
+	a.branchDeletionMu.Lock()
+	if a.branchDeletions == nil {
+		a.branchDeletions = newDedupCache(branchDeletionDedupSize, branchDeletionDedupWindow)
+	}
+	deletions := a.branchDeletions
+	a.branchDeletionMu.Unlock()
+
+	claimed, err := deletions.claim(ctx, repo+"@"+branch, time.Now())
+	if err != nil {
+		a.Logger.Warn("failed to record branch deletion",
+			slog.String("repo", repo),
+			slog.String("branch", branch),
+			slog.String("error", err.Error()))
+		return true
+	}
+	return claimed
+}
+
 // handleTeamWebhook processes GitHub team webhook events.
 // triggers Okta sync when team changes are made externally.
-func (a *App) handleTeamWebhook(ctx context.Context, payload []byte) error {
+func (a *App) handleTeamWebhook(ctx context.Context, payload []byte, whResult *WebhookResult) error {
 	teamEvent, err := webhooks.ParseTeamEvent(payload)
 	if err != nil {
 		return err
 	}
+	whResult.Action = teamEvent.Action
 
 	if !a.Config.IsOktaSyncEnabled() {
-		if a.Config.DebugEnabled {
+		whResult.skip("okta sync not enabled")
+		if a.DebugEnabled() {
 			a.Logger.Debug("okta sync not enabled, skipping team webhook")
 		}
 		return nil
 	}
 
 	if a.shouldIgnoreWebhookChange(ctx, teamEvent) {
-		if a.Config.DebugEnabled {
+		whResult.skip("change made by bot or app")
+		if a.DebugEnabled() {
 			a.Logger.Debug("ignoring team change from bot/app",
 				slog.String("action", teamEvent.Action),
 				slog.String("sender", teamEvent.GetSenderLogin()))
@@ -158,33 +1209,48 @@ func (a *App) handleTeamWebhook(ctx context.Context, payload []byte) error {
 		slog.String("team", teamEvent.GetTeamSlug()),
 		slog.String("sender", teamEvent.GetSenderLogin()))
 
-	return a.handleOktaSync(ctx)
+	return a.runWebhookOktaSync(ctx, whResult)
+}
+
+// runWebhookOktaSync runs a full okta sync triggered by a webhook and records
+// the reports on the webhook result.
+func (a *App) runWebhookOktaSync(ctx context.Context, whResult *WebhookResult) error {
+	whResult.Outcome = WebhookOutcomeOktaSync
+	syncResult, err := a.handleOktaSync(ctx, nil)
+	if syncResult != nil {
+		whResult.SyncReports = syncResult.Reports
+	}
+	return err
 }
 
 // handleMembershipWebhook processes GitHub membership webhook events.
 // triggers Okta sync when team memberships are changed externally.
-func (a *App) handleMembershipWebhook(ctx context.Context, payload []byte) error {
+func (a *App) handleMembershipWebhook(ctx context.Context, payload []byte, whResult *WebhookResult) error {
 	membershipEvent, err := webhooks.ParseMembershipEvent(payload)
 	if err != nil {
 		return err
 	}
+	whResult.Action = membershipEvent.Action
 
 	if !membershipEvent.IsTeamScope() {
-		if a.Config.DebugEnabled {
+		whResult.skip("membership event is not team scope")
+		if a.DebugEnabled() {
 			a.Logger.Debug("membership event is not team scope, skipping")
 		}
 		return nil
 	}
 
 	if !a.Config.IsOktaSyncEnabled() {
-		if a.Config.DebugEnabled {
+		whResult.skip("okta sync not enabled")
+		if a.DebugEnabled() {
 			a.Logger.Debug("okta sync not enabled, skipping membership webhook")
 		}
 		return nil
 	}
 
 	if a.shouldIgnoreWebhookChange(ctx, membershipEvent) {
-		if a.Config.DebugEnabled {
+		whResult.skip("change made by bot or app")
+		if a.DebugEnabled() {
 			a.Logger.Debug("ignoring membership change from bot/app",
 				slog.String("action", membershipEvent.Action),
 				slog.String("team", membershipEvent.GetTeamSlug()),
@@ -193,65 +1259,121 @@ func (a *App) handleMembershipWebhook(ctx context.Context, payload []byte) error
 		return nil
 	}
 
+	switch a.Config.MembershipActionPolicy(membershipEvent.Action) {
+	case config.MembershipPolicyIgnore:
+		whResult.skip("membership action ignored by policy")
+		if a.DebugEnabled() {
+			a.Logger.Debug("membership action ignored by policy",
+				slog.String("action", membershipEvent.Action),
+				slog.String("team", membershipEvent.GetTeamSlug()))
+		}
+		return nil
+	case config.MembershipPolicyTeamSync:
+		a.Logger.Info("external membership change detected, triggering team sync",
+			slog.String("action", membershipEvent.Action),
+			slog.String("team", membershipEvent.GetTeamSlug()),
+			slog.String("sender", membershipEvent.GetSenderLogin()))
+		whResult.Outcome = WebhookOutcomeOktaTeamSync
+		syncResult, err := a.handleTeamOktaSync(ctx, membershipEvent.GetTeamSlug())
+		if syncResult != nil {
+			whResult.SyncReports = syncResult.Reports
+		}
+		return err
+	case config.MembershipPolicyThrottle:
+		if a.oktaSyncedWithin(ctx, a.Config.OktaMembershipThrottleWindow) {
+			whResult.skip("okta sync ran within throttle window")
+			if a.DebugEnabled() {
+				a.Logger.Debug("okta sync ran recently, throttling membership change",
+					slog.String("action", membershipEvent.Action),
+					slog.String("team", membershipEvent.GetTeamSlug()))
+			}
+			return nil
+		}
+	}
+
 	a.Logger.Info("external membership change detected, triggering sync",
 		slog.String("action", membershipEvent.Action),
 		slog.String("team", membershipEvent.GetTeamSlug()),
 		slog.String("sender", membershipEvent.GetSenderLogin()))
 
-	return a.handleOktaSync(ctx)
+	return a.runWebhookOktaSync(ctx, whResult)
 }
 
 // webhookSender provides sender information for webhook events.
 type webhookSender interface {
 	GetSenderType() string
 	GetSenderLogin() string
+	GetSenderID() int64
 }
 
 // shouldIgnoreWebhookChange checks if a webhook should be ignored.
 // ignores changes made by bots or the GitHub App itself to prevent loops.
+// the sender is matched against the app's bot user ID as well as its slug so
+// detection survives app renames and failed slug lookups.
 func (a *App) shouldIgnoreWebhookChange(ctx context.Context, event webhookSender) bool {
 	if event.GetSenderType() == "Bot" {
 		return true
 	}
 
-	if a.GitHubClient != nil {
-		appSlug, err := a.GitHubClient.GetAppSlug(ctx)
+	if a.GitHubClient == nil {
+		return false
+	}
+
+	if senderID := event.GetSenderID(); senderID != 0 {
+		botID, err := a.GitHubClient.GetAppBotUserID(ctx)
 		if err != nil {
-			a.Logger.Warn("failed to get app slug", slog.String("error", err.Error()))
-			return false
-		}
-		if event.GetSenderLogin() == appSlug+"[bot]" {
+			a.Logger.Warn("failed to get app bot user id", slog.String("error", err.Error()))
+		} else if senderID == botID {
 			return true
 		}
 	}
 
-	return false
+	appSlug, err := a.GitHubClient.GetAppSlug(ctx)
+	if err != nil {
+		a.Logger.Warn("failed to get app slug", slog.String("error", err.Error()))
+		return false
+	}
+
+	return event.GetSenderLogin() == appSlug+"[bot]"
 }
 
 // handleSlackTest sends test notifications to Slack with sample data.
 // useful for verifying Slack connectivity and previewing message formats.
+// all test notifications are attempted and failures are combined.
 func (a *App) handleSlackTest(ctx context.Context) error {
 	if a.Notifier == nil {
-		return errors.New("slack is not configured")
+		return notConfigured("slack")
 	}
 
+	var errs []error
+
 	// test 1: PR bypass notification
 	if err := a.Notifier.NotifyPRBypass(ctx, fakePRComplianceResult(), "acme-corp/demo-repo"); err != nil {
-		return errors.Wrap(err, "failed to send test pr bypass notification")
+		errs = append(errs, errors.Wrap(err, "failed to send test pr bypass notification"))
+	} else {
+		a.Logger.Info("sent test pr bypass notification")
 	}
-	a.Logger.Info("sent test pr bypass notification")
 
 	// test 2: Okta sync notification
-	if err := a.Notifier.NotifyOktaSync(ctx, fakeOktaSyncReports(), "acme-corp"); err != nil {
-		return errors.Wrap(err, "failed to send test okta sync notification")
+	if err := a.Notifier.NotifyOktaSync(ctx, fakeOktaSyncResult(), "acme-corp"); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to send test okta sync notification"))
+	} else {
+		a.Logger.Info("sent test okta sync notification")
 	}
-	a.Logger.Info("sent test okta sync notification")
 
 	// test 3: Orphaned users notification
 	if err := a.Notifier.NotifyOrphanedUsers(ctx, fakeOrphanedUsersReport()); err != nil {
-		return errors.Wrap(err, "failed to send test orphaned users notification")
+		errs = append(errs, errors.Wrap(err, "failed to send test orphaned users notification"))
+	} else {
+		a.Logger.Info("sent test orphaned users notification")
 	}
-	a.Logger.Info("sent test orphaned users notification")
 
-	return nil
+	// test 4: Missing GitHub username notification
+	if err := a.Notifier.NotifyMissingUsernames(ctx, fakeMissingUsernamesReport()); err != nil {
+		errs = append(errs, errors.Wrap(err, "failed to send test missing github username notification"))
+	} else {
+		a.Logger.Info("sent test missing github username notification")
+	}
+
+	return errors.Join(errs...)
 }
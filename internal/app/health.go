@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cruxstack/github-ops-app/internal/notifiers"
+)
+
+// HealthResponse reports live connectivity to each configured upstream
+// service.
+type HealthResponse struct {
+	Healthy  bool                     `json:"healthy"`
+	Services map[string]ServiceHealth `json:"services"`
+}
+
+// ServiceHealth is the probe result for a single upstream service.
+type ServiceHealth struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// CheckHealth probes GitHub, Okta, and every notification sink that supports
+// health checks with a lightweight authenticated call. probes run
+// concurrently, each bounded by the configured health check timeout, so a
+// single hung dependency does not block the others. services that are not
+// configured are omitted.
+func (a *App) CheckHealth(ctx context.Context) HealthResponse {
+	probes := a.healthProbes()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	services := make(map[string]ServiceHealth, len(probes))
+
+	for name, checker := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			probeCtx, cancel := context.WithTimeout(ctx, a.Config.HealthCheckTimeout)
+			defer cancel()
+
+			health := ServiceHealth{OK: true}
+			if err := checker.CheckHealth(probeCtx); err != nil {
+				health = ServiceHealth{Error: err.Error()}
+			}
+
+			mu.Lock()
+			services[name] = health
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, health := range services {
+		if !health.OK {
+			healthy = false
+		}
+	}
+
+	return HealthResponse{Healthy: healthy, Services: services}
+}
+
+// healthProbes returns the health checkers for configured services keyed by
+// service name. notification sinks are keyed by sink name.
+func (a *App) healthProbes() map[string]notifiers.HealthChecker {
+	probes := make(map[string]notifiers.HealthChecker)
+
+	if a.GitHubClient != nil {
+		probes["github"] = a.GitHubClient
+	}
+	if a.OktaClient != nil {
+		probes["okta"] = a.OktaClient
+	}
+	for name, source := range a.OktaConnections {
+		if checker, ok := source.(notifiers.HealthChecker); ok {
+			probes["okta:"+name] = checker
+		}
+	}
+
+	switch n := a.Notifier.(type) {
+	case *notifiers.MultiNotifier:
+		for _, sink := range n.Sinks() {
+			if checker, ok := sink.Notifier.(notifiers.HealthChecker); ok {
+				probes[sink.Name] = checker
+			}
+		}
+	case notifiers.HealthChecker:
+		probes["notifier"] = n
+	}
+
+	return probes
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/notifiers"
+	"github.com/slack-go/slack"
+)
+
+// SlackActionHandler handles one action of a Slack block_actions
+// interaction, such as a button click.
+type SlackActionHandler func(ctx context.Context, callback *slack.InteractionCallback, action *slack.BlockAction) error
+
+// HandleSlackAction registers the handler for Slack interactive actions with
+// the given action ID. register handlers before serving requests.
+func (a *App) HandleSlackAction(actionID string, handler SlackActionHandler) {
+	if a.slackActions == nil {
+		a.slackActions = make(map[string]SlackActionHandler)
+	}
+	a.slackActions[actionID] = handler
+}
+
+// handleSlackInteractionRequest verifies the Slack request signature of an
+// interactive component callback, then dispatches it. the endpoint is
+// disabled unless APP_SLACK_SIGNING_SECRET is set.
+func (a *App) handleSlackInteractionRequest(ctx context.Context, req Request) Response {
+	if req.Method != "POST" {
+		return errorResponse(405, "method not allowed")
+	}
+	if a.Config.SlackSigningSecret == "" {
+		return errorResponse(404, "slack interactions not configured")
+	}
+
+	err := notifiers.VerifySlackSignature(req.Body,
+		req.Headers["x-slack-request-timestamp"],
+		req.Headers["x-slack-signature"],
+		a.Config.SlackSigningSecret,
+		time.Now())
+	if err != nil {
+		a.Logger.Warn("slack signature validation failed",
+			slog.String("error", err.Error()))
+		return errorResponse(401, "unauthorized")
+	}
+
+	callback, err := parseSlackInteraction(req.Body)
+	if err != nil {
+		a.Logger.Warn("invalid slack interaction payload",
+			slog.String("error", err.Error()))
+		return errorResponse(400, "invalid interaction payload")
+	}
+
+	if err := a.dispatchSlackInteraction(ctx, callback); err != nil {
+		a.Logger.Error("slack interaction processing failed",
+			slog.String("type", string(callback.Type)),
+			slog.String("user", callback.User.ID),
+			slog.String("error", err.Error()))
+		return errorResponse(500, "interaction processing failed")
+	}
+
+	// slack only needs an empty 200 to acknowledge the interaction
+	return Response{StatusCode: 200}
+}
+
+// parseSlackInteraction decodes the JSON payload form field that Slack posts
+// to the interactivity request URL.
+func parseSlackInteraction(body []byte) (*slack.InteractionCallback, error) {
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse form body")
+	}
+
+	payload := values.Get("payload")
+	if payload == "" {
+		return nil, errors.New("missing payload field")
+	}
+
+	var callback slack.InteractionCallback
+	if err := json.Unmarshal([]byte(payload), &callback); err != nil {
+		return nil, errors.Wrap(err, "failed to decode payload")
+	}
+	return &callback, nil
+}
+
+// dispatchSlackInteraction runs the registered handler of each action in a
+// block_actions interaction. actions without a handler and other interaction
+// types are acknowledged and logged.
+func (a *App) dispatchSlackInteraction(ctx context.Context, callback *slack.InteractionCallback) error {
+	if callback.Type != slack.InteractionTypeBlockActions {
+		a.Logger.Info("ignoring unhandled slack interaction",
+			slog.String("type", string(callback.Type)),
+			slog.String("user", callback.User.ID))
+		return nil
+	}
+
+	for _, action := range callback.ActionCallback.BlockActions {
+		handler, ok := a.slackActions[action.ActionID]
+		if !ok {
+			a.Logger.Info("ignoring unhandled slack action",
+				slog.String("action_id", action.ActionID),
+				slog.String("user", callback.User.ID))
+			continue
+		}
+		if err := handler(ctx, callback, action); err != nil {
+			return errors.Wrapf(err, "failed to handle slack action '%s'", action.ActionID)
+		}
+	}
+	return nil
+}
@@ -1,12 +1,18 @@
 package app
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"strings"
+	"time"
 
+	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/webhooks"
+	"github.com/cruxstack/github-ops-app/internal/metrics"
 )
 
 // RequestType identifies the category of incoming request.
@@ -26,6 +32,7 @@ type Request struct {
 	Method  string            `json:"method,omitempty"`
 	Path    string            `json:"path,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	Query   map[string]string `json:"query,omitempty"`
 	Body    []byte            `json:"body,omitempty"`
 
 	// ScheduledAction is used for scheduled events (e.g., "okta-sync").
@@ -44,13 +51,19 @@ type Response struct {
 }
 
 // HandleRequest routes incoming requests to the appropriate handler.
-// This is the single entry point for all request processing.
+// This is the single entry point for all request processing. requests are
+// counted as in flight in the app metrics until they return.
 func (a *App) HandleRequest(ctx context.Context, req Request) Response {
-	if a.Config.DebugEnabled {
+	if a.DebugEnabled() {
 		j, _ := json.Marshal(req)
 		a.Logger.Debug("handling request", slog.String("request", string(j)))
 	}
 
+	if a.Metrics != nil {
+		a.Metrics.AddInFlight(1)
+		defer a.Metrics.AddInFlight(-1)
+	}
+
 	switch req.Type {
 	case RequestTypeScheduled:
 		return a.handleScheduledRequest(ctx, req)
@@ -68,17 +81,40 @@ func (a *App) handleScheduledRequest(ctx context.Context, req Request) Response
 		Data:   req.ScheduledData,
 	}
 
-	if err := a.ProcessScheduledEvent(ctx, evt); err != nil {
+	result, err := a.ProcessScheduledEventWithResult(ctx, evt)
+	if errors.Is(err, internalerrors.ErrNotConfigured) {
+		a.Logger.Info("scheduled event skipped",
+			slog.String("action", evt.Action),
+			slog.String("reason", err.Error()))
+		return jsonResponse(200, map[string]any{
+			"status":  "skipped",
+			"message": err.Error(),
+		})
+	}
+	if err != nil {
 		a.Logger.Error("scheduled event processing failed",
 			slog.String("action", evt.Action),
 			slog.String("error", err.Error()))
 		return errorResponse(500, "scheduled event processing failed")
 	}
 
-	return jsonResponse(200, map[string]string{
+	body := map[string]any{
 		"status":  "success",
 		"message": evt.Action + " completed",
-	})
+	}
+	if result.SyncReports != nil {
+		body["sync_reports"] = result.SyncReports
+	}
+	if result.OrphanedUsers != nil {
+		body["orphaned_users_report"] = result.OrphanedUsers
+	}
+	if result.MissingUsernames != nil {
+		body["missing_github_username_report"] = result.MissingUsernames
+	}
+	if result.RuleDiagnostics != nil {
+		body["rule_diagnostics"] = result.RuleDiagnostics
+	}
+	return jsonResponse(200, body)
 }
 
 // handleHTTPRequest routes HTTP requests based on path.
@@ -97,6 +133,18 @@ func (a *App) handleHTTPRequest(ctx context.Context, req Request) Response {
 		return a.handleStatusRequest(req)
 	case "/server/config":
 		return a.handleConfigRequest(req)
+	case "/server/healthz":
+		return a.handleHealthzRequest(ctx, req)
+	case "/server/metrics":
+		return a.handleMetricsRequest(req)
+	case "/server/cache/clear":
+		return a.handleCacheClearRequest(req)
+	case "/okta/orphaned/history":
+		return a.handleOrphanedHistoryRequest(ctx, req)
+	case "/okta/last-sync":
+		return a.handleLastSyncRequest(ctx, req)
+	case "/slack/interactions":
+		return a.handleSlackInteractionRequest(ctx, req)
 	case "/webhooks", "/":
 		return a.handleWebhookRequest(ctx, req)
 	default:
@@ -112,21 +160,172 @@ func (a *App) handleStatusRequest(req Request) Response {
 	if req.Method != "GET" {
 		return errorResponse(405, "method not allowed")
 	}
-	if resp := a.checkAdminAuth(req); resp != nil {
+	if resp := a.checkAdminAuth(req, authReadOnly); resp != nil {
 		return *resp
 	}
 	return jsonResponse(200, a.GetStatus())
 }
 
-// handleConfigRequest returns redacted configuration.
+// handleConfigRequest returns redacted configuration. PATCH updates the
+// settings that can change at runtime and returns the updated configuration.
 func (a *App) handleConfigRequest(req Request) Response {
+	switch req.Method {
+	case "GET":
+		if resp := a.checkAdminAuth(req, authReadOnly); resp != nil {
+			return *resp
+		}
+	case "PATCH":
+		if resp := a.checkAdminAuth(req, authAdmin); resp != nil {
+			return *resp
+		}
+		if resp := a.patchConfig(req.Body); resp != nil {
+			return *resp
+		}
+	default:
+		return errorResponse(405, "method not allowed")
+	}
+
+	a.configMu.RLock()
+	defer a.configMu.RUnlock()
+	return jsonResponse(200, a.Config.Redacted())
+}
+
+// patchConfig applies a PATCH /server/config body. only debug_enabled can
+// be changed; any other field is rejected so a typo is not silently ignored.
+// returns an error response if the body is invalid.
+func (a *App) patchConfig(body []byte) *Response {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		resp := errorResponse(400, "request body must be a JSON object")
+		return &resp
+	}
+
+	var debugEnabled *bool
+	for name, value := range fields {
+		if name != "debug_enabled" {
+			resp := errorResponse(400, fmt.Sprintf("field '%s' cannot be changed at runtime; only debug_enabled is mutable", name))
+			return &resp
+		}
+		if err := json.Unmarshal(value, &debugEnabled); err != nil || debugEnabled == nil {
+			resp := errorResponse(400, "debug_enabled must be a boolean")
+			return &resp
+		}
+	}
+	if debugEnabled == nil {
+		resp := errorResponse(400, "no fields to update; expected debug_enabled")
+		return &resp
+	}
+
+	a.SetDebugEnabled(*debugEnabled)
+	a.Logger.Info("debug logging changed at runtime", slog.Bool("debug_enabled", *debugEnabled))
+	return nil
+}
+
+// handleHealthzRequest probes upstream services and returns per-service
+// results. responds 503 if any configured service fails its probe.
+func (a *App) handleHealthzRequest(ctx context.Context, req Request) Response {
 	if req.Method != "GET" {
 		return errorResponse(405, "method not allowed")
 	}
-	if resp := a.checkAdminAuth(req); resp != nil {
+	if resp := a.checkAdminAuth(req, authReadOnly); resp != nil {
 		return *resp
 	}
-	return jsonResponse(200, a.Config.Redacted())
+
+	health := a.CheckHealth(ctx)
+	if !health.Healthy {
+		return jsonResponse(503, health)
+	}
+	return jsonResponse(200, health)
+}
+
+// handleMetricsRequest returns recorded webhook latency and outcome metrics
+// and Okta sync totals. it requires the admin token.
+func (a *App) handleMetricsRequest(req Request) Response {
+	if req.Method != "GET" {
+		return errorResponse(405, "method not allowed")
+	}
+	if resp := a.checkAdminAuth(req, authAdmin); resp != nil {
+		return *resp
+	}
+	if a.Metrics == nil {
+		return errorResponse(404, "metrics not available")
+	}
+	return jsonResponse(200, a.Metrics.Snapshot())
+}
+
+// handleCacheClearRequest flushes in-memory caches, optionally scoped by the
+// type query parameter (defaults to all).
+func (a *App) handleCacheClearRequest(req Request) Response {
+	if req.Method != "POST" {
+		return errorResponse(405, "method not allowed")
+	}
+	if resp := a.checkAdminAuth(req, authAdmin); resp != nil {
+		return *resp
+	}
+
+	cacheType := req.Query["type"]
+	if cacheType == "" {
+		cacheType = CacheTypeAll
+	}
+
+	cleared, err := a.ClearCaches(cacheType)
+	if err != nil {
+		return errorResponse(400, err.Error())
+	}
+
+	return jsonResponse(200, map[string]any{
+		"status":  "success",
+		"cleared": cleared,
+	})
+}
+
+// handleOrphanedHistoryRequest returns recorded orphaned user snapshots,
+// newest first.
+func (a *App) handleOrphanedHistoryRequest(ctx context.Context, req Request) Response {
+	if req.Method != "GET" {
+		return errorResponse(405, "method not allowed")
+	}
+	if resp := a.checkAdminAuth(req, authReadOnly); resp != nil {
+		return *resp
+	}
+	if a.OrphanHistory == nil {
+		return errorResponse(404, "orphaned user history not available")
+	}
+
+	snapshots, err := a.OrphanHistory.List(ctx)
+	if err != nil {
+		a.Logger.Error("failed to list orphaned users history", slog.String("error", err.Error()))
+		return errorResponse(500, "failed to list orphaned users history")
+	}
+
+	return jsonResponse(200, map[string]any{
+		"snapshots": snapshots,
+	})
+}
+
+// handleLastSyncRequest returns the membership changes recorded by the most
+// recent okta sync of each team.
+func (a *App) handleLastSyncRequest(ctx context.Context, req Request) Response {
+	if req.Method != "GET" {
+		return errorResponse(405, "method not allowed")
+	}
+	if resp := a.checkAdminAuth(req, authReadOnly); resp != nil {
+		return *resp
+	}
+	if a.LastSync == nil {
+		return errorResponse(404, "last sync not available")
+	}
+
+	snapshot, err := a.LastSync.Get(ctx)
+	if err != nil {
+		a.Logger.Error("failed to load last sync snapshot", slog.String("error", err.Error()))
+		return errorResponse(500, "failed to load last sync snapshot")
+	}
+	if snapshot == nil {
+		return errorResponse(404, "no sync recorded")
+	}
+
+	return jsonResponse(200, snapshot)
 }
 
 // handleWebhookRequest processes GitHub webhook POST requests.
@@ -136,39 +335,152 @@ func (a *App) handleWebhookRequest(ctx context.Context, req Request) Response {
 	}
 
 	eventType := req.Headers["x-github-event"]
-	signature := req.Headers["x-hub-signature-256"]
 
-	if err := webhooks.ValidateWebhookSignature(
-		req.Body,
-		signature,
-		a.Config.GitHubWebhookSecret,
-	); err != nil {
+	if err := a.validateWebhookSignature(req); err != nil {
 		a.Logger.Warn("webhook signature validation failed",
 			slog.String("error", err.Error()))
 		return errorResponse(401, "unauthorized")
 	}
 
-	if err := a.ProcessWebhook(ctx, req.Body, eventType); err != nil {
+	deliveryID := req.Headers["x-github-delivery"]
+
+	start := time.Now()
+	if !a.claimDelivery(ctx, deliveryID, start) {
+		a.Logger.Debug("skipping duplicate webhook delivery",
+			slog.String("event_type", eventType),
+			slog.String("delivery_id", deliveryID))
+		return a.webhookSuccessResponse(webhookAck{DeliveryID: deliveryID, Duplicate: true})
+	}
+
+	result, err := a.ProcessWebhookWithResult(ctx, req.Body, eventType)
+	a.observeWebhook(eventType, deliveryID, result, err, time.Since(start))
+
+	if err != nil {
+		// let GitHub's redelivery of a failed webhook be processed
+		a.releaseDelivery(ctx, deliveryID)
+
 		a.Logger.Error("webhook processing failed",
 			slog.String("event_type", eventType),
+			slog.String("delivery_id", deliveryID),
 			slog.String("error", err.Error()))
 		return errorResponse(500, "webhook processing failed")
 	}
 
+	return a.webhookSuccessResponse(webhookAck{DeliveryID: deliveryID, Result: result})
+}
+
+// claimDelivery returns false if the webhook delivery was already claimed.
+// a store error is logged and the delivery is processed.
+func (a *App) claimDelivery(ctx context.Context, deliveryID string, now time.Time) bool {
+	if a.deliveries == nil {
+		return true
+	}
+	claimed, err := a.deliveries.claim(ctx, deliveryID, now)
+	if err != nil {
+		a.Logger.Warn("failed to claim webhook delivery",
+			slog.String("delivery_id", deliveryID),
+			slog.String("error", err.Error()))
+		return true
+	}
+	return claimed
+}
+
+// releaseDelivery forgets a webhook delivery so its redelivery is processed.
+func (a *App) releaseDelivery(ctx context.Context, deliveryID string) {
+	if a.deliveries == nil {
+		return
+	}
+	if err := a.deliveries.release(ctx, deliveryID); err != nil {
+		a.Logger.Warn("failed to release webhook delivery",
+			slog.String("delivery_id", deliveryID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// webhookAck is the JSON body returned for a handled webhook when
+// APP_WEBHOOK_SUCCESS_JSON is enabled.
+type webhookAck struct {
+	Status     string         `json:"status"`
+	DeliveryID string         `json:"delivery_id,omitempty"`
+	Duplicate  bool           `json:"duplicate,omitempty"`
+	Result     *WebhookResult `json:"result,omitempty"`
+}
+
+// webhookSuccessResponse returns the configured response for a handled
+// webhook: "ok" with status 200 by default, or a JSON acknowledgment. a 204
+// status never has a body.
+func (a *App) webhookSuccessResponse(ack webhookAck) Response {
+	status := cmp.Or(a.Config.WebhookSuccessStatus, 200)
+	if status == 204 {
+		return Response{StatusCode: status}
+	}
+
+	if a.Config.WebhookSuccessJSON {
+		ack.Status = "ok"
+		return jsonResponse(status, ack)
+	}
+
 	return Response{
-		StatusCode:  200,
+		StatusCode:  status,
 		ContentType: "text/plain",
-		Body:        []byte("ok"),
+		Body:        []byte(cmp.Or(a.Config.WebhookSuccessBody, "ok")),
+	}
+}
+
+// observeWebhook records the processing duration and outcome of a webhook
+// delivery. the delivery ID correlates slow deliveries with GitHub's
+// delivery log.
+func (a *App) observeWebhook(eventType, deliveryID string, result *WebhookResult, err error, duration time.Duration) {
+	outcome := metrics.OutcomeProcessed
+	switch {
+	case err != nil:
+		outcome = metrics.OutcomeError
+	case result != nil && result.Outcome == WebhookOutcomeSkipped:
+		outcome = metrics.OutcomeSkipped
+	}
+
+	if a.Metrics != nil {
+		a.Metrics.ObserveWebhook(eventType, outcome, duration)
+	}
+
+	if a.DebugEnabled() {
+		attrs := []any{
+			slog.String("event_type", eventType),
+			slog.String("delivery_id", deliveryID),
+			slog.String("outcome", outcome),
+			slog.Duration("duration", duration),
+		}
+		if result != nil && result.Action != "" {
+			attrs = append(attrs, slog.String("action", result.Action))
+		}
+		a.Logger.Debug("webhook handled", attrs...)
 	}
 }
 
+// validateWebhookSignature checks the x-hub-signature-256 header. when
+// AllowLegacySignatures is enabled and only the SHA-1 x-hub-signature header
+// is present, that signature is validated instead.
+func (a *App) validateWebhookSignature(req Request) error {
+	signature := req.Headers["x-hub-signature-256"]
+	legacySignature := req.Headers["x-hub-signature"]
+
+	if signature == "" && legacySignature != "" && a.Config.AllowLegacySignatures {
+		if a.DebugEnabled() {
+			a.Logger.Debug("validating legacy sha-1 webhook signature")
+		}
+		return webhooks.ValidateWebhookSignatureLegacy(req.Body, legacySignature, a.Config.GitHubWebhookSecret)
+	}
+
+	return webhooks.ValidateWebhookSignature(req.Body, signature, a.Config.GitHubWebhookSecret)
+}
+
 // handleScheduledHTTPRequest processes scheduled events via HTTP POST.
 // path is the normalized path with BasePath already stripped.
 func (a *App) handleScheduledHTTPRequest(ctx context.Context, req Request, path string) Response {
 	if req.Method != "POST" {
 		return errorResponse(405, "method not allowed")
 	}
-	if resp := a.checkAdminAuth(req); resp != nil {
+	if resp := a.checkAdminAuth(req, authAdmin); resp != nil {
 		return *resp
 	}
 
@@ -182,6 +494,11 @@ func (a *App) handleScheduledHTTPRequest(ctx context.Context, req Request, path
 		Type:            RequestTypeScheduled,
 		ScheduledAction: action,
 	}
+	// a JSON body is passed on as the event's data, e.g. to sync a subset
+	// of rules; other bodies are ignored as before
+	if json.Valid(req.Body) {
+		scheduledReq.ScheduledData = req.Body
+	}
 
 	return a.handleScheduledRequest(ctx, scheduledReq)
 }
@@ -209,10 +526,21 @@ func errorResponse(status int, message string) Response {
 	}
 }
 
-// checkAdminAuth validates the admin token from the request.
-// returns nil if auth is disabled (no token configured) or if token is valid.
-// returns an error response if token is required but missing or invalid.
-func (a *App) checkAdminAuth(req Request) *Response {
+// authLevel is the privilege a route requires.
+type authLevel int
+
+const (
+	// authReadOnly accepts the read-only token or the admin token.
+	authReadOnly authLevel = iota
+	// authAdmin accepts only the admin token.
+	authAdmin
+)
+
+// checkAdminAuth validates the bearer token from the request against the
+// privilege level required by the route. returns nil if auth is disabled (no
+// admin token configured) or if token is valid. returns an error response if
+// token is required but missing or invalid.
+func (a *App) checkAdminAuth(req Request, level authLevel) *Response {
 	if a.Config.AdminToken == "" {
 		return nil
 	}
@@ -228,10 +556,13 @@ func (a *App) checkAdminAuth(req Request) *Response {
 		token = strings.TrimPrefix(authHeader, "bearer ")
 	}
 
-	if token != a.Config.AdminToken {
-		resp := errorResponse(401, "unauthorized")
-		return &resp
+	if token == a.Config.AdminToken {
+		return nil
+	}
+	if level == authReadOnly && a.Config.ReadOnlyToken != "" && token == a.Config.ReadOnlyToken {
+		return nil
 	}
 
-	return nil
+	resp := errorResponse(401, "unauthorized")
+	return &resp
 }
@@ -1,8 +1,11 @@
 package app
 
 import (
+	"time"
+
 	"github.com/cruxstack/github-ops-app/internal/github/client"
 	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	gh "github.com/google/go-github/v79/github"
 )
 
@@ -26,8 +29,8 @@ func fakePRComplianceResult() *client.PRComplianceResult {
 		UserHasBypass:    true,
 		UserBypassReason: "repository admin",
 		Violations: []client.ComplianceViolation{
-			{Type: "insufficient_reviews", Description: "required 2 approving reviews, had 0"},
-			{Type: "missing_status_check", Description: "required check 'ci/build' did not pass"},
+			{Type: types.ViolationInsufficientReviews, Description: "required 2 approving reviews, had 0"},
+			{Type: types.ViolationMissingStatusCheck, Description: "required check 'ci/build' did not pass"},
 		},
 	}
 }
@@ -60,9 +63,64 @@ func fakeOktaSyncReports() []*okta.SyncReport {
 	}
 }
 
+// fakeOktaSyncResult returns a sample Okta sync result for testing.
+func fakeOktaSyncResult() *okta.SyncResult {
+	return &okta.SyncResult{
+		Reports:       fakeOktaSyncReports(),
+		DisabledRules: fakeDisabledRules(),
+		EmptyRules:    fakeEmptyRules(),
+		APIQuotas:     fakeAPIQuotas(),
+		Skipped:       fakeSkipCounts(),
+	}
+}
+
+// fakeDisabledRules returns sample disabled sync rule names for testing.
+func fakeDisabledRules() []string {
+	return []string{"legacy-team"}
+}
+
+// fakeEmptyRules returns sample rules that synced nothing for testing.
+func fakeEmptyRules() []okta.EmptyRule {
+	return []okta.EmptyRule{
+		{Rule: "contractors", Reason: okta.EmptyRuleNoGroups},
+		{Rule: "interns", Reason: okta.EmptyRuleNoMembers, OktaGroups: []string{"github-interns"}},
+	}
+}
+
+// fakeSkipCounts returns sample skipped member counts with a previous sync
+// for testing.
+func fakeSkipCounts() []okta.SkipCount {
+	previous := func(count int) *int { return &count }
+	return []okta.SkipCount{
+		{Reason: okta.SkipReasonNoGitHubUsername, Count: 3, Previous: previous(1)},
+		{Reason: okta.SkipReasonNotOrgMember, Count: 1, Previous: previous(1)},
+		{Reason: okta.SkipReasonExternal, Count: 0, Previous: previous(2)},
+	}
+}
+
+// fakeAPIQuotas returns sample API rate limits for testing.
+func fakeAPIQuotas() []types.APIQuota {
+	reset := time.Now().Add(42 * time.Minute)
+	return []types.APIQuota{
+		{Service: "github", Limit: 5000, Remaining: 4210, Reset: reset},
+		{Service: "okta", Limit: 600, Remaining: 45, Reset: reset},
+	}
+}
+
 // fakeOrphanedUsersReport returns sample orphaned users data for testing.
 func fakeOrphanedUsersReport() *okta.OrphanedUsersReport {
 	return &okta.OrphanedUsersReport{
 		OrphanedUsers: []string{"orphan-user-1", "orphan-user-2", "legacy-bot"},
 	}
 }
+
+// fakeMissingUsernamesReport returns sample users without a GitHub username
+// for testing.
+func fakeMissingUsernamesReport() *okta.MissingUsernamesReport {
+	return &okta.MissingUsernamesReport{
+		Users: []okta.MissingUsername{
+			{Email: "new.hire@example.com", Groups: []string{"github-eng-platform"}},
+			{Email: "contractor@example.com", Groups: []string{"github-eng-platform", "github-eng-security"}},
+		},
+	}
+}
@@ -2,11 +2,29 @@ package config
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/cruxstack/github-ops-app/internal/types"
 )
 
 func TestResolveEnvValue(t *testing.T) {
 	ctx := context.Background()
+	// aws credentials are unavailable in tests; don't retry the ssm fetch
+	t.Setenv("APP_SSM_MAX_ATTEMPTS", "1")
 
 	tests := []struct {
 		name      string
@@ -63,3 +81,778 @@ func TestResolveEnvValue(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSSMParameterWithRetry(t *testing.T) {
+	opts := ssmFetchOptions{timeout: 20 * time.Millisecond, maxAttempts: 3, baseDelay: time.Millisecond}
+	value := "secret"
+	ok := &ssm.GetParameterOutput{Parameter: &ssmtypes.Parameter{Value: &value}}
+
+	t.Run("retries timed out attempts", func(t *testing.T) {
+		calls := 0
+		result, attempts, err := getSSMParameterWithRetry(context.Background(), opts, func(ctx context.Context) (*ssm.GetParameterOutput, error) {
+			calls++
+			if calls < 3 {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}
+			return ok, nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if attempts != 3 || result != ok {
+			t.Errorf("expected success on attempt 3, got attempt %d", attempts)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		_, attempts, err := getSSMParameterWithRetry(context.Background(), opts, func(ctx context.Context) (*ssm.GetParameterOutput, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		if err == nil {
+			t.Fatal("expected error after exhausting attempts")
+		}
+		if attempts != 3 {
+			t.Errorf("expected 3 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		_, attempts, err := getSSMParameterWithRetry(context.Background(), opts, func(ctx context.Context) (*ssm.GetParameterOutput, error) {
+			return nil, &ssmtypes.ParameterNotFound{}
+		})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+func TestSSMFetchOptionsFromEnv(t *testing.T) {
+	opts, err := ssmFetchOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.timeout != DefaultSSMTimeout || opts.maxAttempts != DefaultSSMMaxAttempts {
+		t.Errorf("expected defaults, got %+v", opts)
+	}
+
+	t.Setenv("APP_SSM_TIMEOUT", "2s")
+	t.Setenv("APP_SSM_MAX_ATTEMPTS", "5")
+	opts, err = ssmFetchOptionsFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.timeout != 2*time.Second || opts.maxAttempts != 5 {
+		t.Errorf("expected 2s and 5 attempts, got %+v", opts)
+	}
+
+	t.Setenv("APP_SSM_MAX_ATTEMPTS", "0")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for zero max attempts")
+	}
+}
+
+func TestMinApprovalsForBranch(t *testing.T) {
+	cfg := &Config{
+		PRMinApprovals: map[string]int{
+			"main":      1,
+			"release/*": 2,
+			"release-*": 3,
+		},
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   int
+	}{
+		{name: "exact match", branch: "main", want: 1},
+		{name: "glob match", branch: "release/1.0", want: 2},
+		{name: "refs prefix stripped", branch: "refs/heads/release/2.0", want: 2},
+		{name: "no match", branch: "develop", want: 0},
+		{name: "glob does not cross slash", branch: "release/1.0/hotfix", want: 0},
+		{name: "other glob", branch: "release-1", want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.MinApprovalsForBranch(tt.branch); got != tt.want {
+				t.Errorf("MinApprovalsForBranch(%q) = %d, want %d", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldMonitorBranch_RulesetConditions(t *testing.T) {
+	conditions := []types.RefNameCondition{
+		{Include: []string{"refs/heads/main"}},
+		{Include: []string{"refs/heads/release/**"}, Exclude: []string{"refs/heads/release/*-rc"}},
+	}
+
+	tests := []struct {
+		name    string
+		enabled bool
+		branch  string
+		want    bool
+	}{
+		{name: "static list", branch: "master", want: true},
+		{name: "ruleset ignored when disabled", branch: "release/1.0", want: false},
+		{name: "ruleset glob", enabled: true, branch: "release/1.0", want: true},
+		{name: "double star crosses slash", enabled: true, branch: "refs/heads/release/1.0/hotfix", want: true},
+		{name: "excluded", enabled: true, branch: "release/2.0-rc", want: false},
+		{name: "no match", enabled: true, branch: "develop", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				GitHubOrg:                "acme",
+				GitHubAppID:              1,
+				GitHubAppPrivateKey:      []byte("key"),
+				GitHubInstallationID:     1,
+				PRComplianceEnabled:      true,
+				PRMonitoredBranches:      []string{"master"},
+				PRMonitorRulesetBranches: tt.enabled,
+			}
+			if got := cfg.ShouldMonitorBranch(tt.branch, conditions...); got != tt.want {
+				t.Errorf("ShouldMonitorBranch(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLogFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "", want: LogFormatAuto},
+		{value: "auto", want: LogFormatAuto},
+		{value: "JSON", want: LogFormatJSON},
+		{value: " text ", want: LogFormatText},
+		{value: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLogFormat(tt.value)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("parseLogFormat(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseLogFormat(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value   string
+		debug   bool
+		want    slog.Level
+		wantErr bool
+	}{
+		{value: "", debug: false, want: slog.LevelInfo},
+		{value: "", debug: true, want: slog.LevelDebug},
+		{value: "warn", debug: true, want: slog.LevelWarn},
+		{value: "DEBUG", debug: false, want: slog.LevelDebug},
+		{value: "error", want: slog.LevelError},
+		{value: "verbose", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseLogLevel(tt.value, tt.debug)
+		if tt.wantErr != (err != nil) {
+			t.Errorf("parseLogLevel(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseLogLevel(%q, %v) = %v, want %v", tt.value, tt.debug, got, tt.want)
+		}
+	}
+}
+
+func TestNewConfig_InvalidTeamNameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+	}{
+		{name: "parse error", template: "team-{{.Group"},
+		{name: "unknown field", template: "team-{{.Team}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("APP_OKTA_SYNC_RULES", `[{"name":"eng","okta_group_pattern":"^eng-","team_name_template":"`+tt.template+`"}]`)
+
+			if _, err := NewConfig(); err == nil {
+				t.Error("expected error for invalid team name template")
+			}
+		})
+	}
+}
+
+func TestNewConfig_SyncRuleTeamSlug(t *testing.T) {
+	t.Setenv("APP_OKTA_SYNC_RULES", `[{"okta_group_name":"Platform Engineering","github_team_slug":"platform-eng"}]`)
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OktaSyncRules[0].GitHubTeamSlug != "platform-eng" {
+		t.Errorf("unexpected rule: %+v", cfg.OktaSyncRules[0])
+	}
+
+	for _, rules := range []string{
+		`[{"okta_group_name":"eng","github_team_slug":"eng","github_team_name":"Engineering"}]`,
+		`[{"okta_group_name":"eng","github_team_slug":"eng","parent_team_slug":"org"}]`,
+	} {
+		t.Setenv("APP_OKTA_SYNC_RULES", rules)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", rules)
+		}
+	}
+}
+
+func TestNewConfig_ReadOnlyTokenRequiresAdminToken(t *testing.T) {
+	t.Setenv("APP_ADMIN_TOKEN", "")
+	t.Setenv("APP_READONLY_TOKEN", "read-only")
+
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error when read-only token is set without admin token")
+	}
+
+	t.Setenv("APP_ADMIN_TOKEN", "admin")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.ReadOnlyToken != "read-only" {
+		t.Errorf("ReadOnlyToken = %q, want %q", cfg.ReadOnlyToken, "read-only")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := &Config{
+		ReadOnlyToken:           "read-only",
+		PRComplianceEnabled:     true,
+		SlackToken:              "xoxb-token",
+		OktaSyncSafetyThreshold: 1.5,
+		OktaSyncRules: []types.SyncRule{
+			{Name: "eng", OktaGroupPattern: "^eng-(", PreserveMembers: []string{"["}},
+			{Name: "security", OktaGroupName: "security", TeamPrivacy: "secret", ParentTeamSlug: "engineering"},
+		},
+	}
+
+	errs := cfg.Validate()
+	for _, want := range []string{
+		"APP_READONLY_TOKEN",
+		"APP_PR_COMPLIANCE_ENABLED",
+		"APP_SLACK_CHANNEL",
+		"APP_OKTA_SYNC_SAFETY_THRESHOLD",
+		"okta_group_pattern",
+		"preserve_members",
+		"secret teams cannot have a parent_team_slug",
+	} {
+		found := false
+		for _, err := range errs {
+			found = found || strings.Contains(err.Error(), want)
+		}
+		if !found {
+			t.Errorf("expected an error mentioning %q, got %v", want, errs)
+		}
+	}
+
+	if errs := (&Config{OktaSyncSafetyThreshold: 0.5}).Validate(); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestNewConfig_ReportsAllValidationErrors(t *testing.T) {
+	t.Setenv("APP_SLACK_TOKEN", "xoxb-token")
+	t.Setenv("APP_OKTA_SYNC_SAFETY_THRESHOLD", "2")
+
+	_, err := NewConfig()
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, want := range []string{"2 problems", "APP_SLACK_CHANNEL", "APP_OKTA_SYNC_SAFETY_THRESHOLD"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestNewConfig_StoreBackend(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.StoreBackend != "memory" {
+		t.Errorf("expected memory backend by default, got %q", cfg.StoreBackend)
+	}
+
+	t.Setenv("APP_STORE_BACKEND", "DynamoDB")
+	t.Setenv("APP_STORE_DYNAMODB_TABLE", "github-ops-app")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.StoreBackend != "dynamodb" || cfg.StoreDynamoDBTable != "github-ops-app" {
+		t.Errorf("unexpected store config %q, %q", cfg.StoreBackend, cfg.StoreDynamoDBTable)
+	}
+
+	t.Setenv("APP_STORE_DYNAMODB_TABLE", "")
+	if _, err := NewConfig(); err == nil || !strings.Contains(err.Error(), "APP_STORE_DYNAMODB_TABLE") {
+		t.Errorf("expected error for missing table, got %v", err)
+	}
+
+	t.Setenv("APP_STORE_BACKEND", "redis")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestNewConfig_PRComplianceActions(t *testing.T) {
+	t.Setenv("APP_PR_COMPLIANCE_ACTIONS", "closed, Edited")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for action, want := range map[string]bool{
+		PRActionClosed:   true,
+		PRActionEdited:   true,
+		PRActionReopened: false,
+		"opened":         false,
+	} {
+		if got := cfg.ShouldHandlePRAction(action); got != want {
+			t.Errorf("ShouldHandlePRAction(%q) = %v, want %v", action, got, want)
+		}
+	}
+
+	t.Setenv("APP_PR_COMPLIANCE_ACTIONS", "closed,synchronize")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unsupported pr action")
+	}
+}
+
+func TestNewConfig_PRProtectionCacheTTL(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PRProtectionCacheTTL != time.Minute {
+		t.Errorf("expected default ttl of 1m, got %s", cfg.PRProtectionCacheTTL)
+	}
+
+	t.Setenv("APP_PR_PROTECTION_CACHE_TTL", "0")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PRProtectionCacheTTL != 0 {
+		t.Errorf("expected caching disabled, got %s", cfg.PRProtectionCacheTTL)
+	}
+
+	t.Setenv("APP_PR_PROTECTION_CACHE_TTL", "-5s")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for negative ttl")
+	}
+}
+
+func TestValidateRSAPrivateKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+	pkcs8RSA, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	pkcs8EC, _ := x509.MarshalPKCS8PrivateKey(ecKey)
+
+	encode := func(blockType string, der []byte) []byte {
+		return pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	}
+
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr bool
+	}{
+		{name: "pkcs1 rsa", key: encode("RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))},
+		{name: "pkcs8 rsa", key: encode("PRIVATE KEY", pkcs8RSA)},
+		{name: "pkcs8 ec", key: encode("PRIVATE KEY", pkcs8EC), wantErr: true},
+		{name: "not pem", key: []byte("not-a-key"), wantErr: true},
+		{name: "corrupt body", key: encode("RSA PRIVATE KEY", []byte("garbage")), wantErr: true},
+		{name: "public key", key: encode("PUBLIC KEY", []byte("garbage")), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRSAPrivateKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateRSAPrivateKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewConfig_InvalidPrivateKeyNamesVariable(t *testing.T) {
+	t.Setenv("APP_OKTA_PRIVATE_KEY", "not-a-key")
+	_, err := NewConfig()
+	if err == nil {
+		t.Fatal("expected error for malformed okta private key")
+	}
+	if !strings.Contains(err.Error(), "APP_OKTA_PRIVATE_KEY") {
+		t.Errorf("expected error to name APP_OKTA_PRIVATE_KEY, got %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	base := func() *Config {
+		return &Config{
+			GitHubOrg:           "acme",
+			AdminToken:          "secret-a",
+			Environment:         "staging",
+			PRComplianceEnabled: true,
+			PRMonitoredBranches: []string{"main", "release"},
+			OktaSyncRules: []types.SyncRule{
+				{Name: "eng", OktaGroupPattern: "^eng-", PreserveMembers: []string{"svc-a", "svc-b"}},
+				{Name: "ops", OktaGroupName: "ops"},
+			},
+		}
+	}
+
+	want := base().Fingerprint()
+	if len(want) != 64 {
+		t.Fatalf("expected sha-256 hex fingerprint, got %q", want)
+	}
+
+	reordered := base()
+	reordered.PRMonitoredBranches = []string{"release", "main"}
+	reordered.OktaSyncRules = []types.SyncRule{
+		{Name: "ops", OktaGroupName: "ops"},
+		{Name: "eng", OktaGroupPattern: "^eng-", PreserveMembers: []string{"svc-b", "svc-a"}},
+	}
+	if got := reordered.Fingerprint(); got != want {
+		t.Error("expected fingerprint to ignore list ordering")
+	}
+	if reordered.PRMonitoredBranches[0] != "release" {
+		t.Error("expected fingerprint not to modify config")
+	}
+
+	otherEnv := base()
+	otherEnv.Environment = "production"
+	otherEnv.AdminToken = "secret-b"
+	if got := otherEnv.Fingerprint(); got != want {
+		t.Error("expected fingerprint to ignore environment label and secret values")
+	}
+
+	changed := base()
+	changed.PRMonitoredBranches = []string{"main"}
+	if got := changed.Fingerprint(); got == want {
+		t.Error("expected fingerprint to change with monitored branches")
+	}
+
+	noToken := base()
+	noToken.AdminToken = ""
+	if got := noToken.Fingerprint(); got == want {
+		t.Error("expected fingerprint to change when a secret is unset")
+	}
+}
+
+func TestNewConfig_GitHubRateLimitRetry(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitHubRateLimitMaxRetries != 3 || cfg.GitHubRateLimitMaxBackoff != time.Minute {
+		t.Errorf("expected defaults of 3 retries and 1m backoff, got %d and %s",
+			cfg.GitHubRateLimitMaxRetries, cfg.GitHubRateLimitMaxBackoff)
+	}
+
+	t.Setenv("APP_GITHUB_RATE_LIMIT_MAX_RETRIES", "0")
+	t.Setenv("APP_GITHUB_RATE_LIMIT_MAX_BACKOFF", "30s")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.GitHubRateLimitMaxRetries != 0 || cfg.GitHubRateLimitMaxBackoff != 30*time.Second {
+		t.Errorf("expected 0 retries and 30s backoff, got %d and %s",
+			cfg.GitHubRateLimitMaxRetries, cfg.GitHubRateLimitMaxBackoff)
+	}
+
+	t.Setenv("APP_GITHUB_RATE_LIMIT_MAX_RETRIES", "-1")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for negative retries")
+	}
+
+	t.Setenv("APP_GITHUB_RATE_LIMIT_MAX_RETRIES", "3")
+	t.Setenv("APP_GITHUB_RATE_LIMIT_MAX_BACKOFF", "0s")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for non-positive backoff")
+	}
+}
+
+func TestNewConfig_WebhookSuccessResponse(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.WebhookSuccessStatus != 200 || cfg.WebhookSuccessBody != "ok" || cfg.WebhookSuccessJSON {
+		t.Errorf("expected default 200 ok, got %d %q json=%v",
+			cfg.WebhookSuccessStatus, cfg.WebhookSuccessBody, cfg.WebhookSuccessJSON)
+	}
+
+	for _, status := range []string{"302", "500", "accepted"} {
+		t.Setenv("APP_WEBHOOK_SUCCESS_STATUS", status)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for status %s", status)
+		}
+	}
+}
+
+func TestNewConfig_SlackRetry(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SlackMaxAttempts != 4 || cfg.SlackRetryTimeout != 30*time.Second {
+		t.Errorf("expected defaults of 4 attempts and 30s timeout, got %d and %s",
+			cfg.SlackMaxAttempts, cfg.SlackRetryTimeout)
+	}
+
+	t.Setenv("APP_SLACK_MAX_ATTEMPTS", "1")
+	t.Setenv("APP_SLACK_RETRY_TIMEOUT", "0s")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SlackMaxAttempts != 1 || cfg.SlackRetryTimeout != 0 {
+		t.Errorf("expected 1 attempt and no timeout, got %d and %s", cfg.SlackMaxAttempts, cfg.SlackRetryTimeout)
+	}
+
+	t.Setenv("APP_SLACK_MAX_ATTEMPTS", "0")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for zero attempts")
+	}
+
+	t.Setenv("APP_SLACK_MAX_ATTEMPTS", "4")
+	t.Setenv("APP_SLACK_RETRY_TIMEOUT", "soon")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+}
+
+func TestParseOktaConnections(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}))
+	conn := func(domain string) string {
+		b, _ := json.Marshal(OktaConnection{Domain: domain, ClientID: "client", PrivateKey: keyPEM})
+		return string(b)
+	}
+
+	connections, err := parseOktaConnections(`{"Acquired":`+conn("acquired.okta.com")+`}`, []string{"okta.groups.read"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, ok := connections["acquired"]
+	if !ok || got.Domain != "acquired.okta.com" || !slices.Equal(got.Scopes, []string{"okta.groups.read"}) {
+		t.Errorf("expected lowercased connection with default scopes, got %+v", connections)
+	}
+
+	for _, raw := range []string{
+		`["acquired"]`,
+		`{"acquired":` + conn("") + `}`,
+		`{"acquired":{"domain":"a.okta.com","client_id":"c","private_key":"not-a-key"}}`,
+		`{"Acquired":` + conn("a.okta.com") + `,"acquired":` + conn("b.okta.com") + `}`,
+	} {
+		if _, err := parseOktaConnections(raw, nil); err == nil {
+			t.Errorf("expected error for %s", raw)
+		}
+	}
+
+	cfg := &Config{
+		OktaConnections: connections,
+		OktaSyncRules: []types.SyncRule{
+			{Name: "known", OktaGroupName: "eng", OktaConnection: "ACQUIRED"},
+			{Name: "unknown", OktaGroupName: "eng", OktaConnection: "other"},
+		},
+	}
+	errs := cfg.Validate()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unknown okta_connection 'other'") {
+		t.Errorf("expected one unknown connection error, got %v", errs)
+	}
+
+	redacted := cfg.Redacted().OktaConnections["acquired"]
+	if redacted.PrivateKey == keyPEM || redacted.ClientID == "client" {
+		t.Error("expected connection credentials to be redacted")
+	}
+}
+
+func TestNewConfig_PRBypassPermissions(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.PRBypassPermissions, []string{"admin", "maintain"}) {
+		t.Errorf("expected default [admin maintain], got %v", cfg.PRBypassPermissions)
+	}
+
+	t.Setenv("APP_PR_BYPASS_PERMISSIONS", " Admin, admin ,")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.PRBypassPermissions, []string{"admin"}) {
+		t.Errorf("expected [admin], got %v", cfg.PRBypassPermissions)
+	}
+
+	for _, value := range []string{"owner", " , "} {
+		t.Setenv("APP_PR_BYPASS_PERMISSIONS", value)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
+
+func TestNewConfig_PagerDuty(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PagerDutyRoutingKey != "" || cfg.PagerDutySafetyThresholdRuns != 3 {
+		t.Errorf("expected no routing key and 3 runs by default, got %q and %d",
+			cfg.PagerDutyRoutingKey, cfg.PagerDutySafetyThresholdRuns)
+	}
+
+	t.Setenv("APP_PAGERDUTY_ROUTING_KEY", "R0UT1NGK3Y")
+	t.Setenv("APP_PAGERDUTY_SAFETY_THRESHOLD_RUNS", "0")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PagerDutySafetyThresholdRuns != 0 {
+		t.Errorf("expected 0 runs, got %d", cfg.PagerDutySafetyThresholdRuns)
+	}
+	if got := cfg.Redacted().PagerDutyRoutingKey; got == "R0UT1NGK3Y" {
+		t.Error("expected routing key to be redacted")
+	}
+
+	t.Setenv("APP_PAGERDUTY_SAFETY_THRESHOLD_RUNS", "-1")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for negative runs")
+	}
+}
+
+func TestNewConfig_SlackChannelPRBypassRepos(t *testing.T) {
+	t.Setenv("APP_SLACK_CHANNEL_PR_BYPASS_REPOS", `{"Acme/Payments":"C_PAYMENTS","acme/web":"C_WEB"}`)
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"acme/payments": "C_PAYMENTS", "acme/web": "C_WEB"}
+	if !maps.Equal(cfg.SlackChannelPRBypassRepos, want) {
+		t.Errorf("expected %v, got %v", want, cfg.SlackChannelPRBypassRepos)
+	}
+
+	for _, value := range []string{`["acme/web"]`, `{"web":"C_WEB"}`, `{"acme/web":""}`} {
+		t.Setenv("APP_SLACK_CHANNEL_PR_BYPASS_REPOS", value)
+		if _, err := NewConfig(); err == nil {
+			t.Errorf("expected error for %s", value)
+		}
+	}
+}
+
+func TestNewConfig_OktaGitHubUserFields(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.OktaGitHubUserFields, []string{"githubUsername"}) {
+		t.Errorf("expected default [githubUsername], got %v", cfg.OktaGitHubUserFields)
+	}
+
+	t.Setenv("APP_OKTA_GITHUB_USER_FIELD", " githubUsername, github_login ,,custom.github")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"githubUsername", "github_login", "custom.github"}
+	if !slices.Equal(cfg.OktaGitHubUserFields, want) {
+		t.Errorf("expected %v, got %v", want, cfg.OktaGitHubUserFields)
+	}
+}
+
+func TestNewConfig_OktaSyncParallelism(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OktaSyncParallelism != 1 {
+		t.Errorf("expected default parallelism 1, got %d", cfg.OktaSyncParallelism)
+	}
+
+	t.Setenv("APP_OKTA_SYNC_PARALLELISM", "8")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OktaSyncParallelism != 8 {
+		t.Errorf("expected parallelism 8, got %d", cfg.OktaSyncParallelism)
+	}
+
+	t.Setenv("APP_OKTA_SYNC_PARALLELISM", "0")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for zero parallelism")
+	}
+}
+
+func TestNewConfig_OrphanedUserExemptions(t *testing.T) {
+	t.Setenv("APP_OKTA_ORPHANED_USER_EXEMPT_TEAMS", "Bots, security-auditors,")
+	t.Setenv("APP_OKTA_ORPHANED_USER_EXEMPT_LOGINS", "CI-Runner")
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(cfg.OrphanedUserExemptTeams, []string{"bots", "security-auditors"}) {
+		t.Errorf("expected lowercase exempt teams, got %v", cfg.OrphanedUserExemptTeams)
+	}
+	if !slices.Equal(cfg.OrphanedUserExemptLogins, []string{"ci-runner"}) {
+		t.Errorf("expected lowercase exempt logins, got %v", cfg.OrphanedUserExemptLogins)
+	}
+}
+
+func TestNewConfig_OktaSyncMemberOrder(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OktaSyncMemberOrder != MemberOrderAddFirst {
+		t.Errorf("expected default member order %q, got %q", MemberOrderAddFirst, cfg.OktaSyncMemberOrder)
+	}
+
+	t.Setenv("APP_OKTA_SYNC_MEMBER_ORDER", "remove-first")
+	cfg, err = NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OktaSyncMemberOrder != MemberOrderRemoveFirst {
+		t.Errorf("expected member order %q, got %q", MemberOrderRemoveFirst, cfg.OktaSyncMemberOrder)
+	}
+
+	t.Setenv("APP_OKTA_SYNC_MEMBER_ORDER", "interleaved")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for unknown member order")
+	}
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// OktaConnection is an additional Okta org that sync rules can source groups
+// from by referencing its name in okta_connection, e.g. the tenant of an
+// acquired company. the GitHub username fields are shared with the global
+// Okta org.
+type OktaConnection struct {
+	Domain       string   `json:"domain"`
+	ClientID     string   `json:"client_id"`
+	PrivateKey   string   `json:"private_key"`
+	PrivateKeyID string   `json:"private_key_id,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	BaseURL      string   `json:"base_url,omitempty"`
+}
+
+// parseOktaConnections parses APP_OKTA_CONNECTIONS, a JSON object of
+// connection name to connection. names are lowercased so rules reference
+// them case-insensitively. scopes default to defaultScopes.
+func parseOktaConnections(raw string, defaultScopes []string) (map[string]OktaConnection, error) {
+	var parsed map[string]OktaConnection
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, errors.Wrap(err, "failed to parse APP_OKTA_CONNECTIONS")
+	}
+
+	connections := make(map[string]OktaConnection, len(parsed))
+	for name, conn := range parsed {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return nil, errors.New("invalid APP_OKTA_CONNECTIONS: connection name must not be empty")
+		}
+		if _, dup := connections[key]; dup {
+			return nil, errors.Newf("invalid APP_OKTA_CONNECTIONS: duplicate connection '%s'", name)
+		}
+		if conn.Domain == "" || conn.ClientID == "" || conn.PrivateKey == "" {
+			return nil, errors.Newf("invalid okta connection '%s': domain, client_id, and private_key are required", name)
+		}
+		if err := validateRSAPrivateKey([]byte(conn.PrivateKey)); err != nil {
+			return nil, errors.Wrapf(err, "invalid private_key for okta connection '%s'", name)
+		}
+		if len(conn.Scopes) == 0 {
+			conn.Scopes = slices.Clone(defaultScopes)
+		}
+		connections[key] = conn
+	}
+	return connections, nil
+}
@@ -0,0 +1,314 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// complianceViolationTypes are the violation types a policy may suppress.
+var complianceViolationTypes = []string{
+	types.ViolationInsufficientReviews,
+	types.ViolationMissingTeamReview,
+	types.ViolationMissingStatusCheck,
+	types.ViolationUnsignedCommits,
+	types.ViolationSelfApproval,
+	types.ViolationMergedWhileDraft,
+	types.ViolationMissingRequiredDeployment,
+}
+
+// PRPolicy holds PR compliance settings. it is used both for sections of a
+// policy file and for the effective policy of a repository.
+type PRPolicy struct {
+	// MonitoredBranches lists branch names checked for compliance.
+	MonitoredBranches []string `json:"monitored_branches,omitempty" yaml:"monitored_branches"`
+	// MinApprovals maps branch glob patterns to a policy floor for approving
+	// reviews.
+	MinApprovals map[string]int `json:"min_approvals,omitempty" yaml:"min_approvals"`
+	// RequiredTeam is a team slug that must include an approving reviewer.
+	RequiredTeam string `json:"required_team,omitempty" yaml:"required_team"`
+	// BypassAllowlist lists logins, such as release bots, whose bypasses are
+	// expected and not reported.
+	BypassAllowlist []string `json:"bypass_allowlist,omitempty" yaml:"bypass_allowlist"`
+	// SuppressedViolations lists violation types that are ignored.
+	SuppressedViolations []string `json:"suppressed_violations,omitempty" yaml:"suppressed_violations"`
+	// ExemptLabel is a PR label that downgrades a bypass alert to an audit
+	// log entry.
+	ExemptLabel string `json:"exempt_label,omitempty" yaml:"exempt_label"`
+	// ExemptLabelApprovers lists logins whose exemption label is honored.
+	ExemptLabelApprovers []string `json:"exempt_label_approvers,omitempty" yaml:"exempt_label_approvers"`
+	// ExemptLabelApproverTeams lists team slugs whose members' exemption
+	// label is honored.
+	ExemptLabelApproverTeams []string `json:"exempt_label_approver_teams,omitempty" yaml:"exempt_label_approver_teams"`
+	// PushExemptions lists direct pushes to non-default monitored branches,
+	// such as hotfix branches, that are expected and not reported. the
+	// default branch is never exempt.
+	PushExemptions []PushExemption `json:"push_exemptions,omitempty" yaml:"push_exemptions"`
+}
+
+// PushExemption exempts direct pushes to branches matching a glob pattern.
+type PushExemption struct {
+	// Branch is a glob pattern matched against the pushed branch name.
+	Branch string `json:"branch" yaml:"branch"`
+	// Users lists the logins the exemption applies to. empty means anyone.
+	Users []string `json:"users,omitempty" yaml:"users"`
+	// AllowForcePush also exempts force pushes, which are otherwise always
+	// reported.
+	AllowForcePush bool `json:"allow_force_push,omitempty" yaml:"allow_force_push"`
+}
+
+// CompliancePolicy is a PR compliance policy file. the top-level settings
+// apply to every repository and Repos overrides them per repository, keyed
+// by owner/repo.
+type CompliancePolicy struct {
+	PRPolicy `yaml:",inline"`
+	Repos    map[string]PRPolicy `json:"repos,omitempty" yaml:"repos"`
+}
+
+// LoadCompliancePolicy reads and validates a YAML or JSON policy file.
+// unknown fields are rejected so typos do not silently weaken the policy.
+func LoadCompliancePolicy(file string) (*CompliancePolicy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read policy file %s", file)
+	}
+
+	// json is valid yaml, so one decoder handles both formats
+	var policy CompliancePolicy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Newf("policy file %s is empty", file)
+		}
+		return nil, errors.Wrapf(err, "failed to parse policy file %s", file)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, errors.Wrapf(err, "invalid policy file %s", file)
+	}
+	return &policy, nil
+}
+
+// Validate checks the policy and every repository override.
+func (p *CompliancePolicy) Validate() error {
+	if err := p.PRPolicy.validate(); err != nil {
+		return err
+	}
+	for repo, override := range p.Repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+			return errors.Newf("repo key '%s' must be in owner/repo form", repo)
+		}
+		if err := override.validate(); err != nil {
+			return errors.Wrapf(err, "repo '%s'", repo)
+		}
+	}
+	return nil
+}
+
+// validate checks a single policy section.
+func (p PRPolicy) validate() error {
+	for _, branch := range p.MonitoredBranches {
+		if strings.TrimSpace(branch) == "" {
+			return errors.New("monitored_branches must not contain empty names")
+		}
+	}
+	for pattern, count := range p.MinApprovals {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return errors.Wrapf(err, "invalid branch pattern '%s' in min_approvals", pattern)
+		}
+		if count < 0 {
+			return errors.Newf("invalid approval count %d for branch pattern '%s' in min_approvals", count, pattern)
+		}
+	}
+	for _, login := range p.BypassAllowlist {
+		if strings.TrimSpace(login) == "" {
+			return errors.New("bypass_allowlist must not contain empty logins")
+		}
+	}
+	for _, login := range p.ExemptLabelApprovers {
+		if strings.TrimSpace(login) == "" {
+			return errors.New("exempt_label_approvers must not contain empty logins")
+		}
+	}
+	for _, team := range p.ExemptLabelApproverTeams {
+		if strings.TrimSpace(team) == "" {
+			return errors.New("exempt_label_approver_teams must not contain empty team slugs")
+		}
+	}
+	for i, exemption := range p.PushExemptions {
+		if exemption.Branch == "" {
+			return errors.Newf("push_exemptions[%d] must set a branch pattern", i)
+		}
+		if _, err := path.Match(exemption.Branch, ""); err != nil {
+			return errors.Wrapf(err, "invalid branch pattern '%s' in push_exemptions", exemption.Branch)
+		}
+		for _, login := range exemption.Users {
+			if strings.TrimSpace(login) == "" {
+				return errors.Newf("push_exemptions[%d] users must not contain empty logins", i)
+			}
+		}
+	}
+	for _, violation := range p.SuppressedViolations {
+		if !slices.Contains(complianceViolationTypes, violation) {
+			return errors.Newf("unknown violation type '%s' in suppressed_violations, must be one of: %s",
+				violation, strings.Join(complianceViolationTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// applyCompliancePolicy makes the policy file's top-level settings supersede
+// the individual APP_PR_* variables. settings the policy leaves unset keep
+// their environment values.
+func (c *Config) applyCompliancePolicy(policy *CompliancePolicy) {
+	if len(policy.MonitoredBranches) > 0 {
+		c.PRMonitoredBranches = policy.MonitoredBranches
+	}
+	if policy.MinApprovals != nil {
+		c.PRMinApprovals = policy.MinApprovals
+	}
+	if policy.RequiredTeam != "" {
+		c.PRRequiredTeam = policy.RequiredTeam
+	}
+	if policy.ExemptLabel != "" {
+		c.PRExemptLabel = policy.ExemptLabel
+	}
+	if len(policy.ExemptLabelApprovers) > 0 {
+		c.PRExemptLabelApprovers = policy.ExemptLabelApprovers
+	}
+	if len(policy.ExemptLabelApproverTeams) > 0 {
+		c.PRExemptLabelApproverTeams = policy.ExemptLabelApproverTeams
+	}
+	c.PRBypassAllowlist = policy.BypassAllowlist
+	c.PRSuppressedViolations = policy.SuppressedViolations
+	c.PRPushExemptions = policy.PushExemptions
+
+	if len(policy.Repos) > 0 {
+		c.PRRepoPolicies = make(map[string]PRPolicy, len(policy.Repos))
+		for repo, override := range policy.Repos {
+			c.PRRepoPolicies[strings.ToLower(repo)] = override
+		}
+	}
+}
+
+// PRPolicyFor returns the effective compliance policy for a repository
+// (owner/repo). a repository override replaces the monitored branches,
+// approval floors, required team, exempt label and its approvers, and push
+// exemptions it sets, and adds to the bypass allowlist and suppressed
+// violations.
+func (c *Config) PRPolicyFor(repoFullName string) PRPolicy {
+	policy := PRPolicy{
+		MonitoredBranches:    c.PRMonitoredBranches,
+		MinApprovals:         c.PRMinApprovals,
+		RequiredTeam:         c.PRRequiredTeam,
+		BypassAllowlist:      c.PRBypassAllowlist,
+		SuppressedViolations: c.PRSuppressedViolations,
+		ExemptLabel:          c.PRExemptLabel,
+		PushExemptions:       c.PRPushExemptions,
+
+		ExemptLabelApprovers:     c.PRExemptLabelApprovers,
+		ExemptLabelApproverTeams: c.PRExemptLabelApproverTeams,
+	}
+
+	override, ok := c.PRRepoPolicies[strings.ToLower(repoFullName)]
+	if !ok {
+		return policy
+	}
+	if len(override.MonitoredBranches) > 0 {
+		policy.MonitoredBranches = override.MonitoredBranches
+	}
+	if override.MinApprovals != nil {
+		policy.MinApprovals = override.MinApprovals
+	}
+	if override.RequiredTeam != "" {
+		policy.RequiredTeam = override.RequiredTeam
+	}
+	if override.ExemptLabel != "" {
+		policy.ExemptLabel = override.ExemptLabel
+	}
+	if override.ExemptLabelApprovers != nil {
+		policy.ExemptLabelApprovers = override.ExemptLabelApprovers
+	}
+	if override.ExemptLabelApproverTeams != nil {
+		policy.ExemptLabelApproverTeams = override.ExemptLabelApproverTeams
+	}
+	if override.PushExemptions != nil {
+		policy.PushExemptions = override.PushExemptions
+	}
+	policy.BypassAllowlist = slices.Concat(policy.BypassAllowlist, override.BypassAllowlist)
+	policy.SuppressedViolations = slices.Concat(policy.SuppressedViolations, override.SuppressedViolations)
+	return policy
+}
+
+// IsMonitoredBranch returns true if the branch is in the monitored list.
+func (p PRPolicy) IsMonitoredBranch(branch string) bool {
+	return slices.Contains(p.MonitoredBranches, strings.TrimPrefix(branch, "refs/heads/"))
+}
+
+// MinApprovalsForBranch returns the minimum number of approving reviews for
+// a branch. when multiple patterns match, the highest count wins. returns 0
+// if no pattern matches.
+func (p PRPolicy) MinApprovalsForBranch(branch string) int {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	minApprovals := 0
+	for pattern, count := range p.MinApprovals {
+		if matched, _ := path.Match(pattern, branch); matched && count > minApprovals {
+			minApprovals = count
+		}
+	}
+	return minApprovals
+}
+
+// IsPushExempt returns true if a direct push by login to branch matches one
+// of the policy's push exemptions. pushes to the default branch, or when the
+// default branch is unknown, are never exempt.
+func (p PRPolicy) IsPushExempt(branch, defaultBranch, login string, forced bool) bool {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	if defaultBranch == "" || branch == defaultBranch {
+		return false
+	}
+	for _, exemption := range p.PushExemptions {
+		if forced && !exemption.AllowForcePush {
+			continue
+		}
+		if matched, _ := path.Match(exemption.Branch, branch); !matched {
+			continue
+		}
+		if len(exemption.Users) == 0 || slices.ContainsFunc(exemption.Users, func(user string) bool {
+			return strings.EqualFold(user, login)
+		}) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShouldMonitorRepoBranch returns true if the branch of the repository
+// (owner/repo) should be monitored for PR compliance, using the repository's
+// effective policy. see ShouldMonitorBranch for ruleset conditions.
+func (c *Config) ShouldMonitorRepoBranch(repoFullName, branch string, rulesetConditions ...types.RefNameCondition) bool {
+	if !c.IsPRComplianceEnabled() {
+		return false
+	}
+	if c.PRPolicyFor(repoFullName).IsMonitoredBranch(branch) {
+		return true
+	}
+	if c.PRMonitorRulesetBranches {
+		branch = strings.TrimPrefix(branch, "refs/heads/")
+		for _, condition := range rulesetConditions {
+			if condition.Matches(branch) {
+				return true
+			}
+		}
+	}
+	return false
+}
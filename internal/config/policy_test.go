@@ -0,0 +1,180 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writePolicyFile writes content to a policy file in a temp dir.
+func writePolicyFile(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write policy file: %v", err)
+	}
+	return file
+}
+
+func TestLoadCompliancePolicy(t *testing.T) {
+	yamlPolicy := `
+monitored_branches: [main, release]
+min_approvals:
+  main: 2
+bypass_allowlist: [release-bot]
+suppressed_violations: [missing_status_check]
+repos:
+  acme/legacy:
+    min_approvals:
+      main: 0
+    suppressed_violations: [unsigned_commits]
+`
+	jsonPolicy := `{"monitored_branches":["main","release"],"min_approvals":{"main":2},"bypass_allowlist":["release-bot"],"suppressed_violations":["missing_status_check"],"repos":{"acme/legacy":{"min_approvals":{"main":0},"suppressed_violations":["unsigned_commits"]}}}`
+
+	for name, content := range map[string]string{"policy.yaml": yamlPolicy, "policy.json": jsonPolicy} {
+		t.Run(name, func(t *testing.T) {
+			policy, err := LoadCompliancePolicy(writePolicyFile(t, name, content))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.Equal(policy.MonitoredBranches, []string{"main", "release"}) {
+				t.Errorf("unexpected monitored branches: %v", policy.MonitoredBranches)
+			}
+			if policy.MinApprovals["main"] != 2 || !slices.Equal(policy.BypassAllowlist, []string{"release-bot"}) {
+				t.Errorf("unexpected policy: %+v", policy.PRPolicy)
+			}
+			legacy, ok := policy.Repos["acme/legacy"]
+			if !ok || legacy.MinApprovals["main"] != 0 || !slices.Equal(legacy.SuppressedViolations, []string{"unsigned_commits"}) {
+				t.Errorf("unexpected repo override: %+v", policy.Repos)
+			}
+		})
+	}
+}
+
+func TestLoadCompliancePolicy_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "empty", content: "", wantErr: "is empty"},
+		{name: "unknown field", content: "monitored_branch: [main]", wantErr: "monitored_branch"},
+		{name: "unknown violation", content: "suppressed_violations: [no_tests]", wantErr: "no_tests"},
+		{name: "negative approvals", content: "min_approvals: {main: -1}", wantErr: "invalid approval count"},
+		{name: "bad pattern", content: `min_approvals: {"[": 1}`, wantErr: "invalid branch pattern"},
+		{name: "bad repo key", content: "repos: {legacy: {required_team: core}}", wantErr: "owner/repo"},
+		{name: "exemption without branch", content: "push_exemptions: [{users: [alice]}]", wantErr: "must set a branch pattern"},
+		{name: "bad exemption pattern", content: `push_exemptions: [{branch: "["}]`, wantErr: "invalid branch pattern"},
+		{name: "invalid repo override", content: "repos: {acme/legacy: {bypass_allowlist: ['']}}", wantErr: "acme/legacy"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadCompliancePolicy(writePolicyFile(t, "policy.yaml", tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestNewConfig_PRPolicyFile(t *testing.T) {
+	t.Setenv("APP_PR_MONITORED_BRANCHES", "main,master")
+	t.Setenv("APP_PR_REQUIRED_TEAM_REVIEW", "security")
+	t.Setenv("APP_PR_MIN_APPROVALS", `{"main":1}`)
+	t.Setenv("APP_PR_EXEMPT_LABEL", "compliance-exempt")
+	t.Setenv("APP_PR_EXEMPT_LABEL_APPROVERS", "security-lead, cto")
+	t.Setenv("APP_PR_POLICY_FILE", writePolicyFile(t, "policy.yaml", `
+min_approvals:
+  main: 2
+bypass_allowlist: [release-bot]
+exempt_label_approver_teams: [security]
+repos:
+  Acme/Legacy:
+    monitored_branches: [trunk]
+    required_team: legacy-owners
+    bypass_allowlist: [legacy-bot]
+    exempt_label: legacy-exempt
+    exempt_label_approvers: [legacy-lead]
+`))
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// unset policy settings fall back to the environment
+	if !slices.Equal(cfg.PRMonitoredBranches, []string{"main", "master"}) || cfg.PRRequiredTeam != "security" {
+		t.Errorf("expected env fallbacks, got branches %v and team %q", cfg.PRMonitoredBranches, cfg.PRRequiredTeam)
+	}
+	if cfg.MinApprovalsForBranch("main") != 2 {
+		t.Errorf("expected policy min approvals to supersede env, got %d", cfg.MinApprovalsForBranch("main"))
+	}
+
+	defaults := cfg.PRPolicyFor("acme/other")
+	if defaults.RequiredTeam != "security" || defaults.ExemptLabel != "compliance-exempt" ||
+		!slices.Equal(defaults.BypassAllowlist, []string{"release-bot"}) {
+		t.Errorf("unexpected default policy: %+v", defaults)
+	}
+	if !slices.Equal(defaults.ExemptLabelApprovers, []string{"security-lead", "cto"}) ||
+		!slices.Equal(defaults.ExemptLabelApproverTeams, []string{"security"}) {
+		t.Errorf("unexpected default exemption approvers: %+v", defaults)
+	}
+
+	legacy := cfg.PRPolicyFor("acme/legacy")
+	if legacy.RequiredTeam != "legacy-owners" || legacy.ExemptLabel != "legacy-exempt" || legacy.MinApprovalsForBranch("main") != 2 {
+		t.Errorf("unexpected legacy policy: %+v", legacy)
+	}
+	if !slices.Equal(legacy.ExemptLabelApprovers, []string{"legacy-lead"}) {
+		t.Errorf("expected repo exemption approvers to replace defaults, got %v", legacy.ExemptLabelApprovers)
+	}
+	if !slices.Equal(legacy.BypassAllowlist, []string{"release-bot", "legacy-bot"}) {
+		t.Errorf("expected allowlists to be combined, got %v", legacy.BypassAllowlist)
+	}
+
+	if !legacy.IsMonitoredBranch("refs/heads/trunk") || legacy.IsMonitoredBranch("main") {
+		t.Error("expected legacy repo to monitor only trunk")
+	}
+	if !defaults.IsMonitoredBranch("main") {
+		t.Error("expected other repos to monitor main")
+	}
+
+	t.Setenv("APP_PR_POLICY_FILE", writePolicyFile(t, "policy.yaml", "suppressed_violations: [bogus]"))
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid policy file")
+	}
+}
+
+func TestPRPolicy_IsPushExempt(t *testing.T) {
+	policy := PRPolicy{PushExemptions: []PushExemption{
+		{Branch: "hotfix/*", Users: []string{"Alice"}},
+		{Branch: "release/*", AllowForcePush: true},
+	}}
+
+	tests := []struct {
+		name          string
+		branch        string
+		defaultBranch string
+		login         string
+		forced        bool
+		want          bool
+	}{
+		{name: "listed user on matching branch", branch: "refs/heads/hotfix/db", defaultBranch: "main", login: "alice", want: true},
+		{name: "unlisted user", branch: "hotfix/db", defaultBranch: "main", login: "bob", want: false},
+		{name: "force push not allowed", branch: "hotfix/db", defaultBranch: "main", login: "alice", forced: true, want: false},
+		{name: "any user with force push", branch: "release/1.2", defaultBranch: "main", login: "bob", forced: true, want: true},
+		{name: "unmatched branch", branch: "feature/x", defaultBranch: "main", login: "alice", want: false},
+		{name: "default branch is strict", branch: "release/1.2", defaultBranch: "release/1.2", login: "bob", want: false},
+		{name: "unknown default branch is strict", branch: "release/1.2", login: "bob", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.IsPushExempt(tt.branch, tt.defaultBranch, tt.login, tt.forced); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// parseSyncSchedule validates a sync schedule and returns the interval
+// between runs. accepts EventBridge rate(...) and cron(...) expressions and
+// standard five-field cron strings. the interval is 0 when the expression is
+// valid but does not fire at a fixed interval (e.g., weekdays only).
+func parseSyncSchedule(schedule string) (time.Duration, error) {
+	if expr, ok := strings.CutPrefix(schedule, "rate("); ok {
+		expr, ok = strings.CutSuffix(expr, ")")
+		if !ok {
+			return 0, errors.Newf("invalid rate expression '%s'", schedule)
+		}
+		return parseRateExpression(expr)
+	}
+
+	expr := schedule
+	if inner, ok := strings.CutPrefix(schedule, "cron("); ok {
+		inner, ok = strings.CutSuffix(inner, ")")
+		if !ok {
+			return 0, errors.Newf("invalid cron expression '%s'", schedule)
+		}
+		// eventbridge cron has a trailing year field
+		fields := strings.Fields(inner)
+		if len(fields) != 6 {
+			return 0, errors.Newf("invalid cron expression '%s': expected 6 fields", schedule)
+		}
+		expr = strings.Join(fields[:5], " ")
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return 0, errors.Newf("invalid cron expression '%s': expected 5 fields", schedule)
+	}
+	return cronInterval(fields), nil
+}
+
+// parseRateExpression parses the "value unit" body of a rate expression.
+func parseRateExpression(expr string) (time.Duration, error) {
+	valueStr, unit, ok := strings.Cut(strings.TrimSpace(expr), " ")
+	value, err := strconv.Atoi(valueStr)
+	if !ok || err != nil || value <= 0 {
+		return 0, errors.Newf("invalid rate expression 'rate(%s)'", expr)
+	}
+
+	switch strings.TrimSuffix(strings.TrimSpace(unit), "s") {
+	case "minute":
+		return time.Duration(value) * time.Minute, nil
+	case "hour":
+		return time.Duration(value) * time.Hour, nil
+	case "day":
+		return time.Duration(value) * 24 * time.Hour, nil
+	default:
+		return 0, errors.Newf("invalid rate unit '%s': must be minutes, hours, or days", unit)
+	}
+}
+
+// cronInterval returns the interval of common fixed-interval cron schedules
+// ("*/15 * * * *", "0 * * * *", "0 */6 * * *", "30 2 * * *"), or 0 for
+// anything else.
+func cronInterval(fields []string) time.Duration {
+	minute, hour := fields[0], fields[1]
+	for _, field := range fields[2:] {
+		if field != "*" && field != "?" {
+			return 0
+		}
+	}
+
+	switch {
+	case hour == "*" && minute == "*":
+		return time.Minute
+	case hour == "*":
+		return stepInterval(minute, time.Minute, time.Hour)
+	case isCronNumber(minute):
+		return stepInterval(hour, time.Hour, 24*time.Hour)
+	}
+	return 0
+}
+
+// stepInterval returns unit*N for "*/N" or "0/N", period for a single fixed
+// value, or 0 otherwise.
+func stepInterval(field string, unit, period time.Duration) time.Duration {
+	if isCronNumber(field) {
+		return period
+	}
+	start, step, ok := strings.Cut(field, "/")
+	if !ok || (start != "*" && start != "0") {
+		return 0
+	}
+	n, err := strconv.Atoi(step)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return time.Duration(n) * unit
+}
+
+// isCronNumber returns true if field is a single numeric cron value.
+func isCronNumber(field string) bool {
+	_, err := strconv.Atoi(field)
+	return err == nil
+}
+
+// Diagnose returns warnings about settings that are valid on their own but
+// work poorly together, mostly timing windows relative to APP_SYNC_SCHEDULE,
+// along with any warnings noticed while loading. schedule warnings are
+// skipped when no schedule interval is known.
+func (c *Config) Diagnose() []string {
+	warnings := slices.Clone(c.loadWarnings)
+
+	interval := c.SyncScheduleInterval
+	if interval <= 0 || !c.IsOktaSyncEnabled() {
+		return warnings
+	}
+
+	throttled := false
+	for _, policy := range c.OktaMembershipActionPolicies {
+		throttled = throttled || policy == MembershipPolicyThrottle
+	}
+	if throttled && c.OktaMembershipThrottleWindow >= interval {
+		warnings = append(warnings, fmt.Sprintf(
+			"APP_OKTA_MEMBERSHIP_THROTTLE_WINDOW (%s) is not shorter than the sync schedule interval (%s); throttled membership webhooks will rarely trigger a sync",
+			c.OktaMembershipThrottleWindow, interval))
+	}
+
+	if retryWait := time.Duration(c.GitHubRateLimitMaxRetries) * c.GitHubRateLimitMaxBackoff; retryWait >= interval {
+		warnings = append(warnings, fmt.Sprintf(
+			"github rate limit retries can wait up to %s per call, longer than the sync schedule interval (%s); a slow sync may overlap the next scheduled run",
+			retryWait, interval))
+	}
+
+	if c.OktaOrphanedUserNotifications {
+		if span := time.Duration(c.OktaOrphanedHistorySize) * interval; span < 24*time.Hour {
+			warnings = append(warnings, fmt.Sprintf(
+				"APP_OKTA_ORPHANED_HISTORY_SIZE (%d) keeps only %s of orphaned user history at the sync schedule interval (%s)",
+				c.OktaOrphanedHistorySize, span, interval))
+		}
+	}
+
+	return warnings
+}
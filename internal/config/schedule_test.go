@@ -0,0 +1,102 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruxstack/github-ops-app/internal/types"
+)
+
+func TestParseSyncSchedule(t *testing.T) {
+	tests := []struct {
+		schedule string
+		want     time.Duration
+		wantErr  bool
+	}{
+		{schedule: "rate(15 minutes)", want: 15 * time.Minute},
+		{schedule: "rate(1 hour)", want: time.Hour},
+		{schedule: "rate(2 days)", want: 48 * time.Hour},
+		{schedule: "cron(0/30 * * * ? *)", want: 30 * time.Minute},
+		{schedule: "*/10 * * * *", want: 10 * time.Minute},
+		{schedule: "5 * * * *", want: time.Hour},
+		{schedule: "0 */6 * * *", want: 6 * time.Hour},
+		{schedule: "30 2 * * *", want: 24 * time.Hour},
+		{schedule: "0 9 * * 1-5", want: 0},
+		{schedule: "rate(0 minutes)", wantErr: true},
+		{schedule: "rate(5 weeks)", wantErr: true},
+		{schedule: "rate(5 minutes", wantErr: true},
+		{schedule: "cron(0 * * * *)", wantErr: true},
+		{schedule: "hourly", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.schedule, func(t *testing.T) {
+			got, err := parseSyncSchedule(tt.schedule)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got interval %s", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	newConfig := func(interval time.Duration) *Config {
+		return &Config{
+			OktaDomain:                    "example.okta.com",
+			OktaClientID:                  "client",
+			OktaPrivateKey:                []byte("key"),
+			OktaSyncRules:                 []types.SyncRule{{Name: "eng"}},
+			OktaMembershipActionPolicies:  map[string]string{"added": MembershipPolicyThrottle},
+			OktaMembershipThrottleWindow:  5 * time.Minute,
+			GitHubRateLimitMaxRetries:     3,
+			GitHubRateLimitMaxBackoff:     time.Minute,
+			OktaOrphanedUserNotifications: true,
+			OktaOrphanedHistorySize:       100,
+			SyncScheduleInterval:          interval,
+		}
+	}
+
+	if warnings := newConfig(time.Hour).Diagnose(); len(warnings) != 0 {
+		t.Errorf("expected no warnings for an hourly schedule, got %v", warnings)
+	}
+	if warnings := newConfig(0).Diagnose(); warnings != nil {
+		t.Errorf("expected no warnings without a schedule, got %v", warnings)
+	}
+
+	warnings := newConfig(2 * time.Minute).Diagnose()
+	for _, want := range []string{"APP_OKTA_MEMBERSHIP_THROTTLE_WINDOW", "rate limit retries", "APP_OKTA_ORPHANED_HISTORY_SIZE"} {
+		found := false
+		for _, warning := range warnings {
+			found = found || strings.Contains(warning, want)
+		}
+		if !found {
+			t.Errorf("expected a warning mentioning %q, got %v", want, warnings)
+		}
+	}
+}
+
+func TestNewConfig_SyncSchedule(t *testing.T) {
+	t.Setenv("APP_SYNC_SCHEDULE", "rate(30 minutes)")
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SyncSchedule != "rate(30 minutes)" || cfg.SyncScheduleInterval != 30*time.Minute {
+		t.Errorf("unexpected schedule %q with interval %s", cfg.SyncSchedule, cfg.SyncScheduleInterval)
+	}
+
+	t.Setenv("APP_SYNC_SCHEDULE", "every hour")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for invalid schedule")
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// redactedValue replaces secrets in the redacted config and in logs.
+const redactedValue = "***REDACTED***"
+
+// minRedactLength is the shortest resolved value redacted from arbitrary
+// text. shorter values would mangle unrelated output.
+const minRedactLength = 4
+
+// resolvedSecrets records values resolved from SSM that must never be shown.
+// it is process-wide, like the SSM client, so loggers created before the
+// config is loaded redact them too.
+var resolvedSecrets = &secretRegistry{}
+
+// secretRegistry is a set of secret values and the variables they came from.
+type secretRegistry struct {
+	mu     sync.RWMutex
+	keys   map[string]bool
+	values []string
+}
+
+// add records value, resolved for key, as secret. the JSON-escaped form is
+// also recorded so multi-line values such as private keys are redacted from
+// logged JSON.
+func (r *secretRegistry) add(key, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.keys == nil {
+		r.keys = make(map[string]bool)
+	}
+	r.keys[key] = true
+
+	escaped, _ := json.Marshal(value)
+	for _, v := range []string{value, strings.Trim(string(escaped), `"`)} {
+		if len(v) >= minRedactLength && !slices.Contains(r.values, v) {
+			r.values = append(r.values, v)
+		}
+	}
+	// longest first so a secret containing another is replaced whole
+	slices.SortFunc(r.values, func(a, b string) int { return len(b) - len(a) })
+}
+
+// empty returns true if no secrets have been recorded.
+func (r *secretRegistry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.values) == 0
+}
+
+// redact replaces every recorded secret in s.
+func (r *secretRegistry) redact(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, v := range r.values {
+		s = strings.ReplaceAll(s, v, redactedValue)
+	}
+	return s
+}
+
+// sortedKeys returns the variables whose values were recorded, sorted.
+func (r *secretRegistry) sortedKeys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.keys))
+	for key := range r.keys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// RedactSecrets replaces every value this process resolved from an SSM
+// SecureString parameter, or from any SSM parameter when APP_SSM_REDACT_ALL
+// is enabled, in s.
+func RedactSecrets(s string) string {
+	return resolvedSecrets.redact(s)
+}
+
+// SecretKeys returns the environment variables whose SSM-resolved values are
+// treated as secret, sorted.
+func SecretKeys() []string {
+	return resolvedSecrets.sortedKeys()
+}
+
+// redactSecretsCopy returns a deep copy of v with resolved secrets replaced
+// in every exported string it holds, whatever the field is named. slices,
+// maps, and pointers are copied so the original is never modified.
+func redactSecretsCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		out := reflect.New(v.Type()).Elem()
+		out.SetString(RedactSecrets(v.String()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(redactSecretsCopy(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(redactSecretsCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), redactSecretsCopy(iter.Value()))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(redactSecretsCopy(v.Elem()))
+		return out
+	default:
+		return v
+	}
+}
+
+// redactingHandler is a slog.Handler that replaces resolved secrets in log
+// messages and attribute values before passing records on.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+// NewRedactingHandler wraps next so resolved secrets are redacted from every
+// record, for loggers built outside NewLogger.
+func NewRedactingHandler(next slog.Handler) slog.Handler {
+	return redactingHandler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles level.
+func (h redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it to the wrapped handler.
+func (h redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if resolvedSecrets.empty() {
+		return h.next.Handle(ctx, r)
+	}
+
+	out := slog.NewRecord(r.Time, r.Level, RedactSecrets(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs redacts attrs and adds them to the wrapped handler.
+func (h redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup opens a group on the wrapped handler.
+func (h redactingHandler) WithGroup(name string) slog.Handler {
+	return redactingHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr replaces resolved secrets in a's value. values of other kinds
+// are redacted by their string form, e.g. errors wrapping a secret.
+func redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(RedactSecrets(a.Value.String()))
+	case slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		s := fmt.Sprint(a.Value.Any())
+		if redacted := RedactSecrets(s); redacted != s {
+			a.Value = slog.StringValue(redacted)
+		}
+	}
+	return a
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/cruxstack/github-ops-app/internal/types"
+)
+
+// useSecretRegistry replaces the process-wide secret registry for the test.
+func useSecretRegistry(t *testing.T) *secretRegistry {
+	t.Helper()
+	previous := resolvedSecrets
+	resolvedSecrets = &secretRegistry{}
+	t.Cleanup(func() { resolvedSecrets = previous })
+	return resolvedSecrets
+}
+
+func TestRedactSecrets(t *testing.T) {
+	registry := useSecretRegistry(t)
+	if got := RedactSecrets("token s3cr3t-value"); got != "token s3cr3t-value" {
+		t.Errorf("expected text unchanged without secrets, got %q", got)
+	}
+
+	registry.add("APP_CUSTOM", "s3cr3t-value")
+	registry.add("APP_KEY", "line1\nline2")
+	registry.add("APP_SHORT", "abc")
+
+	tests := map[string]string{
+		"token s3cr3t-value":         "token ***REDACTED***",
+		`{"key":"line1\nline2"}`:     `{"key":"***REDACTED***"}`,
+		"multi\nline1\nline2\nvalue": "multi\n***REDACTED***\nvalue",
+		"abc is too short":           "abc is too short",
+	}
+	for in, want := range tests {
+		if got := RedactSecrets(in); got != want {
+			t.Errorf("RedactSecrets(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	if got := strings.Join(SecretKeys(), ","); got != "APP_CUSTOM,APP_KEY,APP_SHORT" {
+		t.Errorf("unexpected secret keys: %s", got)
+	}
+}
+
+func TestRedacted_ResolvedSecrets(t *testing.T) {
+	registry := useSecretRegistry(t)
+	registry.add("APP_SLACK_CHANNEL", "C0SECRET")
+
+	cfg := &Config{
+		SlackChannel:              "C0SECRET",
+		SlackChannelPRBypassRepos: map[string]string{"acme/api": "C0SECRET"},
+		OktaSyncRules:             []types.SyncRule{{Name: "rule", OktaGroupName: "C0SECRET"}},
+	}
+
+	redacted := cfg.Redacted()
+	if redacted.SlackChannel != redactedValue || redacted.SlackChannelPRBypassRepos["acme/api"] != redactedValue {
+		t.Errorf("expected slack channels redacted, got %q and %v", redacted.SlackChannel, redacted.SlackChannelPRBypassRepos)
+	}
+	if redacted.OktaSyncRules[0].OktaGroupName != redactedValue || redacted.OktaSyncRules[0].Name != "rule" {
+		t.Errorf("expected only the secret redacted in sync rules, got %+v", redacted.OktaSyncRules[0])
+	}
+	if len(redacted.SSMSecretKeys) != 1 || redacted.SSMSecretKeys[0] != "APP_SLACK_CHANNEL" {
+		t.Errorf("expected secret keys listed, got %v", redacted.SSMSecretKeys)
+	}
+
+	// the config itself is left untouched
+	if cfg.SlackChannelPRBypassRepos["acme/api"] != "C0SECRET" || cfg.OktaSyncRules[0].OktaGroupName != "C0SECRET" {
+		t.Error("expected original config unchanged")
+	}
+}
+
+func TestRedactingHandler(t *testing.T) {
+	registry := useSecretRegistry(t)
+
+	var buf bytes.Buffer
+	logger := slog.New(NewRedactingHandler(slog.NewTextHandler(&buf, nil)))
+
+	registry.add("APP_CUSTOM", "hunter22")
+	logger.With(slog.String("preset", "hunter22")).
+		WithGroup("request").
+		Info("resolved hunter22",
+			slog.String("body", `{"token":"hunter22"}`),
+			slog.Any("error", errors.New("bad token hunter22")),
+			slog.Group("nested", slog.String("value", "hunter22")),
+			slog.Int("count", 22))
+
+	out := buf.String()
+	if strings.Contains(out, "hunter22") {
+		t.Errorf("expected secret redacted from log output, got %s", out)
+	}
+	if !strings.Contains(out, "count=22") || strings.Count(out, redactedValue) != 5 {
+		t.Errorf("expected other values kept and 5 redactions, got %s", out)
+	}
+}
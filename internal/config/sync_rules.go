@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"gopkg.in/yaml.v3"
+)
+
+// sync rule document formats.
+const (
+	syncRulesFormatJSON = "json"
+	syncRulesFormatYAML = "yaml"
+)
+
+// loadSyncRules reads okta sync rules from APP_OKTA_SYNC_RULES_PATH or, if
+// unset, the inline APP_OKTA_SYNC_RULES JSON. the path may be a file (YAML
+// when it ends in .yaml or .yml, JSON otherwise) or an SSM parameter
+// reference whose value is the rules document in either format. returns a
+// warning when both variables are set.
+func loadSyncRules(ctx context.Context) ([]types.SyncRule, string, error) {
+	inline := os.Getenv("APP_OKTA_SYNC_RULES")
+	rulesPath := os.Getenv("APP_OKTA_SYNC_RULES_PATH")
+
+	if rulesPath == "" {
+		if inline == "" {
+			return nil, "", nil
+		}
+		rules, err := parseSyncRules([]byte(inline), syncRulesFormatJSON)
+		if err != nil {
+			return nil, "", errors.Wrap(err, "failed to parse APP_OKTA_SYNC_RULES")
+		}
+		return rules, "", nil
+	}
+
+	var data []byte
+	format := syncRulesFormatJSON
+	if strings.HasPrefix(rulesPath, "arn:aws:ssm:") {
+		value, err := resolveEnvValue(ctx, "APP_OKTA_SYNC_RULES_PATH", rulesPath)
+		if err != nil {
+			return nil, "", err
+		}
+		// json is valid yaml, so the yaml parser accepts either format
+		data, format = []byte(value), syncRulesFormatYAML
+	} else {
+		var err error
+		data, err = os.ReadFile(rulesPath)
+		if err != nil {
+			return nil, "", errors.Wrapf(err, "failed to read sync rules from %s", rulesPath)
+		}
+		if ext := strings.ToLower(filepath.Ext(rulesPath)); ext == ".yaml" || ext == ".yml" {
+			format = syncRulesFormatYAML
+		}
+	}
+
+	rules, err := parseSyncRules(data, format)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to parse APP_OKTA_SYNC_RULES_PATH '%s'", rulesPath)
+	}
+
+	var warning string
+	if inline != "" {
+		warning = "APP_OKTA_SYNC_RULES and APP_OKTA_SYNC_RULES_PATH are both set; using the rules from APP_OKTA_SYNC_RULES_PATH"
+	}
+	return rules, warning, nil
+}
+
+// parseSyncRules parses a JSON or YAML array of sync rules. YAML documents
+// use the same field names as JSON.
+func parseSyncRules(data []byte, format string) ([]types.SyncRule, error) {
+	if format == syncRulesFormatYAML {
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return nil, errors.New("sync rules document is empty")
+		}
+		// round trip through json so the rules' json field names apply
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	var rules []types.SyncRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
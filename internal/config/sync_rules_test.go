@@ -0,0 +1,77 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewConfig_SyncRulesPath(t *testing.T) {
+	yamlRules := `
+- name: platform
+  okta_group_name: Platform
+  github_team_name: platform
+  preserve_members: [release-bot]
+- name: infra
+  okta_group_pattern: "^infra-.*"
+  team_name_template: "infra-{{.StrippedGroup}}"
+`
+	jsonRules := `[{"name":"platform","okta_group_name":"Platform","github_team_name":"platform","preserve_members":["release-bot"]},{"name":"infra","okta_group_pattern":"^infra-.*","team_name_template":"infra-{{.StrippedGroup}}"}]`
+
+	for name, content := range map[string]string{"rules.yaml": yamlRules, "rules.yml": yamlRules, "rules.json": jsonRules} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("APP_OKTA_SYNC_RULES_PATH", writePolicyFile(t, name, content))
+			cfg, err := NewConfig()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(cfg.OktaSyncRules) != 2 {
+				t.Fatalf("expected 2 rules, got %+v", cfg.OktaSyncRules)
+			}
+			platform, infra := cfg.OktaSyncRules[0], cfg.OktaSyncRules[1]
+			if platform.GitHubTeamName != "platform" || !slices.Equal(platform.PreserveMembers, []string{"release-bot"}) {
+				t.Errorf("unexpected rule: %+v", platform)
+			}
+			if infra.OktaGroupPattern != "^infra-.*" || infra.TeamNameTemplate != "infra-{{.StrippedGroup}}" {
+				t.Errorf("unexpected rule: %+v", infra)
+			}
+		})
+	}
+}
+
+func TestNewConfig_SyncRulesPathPrecedence(t *testing.T) {
+	t.Setenv("APP_OKTA_SYNC_RULES", `[{"name":"inline","okta_group_name":"Inline","github_team_name":"inline"}]`)
+	t.Setenv("APP_OKTA_SYNC_RULES_PATH", writePolicyFile(t, "rules.yaml", "- {name: file, okta_group_name: File, github_team_name: file}"))
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.OktaSyncRules) != 1 || cfg.OktaSyncRules[0].Name != "file" {
+		t.Errorf("expected rules from the file, got %+v", cfg.OktaSyncRules)
+	}
+	warnings := cfg.Diagnose()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "APP_OKTA_SYNC_RULES_PATH") {
+		t.Errorf("expected a precedence warning, got %v", warnings)
+	}
+}
+
+func TestNewConfig_SyncRulesPathInvalid(t *testing.T) {
+	for name, content := range map[string]string{
+		"empty.yaml":   "",
+		"mapping.yaml": "name: platform",
+		"broken.json":  `[{"name":`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("APP_OKTA_SYNC_RULES_PATH", writePolicyFile(t, name, content))
+			if _, err := NewConfig(); err == nil || !strings.Contains(err.Error(), "APP_OKTA_SYNC_RULES_PATH") {
+				t.Errorf("expected parse error naming the variable, got %v", err)
+			}
+		})
+	}
+
+	t.Setenv("APP_OKTA_SYNC_RULES_PATH", "/nonexistent/rules.yaml")
+	if _, err := NewConfig(); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
@@ -32,11 +32,14 @@ var (
 	ErrInvalidSignature    = errors.Mark(errors.New("invalid webhook signature"), AuthError)
 	ErrMissingSignature    = errors.Mark(errors.New("signature missing but secret configured"), AuthError)
 	ErrUnexpectedSignature = errors.Mark(errors.New("signature provided but secret not configured"), AuthError)
+	ErrStaleSignature      = errors.Mark(errors.New("signature timestamp outside allowed window"), AuthError)
 	ErrTeamNotFound        = errors.Mark(errors.New("github team not found"), APIError)
 	ErrGroupNotFound       = errors.Mark(errors.New("okta group not found"), APIError)
+	ErrGroupProfileMissing = errors.Mark(errors.New("okta group profile missing"), APIError)
 	ErrInvalidPattern      = errors.Mark(errors.New("invalid regex pattern"), ValidationError)
 	ErrEmptyPattern        = errors.Mark(errors.New("pattern cannot be empty"), ValidationError)
 	ErrClientNotInit       = errors.Mark(errors.New("client not initialized"), ConfigError)
+	ErrNotConfigured       = errors.Mark(errors.New("feature not configured"), ConfigError)
 	ErrInvalidEventType    = errors.Mark(errors.New("unknown event type"), ValidationError)
 	ErrMissingOAuthCreds   = errors.Mark(errors.New("must provide either api token or oauth credentials"), ConfigError)
 	ErrOAuthTokenExpired   = errors.Mark(errors.New("oauth token expired"), AuthError)
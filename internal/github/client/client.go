@@ -9,11 +9,13 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
 
 	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/go-github/v79/github"
 	"golang.org/x/oauth2"
@@ -33,6 +35,36 @@ type Client struct {
 	tokenMu    sync.RWMutex
 	token      string
 	tokenExpAt time.Time
+	// tokenRefreshMu serializes token refreshes so concurrent callers that
+	// find the token expiring mint a single new one.
+	tokenRefreshMu sync.Mutex
+
+	teamCacheMu sync.Mutex
+	teamCache   map[string]cachedTeamMembers
+	// orgTeams is the cached org team list used for case-insensitive slug
+	// lookups. teamSlugs maps lowercased slugs to the canonical slugs found
+	// that way.
+	orgTeams          []*github.Team
+	orgTeamsFetchedAt time.Time
+	teamSlugs         map[string]string
+
+	protectionCache *ProtectionCache
+
+	appIdentityMu sync.Mutex
+	appSlug       string
+	appBotUserID  int64
+	// appSlugFetchMu serializes slug fetches so concurrent first calls make
+	// a single API request.
+	appSlugFetchMu sync.Mutex
+
+	dryRun  bool
+	limiter rateLimiter
+	quota   types.QuotaTracker
+
+	retryMaxRetries int
+	retryMaxBackoff time.Duration
+
+	logger *slog.Logger
 }
 
 // NewAppClient creates a GitHub App client with default base URL.
@@ -54,6 +86,19 @@ func NewAppClientWithBaseURL(appID, installationID int64, privateKeyPEM []byte,
 		privateKey:     privateKey,
 		installationID: installationID,
 		baseURL:        baseURL,
+
+		retryMaxRetries: DefaultRateLimitMaxRetries,
+		retryMaxBackoff: DefaultRateLimitMaxBackoff,
+
+		logger: slog.New(slog.DiscardHandler),
+	}
+	c.limiter.set(types.DefaultGitHubMaxRPS)
+
+	// the api client reads the current token on each request, so refreshes
+	// never replace it while other goroutines are using it
+	c.client = github.NewClient(c.newHTTPClient(installationTokenSource{c}, &c.quota))
+	if baseURL != "" {
+		c.client.BaseURL, _ = c.client.BaseURL.Parse(baseURL)
 	}
 
 	if err := c.refreshToken(context.Background()); err != nil {
@@ -109,9 +154,7 @@ func (c *Client) refreshToken(ctx context.Context) error {
 		return errors.Wrap(err, "failed to create JWT")
 	}
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwtToken})
-	tc := oauth2.NewClient(ctx, ts)
-	appClient := github.NewClient(tc)
+	appClient := github.NewClient(c.newHTTPClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwtToken}), nil))
 	if c.baseURL != "" {
 		appClient.BaseURL, _ = appClient.BaseURL.Parse(c.baseURL)
 	}
@@ -132,29 +175,46 @@ func (c *Client) refreshToken(ctx context.Context) error {
 	c.tokenMu.Lock()
 	c.token = installToken.GetToken()
 	c.tokenExpAt = installToken.GetExpiresAt().Time
-	ts2 := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: c.token})
-	tc2 := oauth2.NewClient(ctx, ts2)
-	c.client = github.NewClient(tc2)
-	if c.baseURL != "" {
-		c.client.BaseURL, _ = c.client.BaseURL.Parse(c.baseURL)
-	}
 	c.tokenMu.Unlock()
 
 	return nil
 }
 
-// ensureValidToken refreshes the installation token if it expires within 5
+// installationTokenSource supplies the client's current installation token.
+type installationTokenSource struct {
+	c *Client
+}
+
+// Token returns the current installation token.
+func (s installationTokenSource) Token() (*oauth2.Token, error) {
+	s.c.tokenMu.RLock()
+	defer s.c.tokenMu.RUnlock()
+	return &oauth2.Token{AccessToken: s.c.token}, nil
+}
+
+// tokenNeedsRefresh returns true if the installation token expires within 5
 // minutes.
-func (c *Client) ensureValidToken(ctx context.Context) error {
+func (c *Client) tokenNeedsRefresh() bool {
 	c.tokenMu.RLock()
-	needsRefresh := time.Now().Add(5 * time.Minute).After(c.tokenExpAt)
-	c.tokenMu.RUnlock()
+	defer c.tokenMu.RUnlock()
+	return time.Now().Add(5 * time.Minute).After(c.tokenExpAt)
+}
 
-	if needsRefresh {
-		return c.refreshToken(ctx)
+// ensureValidToken refreshes the installation token if it expires within 5
+// minutes.
+func (c *Client) ensureValidToken(ctx context.Context) error {
+	if !c.tokenNeedsRefresh() {
+		return nil
 	}
 
-	return nil
+	c.tokenRefreshMu.Lock()
+	defer c.tokenRefreshMu.Unlock()
+
+	// another caller may have refreshed while this one waited
+	if !c.tokenNeedsRefresh() {
+		return nil
+	}
+	return c.refreshToken(ctx)
 }
 
 // GetOrg returns the GitHub organization name.
@@ -162,6 +222,42 @@ func (c *Client) GetOrg() string {
 	return c.org
 }
 
+// SetDryRun enables or disables dry-run mode. in dry-run mode team creation
+// and membership changes are computed but not applied.
+func (c *Client) SetDryRun(enabled bool) {
+	c.dryRun = enabled
+}
+
+// SetLogger sets the logger used to report lookups that failed without
+// failing the operation, such as org rulesets the app cannot read.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// DryRun reports whether dry-run mode is enabled.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// dryRunKey is the context key for a per-call dry-run override.
+type dryRunKey struct{}
+
+// WithDryRun returns a context that enables dry-run mode for calls made with
+// it. used to run individual sync rules in dry-run while others enforce. it
+// never disables the client's own dry-run mode.
+func WithDryRun(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, enabled)
+}
+
+// isDryRun returns true if the client is in dry-run mode or ctx enables it.
+func (c *Client) isDryRun(ctx context.Context) bool {
+	if c.dryRun {
+		return true
+	}
+	enabled, _ := ctx.Value(dryRunKey{}).(bool)
+	return enabled
+}
+
 // GetClient returns the underlying go-github client.
 func (c *Client) GetClient() *github.Client {
 	return c.client
@@ -178,17 +274,71 @@ func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, err
 }
 
 // GetAppSlug fetches the GitHub App slug identifier.
-// used to detect changes made by the app itself.
-// requires JWT authentication (not installation token).
+// used to detect changes made by the app itself. the slug is cached after
+// the first successful fetch and survives token refreshes; failed fetches are
+// not cached. requires JWT authentication (not installation token).
 func (c *Client) GetAppSlug(ctx context.Context) (string, error) {
+	if slug := c.cachedAppSlug(); slug != "" {
+		return slug, nil
+	}
+
+	c.appSlugFetchMu.Lock()
+	defer c.appSlugFetchMu.Unlock()
+
+	// another caller may have fetched the slug while this one waited.
+	if slug := c.cachedAppSlug(); slug != "" {
+		return slug, nil
+	}
+
+	slug, err := c.fetchAppSlug(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.appIdentityMu.Lock()
+	c.appSlug = slug
+	c.appIdentityMu.Unlock()
+
+	return slug, nil
+}
+
+// cachedAppSlug returns the cached app slug, or "" if not yet fetched.
+func (c *Client) cachedAppSlug() string {
+	c.appIdentityMu.Lock()
+	defer c.appIdentityMu.Unlock()
+	return c.appSlug
+}
+
+// ShareAppIdentity seeds the client's app slug and bot user ID from another
+// client of the same app, so clients created per installation don't fetch
+// them again. values not yet cached on from are left unset.
+func (c *Client) ShareAppIdentity(from *Client) {
+	if from == nil || from == c {
+		return
+	}
+	from.appIdentityMu.Lock()
+	slug, botUserID := from.appSlug, from.appBotUserID
+	from.appIdentityMu.Unlock()
+
+	c.appIdentityMu.Lock()
+	defer c.appIdentityMu.Unlock()
+	if slug != "" {
+		c.appSlug = slug
+	}
+	if botUserID != 0 {
+		c.appBotUserID = botUserID
+	}
+}
+
+// fetchAppSlug fetches the app slug from the API using JWT authentication,
+// bypassing the cache.
+func (c *Client) fetchAppSlug(ctx context.Context) (string, error) {
 	jwtToken, err := c.createJWT()
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create jwt for app slug fetch")
 	}
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwtToken})
-	tc := oauth2.NewClient(ctx, ts)
-	appClient := github.NewClient(tc)
+	appClient := github.NewClient(c.newHTTPClient(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwtToken}), nil))
 	if c.baseURL != "" {
 		appClient.BaseURL, _ = appClient.BaseURL.Parse(c.baseURL)
 	}
@@ -205,6 +355,60 @@ func (c *Client) GetAppSlug(ctx context.Context) (string, error) {
 	return *app.Slug, nil
 }
 
+// CheckHealth verifies live connectivity to GitHub with the configured app
+// credentials. fetches the app uncached with a JWT, which checks the app ID
+// and private key, and ensures a valid installation token.
+func (c *Client) CheckHealth(ctx context.Context) error {
+	if _, err := c.fetchAppSlug(ctx); err != nil {
+		return err
+	}
+	return c.ensureValidToken(ctx)
+}
+
+// ClearAppIdentityCache drops the cached app slug and bot user ID so they
+// are fetched again on next use, e.g. after the app is renamed.
+func (c *Client) ClearAppIdentityCache() {
+	c.appIdentityMu.Lock()
+	defer c.appIdentityMu.Unlock()
+	c.appSlug = ""
+	c.appBotUserID = 0
+}
+
+// GetAppBotUserID fetches the user ID of the app's bot account. unlike the
+// slug, the ID survives app renames. the ID is cached after the first
+// successful fetch.
+func (c *Client) GetAppBotUserID(ctx context.Context) (int64, error) {
+	c.appIdentityMu.Lock()
+	id := c.appBotUserID
+	c.appIdentityMu.Unlock()
+	if id != 0 {
+		return id, nil
+	}
+
+	slug, err := c.GetAppSlug(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.ensureValidToken(ctx); err != nil {
+		return 0, err
+	}
+
+	user, _, err := c.client.Users.Get(ctx, slug+"[bot]")
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to fetch bot user for app '%s'", slug)
+	}
+	if user.GetID() == 0 {
+		return 0, errors.Newf("bot user id missing for app '%s'", slug)
+	}
+
+	c.appIdentityMu.Lock()
+	c.appBotUserID = user.GetID()
+	c.appIdentityMu.Unlock()
+
+	return user.GetID(), nil
+}
+
 // IsExternalCollaborator checks if a user is an outside collaborator rather
 // than an organization member. returns true if user is not a full org member.
 func (c *Client) IsExternalCollaborator(ctx context.Context, username string) (bool, error) {
@@ -212,7 +416,9 @@ func (c *Client) IsExternalCollaborator(ctx context.Context, username string) (b
 		return false, err
 	}
 
-	membership, resp, err := c.client.Organizations.GetOrgMembership(ctx, username, c.org)
+	membership, resp, err := withRateLimitRetry(ctx, c, func() (*github.Membership, *github.Response, error) {
+		return c.client.Organizations.GetOrgMembership(ctx, username, c.org)
+	})
 	if err != nil {
 		if resp != nil && resp.StatusCode == 404 {
 			return true, nil
@@ -226,24 +432,43 @@ func (c *Client) IsExternalCollaborator(ctx context.Context, username string) (b
 // ListOrgMembers returns all organization members excluding external
 // collaborators.
 func (c *Client) ListOrgMembers(ctx context.Context) ([]string, error) {
-	if err := c.ensureValidToken(ctx); err != nil {
+	var allMembers []string
+	err := c.ForEachOrgMember(ctx, func(login string) error {
+		allMembers = append(allMembers, login)
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	return allMembers, nil
+}
+
+// ForEachOrgMember calls fn for each organization member, one page at a time,
+// without accumulating the full member list. stops and returns the first
+// error returned by fn.
+func (c *Client) ForEachOrgMember(ctx context.Context, fn func(login string) error) error {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return err
+	}
 
 	opts := &github.ListMembersOptions{
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
-	var allMembers []string
 	for {
-		members, resp, err := c.client.Organizations.ListMembers(ctx, c.org, opts)
+		members, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.User, *github.Response, error) {
+			return c.client.Organizations.ListMembers(ctx, c.org, opts)
+		})
 		if err != nil {
-			return nil, errors.Wrapf(err, "failed to list members for org '%s'", c.org)
+			return errors.Wrapf(err, "failed to list members for org '%s'", c.org)
 		}
 
 		for _, member := range members {
-			if member.Login != nil {
-				allMembers = append(allMembers, *member.Login)
+			if member.Login == nil {
+				continue
+			}
+			if err := fn(*member.Login); err != nil {
+				return err
 			}
 		}
 
@@ -253,5 +478,41 @@ func (c *Client) ListOrgMembers(ctx context.Context) ([]string, error) {
 		opts.Page = resp.NextPage
 	}
 
-	return allMembers, nil
+	return nil
+}
+
+// ListOutsideCollaborators returns the logins of all outside collaborators
+// on the organization's repositories. it lets callers classify many users
+// with one paginated listing instead of a membership lookup per user.
+func (c *Client) ListOutsideCollaborators(ctx context.Context) ([]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	opts := &github.ListOutsideCollaboratorsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var logins []string
+	for {
+		users, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.User, *github.Response, error) {
+			return c.client.Organizations.ListOutsideCollaborators(ctx, c.org, opts)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list outside collaborators for org '%s'", c.org)
+		}
+
+		for _, user := range users {
+			if user.Login != nil {
+				logins = append(logins, *user.Login)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return logins, nil
 }
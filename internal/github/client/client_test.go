@@ -0,0 +1,290 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// newTestClient creates a client for org acme against a fake GitHub API
+// that serves mux and issues installation tokens.
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	mux.HandleFunc("POST /app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token":"test-token","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := NewAppClientWithBaseURL(1, 1, keyPEM, "acme", srv.URL+"/")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+// newTestAppClient creates a test client that serves the app slug, counting
+// GET /app calls.
+func newTestAppClient(t *testing.T) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var appGets atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /app", func(w http.ResponseWriter, r *http.Request) {
+		appGets.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"slug":"ops-app"}`)
+	})
+	return newTestClient(t, mux), &appGets
+}
+
+func TestGetAppSlug_Cached(t *testing.T) {
+	c, appGets := newTestAppClient(t)
+	ctx := context.Background()
+
+	for i := range 3 {
+		slug, err := c.GetAppSlug(ctx)
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if slug != "ops-app" {
+			t.Fatalf("call %d: expected slug ops-app, got %q", i, slug)
+		}
+	}
+
+	// force a token refresh; the slug should survive it
+	c.tokenMu.Lock()
+	c.tokenExpAt = time.Now()
+	c.tokenMu.Unlock()
+	if err := c.ensureValidToken(ctx); err != nil {
+		t.Fatalf("failed to refresh token: %v", err)
+	}
+	if _, err := c.GetAppSlug(ctx); err != nil {
+		t.Fatalf("unexpected error after refresh: %v", err)
+	}
+
+	if got := appGets.Load(); got != 1 {
+		t.Errorf("expected 1 Apps.Get call, got %d", got)
+	}
+}
+
+func TestGetAppSlug_ConcurrentFirstCalls(t *testing.T) {
+	c, appGets := newTestAppClient(t)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetAppSlug(context.Background()); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := appGets.Load(); got != 1 {
+		t.Errorf("expected 1 Apps.Get call, got %d", got)
+	}
+}
+
+func TestShareAppIdentity(t *testing.T) {
+	primary, appGets := newTestAppClient(t)
+	if _, err := primary.GetAppSlug(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	installClient := &Client{}
+	installClient.ShareAppIdentity(primary)
+
+	slug, err := installClient.GetAppSlug(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if slug != "ops-app" {
+		t.Errorf("expected shared slug ops-app, got %q", slug)
+	}
+	if got := appGets.Load(); got != 1 {
+		t.Errorf("expected 1 Apps.Get call, got %d", got)
+	}
+}
+
+func TestEnsureValidToken_ConcurrentRefresh(t *testing.T) {
+	var mints atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.Header.Get("Authorization"), fmt.Sprintf("Bearer token-%d", mints.Load()); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("POST /app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token":"token-%d","expires_at":%q}`, mints.Add(1), time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	c, err := NewAppClientWithBaseURL(1, 1, keyPEM, "acme", srv.URL+"/")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	// expire the token; concurrent callers should mint one replacement and
+	// the shared api client should use it
+	c.tokenMu.Lock()
+	c.tokenExpAt = time.Now()
+	c.tokenMu.Unlock()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.GetTeamMembers(context.Background(), "eng"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := mints.Load(); got != 2 {
+		t.Errorf("expected 2 token mints, got %d", got)
+	}
+}
+
+func TestForEachOrgMember(t *testing.T) {
+	var pages atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/members", func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("expected per_page=100, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"login":"carol"}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"login":"alice"},{},{"login":"bob"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	t.Run("all pages", func(t *testing.T) {
+		pages.Store(0)
+		var logins []string
+		err := c.ForEachOrgMember(context.Background(), func(login string) error {
+			logins = append(logins, login)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !slices.Equal(logins, []string{"alice", "bob", "carol"}) {
+			t.Errorf("expected [alice bob carol], got %v", logins)
+		}
+		if got := pages.Load(); got != 2 {
+			t.Errorf("expected 2 pages fetched, got %d", got)
+		}
+	})
+
+	t.Run("stops on error", func(t *testing.T) {
+		pages.Store(0)
+		errStop := errors.New("stop")
+		var logins []string
+		err := c.ForEachOrgMember(context.Background(), func(login string) error {
+			logins = append(logins, login)
+			if login == "alice" {
+				return errStop
+			}
+			return nil
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("expected the callback's error, got %v", err)
+		}
+		if !slices.Equal(logins, []string{"alice"}) {
+			t.Errorf("expected iteration to stop after alice, got %v", logins)
+		}
+		if got := pages.Load(); got != 1 {
+			t.Errorf("expected no further pages fetched, got %d", got)
+		}
+	})
+}
+
+func TestGetAppBotUserID(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		want    int64
+		wantErr bool
+	}{
+		{"bot user found", http.StatusOK, `{"login":"ops-app[bot]","id":99,"type":"Bot"}`, 99, false},
+		{"bot user missing", http.StatusNotFound, `{"message":"Not Found"}`, 0, true},
+		{"bot user without id", http.StatusOK, `{"login":"ops-app[bot]","type":"Bot"}`, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userGets atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /app", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"id":1,"slug":"ops-app"}`)
+			})
+			mux.HandleFunc("GET /users/{login}", func(w http.ResponseWriter, r *http.Request) {
+				userGets.Add(1)
+				if r.PathValue("login") != "ops-app[bot]" {
+					t.Errorf("unexpected user lookup %q", r.PathValue("login"))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+			c := newTestClient(t, mux)
+
+			for range 2 {
+				id, err := c.GetAppBotUserID(context.Background())
+				if (err != nil) != tt.wantErr || id != tt.want {
+					t.Fatalf("GetAppBotUserID() = %d, %v; want %d, error %t", id, err, tt.want, tt.wantErr)
+				}
+			}
+
+			// successful lookups are cached; failures are retried
+			wantGets := int32(2)
+			if !tt.wantErr {
+				wantGets = 1
+			}
+			if got := userGets.Load(); got != wantGets {
+				t.Errorf("expected %d user lookups, got %d", wantGets, got)
+			}
+		})
+	}
+}
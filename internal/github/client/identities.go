@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+)
+
+// externalIdentitiesQuery lists the org's SAML and SCIM linked identities
+// with the GitHub login each one is linked to.
+const externalIdentitiesQuery = `query($org: String!, $cursor: String) {
+  organization(login: $org) {
+    samlIdentityProvider {
+      externalIdentities(first: 100, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          samlIdentity { nameId emails { value } }
+          scimIdentity { username emails { value } }
+          user { login }
+        }
+      }
+    }
+  }
+}`
+
+// externalIdentitiesResponse is the GraphQL response for
+// externalIdentitiesQuery.
+type externalIdentitiesResponse struct {
+	Data struct {
+		Organization *struct {
+			SAMLIdentityProvider *struct {
+				ExternalIdentities struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []struct {
+						SAMLIdentity *struct {
+							NameID string          `json:"nameId"`
+							Emails []identityEmail `json:"emails"`
+						} `json:"samlIdentity"`
+						SCIMIdentity *struct {
+							Username string          `json:"username"`
+							Emails   []identityEmail `json:"emails"`
+						} `json:"scimIdentity"`
+						User *struct {
+							Login string `json:"login"`
+						} `json:"user"`
+					} `json:"nodes"`
+				} `json:"externalIdentities"`
+			} `json:"samlIdentityProvider"`
+		} `json:"organization"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// identityEmail is an email attached to a SAML or SCIM identity.
+type identityEmail struct {
+	Value string `json:"value"`
+}
+
+// GetExternalIdentityLogins returns a map of lowercased email to GitHub login
+// built from the org's SAML and SCIM linked identities. identities not linked
+// to a GitHub user are skipped. returns an empty map when the org has no SAML
+// identity provider.
+func (c *Client) GetExternalIdentityLogins(ctx context.Context) (map[string]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	logins := make(map[string]string)
+	var cursor *string
+
+	for {
+		body := map[string]any{
+			"query": externalIdentitiesQuery,
+			"variables": map[string]any{
+				"org":    c.org,
+				"cursor": cursor,
+			},
+		}
+
+		req, err := c.client.NewRequest("POST", c.graphQLURL(), body)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create external identities request")
+		}
+
+		var resp externalIdentitiesResponse
+		if _, err := c.client.Do(ctx, req, &resp); err != nil {
+			return nil, errors.Wrapf(err, "failed to list external identities for org '%s'", c.org)
+		}
+		if len(resp.Errors) > 0 {
+			return nil, errors.Newf("failed to list external identities for org '%s': %s", c.org, resp.Errors[0].Message)
+		}
+
+		org := resp.Data.Organization
+		if org == nil || org.SAMLIdentityProvider == nil {
+			return logins, nil
+		}
+
+		identities := org.SAMLIdentityProvider.ExternalIdentities
+		for _, node := range identities.Nodes {
+			if node.User == nil || node.User.Login == "" {
+				continue
+			}
+
+			var emails []string
+			if node.SAMLIdentity != nil {
+				emails = append(emails, node.SAMLIdentity.NameID)
+				for _, email := range node.SAMLIdentity.Emails {
+					emails = append(emails, email.Value)
+				}
+			}
+			if node.SCIMIdentity != nil {
+				emails = append(emails, node.SCIMIdentity.Username)
+				for _, email := range node.SCIMIdentity.Emails {
+					emails = append(emails, email.Value)
+				}
+			}
+
+			for _, email := range emails {
+				if strings.Contains(email, "@") {
+					logins[strings.ToLower(email)] = node.User.Login
+				}
+			}
+		}
+
+		if !identities.PageInfo.HasNextPage {
+			break
+		}
+		endCursor := identities.PageInfo.EndCursor
+		cursor = &endCursor
+	}
+
+	return logins, nil
+}
+
+// graphQLURL returns the GraphQL endpoint for the configured API. GitHub
+// Enterprise Server serves GraphQL at /api/graphql rather than under
+// /api/v3/.
+func (c *Client) graphQLURL() string {
+	base := c.client.BaseURL.String()
+	if strings.HasSuffix(base, "/api/v3/") {
+		return strings.TrimSuffix(base, "v3/") + "graphql"
+	}
+	return base + "graphql"
+}
@@ -3,16 +3,20 @@ package client
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	"github.com/google/go-github/v79/github"
 )
 
 // ComplianceViolation represents a single branch protection rule violation.
 type ComplianceViolation struct {
-	Type        string
-	Description string
+	Type        string `json:"type"`
+	Description string `json:"description"`
 }
 
 // PRComplianceResult contains PR compliance check results including
@@ -25,12 +29,65 @@ type PRComplianceResult struct {
 	Violations       []ComplianceViolation
 	UserHasBypass    bool
 	UserBypassReason string
+	// MergeQueue is true when the PR was merged by GitHub's merge queue.
+	MergeQueue bool
+	// MergedBy is the login the merge is attributed to. for merge queue
+	// merges this is the user who queued the PR rather than the queue bot.
+	MergedBy string
+	// MergedByCommitAuthor is true when the PR had no merged_by user and
+	// MergedBy is the author of the merge commit instead. on squash merges
+	// that is the PR author, so it is only shown for context and violations
+	// count as a bypass as for MergedByUnknown.
+	MergedByCommitAuthor bool
+	// MergedByUnknown is true when the PR was merged but who merged it could
+	// not be determined, e.g. for merges by automation tokens or deleted
+	// users. violations of such merges are reported as bypasses.
+	MergedByUnknown bool
+	// BypassAllowlisted is true when MergedBy is on the policy's bypass
+	// allowlist, so violations are expected and not reported as a bypass.
+	BypassAllowlisted bool
+	// Exempted is true when an exemption label approver, who neither
+	// authored nor merged the PR, applied the policy's exemption label, so
+	// violations are recorded but not reported as a bypass.
+	Exempted bool
+	// ExemptedBy is the login that most recently applied the exemption
+	// label, or empty if unknown.
+	ExemptedBy string
+}
+
+// mergeQueueBotLogin is the account GitHub's merge queue merges as.
+const mergeQueueBotLogin = "github-merge-queue[bot]"
+
+// PRComplianceOptions contains policy settings enforced on top of the
+// branch protection configured in GitHub.
+type PRComplianceOptions struct {
+	// MinApprovals is a policy floor for approving reviews. the effective
+	// requirement is the higher of this value and GitHub's configuration.
+	MinApprovals int
+	// RequiredTeam is a team slug whose membership must include at least one
+	// approving reviewer.
+	RequiredTeam string
+	// BypassAllowlist lists logins whose merges with violations are expected,
+	// such as release bots.
+	BypassAllowlist []string
+	// SuppressedViolations lists violation types that are not reported.
+	SuppressedViolations []string
+	// ExemptLabel is a PR label that exempts the merge from bypass alerts.
+	ExemptLabel string
+	// ExemptLabelApprovers lists logins whose exemption label is honored.
+	ExemptLabelApprovers []string
+	// ExemptLabelApproverTeams lists team slugs whose members' exemption
+	// label is honored.
+	ExemptLabelApproverTeams []string
+	// BypassPermissions lists the repository roles whose holders can bypass
+	// branch protection. empty uses types.DefaultBypassPermissions.
+	BypassPermissions []string
 }
 
 // CheckPRCompliance verifies if a merged PR met branch protection
-// requirements. checks review requirements, status checks, and user bypass
-// permissions.
-func (c *Client) CheckPRCompliance(ctx context.Context, owner, repo string, prNumber int) (*PRComplianceResult, error) {
+// requirements. checks review requirements, status checks, required
+// deployments, commit signatures, and user bypass permissions.
+func (c *Client) CheckPRCompliance(ctx context.Context, owner, repo string, prNumber int, opts PRComplianceOptions) (*PRComplianceResult, error) {
 	if err := c.ensureValidToken(ctx); err != nil {
 		return nil, err
 	}
@@ -56,28 +113,223 @@ func (c *Client) CheckPRCompliance(ctx context.Context, owner, repo string, prNu
 		Violations: []ComplianceViolation{},
 	}
 
-	// fetch legacy branch protection rules
-	protection, _, err := c.client.Repositories.GetBranchProtection(ctx, owner, repo, baseBranch)
-	if err == nil {
-		result.Protection = protection
-	}
-
-	// fetch repository rulesets for the branch
-	branchRules, _, err := c.client.Repositories.GetRulesForBranch(ctx, owner, repo, baseBranch, nil)
-	if err == nil {
-		result.BranchRules = branchRules
+	// fetch legacy branch protection rules and repository and org rulesets,
+	// reusing recent results for the same branch when a cache is configured
+	baseRepo := pr.GetBase().GetRepo()
+	if baseRepo == nil {
+		baseRepo = &github.Repository{Name: github.Ptr(repo)}
 	}
+	result.Protection, result.BranchRules = c.fetchBranchRules(ctx, owner, repo, baseBranch, baseRepo)
 
-	c.checkReviewRequirements(ctx, owner, repo, pr, result)
+	c.resolveMergeActor(ctx, owner, repo, pr, result)
+	checkDraftState(pr, result)
+	c.checkReviewRequirements(ctx, owner, repo, pr, opts, result)
 	c.checkStatusRequirements(ctx, owner, repo, pr, result)
-	c.checkUserBypassPermission(ctx, owner, repo, pr, result)
+	c.checkDeploymentRequirements(ctx, owner, repo, pr, result)
+	c.checkSignatureRequirements(ctx, owner, repo, pr, result)
+	c.checkUserBypassPermission(ctx, owner, repo, opts.BypassPermissions, result)
+	applyPolicyExceptions(opts, result)
+	if result.Exempted {
+		result.ExemptedBy = c.findLabelActor(ctx, owner, repo, prNumber, opts.ExemptLabel)
+		result.Exempted = c.isExemptLabelApprover(ctx, result.ExemptedBy, opts, result)
+		if !result.Exempted {
+			c.logger.Warn("ignoring compliance exemption label not applied by an approver",
+				slog.String("repo", owner+"/"+repo),
+				slog.Int("pr_number", prNumber),
+				slog.String("labeled_by", result.ExemptedBy))
+		}
+	}
 
 	return result, nil
 }
 
+// applyPolicyExceptions drops suppressed violation types and flags merges by
+// allowlisted users and PRs carrying the exemption label. CheckPRCompliance
+// then keeps the exemption only if an approver applied the label.
+func applyPolicyExceptions(opts PRComplianceOptions, result *PRComplianceResult) {
+	if len(opts.SuppressedViolations) > 0 {
+		result.Violations = slices.DeleteFunc(result.Violations, func(v ComplianceViolation) bool {
+			return slices.Contains(opts.SuppressedViolations, v.Type)
+		})
+	}
+
+	mergedBy := result.MergedByLogin()
+	result.BypassAllowlisted = mergedBy != "" && slices.ContainsFunc(opts.BypassAllowlist, func(login string) bool {
+		return strings.EqualFold(login, mergedBy)
+	})
+
+	result.Exempted = opts.ExemptLabel != "" && result.PR != nil && slices.ContainsFunc(result.PR.Labels, func(label *github.Label) bool {
+		return strings.EqualFold(label.GetName(), opts.ExemptLabel)
+	})
+}
+
+// resolveMergeActor sets who the merge is attributed to. merge queue merges
+// are made by the queue bot, so they are attributed to the user who enabled
+// auto-merge or, failing that, who last added the PR to the queue.
+func (c *Client) resolveMergeActor(ctx context.Context, owner, repo string, pr *github.PullRequest, result *PRComplianceResult) {
+	result.MergedBy = pr.GetMergedBy().GetLogin()
+
+	if result.MergedBy == "" && (pr.GetMerged() || pr.MergedAt != nil) {
+		if author := c.findCommitAuthor(ctx, owner, repo, pr.GetMergeCommitSHA()); author != "" {
+			result.MergedBy = author
+			result.MergedByCommitAuthor = true
+			return
+		}
+		result.MergedByUnknown = true
+		result.UserBypassReason = "merged by unknown actor"
+		return
+	}
+
+	if !isMergeQueueMerge(pr, result.BranchRules) {
+		return
+	}
+	result.MergeQueue = true
+
+	if enabledBy := pr.GetAutoMerge().GetEnabledBy().GetLogin(); enabledBy != "" {
+		result.MergedBy = enabledBy
+		return
+	}
+
+	if queuedBy := c.findMergeQueueActor(ctx, owner, repo, pr.GetNumber()); queuedBy != "" {
+		result.MergedBy = queuedBy
+	}
+}
+
+// findCommitAuthor returns the GitHub login of the author of commit sha, or
+// an empty string if unknown.
+func (c *Client) findCommitAuthor(ctx context.Context, owner, repo, sha string) string {
+	if sha == "" {
+		return ""
+	}
+	commit, _, err := withRateLimitRetry(ctx, c, func() (*github.RepositoryCommit, *github.Response, error) {
+		return c.client.Repositories.GetCommit(ctx, owner, repo, sha, nil)
+	})
+	if err != nil {
+		c.logger.Warn("failed to read merge commit author",
+			slog.String("repo", owner+"/"+repo),
+			slog.String("sha", sha),
+			slog.String("error", err.Error()))
+		return ""
+	}
+	return commit.GetAuthor().GetLogin()
+}
+
+// isMergeQueueMerge reports whether the PR was merged by the merge queue.
+// a bot merge is treated as a queue merge when the branch requires one.
+func isMergeQueueMerge(pr *github.PullRequest, branchRules *github.BranchRules) bool {
+	mergedBy := pr.GetMergedBy()
+	if strings.EqualFold(mergedBy.GetLogin(), mergeQueueBotLogin) {
+		return true
+	}
+	return mergedBy.GetType() == "Bot" && branchRules != nil && len(branchRules.MergeQueue) > 0
+}
+
+// findMergeQueueActor returns the user who most recently added the PR to the
+// merge queue, or an empty string if unknown.
+func (c *Client) findMergeQueueActor(ctx context.Context, owner, repo string, prNumber int) string {
+	opts := &github.ListOptions{PerPage: 100}
+	actor := ""
+
+	for {
+		events, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.Timeline, *github.Response, error) {
+			return c.client.Issues.ListIssueTimeline(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			// a later page may hold a more recent actor, so a partial answer
+			// is not trusted
+			c.logger.Warn("failed to resolve who added the pr to the merge queue",
+				slog.String("repo", owner+"/"+repo),
+				slog.Int("pr_number", prNumber),
+				slog.String("error", err.Error()))
+			return ""
+		}
+
+		for _, event := range events {
+			if event.GetEvent() == "added_to_merge_queue" && event.GetActor().GetLogin() != "" {
+				actor = event.GetActor().GetLogin()
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return actor
+}
+
+// findLabelActor returns the user who most recently applied label to the PR,
+// or an empty string if unknown.
+func (c *Client) findLabelActor(ctx context.Context, owner, repo string, prNumber int, label string) string {
+	opts := &github.ListOptions{PerPage: 100}
+	actor := ""
+
+	for {
+		events, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.Timeline, *github.Response, error) {
+			return c.client.Issues.ListIssueTimeline(ctx, owner, repo, prNumber, opts)
+		})
+		if err != nil {
+			// a later page may hold a more recent labeler, so a partial
+			// answer is not trusted
+			c.logger.Warn("failed to resolve who applied the exemption label",
+				slog.String("repo", owner+"/"+repo),
+				slog.Int("pr_number", prNumber),
+				slog.String("error", err.Error()))
+			return ""
+		}
+
+		for _, event := range events {
+			if event.GetEvent() == "labeled" && strings.EqualFold(event.GetLabel().GetName(), label) && event.GetActor().GetLogin() != "" {
+				actor = event.GetActor().GetLogin()
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return actor
+}
+
+// isExemptLabelApprover returns true if login may exempt the PR by applying
+// the exemption label: it must be one of the policy's approvers or a member
+// of an approver team, and must not have authored or merged the PR. an
+// unknown labeler never exempts.
+func (c *Client) isExemptLabelApprover(ctx context.Context, login string, opts PRComplianceOptions, result *PRComplianceResult) bool {
+	if login == "" {
+		return false
+	}
+	sameLogin := func(other string) bool { return strings.EqualFold(other, login) }
+	if sameLogin(result.PR.GetUser().GetLogin()) || sameLogin(result.MergedByLogin()) {
+		return false
+	}
+	if slices.ContainsFunc(opts.ExemptLabelApprovers, sameLogin) {
+		return true
+	}
+
+	for _, team := range opts.ExemptLabelApproverTeams {
+		members, err := c.GetTeamMembersCached(ctx, team)
+		if err != nil {
+			c.logger.Warn("failed to read exemption label approver team",
+				slog.String("team", team),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if slices.ContainsFunc(members, sameLogin) {
+			return true
+		}
+	}
+	return false
+}
+
 // checkReviewRequirements validates that PR had required approving reviews.
-// checks both legacy branch protection and repository rulesets.
-func (c *Client) checkReviewRequirements(ctx context.Context, owner, repo string, pr *github.PullRequest, result *PRComplianceResult) {
+// checks both legacy branch protection and repository rulesets, and enforces
+// the configured policy minimum. approvals by the PR author never count, and
+// a PR approved only by its author is reported as self-approved.
+func (c *Client) checkReviewRequirements(ctx context.Context, owner, repo string, pr *github.PullRequest, opts PRComplianceOptions, result *PRComplianceResult) {
 	requiredApprovals := 0
 
 	// check legacy branch protection
@@ -94,7 +346,12 @@ func (c *Client) checkReviewRequirements(ctx context.Context, owner, repo string
 		}
 	}
 
-	if requiredApprovals == 0 {
+	// enforce policy floor even if github protection is weaker
+	if opts.MinApprovals > requiredApprovals {
+		requiredApprovals = opts.MinApprovals
+	}
+
+	if requiredApprovals == 0 && opts.RequiredTeam == "" {
 		return
 	}
 
@@ -103,19 +360,65 @@ func (c *Client) checkReviewRequirements(ctx context.Context, owner, repo string
 		return
 	}
 
-	approvedCount := 0
+	author := strings.ToLower(pr.GetUser().GetLogin())
+	approvers := make(map[string]bool)
+	approvedCount, selfApprovedCount := 0, 0
 	for _, review := range reviews {
 		if review.State != nil && *review.State == "APPROVED" {
+			reviewer := strings.ToLower(review.GetUser().GetLogin())
+			if author != "" && reviewer == author {
+				selfApprovedCount++
+				continue
+			}
 			approvedCount++
+			if reviewer != "" {
+				approvers[reviewer] = true
+			}
 		}
 	}
 
+	if selfApprovedCount > 0 && approvedCount == 0 {
+		result.Violations = append(result.Violations, ComplianceViolation{
+			Type:        types.ViolationSelfApproval,
+			Description: fmt.Sprintf("only approvals were from the pr author '%s'", pr.GetUser().GetLogin()),
+		})
+	}
+
 	if approvedCount < requiredApprovals {
 		result.Violations = append(result.Violations, ComplianceViolation{
-			Type:        "insufficient_reviews",
+			Type:        types.ViolationInsufficientReviews,
 			Description: fmt.Sprintf("required %d approving reviews, had %d", requiredApprovals, approvedCount),
 		})
 	}
+
+	if opts.RequiredTeam != "" {
+		c.checkTeamReview(ctx, opts.RequiredTeam, approvers, result)
+	}
+}
+
+// checkTeamReview validates that at least one approving reviewer is a member
+// of the required team. if the team cannot be read, the check is skipped
+// with a warning rather than reporting a violation that may be wrong.
+func (c *Client) checkTeamReview(ctx context.Context, teamSlug string, approvers map[string]bool, result *PRComplianceResult) {
+	members, err := c.GetTeamMembersCached(ctx, teamSlug)
+	if err != nil {
+		c.logger.Warn("failed to read required review team, skipping team review check",
+			slog.String("team", teamSlug),
+			slog.Int("pr", result.PR.GetNumber()),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	for _, member := range members {
+		if approvers[strings.ToLower(member)] {
+			return
+		}
+	}
+
+	result.Violations = append(result.Violations, ComplianceViolation{
+		Type:        types.ViolationMissingTeamReview,
+		Description: fmt.Sprintf("required approving review from team '%s'", teamSlug),
+	})
 }
 
 // checkStatusRequirements validates that required status checks passed.
@@ -125,6 +428,12 @@ func (c *Client) checkStatusRequirements(ctx context.Context, owner, repo string
 		return
 	}
 
+	// the merge queue runs checks on the commit it merges, not the pr head
+	sha := *pr.Head.SHA
+	if result.MergeQueue && pr.GetMergeCommitSHA() != "" {
+		sha = pr.GetMergeCommitSHA()
+	}
+
 	// collect required checks from both sources
 	requiredChecks := make(map[string]bool)
 
@@ -150,7 +459,7 @@ func (c *Client) checkStatusRequirements(ctx context.Context, owner, repo string
 		return
 	}
 
-	combinedStatus, _, err := c.client.Repositories.GetCombinedStatus(ctx, owner, repo, *pr.Head.SHA, nil)
+	combinedStatus, _, err := c.client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, nil)
 	if err != nil {
 		return
 	}
@@ -165,37 +474,218 @@ func (c *Client) checkStatusRequirements(ctx context.Context, owner, repo string
 	for required := range requiredChecks {
 		if !passedChecks[required] {
 			result.Violations = append(result.Violations, ComplianceViolation{
-				Type:        "missing_status_check",
+				Type:        types.ViolationMissingStatusCheck,
 				Description: fmt.Sprintf("required check '%s' did not pass", required),
 			})
 		}
 	}
 }
 
-// checkUserBypassPermission checks if the user who merged the PR has admin or
-// maintainer permissions allowing bypass.
-func (c *Client) checkUserBypassPermission(ctx context.Context, owner, repo string, pr *github.PullRequest, result *PRComplianceResult) {
-	if pr.MergedBy == nil || pr.MergedBy.Login == nil {
+// checkDeploymentRequirements validates that the PR head was successfully
+// deployed to every environment required by repository or org rulesets.
+// classic branch protection can also require deployments, but the REST API
+// does not expose that setting, so only rulesets are checked. an environment
+// whose deployments cannot be read is skipped with a warning, since the
+// outcome is unknown.
+func (c *Client) checkDeploymentRequirements(ctx context.Context, owner, repo string, pr *github.PullRequest, result *PRComplianceResult) {
+	if pr.Head == nil || pr.Head.SHA == nil || result.BranchRules == nil {
 		return
 	}
 
-	mergedBy := *pr.MergedBy.Login
+	var environments []string
+	for _, rule := range result.BranchRules.RequiredDeployments {
+		environments = append(environments, rule.Parameters.RequiredDeploymentEnvironments...)
+	}
+	slices.Sort(environments)
+	environments = slices.Compact(environments)
+
+	for _, environment := range environments {
+		deployed, err := c.hasSuccessfulDeployment(ctx, owner, repo, *pr.Head.SHA, environment)
+		if err != nil {
+			c.logger.Warn("failed to read deployments, skipping required deployment check",
+				slog.String("environment", environment),
+				slog.Int("pr", pr.GetNumber()),
+				slog.String("error", err.Error()))
+			continue
+		}
+		if deployed {
+			continue
+		}
+		result.Violations = append(result.Violations, ComplianceViolation{
+			Type:        types.ViolationMissingRequiredDeployment,
+			Description: fmt.Sprintf("required deployment to '%s' did not succeed", environment),
+		})
+	}
+}
 
-	permissionLevel, _, err := c.client.Repositories.GetPermissionLevel(ctx, owner, repo, mergedBy)
-	if err != nil {
+// hasSuccessfulDeployment reports whether any deployment of sha to
+// environment has a success status. a repository without deployments
+// reports false.
+func (c *Client) hasSuccessfulDeployment(ctx context.Context, owner, repo, sha, environment string) (bool, error) {
+	opts := &github.DeploymentsListOptions{
+		SHA:         sha,
+		Environment: environment,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		deployments, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.Deployment, *github.Response, error) {
+			return c.client.Repositories.ListDeployments(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to list deployments to '%s' for %s/%s", environment, owner, repo)
+		}
+
+		for _, deployment := range deployments {
+			succeeded, err := c.hasSuccessStatus(ctx, owner, repo, deployment.GetID())
+			if err != nil || succeeded {
+				return succeeded, err
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// hasSuccessStatus reports whether any status of a deployment is success.
+func (c *Client) hasSuccessStatus(ctx context.Context, owner, repo string, deploymentID int64) (bool, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		statuses, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.DeploymentStatus, *github.Response, error) {
+			return c.client.Repositories.ListDeploymentStatuses(ctx, owner, repo, deploymentID, opts)
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to list statuses of deployment %d for %s/%s", deploymentID, owner, repo)
+		}
+		if slices.ContainsFunc(statuses, func(s *github.DeploymentStatus) bool { return s.GetState() == "success" }) {
+			return true, nil
+		}
+
+		if resp.NextPage == 0 {
+			return false, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// checkDraftState reports PRs that were still drafts when merged. GitHub
+// blocks merging drafts, so this only happens through admin or API merges. a
+// nil draft flag is treated as not a draft.
+func checkDraftState(pr *github.PullRequest, result *PRComplianceResult) {
+	if !pr.GetDraft() {
 		return
 	}
+	result.Violations = append(result.Violations, ComplianceViolation{
+		Type:        types.ViolationMergedWhileDraft,
+		Description: "pr was merged while still a draft",
+	})
+}
+
+// checkSignatureRequirements validates that every PR commit has a verified
+// signature when the branch requires signed commits. checks both legacy
+// branch protection and repository rulesets.
+func (c *Client) checkSignatureRequirements(ctx context.Context, owner, repo string, pr *github.PullRequest, result *PRComplianceResult) {
+	required := result.Protection.GetRequiredSignatures().GetEnabled()
+	if result.BranchRules != nil && len(result.BranchRules.RequiredSignatures) > 0 {
+		required = true
+	}
+	if !required {
+		return
+	}
+
+	var unsigned []string
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		commits, resp, err := c.client.PullRequests.ListCommits(ctx, owner, repo, pr.GetNumber(), opts)
+		if err != nil {
+			return
+		}
+
+		for _, commit := range commits {
+			if !commit.GetCommit().GetVerification().GetVerified() {
+				unsigned = append(unsigned, abbrevSHA(commit.GetSHA()))
+			}
+		}
 
-	if permissionLevel.Permission != nil {
-		switch perm := *permissionLevel.Permission; perm {
-		case "admin":
-			result.UserHasBypass = true
-			result.UserBypassReason = "repository admin"
-		case "maintain":
-			result.UserHasBypass = true
-			result.UserBypassReason = "repository maintainer"
+		if resp.NextPage == 0 {
+			break
 		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(unsigned) > 0 {
+		result.Violations = append(result.Violations, ComplianceViolation{
+			Type:        types.ViolationUnsignedCommits,
+			Description: fmt.Sprintf("required signed commits, %d unverified: %s", len(unsigned), strings.Join(unsigned, ", ")),
+		})
+	}
+}
+
+// abbrevSHA shortens a commit SHA to the 7 characters GitHub displays.
+func abbrevSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
 	}
+	return sha
+}
+
+// checkUserBypassPermission checks if the user the merge is attributed to
+// holds one of the repository roles in permissions, which allow bypass. the
+// role name is used when GitHub returns one, since the legacy permission
+// reports maintainers as "write".
+func (c *Client) checkUserBypassPermission(ctx context.Context, owner, repo string, permissions []string, result *PRComplianceResult) {
+	mergedBy := result.MergedBy
+	if mergedBy == "" {
+		return
+	}
+
+	permissionLevel, _, err := c.client.Repositories.GetPermissionLevel(ctx, owner, repo, mergedBy)
+	if err != nil || permissionLevel == nil {
+		return
+	}
+
+	role := strings.ToLower(permissionLevel.GetRoleName())
+	if role == "" {
+		role = strings.ToLower(permissionLevel.GetPermission())
+	}
+	if role == "" {
+		return
+	}
+
+	if len(permissions) == 0 {
+		permissions = types.DefaultBypassPermissions
+	}
+	if slices.ContainsFunc(permissions, func(p string) bool { return strings.EqualFold(p, role) }) {
+		result.UserHasBypass = true
+		result.UserBypassReason = bypassReason(role)
+	}
+}
+
+// bypassReason describes the repository role that granted a bypass.
+func bypassReason(role string) string {
+	switch role {
+	case "admin":
+		return "repository admin"
+	case "maintain":
+		return "repository maintainer"
+	default:
+		return "repository " + role + " role"
+	}
+}
+
+// MergedByLogin returns the login the merge is attributed to, falling back
+// to the PR's merged_by user when MergedBy is unset.
+func (r *PRComplianceResult) MergedByLogin() string {
+	if r.MergedBy != "" {
+		return r.MergedBy
+	}
+	if r.PR != nil {
+		return r.PR.GetMergedBy().GetLogin()
+	}
+	return ""
 }
 
 // HasViolations returns true if any compliance violations were detected.
@@ -204,7 +694,164 @@ func (r *PRComplianceResult) HasViolations() bool {
 }
 
 // WasBypassed returns true if violations exist and user had bypass
-// permission.
+// permission, or who merged the PR is unknown or only inferred from the
+// merge commit author. bypasses by allowlisted users and of exempt PRs are
+// not counted.
 func (r *PRComplianceResult) WasBypassed() bool {
-	return r.HasViolations() && r.UserHasBypass
+	mergerUnknown := r.MergedByUnknown || r.MergedByCommitAuthor
+	return r.HasViolations() && (r.UserHasBypass || mergerUnknown) && !r.BypassAllowlisted && !r.Exempted
+}
+
+// bypassCommentMarker identifies bypass comments posted by the app so they
+// are not duplicated when a webhook is reprocessed.
+const bypassCommentMarker = "<!-- github-ops-app:pr-bypass -->"
+
+// CommentOnBypass posts a comment on the PR listing compliance violations and
+// who bypassed them. returns false without posting if a bypass comment from
+// the app already exists.
+func (c *Client) CommentOnBypass(ctx context.Context, owner, repo string, result *PRComplianceResult) (bool, error) {
+	if result.PR == nil || result.PR.Number == nil {
+		return false, errors.Wrap(internalerrors.ErrMissingPRData, "pr result missing")
+	}
+
+	if err := c.ensureValidToken(ctx); err != nil {
+		return false, err
+	}
+
+	prNumber := *result.PR.Number
+
+	exists, err := c.hasBypassComment(ctx, owner, repo, prNumber)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	body := formatBypassComment(result)
+	_, _, err = c.client.Issues.CreateComment(ctx, owner, repo, prNumber, &github.IssueComment{Body: &body})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to comment on pr #%d in %s/%s", prNumber, owner, repo)
+	}
+
+	return true, nil
+}
+
+// hasBypassComment checks if the PR already has a bypass comment.
+func (c *Client) hasBypassComment(ctx context.Context, owner, repo string, prNumber int) (bool, error) {
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	for {
+		comments, resp, err := c.client.Issues.ListComments(ctx, owner, repo, prNumber, opts)
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to list comments for pr #%d in %s/%s", prNumber, owner, repo)
+		}
+
+		for _, comment := range comments {
+			if strings.Contains(comment.GetBody(), bypassCommentMarker) {
+				return true, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return false, nil
+}
+
+// formatBypassComment builds the markdown body for a bypass comment.
+func formatBypassComment(result *PRComplianceResult) string {
+	return bypassCommentMarker + "\n### Branch protection bypassed\n\n" + formatBypassSummary(result)
+}
+
+// formatBypassSummary describes who merged a bypassed PR and lists its
+// violations as markdown.
+func formatBypassSummary(result *PRComplianceResult) string {
+	mergedBy := "unknown"
+	if login := result.MergedByLogin(); login != "" {
+		mergedBy = "@" + login
+	}
+	if result.MergeQueue {
+		mergedBy += " via merge queue"
+	}
+	if result.MergedByCommitAuthor {
+		mergedBy += " (merge commit author)"
+	}
+	if result.MergedByUnknown {
+		mergedBy = "an unknown actor, e.g. an automation token or a deleted user"
+	} else if result.UserBypassReason != "" {
+		mergedBy = fmt.Sprintf("%s (%s)", mergedBy, result.UserBypassReason)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "This pull request was merged into `%s` by %s without meeting branch protection requirements:\n\n", result.BaseBranch, mergedBy)
+	for _, v := range result.Violations {
+		fmt.Fprintf(&b, "- %s\n", v.Description)
+	}
+
+	return b.String()
+}
+
+// complianceCheckRunName is the name of check runs created for bypassed PRs.
+const complianceCheckRunName = "PR Compliance"
+
+// CreateComplianceCheckRun creates a failed check run on the merge commit of
+// a bypassed PR summarizing its violations, leaving an auditable record on
+// the PR. requires the checks:write permission.
+func (c *Client) CreateComplianceCheckRun(ctx context.Context, owner, repo string, result *PRComplianceResult) error {
+	if result.PR == nil || result.PR.GetMergeCommitSHA() == "" {
+		return errors.Wrap(internalerrors.ErrMissingPRData, "pr merge commit missing")
+	}
+
+	if err := c.ensureValidToken(ctx); err != nil {
+		return err
+	}
+
+	sha := result.PR.GetMergeCommitSHA()
+	_, _, err := c.client.Checks.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:       complianceCheckRunName,
+		HeadSHA:    sha,
+		Status:     github.Ptr("completed"),
+		Conclusion: github.Ptr("failure"),
+		Output: &github.CheckRunOutput{
+			Title:   github.Ptr(fmt.Sprintf("Branch protection bypassed (%d violations)", len(result.Violations))),
+			Summary: github.Ptr(formatBypassSummary(result)),
+		},
+	})
+	if err != nil {
+		var ghErr *github.ErrorResponse
+		if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == 403 {
+			return errors.Wrapf(err, "missing checks:write permission to create check run on %s in %s/%s", sha, owner, repo)
+		}
+		return errors.Wrapf(err, "failed to create check run on %s in %s/%s", sha, owner, repo)
+	}
+
+	return nil
+}
+
+// FindMergedPRForCommit returns the number of the merged PR whose merge
+// commit is sha, or 0 if the commit was not produced by merging a PR. used
+// to tell PR merges apart from direct pushes to a branch.
+func (c *Client) FindMergedPRForCommit(ctx context.Context, owner, repo, sha string) (int, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return 0, err
+	}
+
+	prs, _, err := c.client.PullRequests.ListPullRequestsWithCommit(ctx, owner, repo, sha, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to list prs for commit %s in %s/%s", sha, owner, repo)
+	}
+
+	for _, pr := range prs {
+		if pr.MergedAt != nil && strings.EqualFold(pr.GetMergeCommitSHA(), sha) {
+			return pr.GetNumber(), nil
+		}
+	}
+
+	return 0, nil
 }
@@ -0,0 +1,716 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/google/go-github/v79/github"
+)
+
+func TestCheckSignatureRequirements(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/pulls/7/commits", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"sha":"1111111aaaaaaa","commit":{"verification":{"verified":true}}},
+			{"sha":"2222222bbbbbbb","commit":{"verification":{"verified":false,"reason":"unsigned"}}},
+			{"sha":"3333333ccccccc","commit":{}}
+		]`)
+	})
+	c := newTestClient(t, mux)
+	pr := &github.PullRequest{Number: github.Ptr(7)}
+
+	tests := []struct {
+		name   string
+		result *PRComplianceResult
+		want   string
+	}{
+		{
+			name:   "not required",
+			result: &PRComplianceResult{},
+		},
+		{
+			name: "required by branch protection",
+			result: &PRComplianceResult{Protection: &github.Protection{
+				RequiredSignatures: &github.SignaturesProtectedBranch{Enabled: github.Ptr(true)},
+			}},
+			want: "required signed commits, 2 unverified: 2222222, 3333333",
+		},
+		{
+			name: "required by ruleset",
+			result: &PRComplianceResult{BranchRules: &github.BranchRules{
+				RequiredSignatures: []*github.BranchRuleMetadata{{RulesetID: 1}},
+			}},
+			want: "required signed commits, 2 unverified: 2222222, 3333333",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.checkSignatureRequirements(context.Background(), "acme", "repo", pr, tt.result)
+
+			if tt.want == "" {
+				if len(tt.result.Violations) != 0 {
+					t.Errorf("expected no violations, got %+v", tt.result.Violations)
+				}
+				return
+			}
+			if len(tt.result.Violations) != 1 {
+				t.Fatalf("expected 1 violation, got %+v", tt.result.Violations)
+			}
+			v := tt.result.Violations[0]
+			if v.Type != types.ViolationUnsignedCommits || v.Description != tt.want {
+				t.Errorf("unexpected violation: %+v", v)
+			}
+
+			tt.result.UserHasBypass = true
+			if !tt.result.WasBypassed() {
+				t.Error("expected unsigned commits to count as a bypass")
+			}
+		})
+	}
+}
+
+func TestCheckDeploymentRequirements(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/deployments", func(w http.ResponseWriter, r *http.Request) {
+		if sha := r.URL.Query().Get("sha"); sha != "abc123" {
+			t.Errorf("expected deployments listed for head sha, got %q", sha)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("environment") {
+		case "staging":
+			fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+		case "production":
+			fmt.Fprint(w, `[{"id":3}]`)
+		case "canary":
+			fmt.Fprint(w, `[{"id":4}]`)
+		case "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"message":"server error"}`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	mux.HandleFunc("GET /repos/acme/repo/deployments/{id}/statuses", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PathValue("id") {
+		case "2":
+			fmt.Fprint(w, `[{"state":"success"},{"state":"in_progress"}]`)
+		case "3":
+			fmt.Fprint(w, `[{"state":"failure"}]`)
+		case "4":
+			// the success status is on the second page
+			if r.URL.Query().Get("page") == "2" {
+				fmt.Fprint(w, `[{"state":"success"}]`)
+				return
+			}
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=2>; rel="next"`, r.URL.Path))
+			fmt.Fprint(w, `[{"state":"in_progress"}]`)
+		default:
+			fmt.Fprint(w, `[{"state":"error"}]`)
+		}
+	})
+	c := newTestClient(t, mux)
+	c.retryMaxRetries = 0
+	var logs strings.Builder
+	c.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	pr := &github.PullRequest{Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")}}
+
+	requires := func(environments ...string) *PRComplianceResult {
+		return &PRComplianceResult{BranchRules: &github.BranchRules{
+			RequiredDeployments: []*github.RequiredDeploymentsBranchRule{{
+				Parameters: github.RequiredDeploymentsRuleParameters{RequiredDeploymentEnvironments: environments},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		name   string
+		result *PRComplianceResult
+		want   []string
+	}{
+		{name: "no rulesets", result: &PRComplianceResult{}},
+		{name: "deployed", result: requires("staging")},
+		{name: "failed deployment", result: requires("staging", "production"), want: []string{"required deployment to 'production' did not succeed"}},
+		{name: "never deployed", result: requires("preview"), want: []string{"required deployment to 'preview' did not succeed"}},
+		{name: "success on a later status page", result: requires("canary")},
+		{name: "deployments unreadable", result: requires("broken")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.checkDeploymentRequirements(context.Background(), "acme", "repo", pr, tt.result)
+
+			var got []string
+			for _, v := range tt.result.Violations {
+				if v.Type != types.ViolationMissingRequiredDeployment {
+					t.Errorf("unexpected violation type: %+v", v)
+				}
+				got = append(got, v.Description)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("expected violations %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	if !strings.Contains(logs.String(), "skipping required deployment check") || !strings.Contains(logs.String(), "environment=broken") {
+		t.Errorf("expected the unreadable environment to be logged, got %s", logs.String())
+	}
+}
+
+func TestCheckReviewRequirements_SelfApproval(t *testing.T) {
+	reviews := map[string]string{
+		"7": `[{"state":"APPROVED","user":{"login":"Alice"}},{"state":"COMMENTED","user":{"login":"bob"}}]`,
+		"8": `[{"state":"APPROVED","user":{"login":"alice"}},{"state":"APPROVED","user":{"login":"bob"}}]`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/pulls/{number}/reviews", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, reviews[r.PathValue("number")])
+	})
+	c := newTestClient(t, mux)
+	opts := PRComplianceOptions{MinApprovals: 1}
+
+	selfApproved := &PRComplianceResult{}
+	pr := &github.PullRequest{Number: github.Ptr(7), User: &github.User{Login: github.Ptr("alice")}}
+	c.checkReviewRequirements(context.Background(), "acme", "repo", pr, opts, selfApproved)
+
+	kinds := make([]string, 0, len(selfApproved.Violations))
+	for _, v := range selfApproved.Violations {
+		kinds = append(kinds, v.Type)
+	}
+	if len(kinds) != 2 || kinds[0] != types.ViolationSelfApproval || kinds[1] != types.ViolationInsufficientReviews {
+		t.Errorf("expected self approval and insufficient reviews violations, got %+v", selfApproved.Violations)
+	}
+
+	reviewed := &PRComplianceResult{}
+	pr = &github.PullRequest{Number: github.Ptr(8), User: &github.User{Login: github.Ptr("alice")}}
+	c.checkReviewRequirements(context.Background(), "acme", "repo", pr, opts, reviewed)
+	if len(reviewed.Violations) != 0 {
+		t.Errorf("expected approval from another reviewer to satisfy review requirements, got %+v", reviewed.Violations)
+	}
+}
+
+func TestCheckDraftState(t *testing.T) {
+	tests := []struct {
+		name  string
+		draft *bool
+		want  bool
+	}{
+		{name: "draft", draft: github.Ptr(true), want: true},
+		{name: "ready", draft: github.Ptr(false)},
+		{name: "unset", draft: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &PRComplianceResult{}
+			checkDraftState(&github.PullRequest{Draft: tt.draft}, result)
+			got := len(result.Violations) == 1 && result.Violations[0].Type == types.ViolationMergedWhileDraft
+			if got != tt.want || (!tt.want && len(result.Violations) != 0) {
+				t.Errorf("unexpected violations: %+v", result.Violations)
+			}
+		})
+	}
+}
+
+func TestApplyPolicyExceptions(t *testing.T) {
+	newResult := func() *PRComplianceResult {
+		return &PRComplianceResult{
+			MergedBy:      "Release-Bot",
+			UserHasBypass: true,
+			Violations: []ComplianceViolation{
+				{Type: types.ViolationInsufficientReviews},
+				{Type: types.ViolationMissingStatusCheck},
+			},
+		}
+	}
+
+	result := newResult()
+	applyPolicyExceptions(PRComplianceOptions{SuppressedViolations: []string{types.ViolationMissingStatusCheck}}, result)
+	if len(result.Violations) != 1 || result.Violations[0].Type != types.ViolationInsufficientReviews {
+		t.Errorf("expected status check violation suppressed, got %+v", result.Violations)
+	}
+	if !result.WasBypassed() {
+		t.Error("expected bypass by a user not on the allowlist")
+	}
+
+	result = newResult()
+	applyPolicyExceptions(PRComplianceOptions{BypassAllowlist: []string{"release-bot"}}, result)
+	if !result.BypassAllowlisted || result.WasBypassed() {
+		t.Errorf("expected allowlisted bypass not to count, got %+v", result)
+	}
+
+	result = newResult()
+	result.PR = &github.PullRequest{Labels: []*github.Label{{Name: github.Ptr("Compliance-Exempt")}}}
+	applyPolicyExceptions(PRComplianceOptions{ExemptLabel: "compliance-exempt"}, result)
+	if !result.Exempted || result.WasBypassed() {
+		t.Errorf("expected exempt-labeled bypass not to count, got %+v", result)
+	}
+}
+
+func TestFindLabelActor(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"event":"labeled","actor":{"login":"alice"},"label":{"name":"compliance-exempt"}},
+			{"event":"labeled","actor":{"login":"mallory"},"label":{"name":"bug"}},
+			{"event":"unlabeled","actor":{"login":"bob"},"label":{"name":"compliance-exempt"}},
+			{"event":"labeled","actor":{"login":"carol"},"label":{"name":"Compliance-Exempt"}}
+		]`)
+	})
+	c := newTestClient(t, mux)
+
+	if got := c.findLabelActor(context.Background(), "acme", "repo", 7, "compliance-exempt"); got != "carol" {
+		t.Errorf("expected the most recent labeler, got %q", got)
+	}
+	if got := c.findLabelActor(context.Background(), "acme", "repo", 7, "missing"); got != "" {
+		t.Errorf("expected no labeler, got %q", got)
+	}
+}
+
+func TestIsExemptLabelApprover(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/security/members", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"dave"},{"login":"alice"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	opts := PRComplianceOptions{
+		ExemptLabel:              "compliance-exempt",
+		ExemptLabelApprovers:     []string{"Carol"},
+		ExemptLabelApproverTeams: []string{"security"},
+	}
+	result := &PRComplianceResult{
+		PR:       &github.PullRequest{User: &github.User{Login: github.Ptr("alice")}},
+		MergedBy: "bob",
+	}
+
+	tests := []struct {
+		name    string
+		labeler string
+		want    bool
+	}{
+		{name: "approver login", labeler: "carol", want: true},
+		{name: "approver team member", labeler: "dave", want: true},
+		{name: "unauthorized labeler", labeler: "mallory"},
+		{name: "self-applied by author", labeler: "alice"},
+		{name: "self-applied by merger", labeler: "Bob"},
+		{name: "unknown labeler"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.isExemptLabelApprover(context.Background(), tt.labeler, opts, result); got != tt.want {
+				t.Errorf("isExemptLabelApprover(%q) = %v, want %v", tt.labeler, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindLabelActor_Error(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			http.Error(w, `{"message":"boom"}`, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"event":"labeled","actor":{"login":"carol"},"label":{"name":"compliance-exempt"}}]`)
+	})
+	c := newTestClient(t, mux)
+
+	if got := c.findLabelActor(context.Background(), "acme", "repo", 7, "compliance-exempt"); got != "" {
+		t.Errorf("expected no labeler when the timeline cannot be read, got %q", got)
+	}
+}
+
+func TestCheckTeamReview(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		approvers     map[string]bool
+		wantViolation bool
+		wantWarning   bool
+	}{
+		{"team member approved", http.StatusOK, map[string]bool{"alice": true}, false, false},
+		{"no team member approved", http.StatusOK, map[string]bool{"mallory": true}, true, false},
+		{"team cannot be read", http.StatusInternalServerError, map[string]bool{"mallory": true}, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /orgs/acme/teams/security/members", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				if tt.status != http.StatusOK {
+					fmt.Fprint(w, `{"message":"boom"}`)
+					return
+				}
+				fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"}]`)
+			})
+			c := newTestClient(t, mux)
+			c.retryMaxRetries = 0
+			var logs strings.Builder
+			c.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			result := &PRComplianceResult{PR: &github.PullRequest{Number: github.Ptr(7)}}
+			c.checkTeamReview(context.Background(), "security", tt.approvers, result)
+
+			if got := len(result.Violations) == 1 && result.Violations[0].Type == types.ViolationMissingTeamReview; got != tt.wantViolation {
+				t.Errorf("missing team review violation = %t, want %t (%+v)", got, tt.wantViolation, result.Violations)
+			}
+			if got := strings.Contains(logs.String(), "failed to read required review team"); got != tt.wantWarning {
+				t.Errorf("warning logged = %t, want %t: %s", got, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
+
+func TestResolveMergeActor_MergeQueue(t *testing.T) {
+	queueBot := &github.User{Login: github.Ptr("github-merge-queue[bot]"), Type: github.Ptr("Bot")}
+
+	tests := []struct {
+		name        string
+		autoMerge   *github.PullRequestAutoMerge
+		timeline    string
+		status      int
+		want        string
+		wantWarning bool
+	}{
+		{
+			name:      "auto-merge enabled_by",
+			autoMerge: &github.PullRequestAutoMerge{EnabledBy: &github.User{Login: github.Ptr("alice")}},
+			timeline:  `[{"event":"added_to_merge_queue","actor":{"login":"bob"}}]`,
+			status:    http.StatusOK,
+			want:      "alice",
+		},
+		{
+			name:     "timeline fallback uses the latest actor",
+			timeline: `[{"event":"added_to_merge_queue","actor":{"login":"bob"}},{"event":"added_to_merge_queue","actor":{"login":"carol"}}]`,
+			status:   http.StatusOK,
+			want:     "carol",
+		},
+		{
+			name:     "bot only",
+			timeline: `[{"event":"merged","actor":{"login":"github-merge-queue[bot]"}}]`,
+			status:   http.StatusOK,
+			want:     "github-merge-queue[bot]",
+		},
+		{
+			name:        "timeline cannot be read",
+			timeline:    `{"message":"boom"}`,
+			status:      http.StatusInternalServerError,
+			want:        "github-merge-queue[bot]",
+			wantWarning: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /repos/acme/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.timeline)
+			})
+			c := newTestClient(t, mux)
+			var logs strings.Builder
+			c.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+			pr := &github.PullRequest{
+				Number:    github.Ptr(7),
+				Merged:    github.Ptr(true),
+				MergedBy:  queueBot,
+				AutoMerge: tt.autoMerge,
+			}
+			result := &PRComplianceResult{PR: pr}
+			c.resolveMergeActor(context.Background(), "acme", "repo", pr, result)
+
+			if !result.MergeQueue {
+				t.Error("expected a merge queue merge")
+			}
+			if result.MergedBy != tt.want {
+				t.Errorf("MergedBy = %q, want %q", result.MergedBy, tt.want)
+			}
+			if got := strings.Contains(logs.String(), "merge queue"); got != tt.wantWarning {
+				t.Errorf("warning logged = %t, want %t: %s", got, tt.wantWarning, logs.String())
+			}
+		})
+	}
+}
+
+func TestOrgRulesetTargets(t *testing.T) {
+	repo := &github.Repository{ID: github.Ptr(int64(42)), Name: github.Ptr("API"), DefaultBranch: github.Ptr("trunk")}
+	ruleset := func(conditions *github.RepositoryRulesetConditions) *github.RepositoryRuleset {
+		return &github.RepositoryRuleset{Conditions: conditions}
+	}
+	defaultBranch := &github.RepositoryRulesetRefConditionParameters{Include: []string{"~DEFAULT_BRANCH"}}
+
+	tests := []struct {
+		name    string
+		ruleset *github.RepositoryRuleset
+		branch  string
+		want    bool
+	}{
+		{
+			name: "all repos on default branch",
+			ruleset: ruleset(&github.RepositoryRulesetConditions{
+				RefName:        defaultBranch,
+				RepositoryName: &github.RepositoryRulesetRepositoryNamesConditionParameters{Include: []string{"~ALL"}},
+			}),
+			branch: "trunk",
+			want:   true,
+		},
+		{
+			name: "other branch",
+			ruleset: ruleset(&github.RepositoryRulesetConditions{
+				RefName:        defaultBranch,
+				RepositoryName: &github.RepositoryRulesetRepositoryNamesConditionParameters{Include: []string{"~ALL"}},
+			}),
+			branch: "main",
+		},
+		{
+			name: "excluded repo name",
+			ruleset: ruleset(&github.RepositoryRulesetConditions{
+				RefName:        defaultBranch,
+				RepositoryName: &github.RepositoryRulesetRepositoryNamesConditionParameters{Include: []string{"*"}, Exclude: []string{"api"}},
+			}),
+			branch: "trunk",
+		},
+		{
+			name: "repo id",
+			ruleset: ruleset(&github.RepositoryRulesetConditions{
+				RefName:      &github.RepositoryRulesetRefConditionParameters{Include: []string{"refs/heads/release/*"}},
+				RepositoryID: &github.RepositoryRulesetRepositoryIDsConditionParameters{RepositoryIDs: []int64{7, 42}},
+			}),
+			branch: "release/1.0",
+			want:   true,
+		},
+		{
+			name: "custom property is skipped",
+			ruleset: ruleset(&github.RepositoryRulesetConditions{
+				RefName:            defaultBranch,
+				RepositoryProperty: &github.RepositoryRulesetRepositoryPropertyConditionParameters{},
+			}),
+			branch: "trunk",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orgRulesetTargets(tt.ruleset, repo, tt.branch); got != tt.want {
+				t.Errorf("expected %t, got %t", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFetchBranchRules_OrgRulesets(t *testing.T) {
+	newMux := func(orgStatus int) *http.ServeMux {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/acme/api/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+			http.NotFound(w, r)
+		})
+		mux.HandleFunc("GET /repos/acme/api/rules/branches/main", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"type":"pull_request","ruleset_source_type":"Organization","ruleset_source":"acme","ruleset_id":1,
+				"parameters":{"required_approving_review_count":1,"dismiss_stale_reviews_on_push":false,"require_code_owner_review":false,"require_last_push_approval":false,"required_review_thread_resolution":false}}]`)
+		})
+		mux.HandleFunc("GET /orgs/acme/rulesets", func(w http.ResponseWriter, r *http.Request) {
+			if orgStatus != http.StatusOK {
+				w.WriteHeader(orgStatus)
+				fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `[{"id":1,"name":"reviews","target":"branch","enforcement":"active"},{"id":2,"name":"checks","target":"branch","enforcement":"active"}]`)
+		})
+		mux.HandleFunc("GET /orgs/acme/rulesets/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":%s,"name":"org","target":"branch","enforcement":"active",
+				"conditions":{"ref_name":{"include":["~DEFAULT_BRANCH"],"exclude":[]},"repository_name":{"include":["~ALL"],"exclude":[]}},
+				"rules":[{"type":"pull_request","parameters":{"required_approving_review_count":3,"dismiss_stale_reviews_on_push":false,"require_code_owner_review":false,"require_last_push_approval":false,"required_review_thread_resolution":false}},
+					{"type":"required_status_checks","parameters":{"required_status_checks":[{"context":"ci/build"}],"strict_required_status_checks_policy":false}}]}`, r.PathValue("id"))
+		})
+		return mux
+	}
+	repo := &github.Repository{ID: github.Ptr(int64(42)), Name: github.Ptr("api"), DefaultBranch: github.Ptr("main")}
+
+	c := newTestClient(t, newMux(http.StatusOK))
+	_, rules := c.fetchBranchRules(context.Background(), "acme", "api", "main", repo)
+	if rules == nil || len(rules.PullRequest) != 2 || len(rules.RequiredStatusChecks) != 2 {
+		t.Fatalf("expected org ruleset 2 merged alongside repository rules, got %+v", rules)
+	}
+	if rules.PullRequest[1].RulesetID != 2 || rules.PullRequest[1].Parameters.RequiredApprovingReviewCount != 3 {
+		t.Errorf("unexpected merged pull request rule: %+v", rules.PullRequest[1])
+	}
+
+	c = newTestClient(t, newMux(http.StatusForbidden))
+	_, rules = c.fetchBranchRules(context.Background(), "acme", "api", "main", repo)
+	if rules == nil || len(rules.PullRequest) != 1 || len(rules.RequiredStatusChecks) != 0 {
+		t.Errorf("expected repository rules only when org rulesets are forbidden, got %+v", rules)
+	}
+}
+
+func TestCreateComplianceCheckRun(t *testing.T) {
+	result := &PRComplianceResult{
+		PR: &github.PullRequest{
+			Number:         github.Ptr(7),
+			MergeCommitSHA: github.Ptr("abc123"),
+			MergedBy:       &github.User{Login: github.Ptr("alice")},
+		},
+		BaseBranch: "main",
+		Violations: []ComplianceViolation{{Type: types.ViolationInsufficientReviews, Description: "required 2 approvals, had 0"}},
+	}
+
+	var got github.CreateCheckRunOptions
+	newMux := func(status int) *http.ServeMux {
+		mux := http.NewServeMux()
+		mux.HandleFunc("POST /repos/acme/repo/check-runs", func(w http.ResponseWriter, r *http.Request) {
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Errorf("failed to decode request: %v", err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			if status != http.StatusCreated {
+				fmt.Fprint(w, `{"message":"Resource not accessible by integration"}`)
+				return
+			}
+			fmt.Fprint(w, `{"id":1}`)
+		})
+		return mux
+	}
+
+	c := newTestClient(t, newMux(http.StatusCreated))
+	if err := c.CreateComplianceCheckRun(context.Background(), "acme", "repo", result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.HeadSHA != "abc123" || got.GetConclusion() != "failure" || got.GetStatus() != "completed" {
+		t.Errorf("unexpected check run: %+v", got)
+	}
+	if summary := got.Output.GetSummary(); !strings.Contains(summary, "@alice") || !strings.Contains(summary, "required 2 approvals, had 0") {
+		t.Errorf("unexpected summary: %q", summary)
+	}
+
+	c = newTestClient(t, newMux(http.StatusForbidden))
+	err := c.CreateComplianceCheckRun(context.Background(), "acme", "repo", result)
+	if err == nil || !strings.Contains(err.Error(), "checks:write") {
+		t.Errorf("expected missing permission error, got %v", err)
+	}
+
+	if err := c.CreateComplianceCheckRun(context.Background(), "acme", "repo", &PRComplianceResult{PR: &github.PullRequest{}}); err == nil {
+		t.Error("expected error without a merge commit")
+	}
+}
+
+func TestCheckUserBypassPermission(t *testing.T) {
+	levels := map[string]string{
+		"owner":      `{"permission":"admin","role_name":"admin"}`,
+		"maintainer": `{"permission":"write","role_name":"maintain"}`,
+		"legacy":     `{"permission":"admin"}`,
+		"writer":     `{"permission":"write","role_name":"write"}`,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/collaborators/{user}/permission", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, levels[r.PathValue("user")])
+	})
+	c := newTestClient(t, mux)
+
+	tests := []struct {
+		name        string
+		user        string
+		permissions []string
+		wantReason  string
+	}{
+		{name: "admin by default", user: "owner", wantReason: "repository admin"},
+		{name: "maintainer by default", user: "maintainer", wantReason: "repository maintainer"},
+		{name: "legacy permission", user: "legacy", wantReason: "repository admin"},
+		{name: "writer not by default", user: "writer"},
+		{name: "maintainer excluded", user: "maintainer", permissions: []string{"admin"}},
+		{name: "writer included", user: "writer", permissions: []string{"admin", "write"}, wantReason: "repository write role"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &PRComplianceResult{MergedBy: tt.user}
+			c.checkUserBypassPermission(context.Background(), "acme", "repo", tt.permissions, result)
+			if result.UserHasBypass != (tt.wantReason != "") || result.UserBypassReason != tt.wantReason {
+				t.Errorf("expected bypass reason %q, got %v %q", tt.wantReason, result.UserHasBypass, result.UserBypassReason)
+			}
+		})
+	}
+}
+
+func TestResolveMergeActor_NoMergedBy(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/commits/{sha}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.PathValue("sha") {
+		case "known":
+			fmt.Fprint(w, `{"sha":"known","author":{"login":"alice"}}`)
+		case "missing":
+			http.NotFound(w, r)
+		default:
+			fmt.Fprint(w, `{"sha":"ghost","author":null}`)
+		}
+	})
+	c := newTestClient(t, mux)
+	violations := []ComplianceViolation{{Type: types.ViolationInsufficientReviews}}
+
+	t.Run("falls back to the merge commit author", func(t *testing.T) {
+		result := &PRComplianceResult{Violations: violations}
+		pr := &github.PullRequest{Merged: github.Ptr(true), MergeCommitSHA: github.Ptr("known")}
+		c.resolveMergeActor(context.Background(), "acme", "repo", pr, result)
+		if result.MergedBy != "alice" || !result.MergedByCommitAuthor || result.MergedByUnknown {
+			t.Errorf("expected merge commit author alice, got %+v", result)
+		}
+		if !result.WasBypassed() {
+			t.Error("expected violations attributed to the merge commit author to count as a bypass")
+		}
+	})
+
+	t.Run("records an unknown actor", func(t *testing.T) {
+		result := &PRComplianceResult{Violations: violations}
+		pr := &github.PullRequest{Merged: github.Ptr(true), MergeCommitSHA: github.Ptr("ghost")}
+		c.resolveMergeActor(context.Background(), "acme", "repo", pr, result)
+		if !result.MergedByUnknown || result.UserHasBypass || result.UserBypassReason != "merged by unknown actor" {
+			t.Errorf("expected unknown actor, got %+v", result)
+		}
+		if !result.WasBypassed() {
+			t.Error("expected violations by an unknown actor to count as a bypass")
+		}
+		if summary := formatBypassSummary(result); !strings.Contains(summary, "unknown actor") {
+			t.Errorf("expected unknown actor in summary, got %q", summary)
+		}
+	})
+
+	t.Run("treats an unreadable merge commit as an unknown actor", func(t *testing.T) {
+		result := &PRComplianceResult{Violations: violations}
+		pr := &github.PullRequest{Merged: github.Ptr(true), MergeCommitSHA: github.Ptr("missing")}
+		c.resolveMergeActor(context.Background(), "acme", "repo", pr, result)
+		if !result.MergedByUnknown || result.MergedByCommitAuthor {
+			t.Errorf("expected unknown actor, got %+v", result)
+		}
+	})
+
+	t.Run("ignores unmerged prs", func(t *testing.T) {
+		result := &PRComplianceResult{}
+		c.resolveMergeActor(context.Background(), "acme", "repo", &github.PullRequest{}, result)
+		if result.MergedByUnknown || result.MergedBy != "" {
+			t.Errorf("expected no merge actor, got %+v", result)
+		}
+	})
+}
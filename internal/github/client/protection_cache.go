@@ -0,0 +1,247 @@
+package client
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/google/go-github/v79/github"
+)
+
+// ProtectionCache holds branch protection and ruleset results per
+// repository and branch for a short TTL, so bursts of compliance checks
+// against the same branch reuse one lookup. safe for concurrent use and may
+// be shared by clients for different installations. a nil cache disables
+// caching.
+type ProtectionCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu            sync.Mutex
+	entries       map[string]cachedProtection
+	refConditions map[string]cachedRefConditions
+	orgRulesets   map[string]cachedOrgRulesets
+}
+
+// cachedProtection holds the branch protection lookup results for one
+// branch and when they were fetched.
+type cachedProtection struct {
+	protection  *github.Protection
+	branchRules *github.BranchRules
+	fetchedAt   time.Time
+}
+
+// cachedRefConditions holds the ruleset ref_name conditions of one
+// repository and when they were fetched.
+type cachedRefConditions struct {
+	conditions []types.RefNameCondition
+	fetchedAt  time.Time
+}
+
+// cachedOrgRulesets holds the active branch rulesets of one org and when
+// they were fetched.
+type cachedOrgRulesets struct {
+	rulesets  []*github.RepositoryRuleset
+	fetchedAt time.Time
+}
+
+// NewProtectionCache creates a cache whose entries expire after ttl.
+// returns nil, which disables caching, if ttl is not positive.
+func NewProtectionCache(ttl time.Duration) *ProtectionCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &ProtectionCache{
+		ttl:           ttl,
+		now:           time.Now,
+		entries:       make(map[string]cachedProtection),
+		refConditions: make(map[string]cachedRefConditions),
+		orgRulesets:   make(map[string]cachedOrgRulesets),
+	}
+}
+
+// protectionCacheKey builds the cache key for a branch. owner and repo are
+// case-insensitive on GitHub; branch names are not.
+func protectionCacheKey(owner, repo, branch string) string {
+	return strings.ToLower(owner+"/"+repo) + "@" + branch
+}
+
+// get returns the cached results for a branch if present and not expired.
+func (p *ProtectionCache) get(key string) (cachedProtection, bool) {
+	if p == nil {
+		return cachedProtection{}, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[key]
+	if !ok || p.now().Sub(entry.fetchedAt) >= p.ttl {
+		return cachedProtection{}, false
+	}
+	return entry, true
+}
+
+// put stores results for a branch and drops expired entries so the cache
+// does not grow across long-running processes.
+func (p *ProtectionCache) put(key string, entry cachedProtection) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, e := range p.entries {
+		if p.now().Sub(e.fetchedAt) >= p.ttl {
+			delete(p.entries, k)
+		}
+	}
+	entry.fetchedAt = p.now()
+	p.entries[key] = entry
+}
+
+// getRefConditions returns the cached ruleset ref_name conditions for a
+// repository if present and not expired.
+func (p *ProtectionCache) getRefConditions(key string) ([]types.RefNameCondition, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.refConditions[key]
+	if !ok || p.now().Sub(entry.fetchedAt) >= p.ttl {
+		return nil, false
+	}
+	return entry.conditions, true
+}
+
+// putRefConditions stores the ruleset ref_name conditions for a repository
+// and drops expired entries.
+func (p *ProtectionCache) putRefConditions(key string, conditions []types.RefNameCondition) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, e := range p.refConditions {
+		if p.now().Sub(e.fetchedAt) >= p.ttl {
+			delete(p.refConditions, k)
+		}
+	}
+	p.refConditions[key] = cachedRefConditions{conditions: conditions, fetchedAt: p.now()}
+}
+
+// getOrgRulesets returns the cached branch rulesets of an org if present
+// and not expired.
+func (p *ProtectionCache) getOrgRulesets(key string) ([]*github.RepositoryRuleset, bool) {
+	if p == nil {
+		return nil, false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.orgRulesets[key]
+	if !ok || p.now().Sub(entry.fetchedAt) >= p.ttl {
+		return nil, false
+	}
+	return entry.rulesets, true
+}
+
+// putOrgRulesets stores the branch rulesets of an org and drops expired
+// entries.
+func (p *ProtectionCache) putOrgRulesets(key string, rulesets []*github.RepositoryRuleset) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for k, e := range p.orgRulesets {
+		if p.now().Sub(e.fetchedAt) >= p.ttl {
+			delete(p.orgRulesets, k)
+		}
+	}
+	p.orgRulesets[key] = cachedOrgRulesets{rulesets: rulesets, fetchedAt: p.now()}
+}
+
+// Clear drops all cached entries.
+func (p *ProtectionCache) Clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clear(p.entries)
+	clear(p.refConditions)
+	clear(p.orgRulesets)
+}
+
+// SetProtectionCache sets the cache used for branch protection and ruleset
+// lookups during compliance checks. nil disables caching.
+func (c *Client) SetProtectionCache(cache *ProtectionCache) {
+	c.protectionCache = cache
+}
+
+// fetchBranchRules returns the legacy branch protection and the repository
+// and org ruleset rules for a branch, either of which may be nil. results
+// are cached only when the lookups succeed or report the branch as
+// unprotected, so transient API errors are retried on the next check. org
+// rulesets are matched against baseRepo; those the app cannot read are
+// logged and skipped.
+func (c *Client) fetchBranchRules(ctx context.Context, owner, repo, branch string, baseRepo *github.Repository) (*github.Protection, *github.BranchRules) {
+	key := protectionCacheKey(owner, repo, branch)
+	if entry, ok := c.protectionCache.get(key); ok {
+		return entry.protection, entry.branchRules
+	}
+
+	cacheable := true
+
+	protection, _, err := c.client.Repositories.GetBranchProtection(ctx, owner, repo, branch)
+	if err != nil {
+		protection = nil
+		if !isNotFound(err) && !errors.Is(err, github.ErrBranchNotProtected) {
+			cacheable = false
+		}
+	}
+
+	branchRules, _, err := c.client.Repositories.GetRulesForBranch(ctx, owner, repo, branch, nil)
+	if err != nil {
+		branchRules = nil
+		if !isNotFound(err) {
+			cacheable = false
+		}
+	}
+
+	orgRules, err := c.getOrgBranchRules(ctx, owner, baseRepo, branch)
+	if err != nil {
+		// personal accounts have no org rulesets, and reading them needs
+		// org administration access the app may not have
+		var ghErr *github.ErrorResponse
+		permanent := errors.As(err, &ghErr) && ghErr.Response != nil &&
+			(ghErr.Response.StatusCode == 403 || ghErr.Response.StatusCode == 404)
+		if !permanent {
+			cacheable = false
+		}
+		if !isNotFound(err) {
+			c.logger.Warn("failed to read org rulesets, checking repository rules only",
+				slog.String("repo", owner+"/"+repo),
+				slog.String("error", err.Error()))
+		}
+	} else {
+		branchRules = mergeOrgBranchRules(branchRules, orgRules)
+	}
+
+	if cacheable {
+		c.protectionCache.put(key, cachedProtection{
+			protection:  protection,
+			branchRules: branchRules,
+		})
+	}
+
+	return protection, branchRules
+}
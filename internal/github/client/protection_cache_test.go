@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func TestProtectionCache(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	cache := NewProtectionCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	key := protectionCacheKey("Acme", "API", "main")
+	if key != protectionCacheKey("acme", "api", "main") {
+		t.Error("expected owner and repo to be case-insensitive")
+	}
+	if key == protectionCacheKey("acme", "api", "Main") {
+		t.Error("expected branch names to be case-sensitive")
+	}
+
+	if _, ok := cache.get(key); ok {
+		t.Fatal("expected miss on an empty cache")
+	}
+
+	protection := &github.Protection{}
+	cache.put(key, cachedProtection{protection: protection})
+	if entry, ok := cache.get(key); !ok || entry.protection != protection {
+		t.Fatalf("expected hit with the stored protection, got %+v ok=%t", entry, ok)
+	}
+
+	now = now.Add(59 * time.Second)
+	if _, ok := cache.get(key); !ok {
+		t.Error("expected hit before the ttl")
+	}
+	now = now.Add(time.Second)
+	if _, ok := cache.get(key); ok {
+		t.Error("expected miss once the ttl has passed")
+	}
+
+	cache.put(key, cachedProtection{protection: protection})
+	cache.putRefConditions("acme/api", nil)
+	cache.putOrgRulesets("acme", nil)
+	cache.Clear()
+	if _, ok := cache.get(key); ok {
+		t.Error("expected Clear to drop protection entries")
+	}
+	if _, ok := cache.getRefConditions("acme/api"); ok {
+		t.Error("expected Clear to drop ruleset conditions")
+	}
+	if _, ok := cache.getOrgRulesets("acme"); ok {
+		t.Error("expected Clear to drop org rulesets")
+	}
+
+	// a nil cache disables caching
+	var disabled *ProtectionCache
+	disabled.put(key, cachedProtection{protection: protection})
+	if _, ok := disabled.get(key); ok {
+		t.Error("expected nil cache to always miss")
+	}
+	if NewProtectionCache(0) != nil {
+		t.Error("expected non-positive ttl to disable caching")
+	}
+}
+
+func TestFetchBranchRules_Cached(t *testing.T) {
+	var protectionGets atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/api/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		protectionGets.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"required_pull_request_reviews":{"required_approving_review_count":2}}`)
+	})
+	mux.HandleFunc("GET /repos/acme/api/rules/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("GET /orgs/acme/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	})
+	c := newTestClient(t, mux)
+	now := time.Now()
+	cache := NewProtectionCache(time.Minute)
+	cache.now = func() time.Time { return now }
+	c.SetProtectionCache(cache)
+
+	repo := &github.Repository{Name: github.Ptr("api"), DefaultBranch: github.Ptr("main")}
+	for range 2 {
+		protection, _ := c.fetchBranchRules(context.Background(), "acme", "api", "main", repo)
+		if protection.GetRequiredPullRequestReviews().RequiredApprovingReviewCount != 2 {
+			t.Fatalf("unexpected protection: %+v", protection)
+		}
+	}
+	if got := protectionGets.Load(); got != 1 {
+		t.Errorf("expected one protection lookup while cached, got %d", got)
+	}
+
+	now = now.Add(time.Minute)
+	c.fetchBranchRules(context.Background(), "acme", "api", "main", repo)
+	if got := protectionGets.Load(); got != 2 {
+		t.Errorf("expected a new lookup after expiry, got %d", got)
+	}
+}
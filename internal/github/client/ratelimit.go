@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/cockroachdb/errors"
+
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiter holds the limiter shared by all requests made by a client.
+type rateLimiter struct {
+	limiter atomic.Pointer[rate.Limiter]
+	// now returns the current time. nil uses time.Now; tests replace it.
+	now func() time.Time
+}
+
+// set replaces the limiter. a non-positive rps disables limiting.
+func (r *rateLimiter) set(rps float64) {
+	if rps <= 0 {
+		r.limiter.Store(nil)
+		return
+	}
+	burst := max(int(rps), 1)
+	r.limiter.Store(rate.NewLimiter(rate.Limit(rps), burst))
+}
+
+// wait blocks until a request may be sent or ctx is done. fails without
+// waiting if ctx expires before the request would be allowed.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	l := r.limiter.Load()
+	if l == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	reservation := l.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		reservation.CancelAt(now)
+		return errors.Newf("rate limit wait of %s exceeds context deadline", delay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		reservation.Cancel()
+		return ctx.Err()
+	}
+}
+
+// rateLimitedTransport delays requests to stay within the client's limit
+// and, if quota is set, records the GitHub rate limit from responses.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rateLimiter
+	quota   *types.QuotaTracker
+}
+
+// RoundTrip waits for the limiter before sending the request.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && t.quota != nil {
+		t.quota.Observe("github", "X-RateLimit-", resp.Header)
+	}
+	return resp, err
+}
+
+// SetMaxRPS limits GitHub API requests made by the client to rps per second,
+// allowing bursts of up to rps requests. a non-positive rps disables
+// limiting.
+func (c *Client) SetMaxRPS(rps float64) {
+	c.limiter.set(rps)
+}
+
+// APIQuota returns the GitHub rate limit from the most recent installation
+// API response, if any. app-level calls authenticated with a JWT have a
+// separate quota and are not tracked.
+func (c *Client) APIQuota() (types.APIQuota, bool) {
+	return c.quota.Latest()
+}
+
+// newHTTPClient creates an HTTP client that authenticates with the token
+// from src on every request and applies the client's rate limit. responses
+// update quota if it is not nil.
+func (c *Client) newHTTPClient(src oauth2.TokenSource, quota *types.QuotaTracker) *http.Client {
+	return &http.Client{
+		Transport: &oauth2.Transport{
+			Base:   &rateLimitedTransport{base: http.DefaultTransport, limiter: &c.limiter, quota: quota},
+			Source: src,
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	r := &rateLimiter{now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	// a short deadline makes waits that would block fail immediately
+	expiring := func() context.Context {
+		ctx, cancel := context.WithTimeout(ctx, time.Millisecond)
+		t.Cleanup(cancel)
+		return ctx
+	}
+
+	if err := r.wait(expiring()); err != nil {
+		t.Fatalf("expected unset limiter not to block, got %v", err)
+	}
+
+	r.set(2)
+	for i := range 2 {
+		if err := r.wait(expiring()); err != nil {
+			t.Fatalf("request %d: expected burst to be allowed, got %v", i, err)
+		}
+	}
+	if err := r.wait(expiring()); err == nil {
+		t.Fatal("expected request beyond the burst to wait")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if err := r.wait(expiring()); err != nil {
+		t.Fatalf("expected a token after 500ms at 2 rps, got %v", err)
+	}
+	if err := r.wait(expiring()); err == nil {
+		t.Fatal("expected the refilled token to be used up")
+	}
+
+	r.set(0)
+	for range 5 {
+		if err := r.wait(expiring()); err != nil {
+			t.Fatalf("expected disabled limiter not to block, got %v", err)
+		}
+	}
+
+	// fractional rates still allow one request at a time
+	r.set(0.5)
+	if err := r.wait(expiring()); err != nil {
+		t.Fatalf("expected first request to be allowed, got %v", err)
+	}
+	now = now.Add(time.Second)
+	if err := r.wait(expiring()); err == nil {
+		t.Error("expected request after 1s at 0.5 rps to wait")
+	}
+	now = now.Add(time.Second)
+	if err := r.wait(expiring()); err != nil {
+		t.Errorf("expected request after 2s at 0.5 rps to be allowed, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package client
+
+import (
+	"context"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/google/go-github/v79/github"
+)
+
+// ListOrgRepos returns all repositories in the organization.
+func (c *Client) ListOrgRepos(ctx context.Context) ([]*github.Repository, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	opts := &github.RepositoryListByOrgOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var all []*github.Repository
+	for {
+		repos, resp, err := c.client.Repositories.ListByOrg(ctx, c.org, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list repositories for org '%s'", c.org)
+		}
+		all = append(all, repos...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return all, nil
+}
+
+// HasBranchProtection returns true if the branch is protected by legacy
+// branch protection or has pull request rules from a repository ruleset.
+func (c *Client) HasBranchProtection(ctx context.Context, owner, repo, branch string) (bool, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return false, err
+	}
+
+	_, _, err := c.client.Repositories.GetBranchProtection(ctx, owner, repo, branch)
+	if err == nil {
+		return true, nil
+	}
+	if !isNotFound(err) && !errors.Is(err, github.ErrBranchNotProtected) {
+		return false, errors.Wrapf(err, "failed to fetch branch protection for %s/%s@%s", owner, repo, branch)
+	}
+
+	rules, _, err := c.client.Repositories.GetRulesForBranch(ctx, owner, repo, branch, nil)
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to fetch rules for %s/%s@%s", owner, repo, branch)
+	}
+
+	return rules != nil && len(rules.PullRequest) > 0, nil
+}
+
+// GetRulesetRefConditions returns the ref_name conditions of the active
+// branch rulesets that apply to the repository, including rulesets inherited
+// from the organization. ~DEFAULT_BRANCH is resolved to defaultBranch.
+// results are reused from the protection cache when one is set.
+func (c *Client) GetRulesetRefConditions(ctx context.Context, owner, repo, defaultBranch string) ([]types.RefNameCondition, error) {
+	key := protectionCacheKey(owner, repo, defaultBranch)
+	if conditions, ok := c.protectionCache.getRefConditions(key); ok {
+		return conditions, nil
+	}
+
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	opts := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(true),
+		ListOptions:     github.ListOptions{PerPage: 100},
+	}
+
+	var conditions []types.RefNameCondition
+	for {
+		rulesets, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.RepositoryRuleset, *github.Response, error) {
+			return c.client.Repositories.GetAllRulesets(ctx, owner, repo, opts)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list rulesets for %s/%s", owner, repo)
+		}
+
+		for _, summary := range rulesets {
+			if target := summary.GetTarget(); target != nil && *target != github.RulesetTargetBranch {
+				continue
+			}
+			if summary.Enforcement != github.RulesetEnforcementActive {
+				continue
+			}
+
+			// the list endpoint omits conditions
+			ruleset, _, err := withRateLimitRetry(ctx, c, func() (*github.RepositoryRuleset, *github.Response, error) {
+				return c.client.Repositories.GetRuleset(ctx, owner, repo, summary.GetID(), true)
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch ruleset %d for %s/%s", summary.GetID(), owner, repo)
+			}
+			if ruleset.Conditions == nil || ruleset.Conditions.RefName == nil {
+				continue
+			}
+
+			refName := ruleset.Conditions.RefName
+			conditions = append(conditions, types.RefNameCondition{
+				Include: resolveDefaultBranch(refName.Include, defaultBranch),
+				Exclude: resolveDefaultBranch(refName.Exclude, defaultBranch),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	c.protectionCache.putRefConditions(key, conditions)
+	return conditions, nil
+}
+
+// getOrgBranchRules returns the pull request and required status check rules
+// of the owner's active org rulesets that target the repository and branch.
+// the repository rules endpoint does not always reflect org rulesets, so
+// they are read from the org directly. this needs org administration read
+// access; callers should treat errors as non-fatal.
+func (c *Client) getOrgBranchRules(ctx context.Context, owner string, repo *github.Repository, branch string) (*github.BranchRules, error) {
+	rulesets, err := c.getOrgBranchRulesets(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := &github.BranchRules{}
+	for _, ruleset := range rulesets {
+		if ruleset.Rules == nil || !orgRulesetTargets(ruleset, repo, branch) {
+			continue
+		}
+
+		metadata := github.BranchRuleMetadata{
+			RulesetSourceType: github.RulesetSourceTypeOrganization,
+			RulesetSource:     owner,
+			RulesetID:         ruleset.GetID(),
+		}
+		if params := ruleset.Rules.PullRequest; params != nil {
+			rules.PullRequest = append(rules.PullRequest, &github.PullRequestBranchRule{BranchRuleMetadata: metadata, Parameters: *params})
+		}
+		if params := ruleset.Rules.RequiredStatusChecks; params != nil {
+			rules.RequiredStatusChecks = append(rules.RequiredStatusChecks, &github.RequiredStatusChecksBranchRule{BranchRuleMetadata: metadata, Parameters: *params})
+		}
+	}
+	return rules, nil
+}
+
+// getOrgBranchRulesets returns the owner's active org rulesets that target
+// branches, with their conditions and rules. results are cached per org so
+// compliance checks across repositories reuse one listing.
+func (c *Client) getOrgBranchRulesets(ctx context.Context, owner string) ([]*github.RepositoryRuleset, error) {
+	key := strings.ToLower(owner)
+	if rulesets, ok := c.protectionCache.getOrgRulesets(key); ok {
+		return rulesets, nil
+	}
+
+	var rulesets []*github.RepositoryRuleset
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		summaries, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.RepositoryRuleset, *github.Response, error) {
+			return c.client.Organizations.GetAllRepositoryRulesets(ctx, owner, opts)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list org rulesets for %s", owner)
+		}
+
+		for _, summary := range summaries {
+			if target := summary.GetTarget(); target != nil && *target != github.RulesetTargetBranch {
+				continue
+			}
+			if summary.Enforcement != github.RulesetEnforcementActive {
+				continue
+			}
+
+			// the list endpoint omits conditions and rules
+			ruleset, _, err := withRateLimitRetry(ctx, c, func() (*github.RepositoryRuleset, *github.Response, error) {
+				return c.client.Organizations.GetRepositoryRuleset(ctx, owner, summary.GetID())
+			})
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch org ruleset %d for %s", summary.GetID(), owner)
+			}
+			rulesets = append(rulesets, ruleset)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	c.protectionCache.putOrgRulesets(key, rulesets)
+	return rulesets, nil
+}
+
+// orgRulesetTargets returns true if an org ruleset's repository and ref_name
+// conditions match the repository and branch. rulesets targeting
+// repositories by custom property are skipped since the properties are not
+// fetched.
+func orgRulesetTargets(ruleset *github.RepositoryRuleset, repo *github.Repository, branch string) bool {
+	conditions := ruleset.Conditions
+	if conditions == nil || conditions.RefName == nil || conditions.RepositoryProperty != nil {
+		return false
+	}
+
+	switch {
+	case conditions.RepositoryName != nil:
+		names := conditions.RepositoryName
+		name := strings.ToLower(repo.GetName())
+		if !slices.ContainsFunc(names.Include, func(pattern string) bool { return matchRepoPattern(pattern, name) }) ||
+			slices.ContainsFunc(names.Exclude, func(pattern string) bool { return matchRepoPattern(pattern, name) }) {
+			return false
+		}
+	case conditions.RepositoryID != nil:
+		if !slices.Contains(conditions.RepositoryID.RepositoryIDs, repo.GetID()) {
+			return false
+		}
+	default:
+		return false
+	}
+
+	refName := types.RefNameCondition{
+		Include: resolveDefaultBranch(conditions.RefName.Include, repo.GetDefaultBranch()),
+		Exclude: resolveDefaultBranch(conditions.RefName.Exclude, repo.GetDefaultBranch()),
+	}
+	return refName.Matches(branch)
+}
+
+// matchRepoPattern matches a lowercased repository name against an org
+// ruleset repository_name pattern. ~ALL matches every repository.
+func matchRepoPattern(pattern, name string) bool {
+	if pattern == "~ALL" {
+		return true
+	}
+	matched, _ := path.Match(strings.ToLower(pattern), name)
+	return matched
+}
+
+// mergeOrgBranchRules adds org ruleset rules to the branch rules returned by
+// the repository rules endpoint, skipping rulesets already reflected there.
+func mergeOrgBranchRules(branchRules, orgRules *github.BranchRules) *github.BranchRules {
+	if orgRules == nil || (len(orgRules.PullRequest) == 0 && len(orgRules.RequiredStatusChecks) == 0) {
+		return branchRules
+	}
+	if branchRules == nil {
+		branchRules = &github.BranchRules{}
+	}
+
+	for _, rule := range orgRules.PullRequest {
+		if !slices.ContainsFunc(branchRules.PullRequest, func(r *github.PullRequestBranchRule) bool { return r.RulesetID == rule.RulesetID }) {
+			branchRules.PullRequest = append(branchRules.PullRequest, rule)
+		}
+	}
+	for _, rule := range orgRules.RequiredStatusChecks {
+		if !slices.ContainsFunc(branchRules.RequiredStatusChecks, func(r *github.RequiredStatusChecksBranchRule) bool { return r.RulesetID == rule.RulesetID }) {
+			branchRules.RequiredStatusChecks = append(branchRules.RequiredStatusChecks, rule)
+		}
+	}
+	return branchRules
+}
+
+// resolveDefaultBranch replaces ~DEFAULT_BRANCH in ruleset ref patterns with
+// the repository's default branch. the pattern is dropped when the default
+// branch is unknown.
+func resolveDefaultBranch(patterns []string, defaultBranch string) []string {
+	resolved := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "~DEFAULT_BRANCH" {
+			if defaultBranch == "" {
+				continue
+			}
+			pattern = "refs/heads/" + defaultBranch
+		}
+		resolved = append(resolved, pattern)
+	}
+	return resolved
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func TestGetRulesetRefConditions_Cached(t *testing.T) {
+	var lists atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/acme/repo/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[
+			{"id":1,"name":"protect","target":"branch","enforcement":"active"},
+			{"id":2,"name":"draft","target":"branch","enforcement":"disabled"}
+		]`)
+	})
+	mux.HandleFunc("GET /repos/acme/repo/rulesets/1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"name":"protect","target":"branch","enforcement":"active",
+			"conditions":{"ref_name":{"include":["~DEFAULT_BRANCH","refs/heads/release/*"],"exclude":[]}}}`)
+	})
+	c := newTestClient(t, mux)
+	cache := NewProtectionCache(time.Minute)
+	c.SetProtectionCache(cache)
+
+	for range 2 {
+		conditions, err := c.GetRulesetRefConditions(context.Background(), "acme", "repo", "trunk")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(conditions) != 1 || !slices.Equal(conditions[0].Include, []string{"refs/heads/trunk", "refs/heads/release/*"}) {
+			t.Fatalf("unexpected conditions: %+v", conditions)
+		}
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("expected rulesets listed once, got %d", got)
+	}
+
+	cache.Clear()
+	if _, err := c.GetRulesetRefConditions(context.Background(), "acme", "repo", "trunk"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("expected rulesets listed again after clear, got %d", got)
+	}
+}
+
+func TestGetOrgBranchRules_CachedPerOrg(t *testing.T) {
+	var lists, fetches atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		lists.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":1,"name":"reviews","target":"branch","enforcement":"active"},{"id":2,"name":"tags","target":"tag","enforcement":"active"}]`)
+	})
+	mux.HandleFunc("GET /orgs/acme/rulesets/1", func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":1,"name":"reviews","target":"branch","enforcement":"active",
+			"conditions":{"ref_name":{"include":["~DEFAULT_BRANCH"],"exclude":[]},"repository_name":{"include":["api"],"exclude":[]}},
+			"rules":[{"type":"pull_request","parameters":{"required_approving_review_count":2,"dismiss_stale_reviews_on_push":false,"require_code_owner_review":false,"require_last_push_approval":false,"required_review_thread_resolution":false}}]}`)
+	})
+	c := newTestClient(t, mux)
+	c.SetProtectionCache(NewProtectionCache(time.Minute))
+
+	api := &github.Repository{ID: github.Ptr(int64(1)), Name: github.Ptr("api"), DefaultBranch: github.Ptr("main")}
+	web := &github.Repository{ID: github.Ptr(int64(2)), Name: github.Ptr("web"), DefaultBranch: github.Ptr("main")}
+
+	rules, err := c.getOrgBranchRules(context.Background(), "acme", api, "main")
+	if err != nil || len(rules.PullRequest) != 1 {
+		t.Fatalf("expected one pull request rule for api, got %+v err=%v", rules, err)
+	}
+	rules, err = c.getOrgBranchRules(context.Background(), "ACME", web, "main")
+	if err != nil || len(rules.PullRequest) != 0 {
+		t.Fatalf("expected no rules for web, got %+v err=%v", rules, err)
+	}
+	if lists.Load() != 1 || fetches.Load() != 1 {
+		t.Errorf("expected org rulesets fetched once, got %d lists and %d fetches", lists.Load(), fetches.Load())
+	}
+}
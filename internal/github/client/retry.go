@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v79/github"
+)
+
+// default rate limit retry settings. a sync that trips a limit waits for a
+// short reset instead of failing, but never blocks for longer than a
+// typical scheduled invocation can afford.
+const (
+	DefaultRateLimitMaxRetries = 3
+	DefaultRateLimitMaxBackoff = time.Minute
+)
+
+// rateLimitBaseBackoff is the first backoff used when a secondary rate limit
+// response does not say how long to wait. doubled on each retry.
+const rateLimitBaseBackoff = time.Second
+
+// SetRateLimitRetry configures how calls that hit a GitHub rate limit are
+// retried. each call is retried at most maxRetries times, waiting until the
+// limit resets but no longer than maxBackoff per wait. a maxRetries of zero
+// disables retries.
+func (c *Client) SetRateLimitRetry(maxRetries int, maxBackoff time.Duration) {
+	c.retryMaxRetries = max(maxRetries, 0)
+	c.retryMaxBackoff = maxBackoff
+}
+
+// withRateLimitRetry calls fn, retrying on primary and secondary rate limit
+// errors as configured on c. gives up when retries are exhausted, when the
+// limit resets later than the max backoff allows, or when ctx is done while
+// waiting.
+func withRateLimitRetry[T any](ctx context.Context, c *Client, fn func() (T, *github.Response, error)) (T, *github.Response, error) {
+	for attempt := 0; ; attempt++ {
+		result, resp, err := fn()
+		if err == nil || attempt >= c.retryMaxRetries {
+			return result, resp, err
+		}
+
+		wait, ok := rateLimitWait(err, attempt, c.retryMaxBackoff)
+		if !ok {
+			return result, resp, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, resp, errors.WithSecondaryError(
+				errors.Wrap(ctx.Err(), "canceled while waiting for github rate limit reset"), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying a call that failed
+// with err, or false if err is not a rate limit error or the wait would
+// exceed maxBackoff. primary limits wait until the reported reset and
+// secondary limits honor Retry-After, falling back to exponential backoff
+// capped at maxBackoff.
+func rateLimitWait(err error, attempt int, maxBackoff time.Duration) (time.Duration, bool) {
+	var wait time.Duration
+
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		wait = max(time.Until(rateErr.Rate.Reset.Time), 0)
+	case errors.As(err, &abuseErr):
+		if retryAfter := abuseErr.GetRetryAfter(); retryAfter > 0 {
+			wait = retryAfter
+		} else {
+			wait = min(rateLimitBaseBackoff<<min(attempt, 16), maxBackoff)
+		}
+	default:
+		return 0, false
+	}
+
+	if wait > maxBackoff {
+		return 0, false
+	}
+	return wait, true
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/google/go-github/v79/github"
+)
+
+// writeSecondaryRateLimit responds with a GitHub secondary rate limit error.
+func writeSecondaryRateLimit(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusForbidden)
+	fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit","documentation_url":"https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits"}`)
+}
+
+func TestGetTeamMembers_RetriesSecondaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			writeSecondaryRateLimit(w)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"alice"}]`)
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimitRetry(3, 10*time.Millisecond)
+
+	members, err := c.GetTeamMembers(context.Background(), "eng")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 1 || members[0] != "alice" {
+		t.Errorf("expected [alice], got %v", members)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 calls, got %d", got)
+	}
+}
+
+func TestListOrgMembers_RetriesPrimaryRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/members", func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Limit", "5000")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"}]`)
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimitRetry(1, time.Second)
+
+	members, err := c.ListOrgMembers(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(members) != 2 {
+		t.Errorf("expected 2 members, got %v", members)
+	}
+}
+
+func TestGetTeamMembers_RateLimitRetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeSecondaryRateLimit(w)
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimitRetry(2, time.Millisecond)
+
+	_, err := c.GetTeamMembers(context.Background(), "eng")
+	var abuseErr *github.AbuseRateLimitError
+	if !errors.As(err, &abuseErr) {
+		t.Fatalf("expected secondary rate limit error, got %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("expected 3 calls, got %d", got)
+	}
+}
+
+func TestGetTeamMembers_RateLimitWaitExceedsMaxBackoff(t *testing.T) {
+	var calls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		writeSecondaryRateLimit(w)
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimitRetry(3, time.Minute)
+
+	if _, err := c.GetTeamMembers(context.Background(), "eng"); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected no retry, got %d calls", got)
+	}
+}
+
+func TestGetTeamMembers_RateLimitWaitCanceled(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/eng/members", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		writeSecondaryRateLimit(w)
+	})
+	c := newTestClient(t, mux)
+	c.SetRateLimitRetry(3, time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.GetTeamMembers(ctx, "eng")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected wait to stop on cancellation, took %s", elapsed)
+	}
+}
@@ -3,6 +3,10 @@ package client
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
@@ -15,77 +19,471 @@ type TeamSyncResult struct {
 	MembersAdded           []string
 	MembersRemoved         []string
 	MembersSkippedExternal []string
-	Errors                 []string
+	MembersPreserved       []string
+	// MembersPending are requested adds whose membership is still pending,
+	// usually because the user has not accepted an org invitation. only set
+	// by VerifyTeamMembers.
+	MembersPending []string
+	Errors         []string
+	// SafetyThresholdBlocked is true when removals were skipped because they
+	// exceeded the safety threshold.
+	SafetyThresholdBlocked bool
 }
 
-// GetOrCreateTeam fetches an existing team by slug or creates it if missing.
-func (c *Client) GetOrCreateTeam(ctx context.Context, teamName, privacy string) (*github.Team, error) {
+// teamSlugInvalidChars matches characters that cannot appear in a team slug.
+var teamSlugInvalidChars = regexp.MustCompile(`[^a-z0-9-]`)
+
+// TeamSlug normalizes a team name to the slug GitHub derives from it for
+// simple names: lowercased, with every character other than a letter, digit,
+// or hyphen replaced by a hyphen, e.g. "My Team" becomes "my-team".
+func TeamSlug(name string) string {
+	return teamSlugInvalidChars.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// GetOrCreateTeam fetches an existing team or creates it if missing. the
+// team is looked up by the slug of teamName and then by slug or display name
+// ignoring case, so a team created as "My Team" is found again by either
+// "My Team" or "my-team". a created team is named teamName and the slug
+// GitHub returns is remembered for later lookups, since GitHub may
+// slugify the name differently.
+// if parentSlug is set, a created team is nested under that parent and an
+// existing team with a different parent is moved under it; the parent must
+// already exist. in dry-run mode a missing team is returned without being
+// created and existing teams are not moved.
+func (c *Client) GetOrCreateTeam(ctx context.Context, teamName, privacy, parentSlug string) (*github.Team, error) {
 	if err := c.ensureValidToken(ctx); err != nil {
 		return nil, err
 	}
 
-	team, resp, err := c.client.Teams.GetTeamBySlug(ctx, c.org, teamName)
+	slug := TeamSlug(teamName)
+	team, resp, err := c.client.Teams.GetTeamBySlug(ctx, c.org, c.canonicalTeamSlug(slug))
 	if err == nil {
-		return team, nil
+		return c.reparentTeam(ctx, team, parentSlug)
 	}
 
 	if resp != nil && resp.StatusCode == 404 {
+		team, err := c.findTeamByName(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+		if team != nil {
+			return c.reparentTeam(ctx, team, parentSlug)
+		}
+
+		var parentID *int64
+		if parentSlug != "" {
+			parent, err := c.getParentTeam(ctx, parentSlug)
+			if err != nil {
+				return nil, err
+			}
+			parentID = parent.ID
+		}
+
+		if c.isDryRun(ctx) {
+			return &github.Team{Name: &teamName, Slug: &slug}, nil
+		}
 		newTeam := &github.NewTeam{
-			Name:    teamName,
-			Privacy: &privacy,
+			Name:         teamName,
+			Privacy:      &privacy,
+			ParentTeamID: parentID,
 		}
 		team, _, err = c.client.Teams.CreateTeam(ctx, c.org, *newTeam)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to create team '%s' in org '%s'", teamName, c.org)
 		}
+		c.rememberCreatedTeam(team, teamName, slug)
 		return team, nil
 	}
 
 	return nil, errors.Wrapf(internalerrors.ErrTeamNotFound, "failed to fetch team '%s' from org '%s'", teamName, c.org)
 }
 
-// GetTeamMembers returns GitHub usernames of all team members.
-func (c *Client) GetTeamMembers(ctx context.Context, teamSlug string) ([]string, error) {
+// rememberCreatedTeam maps the requested name and slug of a newly created
+// team to the slug GitHub assigned and adds the team to the cached org team
+// list, so lookups before the cache expires do not create it again.
+func (c *Client) rememberCreatedTeam(team *github.Team, teamName, slug string) {
+	if team.GetSlug() == "" {
+		return
+	}
+
+	c.teamCacheMu.Lock()
+	defer c.teamCacheMu.Unlock()
+	if c.teamSlugs == nil {
+		c.teamSlugs = make(map[string]string)
+	}
+	c.teamSlugs[strings.ToLower(teamName)] = team.GetSlug()
+	c.teamSlugs[strings.ToLower(slug)] = team.GetSlug()
+	if c.orgTeams != nil {
+		c.orgTeams = append(slices.Clone(c.orgTeams), team)
+	}
+}
+
+// GetTeamBySlug fetches an existing team by slug without creating or
+// modifying it. a slug that differs in case from the team's actual slug is
+// resolved against the org's teams. returns ErrTeamNotFound if no team has
+// the slug.
+func (c *Client) GetTeamBySlug(ctx context.Context, slug string) (*github.Team, error) {
 	if err := c.ensureValidToken(ctx); err != nil {
 		return nil, err
 	}
 
-	members, _, err := c.client.Teams.ListTeamMembersBySlug(ctx, c.org, teamSlug, nil)
+	team, _, err := withRateLimitRetry(ctx, c, func() (*github.Team, *github.Response, error) {
+		return c.client.Teams.GetTeamBySlug(ctx, c.org, c.canonicalTeamSlug(slug))
+	})
+	if err == nil {
+		return team, nil
+	}
+	if !isNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to fetch team '%s' from org '%s'", slug, c.org)
+	}
+
+	// only slugs bind; a team whose display name matches does not
+	team, err = c.findTeamFold(ctx, slug)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to list members for team '%s'", teamSlug)
+		return nil, err
+	}
+	if team == nil || !strings.EqualFold(team.GetSlug(), slug) {
+		return nil, errors.Wrapf(internalerrors.ErrTeamNotFound, "team with slug '%s' not found in org '%s'", slug, c.org)
 	}
+	return team, nil
+}
 
-	logins := make([]string, 0, len(members))
-	for _, member := range members {
-		if member.Login != nil {
-			logins = append(logins, *member.Login)
+// FindTeam looks a team up the way GetOrCreateTeam does, by the slug of
+// teamName and then by slug or name ignoring case, without creating or
+// modifying it. returns nil if no team matches.
+func (c *Client) FindTeam(ctx context.Context, teamName string) (*github.Team, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	team, _, err := withRateLimitRetry(ctx, c, func() (*github.Team, *github.Response, error) {
+		return c.client.Teams.GetTeamBySlug(ctx, c.org, c.canonicalTeamSlug(TeamSlug(teamName)))
+	})
+	if err == nil {
+		return team, nil
+	}
+	if !isNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to fetch team '%s' from org '%s'", teamName, c.org)
+	}
+	return c.findTeamByName(ctx, teamName)
+}
+
+// findTeamByName finds the org team whose slug or display name matches
+// teamName, or whose slug matches the slug of teamName, ignoring case.
+// returns nil if no team matches.
+func (c *Client) findTeamByName(ctx context.Context, teamName string) (*github.Team, error) {
+	team, err := c.findTeamFold(ctx, teamName)
+	if err != nil || team != nil {
+		return team, err
+	}
+	if slug := TeamSlug(teamName); slug != teamName {
+		return c.findTeamFold(ctx, slug)
+	}
+	return nil, nil
+}
+
+// SetTeamDescription updates the team's description when it differs from
+// description and returns true if it was changed. in dry-run mode the change
+// is reported without being applied.
+func (c *Client) SetTeamDescription(ctx context.Context, team *github.Team, description string) (bool, error) {
+	if team.GetDescription() == description {
+		return false, nil
+	}
+	if c.isDryRun(ctx) {
+		return true, nil
+	}
+	if err := c.ensureValidToken(ctx); err != nil {
+		return false, err
+	}
+
+	_, _, err := withRateLimitRetry(ctx, c, func() (*github.Team, *github.Response, error) {
+		return c.client.Teams.EditTeamBySlug(ctx, c.org, team.GetSlug(), github.NewTeam{
+			Name:        team.GetName(),
+			Description: &description,
+		}, false)
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to update description of team '%s'", team.GetSlug())
+	}
+	return true, nil
+}
+
+// getParentTeam fetches the team a synced team is nested under. a missing
+// parent is an error so the team is never created at the top level instead.
+func (c *Client) getParentTeam(ctx context.Context, parentSlug string) (*github.Team, error) {
+	parent, resp, err := c.client.Teams.GetTeamBySlug(ctx, c.org, parentSlug)
+	if err != nil {
+		if resp != nil && resp.StatusCode == 404 {
+			return nil, errors.Wrapf(internalerrors.ErrTeamNotFound, "parent team '%s' not found in org '%s'", parentSlug, c.org)
 		}
+		return nil, errors.Wrapf(err, "failed to fetch parent team '%s'", parentSlug)
 	}
+	return parent, nil
+}
 
+// reparentTeam moves an existing team under parentSlug when its current
+// parent differs. teams are left as is when parentSlug is empty or in
+// dry-run mode.
+func (c *Client) reparentTeam(ctx context.Context, team *github.Team, parentSlug string) (*github.Team, error) {
+	if parentSlug == "" || strings.EqualFold(team.GetParent().GetSlug(), parentSlug) {
+		return team, nil
+	}
+
+	parent, err := c.getParentTeam(ctx, parentSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.isDryRun(ctx) {
+		return team, nil
+	}
+
+	edited, _, err := c.client.Teams.EditTeamBySlug(ctx, c.org, team.GetSlug(), github.NewTeam{
+		Name:         team.GetName(),
+		ParentTeamID: parent.ID,
+	}, false)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to move team '%s' under parent team '%s'", team.GetSlug(), parentSlug)
+	}
+	return edited, nil
+}
+
+// GetTeamMembers returns GitHub usernames of all team members. a slug that
+// differs in case from the team's actual slug is resolved against the org's
+// teams.
+func (c *Client) GetTeamMembers(ctx context.Context, teamSlug string) ([]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	members, _, err := c.getTeamMembers(ctx, teamSlug)
+	return members, err
+}
+
+// getTeamMembers returns the team's members and its canonical slug. on a
+// 404 the slug is looked up case-insensitively among the org's teams and
+// the call is retried with the canonical slug.
+func (c *Client) getTeamMembers(ctx context.Context, teamSlug string) ([]string, string, error) {
+	slug := c.canonicalTeamSlug(teamSlug)
+	members, err := c.listTeamMembers(ctx, slug)
+	if isNotFound(err) {
+		team, findErr := c.findTeamFold(ctx, teamSlug)
+		if findErr != nil {
+			return nil, slug, findErr
+		}
+		if team != nil {
+			slug = team.GetSlug()
+			members, err = c.listTeamMembers(ctx, slug)
+		}
+	}
+	if err != nil {
+		return nil, slug, errors.Wrapf(err, "failed to list members for team '%s'", teamSlug)
+	}
+	return members, slug, nil
+}
+
+// listTeamMembers lists the logins of all of a team's members by exact slug,
+// following every page.
+func (c *Client) listTeamMembers(ctx context.Context, slug string) ([]string, error) {
+	opts := &github.TeamListTeamMembersOptions{
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	logins := []string{}
+	for {
+		members, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.User, *github.Response, error) {
+			return c.client.Teams.ListTeamMembersBySlug(ctx, c.org, slug, opts)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range members {
+			if member.Login != nil {
+				logins = append(logins, *member.Login)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
 	return logins, nil
 }
 
+// orgTeamsCacheTTL is how long the org team list used for case-insensitive
+// slug lookups remains valid.
+const orgTeamsCacheTTL = 5 * time.Minute
+
+// canonicalTeamSlug returns the canonical slug previously discovered for
+// slug by a case-insensitive lookup, or slug itself.
+func (c *Client) canonicalTeamSlug(slug string) string {
+	c.teamCacheMu.Lock()
+	defer c.teamCacheMu.Unlock()
+	if canonical, ok := c.teamSlugs[strings.ToLower(slug)]; ok {
+		return canonical
+	}
+	return slug
+}
+
+// findTeamFold finds the org team whose slug or name matches slug ignoring
+// case, using the cached org team list. the canonical slug is remembered
+// for later calls. returns nil if no team matches.
+func (c *Client) findTeamFold(ctx context.Context, slug string) (*github.Team, error) {
+	teams, err := c.listOrgTeamsCached(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, team := range teams {
+		if !strings.EqualFold(team.GetSlug(), slug) && !strings.EqualFold(team.GetName(), slug) {
+			continue
+		}
+		c.teamCacheMu.Lock()
+		if c.teamSlugs == nil {
+			c.teamSlugs = make(map[string]string)
+		}
+		c.teamSlugs[strings.ToLower(slug)] = team.GetSlug()
+		c.teamCacheMu.Unlock()
+		return team, nil
+	}
+	return nil, nil
+}
+
+// listOrgTeamsCached returns all org teams, reusing a list fetched within
+// orgTeamsCacheTTL.
+func (c *Client) listOrgTeamsCached(ctx context.Context) ([]*github.Team, error) {
+	c.teamCacheMu.Lock()
+	if c.orgTeams != nil && time.Since(c.orgTeamsFetchedAt) < orgTeamsCacheTTL {
+		teams := c.orgTeams
+		c.teamCacheMu.Unlock()
+		return teams, nil
+	}
+	c.teamCacheMu.Unlock()
+
+	var teams []*github.Team
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.Team, *github.Response, error) {
+			return c.client.Teams.ListTeams(ctx, c.org, opts)
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list teams for org '%s'", c.org)
+		}
+		teams = append(teams, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	c.teamCacheMu.Lock()
+	c.orgTeams = teams
+	c.orgTeamsFetchedAt = time.Now()
+	c.teamCacheMu.Unlock()
+
+	return teams, nil
+}
+
+// teamMembersCacheTTL is how long cached team memberships remain valid.
+const teamMembersCacheTTL = 5 * time.Minute
+
+// cachedTeamMembers holds a team membership snapshot and when it was fetched.
+type cachedTeamMembers struct {
+	members   []string
+	fetchedAt time.Time
+}
+
+// GetTeamMembersCached returns team members, reusing results fetched within
+// the cache TTL. intended for read-only lookups such as compliance checks.
+func (c *Client) GetTeamMembersCached(ctx context.Context, teamSlug string) ([]string, error) {
+	c.teamCacheMu.Lock()
+	cached, ok := c.teamCache[teamSlug]
+	c.teamCacheMu.Unlock()
+	if ok && time.Since(cached.fetchedAt) < teamMembersCacheTTL {
+		return cached.members, nil
+	}
+
+	members, err := c.GetTeamMembers(ctx, teamSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	c.teamCacheMu.Lock()
+	if c.teamCache == nil {
+		c.teamCache = make(map[string]cachedTeamMembers)
+	}
+	c.teamCache[teamSlug] = cachedTeamMembers{members: members, fetchedAt: time.Now()}
+	c.teamCacheMu.Unlock()
+
+	return members, nil
+}
+
+// ClearTeamMembersCache drops all cached team memberships and the cached
+// org team list.
+func (c *Client) ClearTeamMembersCache() {
+	c.teamCacheMu.Lock()
+	defer c.teamCacheMu.Unlock()
+	c.teamCache = nil
+	c.orgTeams = nil
+}
+
 // SyncTeamMembers adds and removes members to match desired state.
 // collects errors for individual operations but continues processing. skips
 // removal of external collaborators (outside org members). applies safety
-// threshold to prevent mass removal during outages.
-func (c *Client) SyncTeamMembers(ctx context.Context, teamSlug string, desiredMembers []string, safetyThreshold float64) (*TeamSyncResult, error) {
-	if err := c.ensureValidToken(ctx); err != nil {
+// threshold to prevent mass removal during outages. members for which
+// isPreserved returns true are never removed. in dry-run mode the result
+// lists planned changes without applying them.
+func (c *Client) SyncTeamMembers(ctx context.Context, teamSlug string, desiredMembers []string, safetyThreshold float64, isPreserved func(login string) bool) (*TeamSyncResult, error) {
+	plan, err := c.PlanTeamMembers(ctx, teamSlug, desiredMembers, safetyThreshold, isPreserved)
+	if err != nil {
 		return nil, err
 	}
+	c.ApplyTeamAdds(ctx, plan)
+	c.ApplyTeamRemovals(ctx, plan)
+	return plan.Result, nil
+}
 
-	result := &TeamSyncResult{
-		TeamName:               teamSlug,
-		MembersAdded:           []string{},
-		MembersRemoved:         []string{},
-		MembersSkippedExternal: []string{},
-		Errors:                 []string{},
+// TeamMemberPlan is the set of membership changes that syncs a team to its
+// desired members. adds and removals are applied separately so callers can
+// order them across teams.
+type TeamMemberPlan struct {
+	TeamSlug string
+	ToAdd    []string
+	// ToRemove is empty when the safety threshold blocked removals.
+	ToRemove []string
+	// Result collects the outcome as the plan is applied.
+	Result *TeamSyncResult
+}
+
+// PlanTeamMembers computes the adds and removals that sync a team to
+// desiredMembers. the safety threshold is checked against the team's
+// current members, so applying adds first never loosens it. members for
+// which isPreserved returns true are never removed.
+func (c *Client) PlanTeamMembers(ctx context.Context, teamSlug string, desiredMembers []string, safetyThreshold float64, isPreserved func(login string) bool) (*TeamMemberPlan, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
 	}
 
-	currentMembers, err := c.GetTeamMembers(ctx, teamSlug)
-	if err != nil {
+	currentMembers, canonicalSlug, err := c.getTeamMembers(ctx, teamSlug)
+	if err != nil && !(c.isDryRun(ctx) && isNotFound(err)) {
 		return nil, errors.Wrapf(err, "failed to fetch current members for team '%s'", teamSlug)
 	}
+	teamSlug = canonicalSlug
+
+	plan := &TeamMemberPlan{
+		TeamSlug: teamSlug,
+		Result: &TeamSyncResult{
+			TeamName:               teamSlug,
+			MembersAdded:           []string{},
+			MembersRemoved:         []string{},
+			MembersSkippedExternal: []string{},
+			MembersPreserved:       []string{},
+			Errors:                 []string{},
+		},
+	}
+	result := plan.Result
 
 	currentSet := make(map[string]bool)
 	for _, member := range currentMembers {
@@ -99,21 +497,20 @@ func (c *Client) SyncTeamMembers(ctx context.Context, teamSlug string, desiredMe
 
 	for _, desired := range desiredMembers {
 		if !currentSet[desired] {
-			_, _, err := c.client.Teams.AddTeamMembershipBySlug(ctx, c.org, teamSlug, desired, nil)
-			if err != nil {
-				errMsg := fmt.Sprintf("failed to add '%s' to team '%s': %v", desired, teamSlug, err)
-				result.Errors = append(result.Errors, errMsg)
-			} else {
-				result.MembersAdded = append(result.MembersAdded, desired)
-			}
+			plan.ToAdd = append(plan.ToAdd, desired)
 		}
 	}
 
 	var toRemove []string
 	for _, current := range currentMembers {
-		if !desiredSet[current] {
-			toRemove = append(toRemove, current)
+		if desiredSet[current] {
+			continue
+		}
+		if isPreserved != nil && isPreserved(current) {
+			result.MembersPreserved = append(result.MembersPreserved, current)
+			continue
 		}
+		toRemove = append(toRemove, current)
 	}
 
 	if len(currentMembers) > 0 {
@@ -122,11 +519,42 @@ func (c *Client) SyncTeamMembers(ctx context.Context, teamSlug string, desiredMe
 			errMsg := fmt.Sprintf("refusing to remove %d of %d members (%.0f%%) as it exceeds safety threshold of %.0f%%",
 				len(toRemove), len(currentMembers), removalRatio*100, safetyThreshold*100)
 			result.Errors = append(result.Errors, errMsg)
-			return result, nil
+			result.SafetyThresholdBlocked = true
+			return plan, nil
 		}
 	}
+	plan.ToRemove = toRemove
 
-	for _, username := range toRemove {
+	return plan, nil
+}
+
+// ApplyTeamAdds adds the planned members to the team, recording each
+// outcome in the plan's result. in dry-run mode adds are only recorded.
+func (c *Client) ApplyTeamAdds(ctx context.Context, plan *TeamMemberPlan) {
+	result := plan.Result
+	for _, desired := range plan.ToAdd {
+		if c.isDryRun(ctx) {
+			result.MembersAdded = append(result.MembersAdded, desired)
+			continue
+		}
+		_, _, err := withRateLimitRetry(ctx, c, func() (*github.Membership, *github.Response, error) {
+			return c.client.Teams.AddTeamMembershipBySlug(ctx, c.org, plan.TeamSlug, desired, nil)
+		})
+		if err != nil {
+			errMsg := fmt.Sprintf("failed to add '%s' to team '%s': %v", desired, plan.TeamSlug, err)
+			result.Errors = append(result.Errors, errMsg)
+		} else {
+			result.MembersAdded = append(result.MembersAdded, desired)
+		}
+	}
+}
+
+// ApplyTeamRemovals removes the planned members from the team, skipping
+// external collaborators, and records each outcome in the plan's result. in
+// dry-run mode removals are only recorded.
+func (c *Client) ApplyTeamRemovals(ctx context.Context, plan *TeamMemberPlan) {
+	result := plan.Result
+	for _, username := range plan.ToRemove {
 		isExternal, err := c.IsExternalCollaborator(ctx, username)
 		if err != nil {
 			errMsg := fmt.Sprintf("failed to check if '%s' is external: %v", username, err)
@@ -139,14 +567,102 @@ func (c *Client) SyncTeamMembers(ctx context.Context, teamSlug string, desiredMe
 			continue
 		}
 
-		_, err = c.client.Teams.RemoveTeamMembershipBySlug(ctx, c.org, teamSlug, username)
+		if c.isDryRun(ctx) {
+			result.MembersRemoved = append(result.MembersRemoved, username)
+			continue
+		}
+
+		_, _, err = withRateLimitRetry(ctx, c, func() (struct{}, *github.Response, error) {
+			resp, err := c.client.Teams.RemoveTeamMembershipBySlug(ctx, c.org, plan.TeamSlug, username)
+			return struct{}{}, resp, err
+		})
 		if err != nil {
-			errMsg := fmt.Sprintf("failed to remove '%s' from team '%s': %v", username, teamSlug, err)
+			errMsg := fmt.Sprintf("failed to remove '%s' from team '%s': %v", username, plan.TeamSlug, err)
 			result.Errors = append(result.Errors, errMsg)
 		} else {
 			result.MembersRemoved = append(result.MembersRemoved, username)
 		}
 	}
+}
+
+// VerifyTeamMembers re-lists the team's members after a sync and moves
+// reported adds that are not active members into MembersPending. GitHub only
+// lists active members, so adds awaiting an invitation are not listed. does
+// nothing in dry-run mode or when no members were added.
+func (c *Client) VerifyTeamMembers(ctx context.Context, result *TeamSyncResult) error {
+	if c.isDryRun(ctx) || len(result.MembersAdded) == 0 {
+		return nil
+	}
+	if err := c.ensureValidToken(ctx); err != nil {
+		return err
+	}
+
+	members, err := c.listTeamMembers(ctx, result.TeamName)
+	if err != nil {
+		return errors.Wrapf(err, "failed to verify members of team '%s'", result.TeamName)
+	}
+
+	active := make(map[string]bool, len(members))
+	for _, member := range members {
+		active[strings.ToLower(member)] = true
+	}
+
+	added := make([]string, 0, len(result.MembersAdded))
+	for _, member := range result.MembersAdded {
+		if active[strings.ToLower(member)] {
+			added = append(added, member)
+		} else {
+			result.MembersPending = append(result.MembersPending, member)
+		}
+	}
+	result.MembersAdded = added
+	return nil
+}
+
+// ListPendingTeamInvitations returns the logins of users invited to the team
+// who have not accepted yet. GitHub does not list them as team members until
+// they accept their org invitation. invitations sent by email without a
+// GitHub account have no login and are skipped. a missing team has no
+// invitations in dry-run mode.
+func (c *Client) ListPendingTeamInvitations(ctx context.Context, teamSlug string) ([]string, error) {
+	if err := c.ensureValidToken(ctx); err != nil {
+		return nil, err
+	}
+
+	slug := c.canonicalTeamSlug(teamSlug)
+	opts := &github.ListOptions{PerPage: 100}
+
+	var logins []string
+	for {
+		invitations, resp, err := withRateLimitRetry(ctx, c, func() ([]*github.Invitation, *github.Response, error) {
+			return c.client.Teams.ListPendingTeamInvitationsBySlug(ctx, c.org, slug, opts)
+		})
+		if err != nil {
+			// a team that dry-run would create has no invitations yet
+			if c.isDryRun(ctx) && isNotFound(err) {
+				return nil, nil
+			}
+			return nil, errors.Wrapf(err, "failed to list pending invitations for team '%s'", teamSlug)
+		}
+
+		for _, invitation := range invitations {
+			if login := invitation.GetLogin(); login != "" {
+				logins = append(logins, login)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return logins, nil
+}
 
-	return result, nil
+// isNotFound reports whether err is a GitHub API 404 response. a team that
+// would be created in dry-run mode does not exist yet.
+func isNotFound(err error) bool {
+	var ghErr *github.ErrorResponse
+	return errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == 404
 }
@@ -0,0 +1,428 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+	"github.com/google/go-github/v79/github"
+)
+
+// teamsMux serves existing teams and records created and edited teams.
+func teamsMux(t *testing.T, teams map[string]string, created, edited *map[string]any) *http.ServeMux {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		body, ok := teams[r.PathValue("slug")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("GET /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+		bodies := make([]string, 0, len(teams))
+		for _, body := range teams {
+			bodies = append(bodies, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, "[%s]", strings.Join(bodies, ","))
+	})
+	mux.HandleFunc("POST /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(created); err != nil {
+			t.Errorf("failed to decode create request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":3,"slug":%q}`, (*created)["name"])
+	})
+	mux.HandleFunc("PATCH /orgs/acme/teams/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(edited); err != nil {
+			t.Errorf("failed to decode edit request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":2,"slug":%q,"parent":{"id":1,"slug":"engineering"}}`, r.PathValue("slug"))
+	})
+	return mux
+}
+
+func TestGetOrCreateTeam_Parent(t *testing.T) {
+	engineering := `{"id":1,"slug":"engineering","name":"engineering"}`
+
+	t.Run("creates team under parent", func(t *testing.T) {
+		var created, edited map[string]any
+		c := newTestClient(t, teamsMux(t, map[string]string{"engineering": engineering}, &created, &edited))
+
+		if _, err := c.GetOrCreateTeam(context.Background(), "engineering-backend", "closed", "engineering"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if created["parent_team_id"] != float64(1) {
+			t.Errorf("expected team created with parent_team_id 1, got %v", created)
+		}
+	})
+
+	t.Run("missing parent fails", func(t *testing.T) {
+		var created, edited map[string]any
+		c := newTestClient(t, teamsMux(t, map[string]string{}, &created, &edited))
+
+		_, err := c.GetOrCreateTeam(context.Background(), "engineering-backend", "closed", "engineering")
+		if !errors.Is(err, internalerrors.ErrTeamNotFound) {
+			t.Errorf("expected parent team not found error, got %v", err)
+		}
+		if created != nil {
+			t.Errorf("expected no team created, got %v", created)
+		}
+	})
+
+	t.Run("moves existing team to configured parent", func(t *testing.T) {
+		var created, edited map[string]any
+		c := newTestClient(t, teamsMux(t, map[string]string{
+			"engineering":         engineering,
+			"engineering-backend": `{"id":2,"slug":"engineering-backend","name":"engineering-backend","parent":{"id":9,"slug":"legacy"}}`,
+		}, &created, &edited))
+
+		team, err := c.GetOrCreateTeam(context.Background(), "engineering-backend", "closed", "engineering")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if edited["parent_team_id"] != float64(1) {
+			t.Errorf("expected team moved to parent_team_id 1, got %v", edited)
+		}
+		if team.GetParent().GetSlug() != "engineering" {
+			t.Errorf("expected edited team returned, got %v", team)
+		}
+	})
+
+	t.Run("leaves team with matching parent", func(t *testing.T) {
+		var created, edited map[string]any
+		c := newTestClient(t, teamsMux(t, map[string]string{
+			"engineering-backend": `{"id":2,"slug":"engineering-backend","parent":{"id":1,"slug":"Engineering"}}`,
+		}, &created, &edited))
+
+		if _, err := c.GetOrCreateTeam(context.Background(), "engineering-backend", "closed", "engineering"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if edited != nil {
+			t.Errorf("expected no edit, got %v", edited)
+		}
+	})
+}
+
+func TestGetOrCreateTeam_CaseInsensitiveSlug(t *testing.T) {
+	var created, edited map[string]any
+	c := newTestClient(t, teamsMux(t, map[string]string{
+		"platform": `{"id":2,"slug":"platform","name":"Platform"}`,
+	}, &created, &edited))
+
+	team, err := c.GetOrCreateTeam(context.Background(), "Platform", "closed", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if team.GetSlug() != "platform" {
+		t.Errorf("expected canonical slug platform, got %q", team.GetSlug())
+	}
+	if created != nil {
+		t.Errorf("expected no team created, got %v", created)
+	}
+}
+
+func TestGetOrCreateTeam_MultiWordName(t *testing.T) {
+	// github collapses runs of other characters into one hyphen, which can
+	// differ from TeamSlug, e.g. "Data  Platform" becomes "data-platform"
+	githubSlug := func(name string) string {
+		fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+			return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+		})
+		return strings.Join(fields, "-")
+	}
+
+	for _, name := range []string{"My Team", "Data  Platform"} {
+		t.Run(name, func(t *testing.T) {
+			teams := map[string]string{}
+			var creates atomic.Int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("GET /orgs/acme/teams/{slug}", func(w http.ResponseWriter, r *http.Request) {
+				body, ok := teams[r.PathValue("slug")]
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, body)
+			})
+			mux.HandleFunc("GET /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, "[]")
+			})
+			mux.HandleFunc("POST /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Name string `json:"name"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Errorf("failed to decode create request: %v", err)
+				}
+				if creates.Add(1) > 1 {
+					w.WriteHeader(http.StatusUnprocessableEntity)
+					fmt.Fprint(w, `{"message":"Name must be unique for this org"}`)
+					return
+				}
+				slug := githubSlug(req.Name)
+				teams[slug] = fmt.Sprintf(`{"id":3,"slug":%q,"name":%q}`, slug, req.Name)
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, teams[slug])
+			})
+			c := newTestClient(t, mux)
+
+			// the org team list is cached before the team exists
+			for range 3 {
+				team, err := c.GetOrCreateTeam(context.Background(), name, "closed", "")
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if team.GetSlug() != githubSlug(name) || team.GetName() != name {
+					t.Errorf("expected team %q with slug %q, got %q and %q", name, githubSlug(name), team.GetName(), team.GetSlug())
+				}
+			}
+			if got := creates.Load(); got != 1 {
+				t.Errorf("expected team created once, got %d creates", got)
+			}
+		})
+	}
+}
+
+func TestGetTeamBySlug(t *testing.T) {
+	var created, edited map[string]any
+	c := newTestClient(t, teamsMux(t, map[string]string{
+		"platform-eng": `{"id":2,"slug":"platform-eng","name":"Platform Engineering"}`,
+	}, &created, &edited))
+
+	for _, slug := range []string{"platform-eng", "Platform-Eng"} {
+		team, err := c.GetTeamBySlug(context.Background(), slug)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", slug, err)
+		}
+		if team.GetSlug() != "platform-eng" {
+			t.Errorf("%s: expected slug platform-eng, got %q", slug, team.GetSlug())
+		}
+	}
+
+	// display names never bind, and missing teams are not created
+	for _, slug := range []string{"platform engineering", "missing"} {
+		if _, err := c.GetTeamBySlug(context.Background(), slug); !errors.Is(err, internalerrors.ErrTeamNotFound) {
+			t.Errorf("%s: expected team not found error, got %v", slug, err)
+		}
+	}
+	if created != nil || edited != nil {
+		t.Errorf("expected team left unchanged, got created %v and edited %v", created, edited)
+	}
+}
+
+func TestTeamSlug(t *testing.T) {
+	tests := map[string]string{
+		"platform":      "platform",
+		"My Team":       "my-team",
+		"eng_backend.1": "eng-backend-1",
+		"Data-Ops":      "data-ops",
+	}
+	for name, want := range tests {
+		if got := TeamSlug(name); got != want {
+			t.Errorf("TeamSlug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFindTeam(t *testing.T) {
+	var created, edited map[string]any
+	c := newTestClient(t, teamsMux(t, map[string]string{
+		"platform-eng": `{"id":2,"slug":"platform-eng","name":"Platform Engineering"}`,
+	}, &created, &edited))
+
+	// like GetOrCreateTeam, display names match too
+	for _, name := range []string{"platform-eng", "Platform-Eng", "platform engineering"} {
+		team, err := c.FindTeam(context.Background(), name)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if team.GetSlug() != "platform-eng" {
+			t.Errorf("%s: expected slug platform-eng, got %q", name, team.GetSlug())
+		}
+	}
+
+	team, err := c.FindTeam(context.Background(), "missing")
+	if err != nil || team != nil {
+		t.Errorf("expected no team and no error, got %v and %v", team, err)
+	}
+	if created != nil || edited != nil {
+		t.Errorf("expected team left unchanged, got created %v and edited %v", created, edited)
+	}
+}
+
+func TestSetTeamDescription(t *testing.T) {
+	const description = "Managed by Okta sync - do not edit manually"
+	team := &github.Team{Slug: github.Ptr("platform"), Name: github.Ptr("Platform"), Description: github.Ptr("old")}
+
+	var created, edited map[string]any
+	c := newTestClient(t, teamsMux(t, map[string]string{}, &created, &edited))
+
+	updated, err := c.SetTeamDescription(WithDryRun(context.Background(), true), team, description)
+	if err != nil || !updated || edited != nil {
+		t.Fatalf("expected dry-run update to be reported but not applied, got %t, %v, %v", updated, err, edited)
+	}
+
+	updated, err = c.SetTeamDescription(context.Background(), team, description)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !updated || edited["description"] != description || edited["name"] != "Platform" {
+		t.Errorf("expected description edit keeping the name, got %t and %v", updated, edited)
+	}
+
+	edited = nil
+	team.Description = github.Ptr(description)
+	if updated, err := c.SetTeamDescription(context.Background(), team, description); err != nil || updated || edited != nil {
+		t.Errorf("expected matching description left unchanged, got %t, %v, %v", updated, err, edited)
+	}
+}
+
+func TestGetTeamMembers_CaseInsensitiveSlug(t *testing.T) {
+	var listTeams atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+		listTeams.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"id":1,"slug":"other"},{"id":2,"slug":"platform"}]`)
+	})
+	mux.HandleFunc("GET /orgs/acme/teams/{slug}/members", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("slug") != "platform" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"alice"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	for i := range 2 {
+		members, err := c.GetTeamMembers(context.Background(), "PLATFORM")
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i, err)
+		}
+		if len(members) != 1 || members[0] != "alice" {
+			t.Errorf("call %d: expected [alice], got %v", i, members)
+		}
+	}
+	if got := listTeams.Load(); got != 1 {
+		t.Errorf("expected org teams listed once, got %d", got)
+	}
+
+	result, err := c.SyncTeamMembers(context.Background(), "Platform", []string{"alice"}, 1, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.TeamName != "platform" {
+		t.Errorf("expected canonical team name platform, got %q", result.TeamName)
+	}
+
+	if _, err := c.GetTeamMembers(context.Background(), "missing"); err == nil {
+		t.Error("expected error for unknown team")
+	}
+}
+
+func TestVerifyTeamMembers(t *testing.T) {
+	var listed atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/platform/members", func(w http.ResponseWriter, r *http.Request) {
+		listed.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[{"login":"alice"},{"login":"Bob"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	result := &TeamSyncResult{TeamName: "platform", MembersAdded: []string{"bob", "carol"}}
+	if err := c.VerifyTeamMembers(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.MembersAdded) != 1 || result.MembersAdded[0] != "bob" {
+		t.Errorf("expected [bob] added, got %v", result.MembersAdded)
+	}
+	if len(result.MembersPending) != 1 || result.MembersPending[0] != "carol" {
+		t.Errorf("expected [carol] pending, got %v", result.MembersPending)
+	}
+
+	dryRun := &TeamSyncResult{TeamName: "platform", MembersAdded: []string{"carol"}}
+	if err := c.VerifyTeamMembers(WithDryRun(context.Background(), true), dryRun); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dryRun.MembersPending) != 0 || listed.Load() != 1 {
+		t.Errorf("expected dry-run verification to be skipped, got pending %v", dryRun.MembersPending)
+	}
+}
+
+func TestVerifyTeamMembers_Paginated(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/platform/members", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("per_page"); got != "100" {
+			t.Errorf("expected per_page=100, got %q", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"login":"carol"}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"}]`)
+	})
+	c := newTestClient(t, mux)
+
+	result := &TeamSyncResult{TeamName: "platform", MembersAdded: []string{"alice", "carol", "dave"}}
+	if err := c.VerifyTeamMembers(context.Background(), result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(result.MembersAdded, []string{"alice", "carol"}) {
+		t.Errorf("expected members on both pages verified, got %v", result.MembersAdded)
+	}
+	if !slices.Equal(result.MembersPending, []string{"dave"}) {
+		t.Errorf("expected [dave] pending, got %v", result.MembersPending)
+	}
+}
+
+func TestListPendingTeamInvitations(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/acme/teams/platform/invitations", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"login":"carol"}]`)
+			return
+		}
+		w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"login":"bob"},{"email":"dave@example.com"}]`)
+	})
+	mux.HandleFunc("GET /orgs/acme/teams/new-team/invitations", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	c := newTestClient(t, mux)
+
+	logins, err := c.ListPendingTeamInvitations(context.Background(), "platform")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(logins, []string{"bob", "carol"}) {
+		t.Errorf("expected [bob carol], got %v", logins)
+	}
+
+	if _, err := c.ListPendingTeamInvitations(context.Background(), "new-team"); err == nil {
+		t.Error("expected error for a missing team")
+	}
+	logins, err = c.ListPendingTeamInvitations(WithDryRun(context.Background(), true), "new-team")
+	if err != nil || len(logins) != 0 {
+		t.Errorf("expected no invitations for a missing team in dry-run, got %v, %v", logins, err)
+	}
+}
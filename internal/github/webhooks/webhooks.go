@@ -1,9 +1,11 @@
 // Package webhooks provides GitHub webhook event parsing and signature
-// validation. Supports pull_request, team, and membership event types.
+// validation. Supports pull_request, team, membership, push, and delete event
+// types.
 package webhooks
 
 import (
 	"crypto/hmac"
+	"crypto/sha1"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
@@ -19,11 +21,25 @@ type PullRequestEvent struct {
 	Action       string               `json:"action"`
 	Number       int                  `json:"number"`
 	PullRequest  *github.PullRequest  `json:"pull_request"`
+	Changes      *PullRequestChanges  `json:"changes,omitempty"`
 	Repository   *github.Repository   `json:"repository"`
 	Sender       *github.User         `json:"sender"`
 	Installation *github.Installation `json:"installation"`
 }
 
+// PullRequestChanges contains details about what changed in an edited pull
+// request event.
+type PullRequestChanges struct {
+	Base *PullRequestBaseChanges `json:"base,omitempty"`
+}
+
+// PullRequestBaseChanges contains the previous base ref and sha of a pull
+// request whose base branch was changed.
+type PullRequestBaseChanges struct {
+	Ref *TeamChangeDetail `json:"ref,omitempty"`
+	SHA *TeamChangeDetail `json:"sha,omitempty"`
+}
+
 // TeamEvent represents a GitHub team webhook payload.
 type TeamEvent struct {
 	Action       string               `json:"action"`
@@ -59,6 +75,34 @@ type MembershipEvent struct {
 	Installation *github.Installation `json:"installation"`
 }
 
+// PushEvent represents a GitHub push webhook payload. only the fields needed
+// to detect branch deletions and direct pushes are decoded.
+type PushEvent struct {
+	Ref          string                      `json:"ref"`
+	Before       string                      `json:"before"`
+	After        string                      `json:"after"`
+	Created      bool                        `json:"created"`
+	Deleted      bool                        `json:"deleted"`
+	Forced       bool                        `json:"forced"`
+	Compare      string                      `json:"compare"`
+	Commits      []*github.HeadCommit        `json:"commits"`
+	HeadCommit   *github.HeadCommit          `json:"head_commit"`
+	Repository   *github.PushEventRepository `json:"repository"`
+	Pusher       *github.CommitAuthor        `json:"pusher"`
+	Sender       *github.User                `json:"sender"`
+	Installation *github.Installation        `json:"installation"`
+}
+
+// DeleteEvent represents a GitHub delete webhook payload, sent when a branch
+// or tag is deleted.
+type DeleteEvent struct {
+	Ref          string               `json:"ref"`
+	RefType      string               `json:"ref_type"`
+	Repository   *github.Repository   `json:"repository"`
+	Sender       *github.User         `json:"sender"`
+	Installation *github.Installation `json:"installation"`
+}
+
 // ValidateWebhookSignature verifies HMAC-SHA256 webhook signature.
 // returns error if signature is invalid or missing when required.
 func ValidateWebhookSignature(payload []byte, signature string, secret string) error {
@@ -89,6 +133,39 @@ func ValidateWebhookSignature(payload []byte, signature string, secret string) e
 	return nil
 }
 
+// ValidateWebhookSignatureLegacy verifies an HMAC-SHA1 webhook signature
+// from the legacy x-hub-signature header. only for GitHub Enterprise Server
+// versions or proxies that do not send x-hub-signature-256; prefer
+// ValidateWebhookSignature. returns error if signature is invalid or missing
+// when required.
+func ValidateWebhookSignatureLegacy(payload []byte, signature string, secret string) error {
+	if secret == "" {
+		if signature != "" {
+			return internalerrors.ErrUnexpectedSignature
+		}
+		return nil
+	}
+
+	if signature == "" {
+		return internalerrors.ErrMissingSignature
+	}
+
+	if !strings.HasPrefix(signature, "sha1=") {
+		return errors.Wrap(internalerrors.ErrInvalidSignature, "must start with 'sha1='")
+	}
+
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(payload)
+	expectedMAC := hex.EncodeToString(mac.Sum(nil))
+	expectedSignature := "sha1=" + expectedMAC
+
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return errors.Wrap(internalerrors.ErrInvalidSignature, "computed signature does not match")
+	}
+
+	return nil
+}
+
 // ParsePullRequestEvent unmarshals and validates a pull_request webhook.
 // returns error if required fields are missing.
 func ParsePullRequestEvent(payload []byte) (*PullRequestEvent, error) {
@@ -113,7 +190,27 @@ func ParsePullRequestEvent(payload []byte) (*PullRequestEvent, error) {
 
 // IsMerged returns true if the PR was closed via merge.
 func (e *PullRequestEvent) IsMerged() bool {
-	return e.Action == "closed" && e.PullRequest != nil && e.PullRequest.Merged != nil && *e.PullRequest.Merged
+	return e.Action == "closed" && e.WasMerged()
+}
+
+// WasMerged returns true if the PR has been merged, regardless of the event
+// action.
+func (e *PullRequestEvent) WasMerged() bool {
+	return e.PullRequest != nil && e.PullRequest.Merged != nil && *e.PullRequest.Merged
+}
+
+// BaseChanged returns true if the event records a change of base branch.
+func (e *PullRequestEvent) BaseChanged() bool {
+	return e.Changes != nil && e.Changes.Base != nil && e.Changes.Base.Ref != nil
+}
+
+// GetPreviousBaseBranch returns the base branch before an edit, or an empty
+// string if the base branch did not change.
+func (e *PullRequestEvent) GetPreviousBaseBranch() string {
+	if e.BaseChanged() {
+		return e.Changes.Base.Ref.From
+	}
+	return ""
 }
 
 // GetBaseBranch returns the target branch name.
@@ -124,6 +221,14 @@ func (e *PullRequestEvent) GetBaseBranch() string {
 	return ""
 }
 
+// GetDefaultBranch returns the repository's default branch.
+func (e *PullRequestEvent) GetDefaultBranch() string {
+	if e.Repository != nil && e.Repository.DefaultBranch != nil {
+		return *e.Repository.DefaultBranch
+	}
+	return ""
+}
+
 // GetRepoFullName returns the repository in owner/name format.
 func (e *PullRequestEvent) GetRepoFullName() string {
 	if e.Repository != nil && e.Repository.FullName != nil {
@@ -213,6 +318,14 @@ func (e *TeamEvent) GetSenderLogin() string {
 	return ""
 }
 
+// GetSenderID returns the user ID of the user who triggered the event.
+func (e *TeamEvent) GetSenderID() int64 {
+	if e.Sender != nil && e.Sender.ID != nil {
+		return *e.Sender.ID
+	}
+	return 0
+}
+
 // GetSenderType returns the sender's type (User or Bot).
 func (e *TeamEvent) GetSenderType() string {
 	if e.Sender != nil && e.Sender.Type != nil {
@@ -245,6 +358,14 @@ func (e *MembershipEvent) GetSenderLogin() string {
 	return ""
 }
 
+// GetSenderID returns the user ID of the user who triggered the event.
+func (e *MembershipEvent) GetSenderID() int64 {
+	if e.Sender != nil && e.Sender.ID != nil {
+		return *e.Sender.ID
+	}
+	return 0
+}
+
 // GetSenderType returns the sender's type (User or Bot).
 func (e *MembershipEvent) GetSenderType() string {
 	if e.Sender != nil && e.Sender.Type != nil {
@@ -257,3 +378,178 @@ func (e *MembershipEvent) GetSenderType() string {
 func (e *MembershipEvent) IsTeamScope() bool {
 	return e.Scope == "team"
 }
+
+// ParsePushEvent unmarshals and validates a push webhook.
+func ParsePushEvent(payload []byte) (*PushEvent, error) {
+	var event PushEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal push event")
+	}
+	if event.Ref == "" {
+		return nil, errors.New("missing ref field in event")
+	}
+	if event.Repository == nil {
+		return nil, errors.New("missing repository field in event")
+	}
+	return &event, nil
+}
+
+// IsBranchDeletion returns true if the push deleted a branch.
+func (e *PushEvent) IsBranchDeletion() bool {
+	return e.Deleted && strings.HasPrefix(e.Ref, "refs/heads/")
+}
+
+// GetBranch returns the pushed branch name without the refs/heads/ prefix.
+func (e *PushEvent) GetBranch() string {
+	return strings.TrimPrefix(e.Ref, "refs/heads/")
+}
+
+// GetRepoOwner returns the repository owner login.
+func (e *PushEvent) GetRepoOwner() string {
+	if e.Repository != nil && e.Repository.Owner != nil {
+		return e.Repository.Owner.GetLogin()
+	}
+	return ""
+}
+
+// GetRepoName returns the repository name without owner.
+func (e *PushEvent) GetRepoName() string {
+	if e.Repository != nil {
+		return e.Repository.GetName()
+	}
+	return ""
+}
+
+// GetRepoFullName returns the repository in owner/name format.
+func (e *PushEvent) GetRepoFullName() string {
+	if e.Repository != nil {
+		return e.Repository.GetFullName()
+	}
+	return ""
+}
+
+// GetDefaultBranch returns the repository's default branch.
+func (e *PushEvent) GetDefaultBranch() string {
+	if e.Repository != nil {
+		return e.Repository.GetDefaultBranch()
+	}
+	return ""
+}
+
+// GetSenderLogin returns the login of the user who pushed, falling back to
+// the pusher name.
+func (e *PushEvent) GetSenderLogin() string {
+	if e.Sender != nil && e.Sender.Login != nil {
+		return *e.Sender.Login
+	}
+	if e.Pusher != nil && e.Pusher.Name != nil {
+		return *e.Pusher.Name
+	}
+	return ""
+}
+
+// GetSenderID returns the user ID of the user who pushed.
+func (e *PushEvent) GetSenderID() int64 {
+	if e.Sender != nil && e.Sender.ID != nil {
+		return *e.Sender.ID
+	}
+	return 0
+}
+
+// GetSenderType returns the sender's type (User or Bot).
+func (e *PushEvent) GetSenderType() string {
+	if e.Sender != nil && e.Sender.Type != nil {
+		return *e.Sender.Type
+	}
+	return ""
+}
+
+// GetHeadCommitMessage returns the first line of the head commit message.
+func (e *PushEvent) GetHeadCommitMessage() string {
+	message := e.HeadCommit.GetMessage()
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+	return message
+}
+
+// GetInstallationID returns the GitHub App installation ID.
+func (e *PushEvent) GetInstallationID() int64 {
+	if e.Installation != nil && e.Installation.ID != nil {
+		return *e.Installation.ID
+	}
+	return 0
+}
+
+// ParseDeleteEvent unmarshals and validates a delete webhook.
+func ParseDeleteEvent(payload []byte) (*DeleteEvent, error) {
+	var event DeleteEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal delete event")
+	}
+	if event.Ref == "" {
+		return nil, errors.New("missing ref field in event")
+	}
+	if event.Repository == nil {
+		return nil, errors.New("missing repository field in event")
+	}
+	return &event, nil
+}
+
+// IsBranchDeletion returns true if a branch (rather than a tag) was deleted.
+func (e *DeleteEvent) IsBranchDeletion() bool {
+	return e.RefType == "branch"
+}
+
+// GetBranch returns the deleted branch name.
+func (e *DeleteEvent) GetBranch() string {
+	return strings.TrimPrefix(e.Ref, "refs/heads/")
+}
+
+// GetRepoOwner returns the repository owner login.
+func (e *DeleteEvent) GetRepoOwner() string {
+	if e.Repository != nil && e.Repository.Owner != nil && e.Repository.Owner.Login != nil {
+		return *e.Repository.Owner.Login
+	}
+	return ""
+}
+
+// GetRepoName returns the repository name without owner.
+func (e *DeleteEvent) GetRepoName() string {
+	if e.Repository != nil && e.Repository.Name != nil {
+		return *e.Repository.Name
+	}
+	return ""
+}
+
+// GetRepoFullName returns the repository in owner/name format.
+func (e *DeleteEvent) GetRepoFullName() string {
+	if e.Repository != nil && e.Repository.FullName != nil {
+		return *e.Repository.FullName
+	}
+	return ""
+}
+
+// GetDefaultBranch returns the repository's default branch.
+func (e *DeleteEvent) GetDefaultBranch() string {
+	if e.Repository != nil && e.Repository.DefaultBranch != nil {
+		return *e.Repository.DefaultBranch
+	}
+	return ""
+}
+
+// GetSenderLogin returns the login of the user who deleted the ref.
+func (e *DeleteEvent) GetSenderLogin() string {
+	if e.Sender != nil && e.Sender.Login != nil {
+		return *e.Sender.Login
+	}
+	return ""
+}
+
+// GetInstallationID returns the GitHub App installation ID.
+func (e *DeleteEvent) GetInstallationID() int64 {
+	if e.Installation != nil && e.Installation.ID != nil {
+		return *e.Installation.ID
+	}
+	return 0
+}
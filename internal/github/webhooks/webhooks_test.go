@@ -0,0 +1,34 @@
+package webhooks
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v79/github"
+)
+
+func TestGetSenderID(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender *github.User
+		want   int64
+	}{
+		{"bot sender", &github.User{Login: github.Ptr("ops-app[bot]"), ID: github.Ptr(int64(99)), Type: github.Ptr("Bot")}, 99},
+		{"human sender", &github.User{Login: github.Ptr("alice"), ID: github.Ptr(int64(7)), Type: github.Ptr("User")}, 7},
+		{"missing sender", nil, 0},
+		{"sender without id", &github.User{Login: github.Ptr("alice")}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for kind, got := range map[string]int64{
+				"team":       (&TeamEvent{Sender: tt.sender}).GetSenderID(),
+				"membership": (&MembershipEvent{Sender: tt.sender}).GetSenderID(),
+				"push":       (&PushEvent{Sender: tt.sender}).GetSenderID(),
+			} {
+				if got != tt.want {
+					t.Errorf("%s event: GetSenderID() = %d, want %d", kind, got, tt.want)
+				}
+			}
+		})
+	}
+}
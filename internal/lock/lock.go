@@ -0,0 +1,75 @@
+// Package lock provides named locks used to prevent overlapping runs of
+// long-running operations such as Okta sync.
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// Locker acquires named locks. the default MemoryLocker serializes work
+// within a single process; distributed runtimes (e.g., multiple Lambda
+// instances) can provide an implementation backed by shared storage.
+type Locker interface {
+	// Lock blocks until the named lock is acquired or ctx is done.
+	Lock(ctx context.Context, key string) (release func(), err error)
+	// TryLock acquires the named lock only if it is free. returns false
+	// without blocking if the lock is held.
+	TryLock(ctx context.Context, key string) (release func(), acquired bool, err error)
+}
+
+// MemoryLocker is an in-process Locker backed by per-key semaphores.
+type MemoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]chan struct{}
+}
+
+// NewMemoryLocker creates an in-process locker.
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{
+		locks: make(map[string]chan struct{}),
+	}
+}
+
+// semaphore returns the semaphore for a key, creating it if missing.
+func (l *MemoryLocker) semaphore(key string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.locks[key]
+	if !ok {
+		sem = make(chan struct{}, 1)
+		l.locks[key] = sem
+	}
+	return sem
+}
+
+// Lock blocks until the named lock is acquired or ctx is done.
+func (l *MemoryLocker) Lock(ctx context.Context, key string) (func(), error) {
+	sem := l.semaphore(key)
+	select {
+	case sem <- struct{}{}:
+		return releaseFunc(sem), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// TryLock acquires the named lock only if it is free.
+func (l *MemoryLocker) TryLock(_ context.Context, key string) (func(), bool, error) {
+	sem := l.semaphore(key)
+	select {
+	case sem <- struct{}{}:
+		return releaseFunc(sem), true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// releaseFunc returns an idempotent release function for a semaphore.
+func releaseFunc(sem chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-sem })
+	}
+}
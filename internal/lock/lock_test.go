@@ -0,0 +1,169 @@
+package lock
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cruxstack/github-ops-app/internal/store"
+)
+
+func TestMemoryLocker_TryLock(t *testing.T) {
+	l := NewMemoryLocker()
+	ctx := context.Background()
+
+	release, ok, err := l.TryLock(ctx, "sync")
+	if err != nil || !ok {
+		t.Fatalf("expected first TryLock to succeed, got ok=%v err=%v", ok, err)
+	}
+
+	if _, ok, _ := l.TryLock(ctx, "sync"); ok {
+		t.Error("expected second TryLock to fail while held")
+	}
+
+	if _, ok, _ := l.TryLock(ctx, "other"); !ok {
+		t.Error("expected TryLock on different key to succeed")
+	}
+
+	release()
+	release() // release is idempotent
+
+	if _, ok, _ := l.TryLock(ctx, "sync"); !ok {
+		t.Error("expected TryLock to succeed after release")
+	}
+}
+
+func TestMemoryLocker_LockWaits(t *testing.T) {
+	l := NewMemoryLocker()
+	ctx := context.Background()
+
+	release, err := l.Lock(ctx, "sync")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		r, err := l.Lock(ctx, "sync")
+		if err == nil {
+			r()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("expected Lock to block while held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	release()
+
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected Lock to be acquired after release")
+	}
+}
+
+func TestMemoryLocker_LockContextCanceled(t *testing.T) {
+	l := NewMemoryLocker()
+
+	release, err := l.Lock(context.Background(), "sync")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := l.Lock(ctx, "sync"); err == nil {
+		t.Error("expected error when context is done before lock is acquired")
+	}
+}
+
+func TestStoreLocker(t *testing.T) {
+	s := store.NewMemoryStore()
+	a := NewStoreLocker(s, time.Minute)
+	b := NewStoreLocker(s, time.Minute)
+	b.poll = time.Millisecond
+	ctx := context.Background()
+
+	release, ok, err := a.TryLock(ctx, "sync")
+	if err != nil || !ok {
+		t.Fatalf("expected first TryLock to succeed, got ok=%v err=%v", ok, err)
+	}
+	if _, ok, _ := b.TryLock(ctx, "sync"); ok {
+		t.Error("expected TryLock from another locker sharing the store to fail while held")
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := b.Lock(waitCtx, "sync"); err == nil {
+		t.Error("expected Lock to time out while held")
+	}
+
+	release()
+	release() // release is idempotent
+
+	releaseB, err := b.Lock(ctx, "sync")
+	if err != nil {
+		t.Fatalf("expected Lock to succeed after release, got %v", err)
+	}
+
+	// a stale release must not free a lock now held by someone else
+	release()
+	if _, ok, _ := a.TryLock(ctx, "sync"); ok {
+		t.Error("expected lock to remain held by the second locker")
+	}
+	releaseB()
+}
+
+func TestStoreLocker_RenewsWhileHeld(t *testing.T) {
+	s := store.NewMemoryStore()
+	a := NewStoreLocker(s, 30*time.Millisecond)
+	a.renew = 5 * time.Millisecond
+	b := NewStoreLocker(s, 30*time.Millisecond)
+	ctx := context.Background()
+
+	release, ok, err := a.TryLock(ctx, "sync")
+	if err != nil || !ok {
+		t.Fatalf("expected TryLock to succeed, got ok=%v err=%v", ok, err)
+	}
+
+	// the lock outlives several ttls while its holder keeps renewing it
+	time.Sleep(100 * time.Millisecond)
+	if _, ok, _ := b.TryLock(ctx, "sync"); ok {
+		t.Fatal("expected renewed lock to remain held")
+	}
+
+	release()
+	if releaseB, ok, _ := b.TryLock(ctx, "sync"); !ok {
+		t.Error("expected lock to be free after release")
+	} else {
+		releaseB()
+	}
+}
+
+func TestStoreLocker_ReleaseAfterTakeover(t *testing.T) {
+	s := store.NewMemoryStore()
+	a := NewStoreLocker(s, time.Minute)
+	ctx := context.Background()
+
+	release, ok, err := a.TryLock(ctx, "sync")
+	if err != nil || !ok {
+		t.Fatalf("expected TryLock to succeed, got ok=%v err=%v", ok, err)
+	}
+
+	// simulate the lock expiring and another holder taking it over between
+	// the holder's last renewal and its release
+	if err := s.Set(ctx, storeLockPrefix+"sync", []byte("other"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	release()
+
+	if got, found, _ := s.Get(ctx, storeLockPrefix+"sync"); !found || string(got) != "other" {
+		t.Errorf("expected the other holder's lock to survive, got %q found=%t", got, found)
+	}
+}
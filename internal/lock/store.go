@@ -0,0 +1,112 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/store"
+)
+
+// storeLockPrefix namespaces lock keys in a shared store.
+const storeLockPrefix = "lock/"
+
+// DefaultStoreLockTTL bounds how long a lock is held if its holder exits
+// without releasing it. holders renew the lock while they work, so the ttl
+// only needs to cover a crashed holder, not the longest run.
+const DefaultStoreLockTTL = 15 * time.Minute
+
+// storeLockPollInterval is how often Lock retries a held lock.
+const storeLockPollInterval = 500 * time.Millisecond
+
+// StoreLocker is a Locker backed by a store.Store, so locks are shared by
+// every process using the same backend. held locks are renewed every third
+// of the ttl and expire after the ttl in case the holder crashes.
+type StoreLocker struct {
+	store store.Store
+	ttl   time.Duration
+	poll  time.Duration
+	renew time.Duration
+}
+
+// NewStoreLocker creates a locker that holds locks in s for up to ttl.
+func NewStoreLocker(s store.Store, ttl time.Duration) *StoreLocker {
+	if ttl <= 0 {
+		ttl = DefaultStoreLockTTL
+	}
+	return &StoreLocker{store: s, ttl: ttl, poll: storeLockPollInterval, renew: ttl / 3}
+}
+
+// Lock retries until the named lock is acquired or ctx is done.
+func (l *StoreLocker) Lock(ctx context.Context, key string) (func(), error) {
+	ticker := time.NewTicker(l.poll)
+	defer ticker.Stop()
+
+	for {
+		release, acquired, err := l.TryLock(ctx, key)
+		if err != nil || acquired {
+			return release, err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// TryLock acquires the named lock only if it is free or expired.
+func (l *StoreLocker) TryLock(ctx context.Context, key string) (func(), bool, error) {
+	token := []byte(rand.Text())
+	acquired, err := l.store.PutIfAbsent(ctx, storeLockPrefix+key, token, l.ttl)
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to acquire lock '%s'", key)
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go l.keepAlive(key, token, stop, done)
+	return l.releaseFunc(key, token, stop, done), true, nil
+}
+
+// keepAlive extends the lock every renew interval until stop is closed or
+// the lock is lost. failed refreshes are retried on the next tick; the
+// lock expires if none succeed within the ttl.
+func (l *StoreLocker) keepAlive(key string, token []byte, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(l.renew)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			held, err := l.store.RefreshIfValue(context.Background(), storeLockPrefix+key, token, l.ttl)
+			if err == nil && !held {
+				return
+			}
+		}
+	}
+}
+
+// releaseFunc returns an idempotent release function that stops renewal and
+// deletes the lock only if it still holds token, so a lock that expired and
+// was taken by another holder is left alone.
+func (l *StoreLocker) releaseFunc(key string, token []byte, stop chan<- struct{}, done <-chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+			// release runs after the caller's work, which may have outlived ctx
+			_, _ = l.store.DeleteIfValue(context.Background(), storeLockPrefix+key, token)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+// Package metrics records request outcomes, latencies, and sync results for
+// SLO tracking.
+package metrics
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// webhook outcomes recorded by ObserveWebhook.
+const (
+	OutcomeProcessed = "processed"
+	OutcomeSkipped   = "skipped"
+	OutcomeError     = "error"
+)
+
+// WebhookStats aggregates webhook deliveries of one event type and outcome.
+type WebhookStats struct {
+	EventType  string    `json:"event_type"`
+	Outcome    string    `json:"outcome"`
+	Count      int64     `json:"count"`
+	TotalMs    float64   `json:"total_ms"`
+	AvgMs      float64   `json:"avg_ms"`
+	MaxMs      float64   `json:"max_ms"`
+	LastMs     float64   `json:"last_ms"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// OktaSyncRun summarizes one Okta sync run.
+type OktaSyncRun struct {
+	// Failed is true when the sync aborted before producing any reports.
+	Failed         bool
+	MembersAdded   int
+	MembersRemoved int
+	OrphanedUsers  int
+	// Rules maps each synced rule name to whether it completed without
+	// errors.
+	Rules map[string]bool
+	// MembersSkipped maps each skip reason to the distinct users skipped
+	// for it.
+	MembersSkipped map[string]int
+}
+
+// OktaSyncStats aggregates Okta sync runs.
+type OktaSyncStats struct {
+	Runs           int64       `json:"runs"`
+	FailedRuns     int64       `json:"failed_runs"`
+	MembersAdded   int64       `json:"members_added"`
+	MembersRemoved int64       `json:"members_removed"`
+	RulesFailed    int64       `json:"rules_failed"`
+	OrphanedUsers  int64       `json:"orphaned_users"`
+	LastSyncUnix   int64       `json:"last_sync_unix"`
+	Rules          []RuleStats `json:"rules"`
+	// MembersSkipped totals the skipped users per skip reason across runs.
+	MembersSkipped map[string]int64 `json:"members_skipped"`
+	// LastMembersSkipped is the skipped users per skip reason in the most
+	// recent run, for alerting on rising counts.
+	LastMembersSkipped map[string]int64 `json:"last_members_skipped"`
+}
+
+// RuleStats tallies the outcomes of one sync rule.
+type RuleStats struct {
+	Rule      string `json:"rule"`
+	Succeeded int64  `json:"succeeded"`
+	Failed    int64  `json:"failed"`
+	// ConsecutiveFailures counts runs that failed since the rule last
+	// succeeded, for alerting on rules that keep failing.
+	ConsecutiveFailures int64 `json:"consecutive_failures"`
+}
+
+// Snapshot is a point-in-time copy of recorded metrics.
+type Snapshot struct {
+	Since    time.Time      `json:"since"`
+	Webhooks []WebhookStats `json:"webhooks"`
+	OktaSync OktaSyncStats  `json:"okta_sync"`
+	// InFlight is the number of requests being processed, including webhook
+	// deliveries and scheduled syncs, when the snapshot was taken.
+	InFlight int64 `json:"in_flight"`
+}
+
+// Recorder records webhook processing and Okta sync metrics.
+type Recorder interface {
+	// ObserveWebhook records the processing duration and outcome of one
+	// webhook delivery.
+	ObserveWebhook(eventType, outcome string, duration time.Duration)
+	// ObserveOktaSync records the outcome of one Okta sync run.
+	ObserveOktaSync(run OktaSyncRun)
+	// AddInFlight adjusts the number of requests being processed by delta.
+	AddInFlight(delta int64)
+	// Snapshot returns the metrics recorded so far.
+	Snapshot() Snapshot
+}
+
+// webhookKey identifies an aggregated webhook series.
+type webhookKey struct {
+	eventType string
+	outcome   string
+}
+
+// MemoryRecorder aggregates metrics in memory. contents are lost when the
+// process exits.
+type MemoryRecorder struct {
+	mu        sync.Mutex
+	since     time.Time
+	webhooks  map[webhookKey]*WebhookStats
+	oktaSync  OktaSyncStats
+	syncRules map[string]*RuleStats
+	inFlight  atomic.Int64
+}
+
+// NewMemoryRecorder creates an empty in-memory recorder.
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{
+		since:     time.Now(),
+		webhooks:  make(map[webhookKey]*WebhookStats),
+		syncRules: make(map[string]*RuleStats),
+	}
+}
+
+// ObserveWebhook records the processing duration and outcome of one webhook
+// delivery.
+func (r *MemoryRecorder) ObserveWebhook(eventType, outcome string, duration time.Duration) {
+	ms := float64(duration) / float64(time.Millisecond)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := webhookKey{eventType: eventType, outcome: outcome}
+	stats, ok := r.webhooks[key]
+	if !ok {
+		stats = &WebhookStats{EventType: eventType, Outcome: outcome}
+		r.webhooks[key] = stats
+	}
+
+	stats.Count++
+	stats.TotalMs += ms
+	stats.AvgMs = stats.TotalMs / float64(stats.Count)
+	stats.MaxMs = max(stats.MaxMs, ms)
+	stats.LastMs = ms
+	stats.LastSeenAt = time.Now()
+}
+
+// ObserveOktaSync records the outcome of one Okta sync run.
+func (r *MemoryRecorder) ObserveOktaSync(run OktaSyncRun) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.oktaSync.Runs++
+	r.oktaSync.LastSyncUnix = time.Now().Unix()
+	if run.Failed {
+		r.oktaSync.FailedRuns++
+	}
+	r.oktaSync.MembersAdded += int64(run.MembersAdded)
+	r.oktaSync.MembersRemoved += int64(run.MembersRemoved)
+	r.oktaSync.OrphanedUsers += int64(run.OrphanedUsers)
+
+	if run.MembersSkipped != nil {
+		if r.oktaSync.MembersSkipped == nil {
+			r.oktaSync.MembersSkipped = make(map[string]int64)
+		}
+		r.oktaSync.LastMembersSkipped = make(map[string]int64, len(run.MembersSkipped))
+		for reason, count := range run.MembersSkipped {
+			r.oktaSync.MembersSkipped[reason] += int64(count)
+			r.oktaSync.LastMembersSkipped[reason] = int64(count)
+		}
+	}
+
+	for rule, succeeded := range run.Rules {
+		stats, ok := r.syncRules[rule]
+		if !ok {
+			stats = &RuleStats{Rule: rule}
+			r.syncRules[rule] = stats
+		}
+		if succeeded {
+			stats.Succeeded++
+			stats.ConsecutiveFailures = 0
+			continue
+		}
+		stats.Failed++
+		stats.ConsecutiveFailures++
+		r.oktaSync.RulesFailed++
+	}
+}
+
+// AddInFlight adjusts the number of requests being processed by delta. it
+// does not take the recorder's lock, so it is cheap on every request.
+func (r *MemoryRecorder) AddInFlight(delta int64) {
+	r.inFlight.Add(delta)
+}
+
+// Snapshot returns a copy of the recorded metrics. webhook stats are ordered
+// by event type and outcome, and rule stats by rule name.
+func (r *MemoryRecorder) Snapshot() Snapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	webhooks := make([]WebhookStats, 0, len(r.webhooks))
+	for _, stats := range r.webhooks {
+		webhooks = append(webhooks, *stats)
+	}
+	slices.SortFunc(webhooks, func(a, b WebhookStats) int {
+		return cmp.Or(cmp.Compare(a.EventType, b.EventType), cmp.Compare(a.Outcome, b.Outcome))
+	})
+
+	oktaSync := r.oktaSync
+	oktaSync.MembersSkipped = maps.Clone(r.oktaSync.MembersSkipped)
+	oktaSync.LastMembersSkipped = maps.Clone(r.oktaSync.LastMembersSkipped)
+	oktaSync.Rules = make([]RuleStats, 0, len(r.syncRules))
+	for _, stats := range r.syncRules {
+		oktaSync.Rules = append(oktaSync.Rules, *stats)
+	}
+	slices.SortFunc(oktaSync.Rules, func(a, b RuleStats) int {
+		return cmp.Compare(a.Rule, b.Rule)
+	})
+
+	return Snapshot{Since: r.since, InFlight: r.inFlight.Load(), Webhooks: webhooks, OktaSync: oktaSync}
+}
@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"maps"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryRecorder(t *testing.T) {
+	r := NewMemoryRecorder()
+
+	if got := r.Snapshot().Webhooks; len(got) != 0 {
+		t.Fatalf("expected no webhook stats, got %+v", got)
+	}
+
+	r.ObserveWebhook("push", OutcomeProcessed, 10*time.Millisecond)
+	r.ObserveWebhook("push", OutcomeProcessed, 30*time.Millisecond)
+	r.ObserveWebhook("push", OutcomeError, 5*time.Millisecond)
+	r.ObserveWebhook("membership", OutcomeSkipped, time.Millisecond)
+
+	webhooks := r.Snapshot().Webhooks
+	if len(webhooks) != 3 {
+		t.Fatalf("expected 3 series, got %+v", webhooks)
+	}
+
+	// ordered by event type, then outcome
+	if webhooks[0].EventType != "membership" || webhooks[1].Outcome != OutcomeError || webhooks[2].Outcome != OutcomeProcessed {
+		t.Errorf("unexpected order: %+v", webhooks)
+	}
+
+	processed := webhooks[2]
+	if processed.Count != 2 {
+		t.Errorf("expected count 2, got %d", processed.Count)
+	}
+	if processed.TotalMs != 40 || processed.AvgMs != 20 || processed.MaxMs != 30 || processed.LastMs != 30 {
+		t.Errorf("unexpected latency stats: %+v", processed)
+	}
+}
+
+func TestMemoryRecorder_OktaSync(t *testing.T) {
+	r := NewMemoryRecorder()
+
+	r.ObserveOktaSync(OktaSyncRun{
+		MembersAdded:   3,
+		MembersRemoved: 1,
+		OrphanedUsers:  2,
+		Rules:          map[string]bool{"eng": true, "ops": false},
+		MembersSkipped: map[string]int{"no_github_username": 2, "external_collaborator": 1},
+	})
+	r.ObserveOktaSync(OktaSyncRun{Failed: true})
+	r.ObserveOktaSync(OktaSyncRun{
+		MembersAdded:   1,
+		Rules:          map[string]bool{"eng": false, "ops": false},
+		MembersSkipped: map[string]int{"no_github_username": 3, "external_collaborator": 0},
+	})
+
+	stats := r.Snapshot().OktaSync
+	if stats.Runs != 3 || stats.FailedRuns != 1 {
+		t.Errorf("expected 3 runs with 1 failed, got %+v", stats)
+	}
+	if stats.MembersAdded != 4 || stats.MembersRemoved != 1 || stats.OrphanedUsers != 2 || stats.RulesFailed != 3 {
+		t.Errorf("unexpected totals: %+v", stats)
+	}
+	if stats.LastSyncUnix == 0 {
+		t.Error("expected last sync time to be set")
+	}
+	if !maps.Equal(stats.MembersSkipped, map[string]int64{"no_github_username": 5, "external_collaborator": 1}) {
+		t.Errorf("unexpected skipped totals: %v", stats.MembersSkipped)
+	}
+	if !maps.Equal(stats.LastMembersSkipped, map[string]int64{"no_github_username": 3, "external_collaborator": 0}) {
+		t.Errorf("expected skipped counts of the last run, got %v", stats.LastMembersSkipped)
+	}
+
+	want := []RuleStats{
+		{Rule: "eng", Succeeded: 1, Failed: 1, ConsecutiveFailures: 1},
+		{Rule: "ops", Failed: 2, ConsecutiveFailures: 2},
+	}
+	if len(stats.Rules) != len(want) {
+		t.Fatalf("expected %d rule stats, got %+v", len(want), stats.Rules)
+	}
+	for i := range want {
+		if stats.Rules[i] != want[i] {
+			t.Errorf("rule %d: expected %+v, got %+v", i, want[i], stats.Rules[i])
+		}
+	}
+}
+
+func TestMemoryRecorder_InFlight(t *testing.T) {
+	r := NewMemoryRecorder()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.AddInFlight(1)
+			r.AddInFlight(1)
+			r.AddInFlight(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := r.Snapshot().InFlight; got != 50 {
+		t.Errorf("expected 50 in flight, got %d", got)
+	}
+}
@@ -0,0 +1,166 @@
+package notifiers
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/github/client"
+	"github.com/cruxstack/github-ops-app/internal/okta"
+)
+
+// Notifier delivers notifications for app events to a single sink.
+type Notifier interface {
+	NotifyPRBypass(ctx context.Context, result *client.PRComplianceResult, repoFullName string) error
+	NotifyOktaSync(ctx context.Context, result *okta.SyncResult, githubOrg string) error
+	NotifyOrphanedUsers(ctx context.Context, report *okta.OrphanedUsersReport) error
+	NotifyMissingUsernames(ctx context.Context, report *okta.MissingUsernamesReport) error
+	NotifyStartup(ctx context.Context, info StartupInfo) error
+	NotifyBranchDeletion(ctx context.Context, deletion BranchDeletion) error
+	NotifyDirectPush(ctx context.Context, push DirectPush) error
+}
+
+// BranchDeletion describes the deletion of a monitored branch.
+type BranchDeletion struct {
+	Repo      string
+	Branch    string
+	DeletedBy string
+	DeletedAt time.Time
+	// LastCommit is the branch head before deletion, when known. the branch
+	// can be restored from it.
+	LastCommit string
+}
+
+// DirectPush describes commits pushed straight to a monitored branch rather
+// than merged through a pull request.
+type DirectPush struct {
+	Repo     string
+	Branch   string
+	PushedBy string
+	PushedAt time.Time
+	// Forced is true when the push rewrote the branch history.
+	Forced bool
+	// Before and After are the branch heads before and after the push.
+	Before string
+	After  string
+	// Commits is the number of commits in the push.
+	Commits int
+	// HeadCommitMessage is the first line of the new head commit message.
+	HeadCommitMessage string
+	// CompareURL links to the diff between Before and After.
+	CompareURL string
+}
+
+// StartupInfo describes a running app instance for startup notifications.
+type StartupInfo struct {
+	Version     string
+	Environment string
+	DryRun      bool
+	Features    []FeatureStatus
+}
+
+// FeatureStatus reports whether a named feature is enabled.
+type FeatureStatus struct {
+	Name    string
+	Enabled bool
+}
+
+// HealthChecker is implemented by notifiers that can verify connectivity to
+// their backing service.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// Sink is a named notification destination.
+type Sink struct {
+	Name     string
+	Notifier Notifier
+}
+
+// MultiNotifier fans out notifications to multiple sinks. every sink is
+// attempted even if others fail; failures are logged individually and
+// returned as a combined error.
+type MultiNotifier struct {
+	sinks  []Sink
+	logger *slog.Logger
+}
+
+// NewMultiNotifier creates a notifier that delivers to all given sinks.
+func NewMultiNotifier(logger *slog.Logger, sinks ...Sink) *MultiNotifier {
+	return &MultiNotifier{
+		sinks:  sinks,
+		logger: logger,
+	}
+}
+
+// Sinks returns the configured sinks.
+func (m *MultiNotifier) Sinks() []Sink {
+	return m.sinks
+}
+
+// NotifyPRBypass sends a PR bypass notification to all sinks.
+func (m *MultiNotifier) NotifyPRBypass(ctx context.Context, result *client.PRComplianceResult, repoFullName string) error {
+	return m.fanOut("pr_bypass", func(n Notifier) error {
+		return n.NotifyPRBypass(ctx, result, repoFullName)
+	})
+}
+
+// NotifyOktaSync sends an Okta sync report to all sinks.
+func (m *MultiNotifier) NotifyOktaSync(ctx context.Context, result *okta.SyncResult, githubOrg string) error {
+	return m.fanOut("okta_sync", func(n Notifier) error {
+		return n.NotifyOktaSync(ctx, result, githubOrg)
+	})
+}
+
+// NotifyOrphanedUsers sends an orphaned users report to all sinks.
+func (m *MultiNotifier) NotifyOrphanedUsers(ctx context.Context, report *okta.OrphanedUsersReport) error {
+	return m.fanOut("orphaned_users", func(n Notifier) error {
+		return n.NotifyOrphanedUsers(ctx, report)
+	})
+}
+
+// NotifyMissingUsernames sends a missing GitHub username report to all sinks.
+func (m *MultiNotifier) NotifyMissingUsernames(ctx context.Context, report *okta.MissingUsernamesReport) error {
+	return m.fanOut("missing_usernames", func(n Notifier) error {
+		return n.NotifyMissingUsernames(ctx, report)
+	})
+}
+
+// NotifyStartup sends a startup notification to all sinks.
+func (m *MultiNotifier) NotifyStartup(ctx context.Context, info StartupInfo) error {
+	return m.fanOut("startup", func(n Notifier) error {
+		return n.NotifyStartup(ctx, info)
+	})
+}
+
+// NotifyBranchDeletion sends a branch deletion alert to all sinks.
+func (m *MultiNotifier) NotifyBranchDeletion(ctx context.Context, deletion BranchDeletion) error {
+	return m.fanOut("branch_deletion", func(n Notifier) error {
+		return n.NotifyBranchDeletion(ctx, deletion)
+	})
+}
+
+// NotifyDirectPush sends a direct push alert to all sinks.
+func (m *MultiNotifier) NotifyDirectPush(ctx context.Context, push DirectPush) error {
+	return m.fanOut("direct_push", func(n Notifier) error {
+		return n.NotifyDirectPush(ctx, push)
+	})
+}
+
+// fanOut calls notify for every sink and combines any errors.
+func (m *MultiNotifier) fanOut(notification string, notify func(Notifier) error) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := notify(sink.Notifier); err != nil {
+			if m.logger != nil {
+				m.logger.Warn("notification sink failed",
+					slog.String("sink", sink.Name),
+					slog.String("notification", notification),
+					slog.String("error", err.Error()))
+			}
+			errs = append(errs, errors.Wrapf(err, "sink '%s'", sink.Name))
+		}
+	}
+	return errors.Join(errs...)
+}
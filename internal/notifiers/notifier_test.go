@@ -0,0 +1,88 @@
+package notifiers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/github/client"
+	"github.com/cruxstack/github-ops-app/internal/okta"
+)
+
+// fakeNotifier records calls and returns a fixed error.
+type fakeNotifier struct {
+	calls int
+	err   error
+}
+
+func (f *fakeNotifier) NotifyPRBypass(context.Context, *client.PRComplianceResult, string) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyOktaSync(context.Context, *okta.SyncResult, string) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyOrphanedUsers(context.Context, *okta.OrphanedUsersReport) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyMissingUsernames(context.Context, *okta.MissingUsernamesReport) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyStartup(context.Context, StartupInfo) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyBranchDeletion(context.Context, BranchDeletion) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeNotifier) NotifyDirectPush(context.Context, DirectPush) error {
+	f.calls++
+	return f.err
+}
+
+func TestMultiNotifier_AttemptsAllSinks(t *testing.T) {
+	first := &fakeNotifier{err: errors.New("first broken")}
+	second := &fakeNotifier{}
+	third := &fakeNotifier{err: errors.New("third broken")}
+
+	m := NewMultiNotifier(nil,
+		Sink{Name: "first", Notifier: first},
+		Sink{Name: "second", Notifier: second},
+		Sink{Name: "third", Notifier: third},
+	)
+
+	err := m.NotifyOrphanedUsers(context.Background(), &okta.OrphanedUsersReport{})
+	if err == nil {
+		t.Fatal("expected combined error")
+	}
+
+	for name, n := range map[string]*fakeNotifier{"first": first, "second": second, "third": third} {
+		if n.calls != 1 {
+			t.Errorf("expected sink %q to be called once, got %d", name, n.calls)
+		}
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, "first broken") || !strings.Contains(msg, "third broken") {
+		t.Errorf("expected combined error to include both failures, got %q", msg)
+	}
+}
+
+func TestMultiNotifier_NoErrors(t *testing.T) {
+	m := NewMultiNotifier(nil, Sink{Name: "ok", Notifier: &fakeNotifier{}})
+
+	if err := m.NotifyOktaSync(context.Background(), nil, "org"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 endpoint.
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutyTimeout bounds each Events API request.
+const pagerDutyTimeout = 10 * time.Second
+
+// incident types raised with PagerDuty. each type has its own dedup key, so
+// an open incident is updated rather than duplicated and is resolved
+// independently of the others.
+const (
+	// IncidentOktaSyncFailed is raised when every okta sync rule fails.
+	IncidentOktaSyncFailed = "okta-sync-failed"
+	// IncidentSafetyThresholdBlocked is raised when the safety threshold
+	// blocks removals on several consecutive syncs.
+	IncidentSafetyThresholdBlocked = "okta-sync-safety-threshold"
+)
+
+// Incident describes an on-call alert.
+type Incident struct {
+	// Type identifies the failure and derives the dedup key.
+	Type    string
+	Summary string
+	// Details are shown with the alert in PagerDuty.
+	Details map[string]any
+}
+
+// PagerDutyNotifier triggers and resolves PagerDuty alerts through the
+// Events API v2.
+type PagerDutyNotifier struct {
+	routingKey string
+	source     string
+	eventsURL  string
+	httpClient *http.Client
+}
+
+// NewPagerDutyNotifier creates a notifier sending alerts to the service of
+// routingKey. source names this deployment in alerts and dedup keys, e.g.
+// the GitHub org, so deployments for different orgs do not share incidents.
+func NewPagerDutyNotifier(routingKey, source string) *PagerDutyNotifier {
+	return NewPagerDutyNotifierWithURL(routingKey, source, "")
+}
+
+// NewPagerDutyNotifierWithURL creates a PagerDuty notifier with a custom
+// Events API URL. useful for testing with mock servers.
+func NewPagerDutyNotifierWithURL(routingKey, source, eventsURL string) *PagerDutyNotifier {
+	if eventsURL == "" {
+		eventsURL = DefaultPagerDutyEventsURL
+	}
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		source:     source,
+		eventsURL:  eventsURL,
+		httpClient: &http.Client{Timeout: pagerDutyTimeout},
+	}
+}
+
+// pagerDutyEvent is an Events API v2 request body.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes a triggered alert.
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Component     string         `json:"component"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// Trigger opens a critical alert for incident, or updates the open alert
+// of the same type.
+func (p *PagerDutyNotifier) Trigger(ctx context.Context, incident Incident) error {
+	return p.send(ctx, pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "trigger",
+		DedupKey:    p.dedupKey(incident.Type),
+		Payload: &pagerDutyPayload{
+			Summary:       incident.Summary,
+			Source:        p.source,
+			Severity:      "critical",
+			Component:     "github-ops-app",
+			CustomDetails: incident.Details,
+		},
+	})
+}
+
+// Resolve resolves the open alert of incidentType. resolving a type with no
+// open alert is a no-op in PagerDuty.
+func (p *PagerDutyNotifier) Resolve(ctx context.Context, incidentType string) error {
+	return p.send(ctx, pagerDutyEvent{
+		RoutingKey:  p.routingKey,
+		EventAction: "resolve",
+		DedupKey:    p.dedupKey(incidentType),
+	})
+}
+
+// dedupKey derives the dedup key of an incident type for this deployment.
+func (p *PagerDutyNotifier) dedupKey(incidentType string) string {
+	return "github-ops-app/" + p.source + "/" + incidentType
+}
+
+// send posts event to the Events API.
+func (p *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode pagerduty event")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.eventsURL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to create pagerduty request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to send pagerduty %s event", event.EventAction)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Newf("pagerduty %s event for '%s' failed with status %d: %s",
+			event.EventAction, event.DedupKey, resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
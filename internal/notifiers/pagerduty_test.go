@@ -0,0 +1,82 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newRecordingPagerDutyNotifier returns a notifier whose Events API responds
+// with status and records each event it receives.
+func newRecordingPagerDutyNotifier(t *testing.T, status int) (*PagerDutyNotifier, *[]pagerDutyEvent) {
+	t.Helper()
+
+	var events []pagerDutyEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("failed to decode event: %v", err)
+		}
+		events = append(events, event)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"status":"invalid event"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return NewPagerDutyNotifierWithURL("routing-key", "acme", srv.URL), &events
+}
+
+func TestPagerDutyNotifier_TriggerAndResolve(t *testing.T) {
+	n, events := newRecordingPagerDutyNotifier(t, http.StatusAccepted)
+	ctx := context.Background()
+
+	err := n.Trigger(ctx, Incident{
+		Type:    IncidentOktaSyncFailed,
+		Summary: "okta sync failed for acme",
+		Details: map[string]any{"error": "all sync rules failed: 2 errors"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected trigger error: %v", err)
+	}
+	if err := n.Resolve(ctx, IncidentOktaSyncFailed); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if len(*events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(*events))
+	}
+	trigger, resolve := (*events)[0], (*events)[1]
+
+	wantKey := "github-ops-app/acme/okta-sync-failed"
+	if trigger.EventAction != "trigger" || trigger.DedupKey != wantKey || trigger.RoutingKey != "routing-key" {
+		t.Errorf("unexpected trigger event: %+v", trigger)
+	}
+	if trigger.Payload == nil || trigger.Payload.Severity != "critical" || trigger.Payload.Source != "acme" {
+		t.Errorf("unexpected trigger payload: %+v", trigger.Payload)
+	}
+	if resolve.EventAction != "resolve" || resolve.DedupKey != wantKey || resolve.Payload != nil {
+		t.Errorf("unexpected resolve event: %+v", resolve)
+	}
+}
+
+func TestPagerDutyNotifier_DedupKeyPerType(t *testing.T) {
+	n := NewPagerDutyNotifier("routing-key", "acme")
+	if n.dedupKey(IncidentOktaSyncFailed) == n.dedupKey(IncidentSafetyThresholdBlocked) {
+		t.Error("expected incident types to have distinct dedup keys")
+	}
+}
+
+func TestPagerDutyNotifier_RejectedEvent(t *testing.T) {
+	n, _ := newRecordingPagerDutyNotifier(t, http.StatusBadRequest)
+
+	err := n.Resolve(context.Background(), IncidentOktaSyncFailed)
+	if err == nil {
+		t.Fatal("expected error for rejected event")
+	}
+	if !strings.Contains(err.Error(), "400") || !strings.Contains(err.Error(), "invalid event") {
+		t.Errorf("expected status and body in error, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package notifiers
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/slack-go/slack"
+)
+
+// slackBaseBackoff is the first backoff used after a transient server
+// error. doubled on each retry, plus up to 50% jitter.
+const slackBaseBackoff = 500 * time.Millisecond
+
+// SetRetry configures how posts that fail with a Slack rate limit or a
+// transient server error are retried. each post makes at most maxAttempts
+// attempts and gives up once timeout has elapsed. a maxAttempts of 1
+// disables retries and a timeout of zero removes the time bound.
+func (s *SlackNotifier) SetRetry(maxAttempts int, timeout time.Duration) {
+	s.retryMaxAttempts = max(maxAttempts, 1)
+	s.retryTimeout = max(timeout, 0)
+}
+
+// postMessage posts a message to channel, retrying as configured by
+// SetRetry. returns the channel and timestamp of the posted message.
+func (s *SlackNotifier) postMessage(ctx context.Context, channel string, options ...slack.MsgOption) (string, string, error) {
+	if s.retryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.retryTimeout)
+		defer cancel()
+	}
+
+	for attempt := 1; ; attempt++ {
+		respChannel, ts, err := s.client.PostMessageContext(ctx, channel, options...)
+		if err == nil || attempt >= s.retryMaxAttempts {
+			return respChannel, ts, attemptsError(err, attempt)
+		}
+
+		wait, ok := s.slackRetryWait(err, attempt)
+		if !ok {
+			return respChannel, ts, attemptsError(err, attempt)
+		}
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < wait {
+			return respChannel, ts, attemptsError(err, attempt)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return respChannel, ts, errors.WithSecondaryError(
+				errors.Wrap(ctx.Err(), "canceled while waiting to retry slack post"), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// slackRetryWait returns how long to wait before retrying a post that failed
+// with err, or false if err is not retryable. rate limits honor Retry-After
+// and 5xx responses use exponential backoff with jitter.
+func (s *SlackNotifier) slackRetryWait(err error, attempt int) (time.Duration, bool) {
+	var rateErr *slack.RateLimitedError
+	if errors.As(err, &rateErr) {
+		return rateErr.RetryAfter, true
+	}
+
+	var statusErr slack.StatusCodeError
+	if errors.As(err, &statusErr) && statusErr.Code >= http.StatusInternalServerError {
+		backoff := s.retryBaseBackoff << min(attempt-1, 16)
+		return backoff + rand.N(backoff/2+1), true
+	}
+
+	return 0, false
+}
+
+// attemptsError annotates err with the number of attempts made when the
+// post was retried.
+func attemptsError(err error, attempts int) error {
+	if err == nil || attempts < 2 {
+		return err
+	}
+	return errors.Wrapf(err, "slack post failed after %d attempts", attempts)
+}
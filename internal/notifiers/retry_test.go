@@ -0,0 +1,97 @@
+package notifiers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newScriptedSlackNotifier returns a notifier whose API responds to each
+// post with the next status code in statuses, then with success.
+func newScriptedSlackNotifier(t *testing.T, statuses ...int) (*SlackNotifier, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(statuses) {
+			switch status := statuses[n-1]; status {
+			case http.StatusTooManyRequests:
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+			case http.StatusOK:
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"ok":false,"error":"channel_not_found"}`)
+			default:
+				w.WriteHeader(status)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"1.0"}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1"}, SlackMessages{}, srv.URL+"/")
+	n.retryBaseBackoff = time.Millisecond
+	return n, &calls
+}
+
+func TestSlackNotifier_PostMessageRetry(t *testing.T) {
+	t.Run("retries rate limits and server errors", func(t *testing.T) {
+		n, calls := newScriptedSlackNotifier(t, http.StatusTooManyRequests, http.StatusServiceUnavailable)
+		_, ts, err := n.postMessage(context.Background(), "C1")
+		if err != nil || ts != "1.0" {
+			t.Fatalf("expected success after retries, got ts %q and error %v", ts, err)
+		}
+		if got := calls.Load(); got != 3 {
+			t.Errorf("expected 3 attempts, got %d", got)
+		}
+	})
+
+	t.Run("returns the final error when attempts are exhausted", func(t *testing.T) {
+		n, calls := newScriptedSlackNotifier(t, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway)
+		n.SetRetry(2, time.Minute)
+		_, _, err := n.postMessage(context.Background(), "C1")
+		if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+			t.Errorf("expected error after 2 attempts, got %v", err)
+		}
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected 2 attempts, got %d", got)
+		}
+	})
+
+	t.Run("does not retry api errors", func(t *testing.T) {
+		n, calls := newScriptedSlackNotifier(t, http.StatusOK)
+		if _, _, err := n.postMessage(context.Background(), "C1"); err == nil {
+			t.Error("expected error")
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected 1 attempt, got %d", got)
+		}
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		n, calls := newScriptedSlackNotifier(t, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+		n.retryBaseBackoff = time.Hour
+		n.SetRetry(4, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if _, _, err := n.postMessage(ctx, "C1"); err == nil {
+			t.Error("expected error")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("expected to stop promptly, took %s", elapsed)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected 1 attempt, got %d", got)
+		}
+	})
+}
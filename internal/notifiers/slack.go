@@ -1,7 +1,18 @@
-// Package notifiers provides Slack notification formatting and delivery.
+// Package notifiers provides Slack and PagerDuty notification formatting and
+// delivery.
 package notifiers
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	"github.com/slack-go/slack"
 )
 
@@ -12,12 +23,45 @@ type SlackChannels struct {
 	PRBypass      string
 	OktaSync      string
 	OrphanedUsers string
+	Startup       string
+	// PRBypassRepos maps lowercase repository full names (owner/repo) to the
+	// channel for that repository's PR bypass alerts. repositories without an
+	// entry use PRBypass.
+	PRBypassRepos map[string]string
 }
 
 // SlackMessages holds optional custom messages for different notification
 // types. empty values are excluded from the notification.
 type SlackMessages struct {
 	PRBypassFooterNote string
+	// Remediations overrides DefaultRemediations by violation type. an empty
+	// value hides the remediation for that type.
+	Remediations map[string]string
+	// OrphanedUsersLimit caps how many orphaned users, and users missing a
+	// GitHub username, are listed inline. the full list is uploaded as a file
+	// in the message thread. 0 lists all.
+	OrphanedUsersLimit int
+}
+
+// DefaultRemediations maps built-in violation types to short "how to fix"
+// guidance shown in PR bypass notifications.
+var DefaultRemediations = map[string]string{
+	types.ViolationInsufficientReviews:       "Get the change reviewed after the fact. To prevent this, limit who can bypass required reviews in Settings → Branches or Rules.",
+	types.ViolationMissingTeamReview:         "Ask the required team to review the change. Adding the team as a code owner requests its review automatically.",
+	types.ViolationMissingStatusCheck:        "Re-run the failed checks on the merged commit and fix any failures. Make sure the check is required in Settings → Branches or Rules.",
+	types.ViolationUnsignedCommits:           "Confirm the listed commits came from their stated authors and ask them to set up commit signing. Make sure signed commits are required in Settings → Branches or Rules.",
+	types.ViolationSelfApproval:              "Have someone other than the author review the change. Check that branch protection or rulesets do not let authors approve their own PRs.",
+	types.ViolationMergedWhileDraft:          "Review the change as if it were unreviewed and ask the merger why a draft was merged. Limit who can bypass branch protection or rulesets.",
+	types.ViolationMissingRequiredDeployment: "Deploy the merged commit to the listed environments and check it there. Limit who can bypass the required deployments rule in Settings → Rules.",
+}
+
+// remediationFor returns the remediation guidance for a violation type, or
+// an empty string if none is configured.
+func (s *SlackNotifier) remediationFor(violationType string) string {
+	if text, ok := s.messages.Remediations[violationType]; ok {
+		return text
+	}
+	return DefaultRemediations[violationType]
 }
 
 // SlackNotifier sends formatted messages to Slack channels.
@@ -25,6 +69,33 @@ type SlackNotifier struct {
 	client   *slack.Client
 	channels SlackChannels
 	messages SlackMessages
+
+	// threadSync posts follow-up messages of a sync run as replies to the
+	// sync summary.
+	threadSync bool
+
+	// threadSyncDaily posts sync summaries as replies to one parent message
+	// per channel and day. parents are tracked in memory by channel.
+	threadSyncDaily bool
+	dailyMu         sync.Mutex
+	dailyThreads    map[string]dailySyncThread
+	now             func() time.Time
+
+	// githubURL is the web URL that GitHub links in messages point to.
+	githubURL string
+
+	logger *slog.Logger
+
+	// retry settings for posts that hit a rate limit or server error.
+	retryMaxAttempts int
+	retryTimeout     time.Duration
+	retryBaseBackoff time.Duration
+}
+
+// dailySyncThread is the parent message of a day's sync summaries.
+type dailySyncThread struct {
+	date string
+	ts   string
 }
 
 // NewSlackNotifier creates a Slack notifier with default API URL.
@@ -43,7 +114,170 @@ func NewSlackNotifierWithAPIURL(token string, channels SlackChannels, messages S
 		client:   slack.New(token, opts...),
 		channels: channels,
 		messages: messages,
+		now:      time.Now,
+
+		githubURL: defaultGitHubURL,
+		logger:    slog.New(slog.DiscardHandler),
+
+		retryMaxAttempts: types.DefaultSlackMaxAttempts,
+		retryTimeout:     types.DefaultSlackRetryTimeout,
+		retryBaseBackoff: slackBaseBackoff,
+	}
+}
+
+// defaultGitHubURL is the web URL of github.com.
+const defaultGitHubURL = "https://github.com"
+
+// SetGitHubBaseURL points GitHub links in messages at the web UI of the
+// GitHub instance whose API is served at baseURL, e.g. a GitHub Enterprise
+// Server. an empty baseURL keeps github.com.
+func (s *SlackNotifier) SetGitHubBaseURL(baseURL string) {
+	s.githubURL = githubWebURL(baseURL)
+}
+
+// githubWebURL derives the web URL of a GitHub instance from its API base
+// URL. GitHub Enterprise Server serves the API under /api/v3 on the web
+// host, while github.com and GitHub Enterprise Cloud serve it from an api.
+// subdomain.
+func githubWebURL(baseURL string) string {
+	if baseURL == "" {
+		return defaultGitHubURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil || u.Host == "" {
+		return defaultGitHubURL
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/api/v3")
+	u.Host = strings.TrimPrefix(u.Host, "api.")
+	u.RawQuery, u.Fragment = "", ""
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// githubLink returns the GitHub web URL for path, e.g. "acme/repo".
+func (s *SlackNotifier) githubLink(path string) string {
+	return s.githubURL + "/" + path
+}
+
+// SetLogger sets the logger used to report failures that do not fail the
+// notification, such as a daily sync thread that could not be started.
+func (s *SlackNotifier) SetLogger(logger *slog.Logger) {
+	s.logger = logger
+}
+
+// SetThreadSync enables grouping the messages of a sync run into a thread.
+// the sync summary is posted first, and rule failures and orphaned users
+// sent with the same WithSyncThread context are posted as replies to it.
+func (s *SlackNotifier) SetThreadSync(enabled bool) {
+	s.threadSync = enabled
+}
+
+// SetThreadSyncDaily enables posting each sync summary as a reply to a
+// parent message for the current UTC day, so frequent scheduled syncs do
+// not flood the channel. the parent is posted by the first sync of the day;
+// if that post fails, the summary is posted unthreaded and the next sync
+// tries again.
+func (s *SlackNotifier) SetThreadSyncDaily(enabled bool) {
+	s.threadSyncDaily = enabled
+}
+
+// dailySyncParent returns the ts of today's sync parent message in channel,
+// posting it if this is the first sync of the day. returns an empty ts when
+// daily threads are disabled.
+func (s *SlackNotifier) dailySyncParent(ctx context.Context, channel string) (string, error) {
+	if !s.threadSyncDaily {
+		return "", nil
+	}
+
+	s.dailyMu.Lock()
+	defer s.dailyMu.Unlock()
+
+	date := s.now().UTC().Format(time.DateOnly)
+	if thread, ok := s.dailyThreads[channel]; ok && thread.date == date {
+		return thread.ts, nil
+	}
+
+	text := fmt.Sprintf("Okta sync runs for %s", date)
+	_, ts, err := s.postMessage(
+		ctx,
+		channel,
+		slack.MsgOptionBlocks(slack.NewHeaderBlock(slack.NewTextBlockObject("plain_text", text, false, false))),
+		slack.MsgOptionText(text, false),
+	)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to post daily okta sync thread to slack")
+	}
+
+	if s.dailyThreads == nil {
+		s.dailyThreads = make(map[string]dailySyncThread)
+	}
+	s.dailyThreads[channel] = dailySyncThread{date: date, ts: ts}
+	return ts, nil
+}
+
+// syncThreadKey is the context key for the Slack thread of a sync run.
+type syncThreadKey struct{}
+
+// syncThread records the sync summary message that later messages of the
+// same run reply to.
+type syncThread struct {
+	mu      sync.Mutex
+	channel string
+	ts      string
+}
+
+// WithSyncThread returns a context that groups the notifications of one
+// sync run. only notifiers with thread grouping enabled use it.
+func WithSyncThread(ctx context.Context) context.Context {
+	return context.WithValue(ctx, syncThreadKey{}, &syncThread{})
+}
+
+// syncThreadFrom returns the sync run thread from ctx if thread grouping is
+// enabled.
+func (s *SlackNotifier) syncThreadFrom(ctx context.Context) *syncThread {
+	if !s.threadSync {
+		return nil
 	}
+	thread, _ := ctx.Value(syncThreadKey{}).(*syncThread)
+	return thread
+}
+
+// startSyncThread records the message at ts in channel as the parent of
+// the sync run thread in ctx.
+func (s *SlackNotifier) startSyncThread(ctx context.Context, channel, ts string) {
+	thread := s.syncThreadFrom(ctx)
+	if thread == nil {
+		return
+	}
+	thread.mu.Lock()
+	defer thread.mu.Unlock()
+	thread.channel = channel
+	thread.ts = ts
+}
+
+// syncThreadOptions returns the message options that post a message in
+// channel as a reply to the sync run thread in ctx. slack threads cannot
+// span channels, so messages for a different channel are not threaded.
+func (s *SlackNotifier) syncThreadOptions(ctx context.Context, channel string) []slack.MsgOption {
+	ts := s.syncThreadTS(ctx, channel)
+	if ts == "" {
+		return nil
+	}
+	return []slack.MsgOption{slack.MsgOptionTS(ts)}
+}
+
+// syncThreadTS returns the ts of the sync run thread in ctx if it is in
+// channel, or an empty string.
+func (s *SlackNotifier) syncThreadTS(ctx context.Context, channel string) string {
+	thread := s.syncThreadFrom(ctx)
+	if thread == nil {
+		return ""
+	}
+	thread.mu.Lock()
+	defer thread.mu.Unlock()
+	if thread.channel != channel {
+		return ""
+	}
+	return thread.ts
 }
 
 // channelFor returns the channel for a notification type, falling back to
@@ -54,3 +288,20 @@ func (s *SlackNotifier) channelFor(typeChannel string) string {
 	}
 	return s.channels.Default
 }
+
+// prBypassChannelFor returns the channel for a repository's PR bypass
+// alerts, falling back to the PR bypass and then the default channel.
+func (s *SlackNotifier) prBypassChannelFor(repoFullName string) string {
+	if channel := s.channels.PRBypassRepos[strings.ToLower(repoFullName)]; channel != "" {
+		return channel
+	}
+	return s.channelFor(s.channels.PRBypass)
+}
+
+// CheckHealth verifies the Slack token by calling auth.test.
+func (s *SlackNotifier) CheckHealth(ctx context.Context) error {
+	if _, err := s.client.AuthTestContext(ctx); err != nil {
+		return errors.Wrap(err, "slack auth.test failed")
+	}
+	return nil
+}
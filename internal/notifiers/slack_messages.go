@@ -1,13 +1,18 @@
 package notifiers
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/cockroachdb/errors"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/client"
 	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	"github.com/slack-go/slack"
 )
 
@@ -32,13 +37,23 @@ func (s *SlackNotifier) NotifyPRBypass(ctx context.Context, result *client.PRCom
 	if result.PR.Number != nil {
 		prNumber = *result.PR.Number
 	}
-	if result.PR.MergedBy != nil && result.PR.MergedBy.Login != nil {
-		mergedBy = *result.PR.MergedBy.Login
+	if login := result.MergedByLogin(); login != "" {
+		mergedBy = login
+	}
+	if result.MergeQueue {
+		mergedBy += " via merge queue"
+	}
+
+	if result.MergedByCommitAuthor {
+		mergedBy += " (merge commit author)"
 	}
 
 	// build merged by line with optional bypass reason
 	mergedByText := fmt.Sprintf("Merged by %s", mergedBy)
-	if result.UserHasBypass {
+	switch {
+	case result.MergedByUnknown:
+		mergedByText = "⚠️ Merged by an unknown actor, e.g. an automation token or a deleted user. Check the audit log to see who merged it."
+	case result.UserHasBypass:
 		mergedByText = fmt.Sprintf("Merged by %s (%s)", mergedBy, result.UserBypassReason)
 	}
 
@@ -60,6 +75,9 @@ func (s *SlackNotifier) NotifyPRBypass(ctx context.Context, result *client.PRCom
 		violationText := "*Violations:*\n"
 		for _, v := range result.Violations {
 			violationText += fmt.Sprintf("• %s\n", v.Description)
+			if remediation := s.remediationFor(v.Type); remediation != "" {
+				violationText += fmt.Sprintf("    _How to fix: %s_\n", remediation)
+			}
 		}
 		blocks = append(blocks, slack.NewSectionBlock(
 			slack.NewTextBlockObject("mrkdwn", violationText, false, false),
@@ -74,8 +92,8 @@ func (s *SlackNotifier) NotifyPRBypass(ctx context.Context, result *client.PRCom
 		))
 	}
 
-	channel := s.channelFor(s.channels.PRBypass)
-	_, _, err := s.client.PostMessageContext(
+	channel := s.prBypassChannelFor(repoFullName)
+	_, _, err := s.postMessage(
 		ctx,
 		channel,
 		slack.MsgOptionBlocks(blocks...),
@@ -89,9 +107,16 @@ func (s *SlackNotifier) NotifyPRBypass(ctx context.Context, result *client.PRCom
 	return nil
 }
 
-// NotifyOktaSync sends a Slack notification with Okta sync results.
-func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.SyncReport, githubOrg string) error {
-	if len(reports) == 0 {
+// NotifyOktaSync sends a Slack notification with Okta sync results. the
+// result's disabled rules are listed as skipped, so callers clear them when
+// they should not be reported.
+func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, result *okta.SyncResult, githubOrg string) error {
+	if result == nil {
+		return nil
+	}
+	reports, disabledRules, emptyRules := result.Reports, result.DisabledRules, result.EmptyRules
+	skipped, quotas := result.Skipped, result.APIQuotas
+	if len(reports) == 0 && len(disabledRules) == 0 && len(emptyRules) == 0 {
 		return nil
 	}
 
@@ -99,7 +124,8 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 	var totalAdded, totalRemoved int
 	var rulesWithChanges, rulesWithoutChanges []*okta.SyncReport
 	var allErrors []string
-	var allSkippedExternal, allSkippedNoGHUsername []string
+	var allSkippedExternal, allSkippedNoGHUsername, allSkippedNotOrgMember []string
+	var allPreserved, allForceIncluded, allPending []string
 
 	for _, report := range reports {
 		totalAdded += len(report.MembersAdded)
@@ -119,11 +145,26 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 
 		allSkippedExternal = append(allSkippedExternal, report.MembersSkippedExternal...)
 		allSkippedNoGHUsername = append(allSkippedNoGHUsername, report.MembersSkippedNoGHUsername...)
+		allSkippedNotOrgMember = append(allSkippedNotOrgMember, report.MembersSkippedNotOrgMember...)
+		for _, member := range report.MembersPreserved {
+			allPreserved = append(allPreserved, fmt.Sprintf("%s (%s)", member, report.GitHubTeam))
+		}
+		for _, member := range report.MembersForceIncluded {
+			allForceIncluded = append(allForceIncluded, fmt.Sprintf("%s (%s)", member, report.GitHubTeam))
+		}
+		for _, member := range report.MembersPending {
+			allPending = append(allPending, fmt.Sprintf("%s (%s)", member, report.GitHubTeam))
+		}
+	}
+
+	header := "Okta GitHub Team Sync Complete"
+	if allDryRun(reports) {
+		header = "🧪 DRY RUN — no changes applied"
 	}
 
 	blocks := []slack.Block{
 		slack.NewHeaderBlock(
-			slack.NewTextBlockObject("plain_text", "Okta GitHub Team Sync Complete", false, false),
+			slack.NewTextBlockObject("plain_text", header, false, false),
 		),
 	}
 
@@ -139,9 +180,19 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 	blocks = append(blocks, slack.NewSectionBlock(nil, rulesProcessedFields, nil))
 	blocks = append(blocks, slack.NewSectionBlock(nil, memberChangesFields, nil))
 
+	// rules that silently synced nothing, shown near the top so a reorg that
+	// broke group patterns is noticed
+	if len(emptyRules) > 0 {
+		blocks = append(blocks, slack.NewDividerBlock())
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", formatEmptyRules(emptyRules), false, false),
+			nil, nil,
+		))
+	}
+
 	// helper to build team URL
 	teamURL := func(teamSlug string) string {
-		return fmt.Sprintf("https://github.com/orgs/%s/teams/%s", githubOrg, teamSlug)
+		return s.githubLink(fmt.Sprintf("orgs/%s/teams/%s", githubOrg, teamSlug))
 	}
 
 	// list of rules with changes
@@ -150,11 +201,21 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 
 		changesText := "*Rules With Changes*\n"
 		for _, report := range rulesWithChanges {
-			changesText += fmt.Sprintf("- <%s|%s> (+%d, -%d)\n",
+			dryRunNote := ""
+			if report.DryRun {
+				dryRunNote = " _(dry run, not applied)_"
+			}
+			pendingNote := ""
+			if len(report.MembersPending) > 0 {
+				pendingNote = fmt.Sprintf(", %d pending", len(report.MembersPending))
+			}
+			changesText += fmt.Sprintf("- <%s|%s> (+%d, -%d%s)%s\n",
 				teamURL(report.GitHubTeam),
 				report.GitHubTeam,
 				len(report.MembersAdded),
-				len(report.MembersRemoved))
+				len(report.MembersRemoved),
+				pendingNote,
+				dryRunNote)
 		}
 
 		blocks = append(blocks, slack.NewSectionBlock(
@@ -163,6 +224,29 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 		))
 	}
 
+	// trend vs the previous sync for teams with changes
+	var trendText string
+	for _, report := range rulesWithChanges {
+		if report.SincePrevious == nil {
+			continue
+		}
+		trendText += fmt.Sprintf("- %s: net %+d since last sync (%d new, %d churned)\n",
+			report.GitHubTeam,
+			report.SincePrevious.NetChange,
+			len(report.SincePrevious.NewlyAdded),
+			len(report.SincePrevious.Churned))
+		if len(report.SincePrevious.Churned) > 0 {
+			trendText += fmt.Sprintf("  _churned: %s_\n", strings.Join(report.SincePrevious.Churned, ", "))
+		}
+	}
+	if trendText != "" {
+		blocks = append(blocks, slack.NewDividerBlock())
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", "*Changes Since Last Sync*\n"+trendText, false, false),
+			nil, nil,
+		))
+	}
+
 	// list of rules without changes
 	if len(rulesWithoutChanges) > 0 {
 		blocks = append(blocks, slack.NewDividerBlock())
@@ -178,26 +262,48 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 		))
 	}
 
-	// errors section
-	if len(allErrors) > 0 {
+	// disabled rules section
+	if len(disabledRules) > 0 {
 		blocks = append(blocks, slack.NewDividerBlock())
 
-		errorsText := "*Errors*\n"
+		disabledText := "*Rules Skipped (Disabled)*\n"
+		for _, rule := range disabledRules {
+			disabledText += fmt.Sprintf("- %s\n", rule)
+		}
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", disabledText, false, false),
+			nil, nil,
+		))
+	}
+
+	// errors section. when the run is threaded, rule failures are posted as
+	// a reply instead
+	errorsText := ""
+	if len(allErrors) > 0 {
+		errorsText = "*Errors*\n"
 		for _, err := range allErrors {
 			errorsText += fmt.Sprintf("- %s\n", err)
 		}
-
+	}
+	threaded := s.syncThreadFrom(ctx) != nil
+	if errorsText != "" && !threaded {
+		blocks = append(blocks, slack.NewDividerBlock())
 		blocks = append(blocks, slack.NewSectionBlock(
 			slack.NewTextBlockObject("mrkdwn", errorsText, false, false),
 			nil, nil,
 		))
 	}
 
-	// skipped members section
-	if len(allSkippedExternal) > 0 || len(allSkippedNoGHUsername) > 0 {
+	// skipped members section, led by the per-reason counts and their trend
+	skipCountsText := formatSkipCounts(skipped)
+	if skipCountsText != "" || len(allSkippedExternal) > 0 || len(allSkippedNoGHUsername) > 0 || len(allSkippedNotOrgMember) > 0 {
 		blocks = append(blocks, slack.NewDividerBlock())
 
 		skippedText := "*Skipped Members*\n"
+		if skipCountsText != "" {
+			skippedText += skipCountsText + "\n"
+		}
 
 		if len(allSkippedExternal) > 0 {
 			skippedText += "_External Collaborators_\n"
@@ -216,27 +322,142 @@ func (s *SlackNotifier) NotifyOktaSync(ctx context.Context, reports []*okta.Sync
 			}
 		}
 
+		if len(allSkippedNotOrgMember) > 0 {
+			if len(allSkippedExternal) > 0 || len(allSkippedNoGHUsername) > 0 {
+				skippedText += "\n"
+			}
+			skippedText += "_Not Organization Members_\n"
+			for _, member := range allSkippedNotOrgMember {
+				skippedText += fmt.Sprintf("- %s\n", member)
+			}
+		}
+
 		blocks = append(blocks, slack.NewSectionBlock(
 			slack.NewTextBlockObject("mrkdwn", skippedText, false, false),
 			nil, nil,
 		))
 	}
 
+	// members kept by preserve_members patterns
+	if len(allPreserved) > 0 {
+		blocks = append(blocks, slack.NewDividerBlock())
+
+		preservedText := "*Preserved Members (Not In Okta)*\n"
+		for _, member := range allPreserved {
+			preservedText += fmt.Sprintf("- %s\n", member)
+		}
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", preservedText, false, false),
+			nil, nil,
+		))
+	}
+
+	// members added by always_include_members lists
+	if len(allForceIncluded) > 0 {
+		blocks = append(blocks, slack.NewDividerBlock())
+
+		forcedText := "*Always Included Members (Not In Okta)*\n"
+		for _, member := range allForceIncluded {
+			forcedText += fmt.Sprintf("- %s\n", member)
+		}
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", forcedText, false, false),
+			nil, nil,
+		))
+	}
+
+	// adds that are not active members yet
+	if len(allPending) > 0 {
+		blocks = append(blocks, slack.NewDividerBlock())
+
+		pendingText := "*Pending Members (Invitation Not Accepted)*\n"
+		for _, member := range allPending {
+			pendingText += fmt.Sprintf("- %s\n", member)
+		}
+
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", pendingText, false, false),
+			nil, nil,
+		))
+	}
+
+	// api quota remaining after the sync
+	if len(quotas) > 0 {
+		blocks = append(blocks, slack.NewContextBlock("", quotaTextObjects(quotas)...))
+	}
+
 	channel := s.channelFor(s.channels.OktaSync)
-	_, _, err := s.client.PostMessageContext(
-		ctx,
-		channel,
+	parentTS, err := s.dailySyncParent(ctx, channel)
+	if err != nil {
+		s.logger.Warn("failed to start daily okta sync thread, posting unthreaded",
+			slog.String("channel", channel),
+			slog.String("error", err.Error()))
+	}
+
+	msgOpts := []slack.MsgOption{
 		slack.MsgOptionBlocks(blocks...),
 		slack.MsgOptionText(fmt.Sprintf("okta sync: %d rules, +%d/-%d members", len(reports), totalAdded, totalRemoved), false),
-	)
+	}
+	if parentTS != "" {
+		msgOpts = append(msgOpts, slack.MsgOptionTS(parentTS))
+	}
+	_, ts, err := s.postMessage(ctx, channel, msgOpts...)
 
 	if err != nil {
 		return errors.Wrap(err, "failed to post okta sync notification to slack")
 	}
 
+	if !threaded {
+		return nil
+	}
+
+	// slack threads are one level deep, so follow-ups to a summary posted in
+	// the daily thread are posted in the daily thread too
+	threadTS := cmp.Or(parentTS, ts)
+	s.startSyncThread(ctx, channel, threadTS)
+
+	if errorsText != "" {
+		_, _, err := s.postMessage(
+			ctx,
+			channel,
+			slack.MsgOptionTS(threadTS),
+			slack.MsgOptionBlocks(slack.NewSectionBlock(
+				slack.NewTextBlockObject("mrkdwn", errorsText, false, false),
+				nil, nil,
+			)),
+			slack.MsgOptionText(fmt.Sprintf("okta sync errors: %d", len(allErrors)), false),
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to post okta sync errors to slack thread")
+		}
+	}
+
 	return nil
 }
 
+// quotaTextObjects formats API quotas for a context block, flagging quotas
+// that are running low.
+func quotaTextObjects(quotas []types.APIQuota) []slack.MixedElement {
+	elements := make([]slack.MixedElement, 0, len(quotas))
+	for _, q := range quotas {
+		service := q.Service
+		switch service {
+		case "github":
+			service = "GitHub"
+		case "okta":
+			service = "Okta"
+		}
+		text := fmt.Sprintf("%s %s", service, q)
+		if q.IsLow() {
+			text = "⚠️ *" + text + "*"
+		}
+		elements = append(elements, slack.NewTextBlockObject("mrkdwn", text, false, false))
+	}
+	return elements
+}
+
 // NotifyOrphanedUsers sends a Slack notification about organization members
 // not in any synced teams.
 func (s *SlackNotifier) NotifyOrphanedUsers(ctx context.Context, report *okta.OrphanedUsersReport) error {
@@ -256,10 +477,19 @@ func (s *SlackNotifier) NotifyOrphanedUsers(ctx context.Context, report *okta.Or
 		),
 	}
 
+	shown := report.OrphanedUsers
+	if limit := s.messages.OrphanedUsersLimit; limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	hidden := len(report.OrphanedUsers) - len(shown)
+
 	userList := ""
-	for _, user := range report.OrphanedUsers {
+	for _, user := range shown {
 		userList += fmt.Sprintf("• `%s`\n", user)
 	}
+	if hidden > 0 {
+		userList += fmt.Sprintf("_... and %d more (full list in thread)_\n", hidden)
+	}
 
 	blocks = append(blocks, slack.NewSectionBlock(
 		slack.NewTextBlockObject("mrkdwn", userList, false, false),
@@ -272,16 +502,381 @@ func (s *SlackNotifier) NotifyOrphanedUsers(ctx context.Context, report *okta.Or
 	))
 
 	channel := s.channelFor(s.channels.OrphanedUsers)
-	_, _, err := s.client.PostMessageContext(
+	threadTS := s.syncThreadTS(ctx, channel)
+	opts := []slack.MsgOption{
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(fmt.Sprintf("orphaned github users detected: %d users", len(report.OrphanedUsers)), false),
+	}
+	if threadTS != "" {
+		opts = append(opts, slack.MsgOptionTS(threadTS))
+	}
+	_, ts, err := s.postMessage(ctx, channel, opts...)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to post orphaned users notification to slack")
+	}
+
+	if hidden == 0 {
+		return nil
+	}
+
+	// the message is already posted, so a failed upload only loses the
+	// attachment
+	content := strings.Join(report.OrphanedUsers, "\n") + "\n"
+	err = s.uploadListFile(ctx, channel, cmp.Or(threadTS, ts), "orphaned-users.txt",
+		fmt.Sprintf("Orphaned GitHub users (%d)", len(report.OrphanedUsers)), content)
+	if err != nil {
+		return errors.Wrap(err, "orphaned users notification was posted but the full list could not be uploaded to slack")
+	}
+
+	return nil
+}
+
+// uploadListFile uploads the full list behind a truncated inline list as a
+// file in the message's thread.
+func (s *SlackNotifier) uploadListFile(ctx context.Context, channel, threadTS, filename, title, content string) error {
+	_, err := s.client.UploadFileV2Context(ctx, slack.UploadFileV2Parameters{
+		Channel:         channel,
+		ThreadTimestamp: threadTS,
+		Content:         content,
+		FileSize:        len(content),
+		Filename:        filename,
+		Title:           title,
+	})
+	return err
+}
+
+// NotifyMissingUsernames sends a Slack notification listing active Okta
+// users in synced groups whose profile has no GitHub username.
+func (s *SlackNotifier) NotifyMissingUsernames(ctx context.Context, report *okta.MissingUsernamesReport) error {
+	if report == nil || len(report.Users) == 0 {
+		return nil
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject("plain_text", "📇 Okta Users Missing a GitHub Username", false, false),
+		),
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn",
+				fmt.Sprintf("Found *%d* active Okta user(s) in synced groups without a GitHub username. They are skipped by every sync:", len(report.Users)),
+				false, false),
+			nil, nil,
+		),
+	}
+
+	shown := report.Users
+	if limit := s.messages.OrphanedUsersLimit; limit > 0 && len(shown) > limit {
+		shown = shown[:limit]
+	}
+	hidden := len(report.Users) - len(shown)
+
+	userList := ""
+	for _, user := range shown {
+		userList += fmt.Sprintf("• %s (%s)\n", user.Email, strings.Join(user.Groups, ", "))
+	}
+	if hidden > 0 {
+		userList += fmt.Sprintf("_... and %d more (full list in thread)_\n", hidden)
+	}
+
+	blocks = append(blocks,
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", userList, false, false),
+			nil, nil,
+		),
+		slack.NewContextBlock(
+			"context",
+			slack.NewTextBlockObject("mrkdwn", "_Ask these users to add their GitHub username to their Okta profile._", false, false),
+		),
+	)
+
+	channel := s.channelFor(s.channels.OktaSync)
+	_, ts, err := s.postMessage(ctx, channel,
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(fmt.Sprintf("okta users missing a github username: %d users", len(report.Users)), false),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to post missing github username notification to slack")
+	}
+
+	if hidden == 0 {
+		return nil
+	}
+
+	var content strings.Builder
+	for _, user := range report.Users {
+		fmt.Fprintf(&content, "%s\t%s\n", user.Email, strings.Join(user.Groups, ","))
+	}
+	err = s.uploadListFile(ctx, channel, ts, "missing-github-usernames.tsv",
+		fmt.Sprintf("Okta users missing a GitHub username (%d)", len(report.Users)), content.String())
+	if err != nil {
+		return errors.Wrap(err, "missing github username notification was posted but the full list could not be uploaded to slack")
+	}
+
+	return nil
+}
+
+// NotifyStartup sends a Slack notification confirming the app started, with
+// its version, environment, and enabled features.
+func (s *SlackNotifier) NotifyStartup(ctx context.Context, info StartupInfo) error {
+	version := info.Version
+	if version == "" {
+		version = "unknown"
+	}
+
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Version:*\n`%s`", version), false, false),
+	}
+	if info.Environment != "" {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Environment:*\n%s", info.Environment), false, false))
+	}
+	if info.DryRun {
+		fields = append(fields, slack.NewTextBlockObject("mrkdwn", "*Mode:*\nDry run", false, false))
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject("plain_text", "🚀 GitHub Ops App Started", false, false),
+		),
+		slack.NewSectionBlock(nil, fields, nil),
+	}
+
+	if len(info.Features) > 0 {
+		featureList := ""
+		for _, feature := range info.Features {
+			icon := "❌"
+			if feature.Enabled {
+				icon = "✅"
+			}
+			featureList += fmt.Sprintf("%s %s\n", icon, feature.Name)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", "*Features*\n"+featureList, false, false),
+			nil, nil,
+		))
+	}
+
+	summary := fmt.Sprintf("github ops app started: version %s", version)
+	if info.Environment != "" {
+		summary += fmt.Sprintf(" in %s", info.Environment)
+	}
+
+	channel := s.channelFor(s.channels.Startup)
+	_, _, err := s.postMessage(
 		ctx,
 		channel,
 		slack.MsgOptionBlocks(blocks...),
-		slack.MsgOptionText(fmt.Sprintf("orphaned github users detected: %d users", len(report.OrphanedUsers)), false),
+		slack.MsgOptionText(summary, false),
 	)
 
 	if err != nil {
-		return errors.Wrap(err, "failed to post orphaned users notification to slack")
+		return errors.Wrap(err, "failed to post startup notification to slack")
 	}
 
 	return nil
 }
+
+// NotifyBranchDeletion sends a high-severity Slack alert when a monitored
+// branch is deleted. uses the PR bypass channel since both are branch
+// protection alerts.
+func (s *SlackNotifier) NotifyBranchDeletion(ctx context.Context, deletion BranchDeletion) error {
+	deletedBy := deletion.DeletedBy
+	if deletedBy == "" {
+		deletedBy = "unknown"
+	}
+
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Repository:*\n<%s|%s>", s.githubLink(deletion.Repo), deletion.Repo), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Branch:*\n`%s`", deletion.Branch), false, false),
+		slack.NewTextBlockObject("mrkdwn", "*Deleted By:*\n"+s.userLink(deletion.DeletedBy), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Deleted At:*\n%s", deletion.DeletedAt.UTC().Format(time.RFC3339)), false, false),
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject("plain_text", "🚨 Protected Branch Deleted", false, false),
+		),
+		slack.NewSectionBlock(nil, fields, nil),
+	}
+
+	if deletion.LastCommit != "" {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn",
+				fmt.Sprintf("*Last Commit:* <%s|%s>", s.githubLink(deletion.Repo+"/commit/"+deletion.LastCommit), shortSHA(deletion.LastCommit)),
+				false, false),
+			nil, nil,
+		))
+	}
+
+	blocks = append(blocks, slack.NewContextBlock(
+		"context",
+		slack.NewTextBlockObject("mrkdwn", "_Deleting and recreating a protected branch can reset its history and protection. Restore it from the last commit if this was not expected._", false, false),
+	))
+
+	channel := s.channelFor(s.channels.PRBypass)
+	_, _, err := s.postMessage(
+		ctx,
+		channel,
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(fmt.Sprintf("protected branch deleted: %s@%s by %s", deletion.Repo, deletion.Branch, deletedBy), false),
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to post branch deletion notification to slack")
+	}
+
+	return nil
+}
+
+// NotifyDirectPush sends a Slack alert when commits are pushed directly to a
+// monitored branch instead of being merged through a PR. force pushes are
+// reported with a distinct, higher-severity header. uses the PR bypass
+// channel since both are branch protection alerts.
+func (s *SlackNotifier) NotifyDirectPush(ctx context.Context, push DirectPush) error {
+	pushedBy := push.PushedBy
+	if pushedBy == "" {
+		pushedBy = "unknown"
+	}
+
+	header := "⚠️ Direct Push to Protected Branch"
+	kind := "direct push"
+	if push.Forced {
+		header = "🚨 Force Push to Protected Branch"
+		kind = "force push"
+	}
+
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Repository:*\n<%s|%s>", s.githubLink(push.Repo), push.Repo), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Branch:*\n`%s`", push.Branch), false, false),
+		slack.NewTextBlockObject("mrkdwn", "*Pushed By:*\n"+s.userLink(push.PushedBy), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Pushed At:*\n%s", push.PushedAt.UTC().Format(time.RFC3339)), false, false),
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(
+			slack.NewTextBlockObject("plain_text", header, false, false),
+		),
+		slack.NewSectionBlock(nil, fields, nil),
+	}
+
+	var details []string
+	if push.Before != "" && push.After != "" {
+		change := fmt.Sprintf("*Change:* `%s` → `%s`", shortSHA(push.Before), shortSHA(push.After))
+		if push.CompareURL != "" {
+			change = fmt.Sprintf("*Change:* <%s|%s...%s>", push.CompareURL, shortSHA(push.Before), shortSHA(push.After))
+		}
+		details = append(details, change)
+	}
+	if push.Commits > 0 {
+		details = append(details, fmt.Sprintf("*Commits:* %d", push.Commits))
+	}
+	if push.HeadCommitMessage != "" {
+		details = append(details, fmt.Sprintf("*Head Commit:* %s", push.HeadCommitMessage))
+	}
+	if len(details) > 0 {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject("mrkdwn", strings.Join(details, "\n"), false, false),
+			nil, nil,
+		))
+	}
+
+	note := "_These commits were pushed without a pull request, so no review or status check requirements were enforced._"
+	if push.Forced {
+		note = "_The branch history was rewritten. Commits previously on the branch may have been removed; restore them from the previous head if this was not expected._"
+	}
+	blocks = append(blocks, slack.NewContextBlock(
+		"context",
+		slack.NewTextBlockObject("mrkdwn", note, false, false),
+	))
+
+	channel := s.channelFor(s.channels.PRBypass)
+	_, _, err := s.postMessage(
+		ctx,
+		channel,
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionText(fmt.Sprintf("%s to protected branch: %s@%s by %s", kind, push.Repo, push.Branch, pushedBy), false),
+	)
+
+	if err != nil {
+		return errors.Wrap(err, "failed to post direct push notification to slack")
+	}
+
+	return nil
+}
+
+// allDryRun returns true if there is at least one report and every report
+// lists planned rather than applied changes.
+func allDryRun(reports []*okta.SyncReport) bool {
+	for _, report := range reports {
+		if !report.DryRun {
+			return false
+		}
+	}
+	return len(reports) > 0
+}
+
+// userLink returns a Slack link to a GitHub user's profile, or "unknown"
+// if login is empty.
+func (s *SlackNotifier) userLink(login string) string {
+	if login == "" {
+		return "unknown"
+	}
+	return fmt.Sprintf("<%s|%s>", s.githubLink(login), login)
+}
+
+// shortSHA abbreviates a commit SHA for display.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// skipReasonLabels are the Slack labels of okta skip reasons.
+var skipReasonLabels = map[string]string{
+	okta.SkipReasonNoGitHubUsername: "No GitHub username in Okta",
+	okta.SkipReasonNotOrgMember:     "Not organization members",
+	okta.SkipReasonExternal:         "External collaborators",
+}
+
+// formatSkipCounts lists the skipped users per reason with the change since
+// the previous sync, so rising counts stand out. returns an empty string if
+// nothing was skipped in this or the previous sync.
+func formatSkipCounts(skipped []okta.SkipCount) string {
+	var text string
+	var nonZero bool
+	for _, s := range skipped {
+		if s.Count > 0 || (s.Previous != nil && *s.Previous > 0) {
+			nonZero = true
+		}
+		label := cmp.Or(skipReasonLabels[s.Reason], s.Reason)
+		trend := ""
+		if s.Previous != nil {
+			trend = " (no change)"
+			if change := s.Count - *s.Previous; change != 0 {
+				trend = fmt.Sprintf(" (%+d since last sync)", change)
+			}
+		}
+		text += fmt.Sprintf("- %s: %d%s\n", label, s.Count, trend)
+	}
+	if !nonZero {
+		return ""
+	}
+	return text
+}
+
+// formatEmptyRules formats the section listing rules that synced nothing.
+func formatEmptyRules(emptyRules []okta.EmptyRule) string {
+	text := fmt.Sprintf("*:warning: Rules That Synced Nothing (%d)*\n", len(emptyRules))
+	for _, empty := range emptyRules {
+		switch empty.Reason {
+		case okta.EmptyRuleNoGroups:
+			text += fmt.Sprintf("- %s: matched no Okta groups\n", empty.Rule)
+		case okta.EmptyRuleNoMembers:
+			text += fmt.Sprintf("- %s: no members in %s\n", empty.Rule, strings.Join(empty.OktaGroups, ", "))
+		default:
+			text += fmt.Sprintf("- %s\n", empty.Rule)
+		}
+	}
+	return text
+}
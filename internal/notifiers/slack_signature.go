@@ -0,0 +1,51 @@
+package notifiers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+)
+
+// SlackSignatureMaxAge is how far a request timestamp may be from now before
+// the request is rejected as a possible replay.
+const SlackSignatureMaxAge = 5 * time.Minute
+
+// VerifySlackSignature verifies a Slack request signature from the
+// x-slack-signature and x-slack-request-timestamp headers. the signature is
+// an HMAC-SHA256 of "v0:<timestamp>:<body>" keyed with the app's signing
+// secret. requests whose timestamp is more than SlackSignatureMaxAge from now
+// are rejected to prevent replays.
+func VerifySlackSignature(body []byte, timestamp, signature, secret string, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return internalerrors.ErrMissingSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.Wrapf(internalerrors.ErrInvalidSignature, "invalid timestamp '%s'", timestamp)
+	}
+	if age := now.Sub(time.Unix(ts, 0)).Abs(); age > SlackSignatureMaxAge {
+		return errors.Wrapf(internalerrors.ErrStaleSignature, "request timestamp is %s old", age.Round(time.Second))
+	}
+
+	if !strings.HasPrefix(signature, "v0=") {
+		return errors.Wrap(internalerrors.ErrInvalidSignature, "must start with 'v0='")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expectedSignature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(signature), []byte(expectedSignature)) {
+		return errors.Wrap(internalerrors.ErrInvalidSignature, "computed signature does not match")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package notifiers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+)
+
+func TestVerifySlackSignature(t *testing.T) {
+	// example request from Slack's signing secret documentation
+	const (
+		secret    = "8f742231b10e8888abcd99yyyzzz85a5"
+		timestamp = "1531420618"
+		signature = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+		body      = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+	)
+	sent := time.Unix(1531420618, 0)
+
+	tests := []struct {
+		name      string
+		body      string
+		timestamp string
+		signature string
+		now       time.Time
+		wantErr   error
+	}{
+		{name: "valid", body: body, timestamp: timestamp, signature: signature, now: sent.Add(time.Minute)},
+		{name: "tampered body", body: body + "&x=1", timestamp: timestamp, signature: signature, now: sent, wantErr: internalerrors.ErrInvalidSignature},
+		{name: "wrong prefix", body: body, timestamp: timestamp, signature: "v1=abc", now: sent, wantErr: internalerrors.ErrInvalidSignature},
+		{name: "missing signature", body: body, timestamp: timestamp, now: sent, wantErr: internalerrors.ErrMissingSignature},
+		{name: "missing timestamp", body: body, signature: signature, now: sent, wantErr: internalerrors.ErrMissingSignature},
+		{name: "non-numeric timestamp", body: body, timestamp: "soon", signature: signature, now: sent, wantErr: internalerrors.ErrInvalidSignature},
+		{name: "replayed", body: body, timestamp: timestamp, signature: signature, now: sent.Add(SlackSignatureMaxAge + time.Second), wantErr: internalerrors.ErrStaleSignature},
+		{name: "future timestamp", body: body, timestamp: timestamp, signature: signature, now: sent.Add(-SlackSignatureMaxAge - time.Second), wantErr: internalerrors.ErrStaleSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySlackSignature([]byte(tt.body), tt.timestamp, tt.signature, secret, tt.now)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -1,6 +1,20 @@
 package notifiers
 
-import "testing"
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cruxstack/github-ops-app/internal/okta"
+	"github.com/cruxstack/github-ops-app/internal/types"
+)
 
 func TestChannelFor(t *testing.T) {
 	defaultChannel := "C_DEFAULT"
@@ -73,4 +87,506 @@ func TestSlackChannels_AllFallbackToDefault(t *testing.T) {
 	if got := n.channelFor(n.channels.OrphanedUsers); got != defaultChannel {
 		t.Errorf("OrphanedUsers channel = %q, want %q", got, defaultChannel)
 	}
+	if got := n.channelFor(n.channels.Startup); got != defaultChannel {
+		t.Errorf("Startup channel = %q, want %q", got, defaultChannel)
+	}
+}
+
+func TestRemediationFor(t *testing.T) {
+	n := &SlackNotifier{
+		messages: SlackMessages{
+			Remediations: map[string]string{
+				types.ViolationMissingStatusCheck: "See <https://wiki.example.com/ci|CI runbook>",
+				types.ViolationMissingTeamReview:  "",
+				"custom_violation":                "Ping #security",
+			},
+		},
+	}
+
+	tests := []struct {
+		violationType string
+		want          string
+	}{
+		{types.ViolationInsufficientReviews, DefaultRemediations[types.ViolationInsufficientReviews]},
+		{types.ViolationMissingStatusCheck, "See <https://wiki.example.com/ci|CI runbook>"},
+		{types.ViolationMissingTeamReview, ""},
+		{"custom_violation", "Ping #security"},
+		{"unknown_violation", ""},
+	}
+
+	for _, tt := range tests {
+		if got := n.remediationFor(tt.violationType); got != tt.want {
+			t.Errorf("remediationFor(%q) = %q, want %q", tt.violationType, got, tt.want)
+		}
+	}
+}
+
+// postedMessage is a chat.postMessage call received by a fake Slack API.
+type postedMessage struct {
+	channel  string
+	threadTS string
+}
+
+// newFakeSlackNotifier creates a notifier against a fake Slack API that
+// records posted messages. each post is assigned the timestamp "N.0".
+func newFakeSlackNotifier(t *testing.T, channels SlackChannels) (*SlackNotifier, func() []postedMessage) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var posted []postedMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		mu.Lock()
+		posted = append(posted, postedMessage{channel: r.FormValue("channel"), threadTS: r.FormValue("thread_ts")})
+		ts := fmt.Sprintf("%d.0", len(posted))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":%q}`, r.FormValue("channel"), ts)
+	}))
+	t.Cleanup(srv.Close)
+
+	n := NewSlackNotifierWithAPIURL("xoxb-test", channels, SlackMessages{}, srv.URL+"/")
+	return n, func() []postedMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]postedMessage(nil), posted...)
+	}
+}
+
+func TestSlackNotifier_ThreadSync(t *testing.T) {
+	reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng", Errors: []string{"boom"}}}
+	orphaned := &okta.OrphanedUsersReport{OrphanedUsers: []string{"mallory"}}
+
+	t.Run("threads follow-ups under the summary", func(t *testing.T) {
+		n, posted := newFakeSlackNotifier(t, SlackChannels{Default: "C1"})
+		n.SetThreadSync(true)
+		ctx := WithSyncThread(context.Background())
+
+		if err := n.NotifyOktaSync(ctx, &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := n.NotifyOrphanedUsers(ctx, orphaned); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := posted()
+		want := []postedMessage{{"C1", ""}, {"C1", "1.0"}, {"C1", "1.0"}}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("expected posts %v, got %v", want, got)
+		}
+	})
+
+	t.Run("does not thread across channels", func(t *testing.T) {
+		n, posted := newFakeSlackNotifier(t, SlackChannels{Default: "C1", OrphanedUsers: "C2"})
+		n.SetThreadSync(true)
+		ctx := WithSyncThread(context.Background())
+
+		if err := n.NotifyOktaSync(ctx, &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := n.NotifyOrphanedUsers(ctx, orphaned); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := posted()
+		if len(got) != 3 || got[2] != (postedMessage{"C2", ""}) {
+			t.Errorf("expected top-level orphaned users post in C2, got %v", got)
+		}
+	})
+
+	t.Run("disabled posts separate messages", func(t *testing.T) {
+		n, posted := newFakeSlackNotifier(t, SlackChannels{Default: "C1"})
+		ctx := WithSyncThread(context.Background())
+
+		if err := n.NotifyOktaSync(ctx, &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := n.NotifyOrphanedUsers(ctx, orphaned); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		got := posted()
+		want := []postedMessage{{"C1", ""}, {"C1", ""}}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("expected posts %v, got %v", want, got)
+		}
+	})
+}
+
+func TestSlackNotifier_ThreadSyncDaily(t *testing.T) {
+	reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng", Errors: []string{"boom"}}}
+	orphaned := &okta.OrphanedUsersReport{OrphanedUsers: []string{"mallory"}}
+
+	n, posted := newFakeSlackNotifier(t, SlackChannels{Default: "C1"})
+	n.SetThreadSyncDaily(true)
+	n.SetThreadSync(true)
+	now := time.Date(2026, 3, 1, 23, 0, 0, 0, time.UTC)
+	n.now = func() time.Time { return now }
+
+	// posts 1.0 (parent), 2.0 (summary), 3.0 (errors), 4.0 (orphaned users)
+	ctx := WithSyncThread(context.Background())
+	if err := n.NotifyOktaSync(ctx, &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.NotifyOrphanedUsers(ctx, orphaned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a later run the same day reuses the parent
+	if err := n.NotifyOktaSync(WithSyncThread(context.Background()), &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the first run of the next day starts a new parent
+	now = now.Add(2 * time.Hour)
+	if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := posted()
+	want := []postedMessage{
+		{"C1", ""}, {"C1", "1.0"}, {"C1", "1.0"}, {"C1", "1.0"},
+		{"C1", "1.0"}, {"C1", "1.0"},
+		{"C1", ""}, {"C1", "7.0"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected posts %v, got %v", want, got)
+	}
+}
+
+func TestSlackNotifier_ThreadSyncDaily_ParentFails(t *testing.T) {
+	var posted []postedMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		// the first post, the day's parent, is rejected
+		if len(posted) == 0 && r.FormValue("thread_ts") == "" && strings.Contains(r.FormValue("text"), "Okta sync runs for") {
+			posted = append(posted, postedMessage{channel: "rejected"})
+			fmt.Fprint(w, `{"ok":false,"error":"not_allowed_token_type"}`)
+			return
+		}
+		posted = append(posted, postedMessage{channel: r.FormValue("channel"), threadTS: r.FormValue("thread_ts")})
+		fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":"%d.0"}`, r.FormValue("channel"), len(posted))
+	}))
+	t.Cleanup(srv.Close)
+	n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1"}, SlackMessages{}, srv.URL+"/")
+	n.SetThreadSyncDaily(true)
+	var logs strings.Builder
+	n.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+	reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng"}}
+
+	if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("expected the summary to be posted unthreaded, got %v", err)
+	}
+	if !strings.Contains(logs.String(), "failed to start daily okta sync thread") {
+		t.Errorf("expected the parent failure to be logged, got %s", logs.String())
+	}
+
+	// the next sync starts the thread
+	if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []postedMessage{{"rejected", ""}, {"C1", ""}, {"C1", ""}, {"C1", "3.0"}}
+	if fmt.Sprint(posted) != fmt.Sprint(want) {
+		t.Errorf("expected posts %v, got %v", want, posted)
+	}
+}
+
+func TestSlackNotifier_DisabledRules(t *testing.T) {
+	var blocks []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		blocks = append(blocks, r.FormValue("blocks"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"1.0"}`)
+	}))
+	t.Cleanup(srv.Close)
+	n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1"}, SlackMessages{}, srv.URL+"/")
+
+	t.Run("lists disabled rules", func(t *testing.T) {
+		blocks = nil
+		reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng"}}
+		if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{Reports: reports, DisabledRules: []string{"legacy", "contractors"}}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(blocks) != 1 {
+			t.Fatalf("expected 1 post, got %d", len(blocks))
+		}
+		for _, want := range []string{"Rules Skipped (Disabled)", "- legacy", "- contractors"} {
+			if !strings.Contains(blocks[0], want) {
+				t.Errorf("expected %q in message, got %s", want, blocks[0])
+			}
+		}
+	})
+
+	t.Run("posts with only disabled rules", func(t *testing.T) {
+		blocks = nil
+		if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{DisabledRules: []string{"legacy"}}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(blocks) != 1 || !strings.Contains(blocks[0], "- legacy") {
+			t.Errorf("expected a post listing the disabled rule, got %v", blocks)
+		}
+	})
+
+	t.Run("omitted without disabled rules", func(t *testing.T) {
+		blocks = nil
+		reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng"}}
+		if err := n.NotifyOktaSync(context.Background(), &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(blocks) != 1 || strings.Contains(blocks[0], "Rules Skipped (Disabled)") {
+			t.Errorf("expected no disabled rules section, got %v", blocks)
+		}
+	})
+}
+
+func TestGitHubWebURL(t *testing.T) {
+	tests := []struct {
+		baseURL string
+		want    string
+	}{
+		{"", "https://github.com"},
+		{"https://api.github.com/", "https://github.com"},
+		{"https://ghe.example.com/api/v3/", "https://ghe.example.com"},
+		{"https://ghe.example.com/api/v3", "https://ghe.example.com"},
+		{"https://api.acme.ghe.com/", "https://acme.ghe.com"},
+		{"http://127.0.0.1:8080/", "http://127.0.0.1:8080"},
+		{"not a url", "https://github.com"},
+	}
+
+	for _, tt := range tests {
+		if got := githubWebURL(tt.baseURL); got != tt.want {
+			t.Errorf("githubWebURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
+
+func TestSlackNotifier_GitHubLinks(t *testing.T) {
+	var blocks []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse form: %v", err)
+		}
+		blocks = append(blocks, r.FormValue("blocks"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"1.0"}`)
+	}))
+	t.Cleanup(srv.Close)
+	n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1"}, SlackMessages{}, srv.URL+"/")
+	n.SetGitHubBaseURL("https://ghe.example.com/api/v3/")
+	ctx := context.Background()
+
+	if err := n.NotifyBranchDeletion(ctx, BranchDeletion{Repo: "acme/api", Branch: "main", DeletedBy: "alice", LastCommit: "abc1234def"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := n.NotifyDirectPush(ctx, DirectPush{Repo: "acme/api", Branch: "main"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reports := []*okta.SyncReport{{Rule: "eng", GitHubTeam: "eng", MembersAdded: []string{"bob"}}}
+	if err := n.NotifyOktaSync(ctx, &okta.SyncResult{Reports: reports}, "acme"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	all := strings.Join(blocks, "\n")
+	for _, want := range []string{
+		"https://ghe.example.com/acme/api|acme/api",
+		"https://ghe.example.com/alice|alice",
+		"https://ghe.example.com/acme/api/commit/abc1234def",
+		"https://ghe.example.com/orgs/acme/teams/eng",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected link %q in messages, got %s", want, all)
+		}
+	}
+	if strings.Contains(all, "https://github.com") {
+		t.Errorf("expected no github.com links, got %s", all)
+	}
+	if !strings.Contains(blocks[1], `Pushed By:*\nunknown`) {
+		t.Errorf("expected an unknown pusher not to be linked, got %s", blocks[1])
+	}
+}
+
+func TestFormatEmptyRules(t *testing.T) {
+	text := formatEmptyRules([]okta.EmptyRule{
+		{Rule: "contractors", Reason: okta.EmptyRuleNoGroups},
+		{Rule: "interns", Reason: okta.EmptyRuleNoMembers, OktaGroups: []string{"interns-a", "interns-b"}},
+	})
+
+	for _, want := range []string{
+		"Rules That Synced Nothing (2)",
+		"- contractors: matched no Okta groups",
+		"- interns: no members in interns-a, interns-b",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+}
+
+func TestFormatSkipCounts(t *testing.T) {
+	previous := func(count int) *int { return &count }
+	text := formatSkipCounts([]okta.SkipCount{
+		{Reason: okta.SkipReasonNoGitHubUsername, Count: 5, Previous: previous(2)},
+		{Reason: okta.SkipReasonNotOrgMember, Count: 1, Previous: previous(1)},
+		{Reason: okta.SkipReasonExternal, Count: 0},
+	})
+
+	for _, want := range []string{
+		"- No GitHub username in Okta: 5 (+3 since last sync)",
+		"- Not organization members: 1 (no change)",
+		"- External collaborators: 0\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+
+	none := formatSkipCounts([]okta.SkipCount{{Reason: okta.SkipReasonExternal, Previous: previous(0)}})
+	if none != "" {
+		t.Errorf("expected no text when nothing was skipped, got %q", none)
+	}
+}
+
+func TestNotifyOrphanedUsers_Limit(t *testing.T) {
+	users := make([]string, 12)
+	for i := range users {
+		users[i] = fmt.Sprintf("user%02d", i)
+	}
+	report := &okta.OrphanedUsersReport{OrphanedUsers: users}
+
+	for _, uploadOK := range []bool{true, false} {
+		t.Run(fmt.Sprintf("upload ok %t", uploadOK), func(t *testing.T) {
+			var blocks, uploaded, uploadThread string
+			mux := http.NewServeMux()
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+			mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+				blocks = r.FormValue("blocks")
+				fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"1.0"}`)
+			})
+			mux.HandleFunc("/files.getUploadURLExternal", func(w http.ResponseWriter, r *http.Request) {
+				if !uploadOK {
+					fmt.Fprint(w, `{"ok":false,"error":"missing_scope"}`)
+					return
+				}
+				fmt.Fprintf(w, `{"ok":true,"upload_url":%q,"file_id":"F1"}`, srv.URL+"/upload")
+			})
+			mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+				file, _, err := r.FormFile("file")
+				if err != nil {
+					t.Errorf("failed to read uploaded file: %v", err)
+					return
+				}
+				content, _ := io.ReadAll(file)
+				uploaded = string(content)
+			})
+			mux.HandleFunc("/files.completeUploadExternal", func(w http.ResponseWriter, r *http.Request) {
+				uploadThread = r.FormValue("thread_ts")
+				fmt.Fprint(w, `{"ok":true,"files":[{"id":"F1"}]}`)
+			})
+
+			n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1"}, SlackMessages{OrphanedUsersLimit: 10}, srv.URL+"/")
+			err := n.NotifyOrphanedUsers(context.Background(), report)
+
+			if !strings.Contains(blocks, "user09") || strings.Contains(blocks, "user10") || !strings.Contains(blocks, "and 2 more") {
+				t.Errorf("expected the first 10 users and a count of the rest, got %s", blocks)
+			}
+			if !uploadOK {
+				if err == nil {
+					t.Error("expected an error for the failed upload")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if uploaded != strings.Join(users, "\n")+"\n" || uploadThread != "1.0" {
+				t.Errorf("expected full list uploaded in thread 1.0, got %q in thread %q", uploaded, uploadThread)
+			}
+		})
+	}
+}
+
+func TestNotifyMissingUsernames(t *testing.T) {
+	report := &okta.MissingUsernamesReport{Users: []okta.MissingUsername{
+		{Email: "alice@example.com", Groups: []string{"eng", "platform"}},
+		{Email: "bob@example.com", Groups: []string{"eng"}},
+		{Email: "carol@example.com", Groups: []string{"sales"}},
+	}}
+
+	var channel, blocks, uploaded, uploadThread string
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		channel, blocks = r.FormValue("channel"), r.FormValue("blocks")
+		fmt.Fprint(w, `{"ok":true,"channel":"C_OKTA","ts":"1.0"}`)
+	})
+	mux.HandleFunc("/files.getUploadURLExternal", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"upload_url":%q,"file_id":"F1"}`, srv.URL+"/upload")
+	})
+	mux.HandleFunc("/upload", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("failed to read uploaded file: %v", err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		uploaded = string(content)
+	})
+	mux.HandleFunc("/files.completeUploadExternal", func(w http.ResponseWriter, r *http.Request) {
+		uploadThread = r.FormValue("thread_ts")
+		fmt.Fprint(w, `{"ok":true,"files":[{"id":"F1"}]}`)
+	})
+
+	n := NewSlackNotifierWithAPIURL("xoxb-test", SlackChannels{Default: "C1", OktaSync: "C_OKTA"},
+		SlackMessages{OrphanedUsersLimit: 2}, srv.URL+"/")
+	ctx := context.Background()
+
+	if err := n.NotifyMissingUsernames(ctx, &okta.MissingUsernamesReport{}); err != nil || channel != "" {
+		t.Fatalf("expected nothing posted for an empty report, got %q (err %v)", channel, err)
+	}
+	if err := n.NotifyMissingUsernames(ctx, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if channel != "C_OKTA" {
+		t.Errorf("expected the okta sync channel, got %q", channel)
+	}
+	if !strings.Contains(blocks, "bob@example.com (eng)") || strings.Contains(blocks, "carol@example.com") || !strings.Contains(blocks, "and 1 more") {
+		t.Errorf("expected the first 2 users and a count of the rest, got %s", blocks)
+	}
+	want := "alice@example.com\teng,platform\nbob@example.com\teng\ncarol@example.com\tsales\n"
+	if uploaded != want || uploadThread != "1.0" {
+		t.Errorf("expected %q uploaded in thread 1.0, got %q in thread %q", want, uploaded, uploadThread)
+	}
+}
+
+func TestPRBypassChannelFor(t *testing.T) {
+	n := &SlackNotifier{channels: SlackChannels{
+		Default:       "C_DEFAULT",
+		PRBypass:      "C_PR_BYPASS",
+		PRBypassRepos: map[string]string{"acme/payments": "C_PAYMENTS"},
+	}}
+
+	if got := n.prBypassChannelFor("Acme/Payments"); got != "C_PAYMENTS" {
+		t.Errorf("expected repo channel C_PAYMENTS, got %q", got)
+	}
+	if got := n.prBypassChannelFor("acme/web"); got != "C_PR_BYPASS" {
+		t.Errorf("expected PR bypass channel C_PR_BYPASS, got %q", got)
+	}
+
+	n.channels.PRBypass = ""
+	if got := n.prBypassChannelFor("acme/web"); got != "C_DEFAULT" {
+		t.Errorf("expected default channel C_DEFAULT, got %q", got)
+	}
 }
@@ -9,11 +9,14 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
+	"github.com/cruxstack/github-ops-app/internal/types"
 	"github.com/okta/okta-sdk-golang/v6/okta"
 )
 
@@ -62,20 +65,23 @@ func convertToPKCS1(keyPEM []byte) ([]byte, error) {
 
 // Client wraps the Okta SDK client with custom configuration.
 type Client struct {
-	apiClient       *okta.APIClient
-	ctx             context.Context
-	githubUserField string
+	apiClient        *okta.APIClient
+	ctx              context.Context
+	githubUserFields []string
+	quota            *types.QuotaTracker
+	logger           *slog.Logger
 }
 
-// ClientConfig contains Okta client configuration.
+// ClientConfig contains Okta client configuration. GitHubUserFields lists the
+// profile fields holding the GitHub username in priority order.
 type ClientConfig struct {
-	Domain          string
-	ClientID        string
-	PrivateKey      []byte
-	PrivateKeyID    string
-	Scopes          []string
-	GitHubUserField string
-	BaseURL         string
+	Domain           string
+	ClientID         string
+	PrivateKey       []byte
+	PrivateKeyID     string
+	Scopes           []string
+	GitHubUserFields []string
+	BaseURL          string
 }
 
 // NewClient creates an Okta client with background context.
@@ -119,16 +125,18 @@ func NewClientWithContext(ctx context.Context, cfg *ClientConfig) (*Client, erro
 		opts = append(opts, okta.WithPrivateKeyId(cfg.PrivateKeyID))
 	}
 
+	var transport http.RoundTripper = http.DefaultTransport
 	if certPool, ok := ctx.Value("okta_tls_cert_pool").(*x509.CertPool); ok && certPool != nil {
-		httpClient := &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					RootCAs: certPool,
-				},
+		transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs: certPool,
 			},
 		}
-		opts = append(opts, okta.WithHttpClientPtr(httpClient))
 	}
+	quota := &types.QuotaTracker{}
+	opts = append(opts, okta.WithHttpClientPtr(&http.Client{
+		Transport: &quotaTransport{base: transport, quota: quota},
+	}))
 
 	oktaCfg, err := okta.NewConfiguration(opts...)
 	if err != nil {
@@ -162,12 +170,46 @@ func NewClientWithContext(ctx context.Context, cfg *ClientConfig) (*Client, erro
 	apiClient := okta.NewAPIClient(oktaCfg)
 
 	return &Client{
-		apiClient:       apiClient,
-		ctx:             ctx,
-		githubUserField: cfg.GitHubUserField,
+		apiClient:        apiClient,
+		ctx:              ctx,
+		githubUserFields: cfg.GitHubUserFields,
+		quota:            quota,
+		logger:           slog.New(slog.DiscardHandler),
 	}, nil
 }
 
+// SetLogger sets the logger used to report groups that cannot be matched
+// by name, such as groups without a profile.
+func (c *Client) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// quotaTransport records the Okta rate limit from API responses. OAuth
+// token requests are not tracked.
+type quotaTransport struct {
+	base  http.RoundTripper
+	quota *types.QuotaTracker
+}
+
+// RoundTrip sends the request and records the rate limit headers.
+func (t *quotaTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && strings.HasPrefix(req.URL.Path, "/api/") {
+		t.quota.Observe("okta", "X-Rate-Limit-", resp.Header)
+	}
+	return resp, err
+}
+
+// APIQuota returns the Okta rate limit from the most recent API response, if
+// any. Okta limits are per endpoint, so this reflects the last endpoint
+// called.
+func (c *Client) APIQuota() (types.APIQuota, bool) {
+	if c.quota == nil {
+		return types.APIQuota{}, false
+	}
+	return c.quota.Latest()
+}
+
 // GetAPIClient returns the underlying Okta SDK API client.
 func (c *Client) GetAPIClient() *okta.APIClient {
 	return c.apiClient
@@ -178,47 +220,86 @@ func (c *Client) GetContext() context.Context {
 	return c.ctx
 }
 
+// CheckHealth verifies live connectivity to Okta with the configured
+// credentials by listing a single group.
+func (c *Client) CheckHealth(ctx context.Context) error {
+	if _, _, err := c.apiClient.GroupAPI.ListGroups(ctx).Limit(1).Execute(); err != nil {
+		return errors.Wrap(err, "failed to list groups")
+	}
+	return nil
+}
+
 // ListGroups fetches all Okta groups.
 func (c *Client) ListGroups() ([]okta.Group, error) {
-	groups, _, err := c.apiClient.GroupAPI.ListGroups(c.ctx).Execute()
+	return c.listGroups("")
+}
+
+// groupsPageSize is the number of groups requested per page.
+const groupsPageSize = 200
+
+// groupUsersPageSize is the number of group members requested per page.
+const groupUsersPageSize = 200
+
+// listGroups fetches all pages of Okta groups. when search is set, results
+// are narrowed server-side using an Okta search expression.
+func (c *Client) listGroups(search string) ([]okta.Group, error) {
+	req := c.apiClient.GroupAPI.ListGroups(c.ctx).Limit(groupsPageSize)
+	if search != "" {
+		req = req.Search(search)
+	}
+
+	groups, resp, err := req.Execute()
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to list groups")
 	}
+
+	for resp != nil && resp.HasNextPage() {
+		var page []okta.Group
+		resp, err = resp.Next(&page)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list groups page")
+		}
+		groups = append(groups, page...)
+	}
+
 	return groups, nil
 }
 
-// GetGroupByName searches for an Okta group by exact name match.
-func (c *Client) GetGroupByName(name string) (*okta.Group, error) {
+// GetGroupByName returns the Okta group with the exact name without
+// fetching its members.
+func (c *Client) GetGroupByName(name string) (*GroupInfo, error) {
+	group, err := c.findGroup(name)
+	if err != nil {
+		return nil, err
+	}
+	groupName, isAD := extractGroupName(group)
+	return &GroupInfo{ID: group.GetId(), Name: groupName, IsActiveDirectory: isAD}, nil
+}
+
+// findGroup searches for an Okta group by exact name match.
+func (c *Client) findGroup(name string) (*okta.Group, error) {
 	groups, _, err := c.apiClient.GroupAPI.ListGroups(c.ctx).Q(name).Execute()
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to search for group '%s'", name)
 	}
 
-	for i := range groups {
-		group := &groups[i]
-		// check if profile is nil
-		if group.Profile == nil {
-			continue
-		}
-
-		// try OktaUserGroupProfile first
-		if group.Profile.OktaUserGroupProfile != nil {
-			groupName := group.Profile.OktaUserGroupProfile.GetName()
-			if groupName == name {
-				return group, nil
-			}
-		}
+	group, unnamed := findGroupByName(groups, name)
+	if group != nil {
+		return group, nil
+	}
 
-		// try OktaActiveDirectoryGroupProfile as fallback
-		if group.Profile.OktaActiveDirectoryGroupProfile != nil {
-			groupName := group.Profile.OktaActiveDirectoryGroupProfile.GetName()
-			if groupName == name {
-				return group, nil
-			}
-		}
+	// one of the groups without a readable name may be the group being
+	// searched for, which is a different problem from it not existing
+	if len(unnamed) > 0 {
+		c.logger.Warn("okta group search returned groups without a profile",
+			slog.String("group", name),
+			slog.Any("group_ids", unnamed))
+		return nil, errors.Wrapf(internalerrors.ErrGroupProfileMissing,
+			"group '%s' not matched, %d search result(s) have no profile name (ids: %s)",
+			name, len(unnamed), strings.Join(unnamed, ", "))
 	}
 
-	return nil, errors.Newf("group '%s' not found", name)
+	return nil, errors.Wrapf(internalerrors.ErrGroupNotFound, "group '%s'", name)
 }
 
 // GroupMembersResult contains the results of fetching group members.
@@ -232,11 +313,22 @@ type GroupMembersResult struct {
 // suspended/deprovisioned users. skips users without a GitHub username in
 // their profile and tracks them separately.
 func (c *Client) GetGroupMembers(groupID string) (*GroupMembersResult, error) {
-	users, _, err := c.apiClient.GroupAPI.ListGroupUsers(c.ctx, groupID).Execute()
+	users, resp, err := c.apiClient.GroupAPI.ListGroupUsers(c.ctx, groupID).
+		Limit(groupUsersPageSize).
+		Execute()
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list members for group '%s'", groupID)
 	}
 
+	for resp != nil && resp.HasNextPage() {
+		var page []okta.User
+		resp, err = resp.Next(&page)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to list members page for group '%s'", groupID)
+		}
+		users = append(users, page...)
+	}
+
 	result := &GroupMembersResult{
 		Members:                 make([]string, 0, len(users)),
 		SkippedNoGitHubUsername: []string{},
@@ -253,12 +345,9 @@ func (c *Client) GetGroupMembers(groupID string) (*GroupMembersResult, error) {
 			continue
 		}
 
-		githubUsername, ok := additionalProps[c.githubUserField]
-		if ok {
-			if username, ok := githubUsername.(string); ok && username != "" {
-				result.Members = append(result.Members, username)
-				continue
-			}
+		if username := c.githubUsername(additionalProps); username != "" {
+			result.Members = append(result.Members, username)
+			continue
 		}
 
 		// user doesn't have github username, track by email
@@ -273,3 +362,42 @@ func (c *Client) GetGroupMembers(groupID string) (*GroupMembersResult, error) {
 
 	return result, nil
 }
+
+// githubUsername returns the first non-empty GitHub username found in the
+// configured profile fields, or an empty string if none is set.
+func (c *Client) githubUsername(props map[string]any) string {
+	for _, field := range c.githubUserFields {
+		if username := lookupProfileString(props, field); username != "" {
+			return username
+		}
+	}
+	return ""
+}
+
+// lookupProfileString resolves a string value from profile attributes. field
+// may be a flat key or a dotted path (e.g., "custom.githubUsername") that
+// traverses nested objects. an exact flat key match takes precedence.
+func lookupProfileString(props map[string]any, field string) string {
+	if value, ok := props[field].(string); ok {
+		return value
+	}
+
+	if !strings.Contains(field, ".") {
+		return ""
+	}
+
+	var current any = props
+	for _, key := range strings.Split(field, ".") {
+		nested, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current, ok = nested[key]
+		if !ok {
+			return ""
+		}
+	}
+
+	value, _ := current.(string)
+	return value
+}
@@ -0,0 +1,178 @@
+package okta
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/okta/okta-sdk-golang/v6/okta"
+)
+
+func TestLookupProfileString(t *testing.T) {
+	props := map[string]any{
+		"githubUsername": "flat-user",
+		"custom": map[string]any{
+			"githubUsername": "nested-user",
+			"deep": map[string]any{
+				"login": "deep-user",
+			},
+			"count": 3,
+		},
+		"dotted.key": "literal-dotted-user",
+	}
+
+	tests := []struct {
+		name  string
+		field string
+		want  string
+	}{
+		{name: "flat key", field: "githubUsername", want: "flat-user"},
+		{name: "nested path", field: "custom.githubUsername", want: "nested-user"},
+		{name: "deeply nested path", field: "custom.deep.login", want: "deep-user"},
+		{name: "literal dotted key wins", field: "dotted.key", want: "literal-dotted-user"},
+		{name: "missing flat key", field: "missing", want: ""},
+		{name: "missing nested key", field: "custom.missing", want: ""},
+		{name: "path through non-object", field: "githubUsername.login", want: ""},
+		{name: "non-string value", field: "custom.count", want: ""},
+		{name: "object value", field: "custom", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookupProfileString(props, tt.field); got != tt.want {
+				t.Errorf("lookupProfileString(%q) = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaTransport(t *testing.T) {
+	reset := time.Now().Add(time.Minute).Unix()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Rate-Limit-Limit", "600")
+		w.Header().Set("X-Rate-Limit-Remaining", "42")
+		w.Header().Set("X-Rate-Limit-Reset", strconv.FormatInt(reset, 10))
+	}))
+	defer srv.Close()
+
+	c := &Client{quota: &types.QuotaTracker{}}
+	httpClient := &http.Client{Transport: &quotaTransport{base: http.DefaultTransport, quota: c.quota}}
+
+	resp, err := httpClient.Get(srv.URL + "/oauth2/v1/token")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if _, ok := c.APIQuota(); ok {
+		t.Fatal("expected oauth token responses to be ignored")
+	}
+
+	resp, err = httpClient.Get(srv.URL + "/api/v1/groups")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	q, ok := c.APIQuota()
+	if !ok {
+		t.Fatal("expected quota to be recorded")
+	}
+	if q.Service != "okta" || q.Limit != 600 || q.Remaining != 42 || q.Reset.Unix() != reset {
+		t.Errorf("unexpected quota: %+v", q)
+	}
+	if !q.IsLow() {
+		t.Error("expected 42/600 to be reported as low")
+	}
+}
+
+func TestGitHubUsername_FieldPriority(t *testing.T) {
+	c := &Client{githubUserFields: []string{"githubUsername", "github_login", "custom.github"}}
+
+	tests := []struct {
+		name  string
+		props map[string]any
+		want  string
+	}{
+		{name: "first field wins", props: map[string]any{"githubUsername": "new-user", "github_login": "legacy-user"}, want: "new-user"},
+		{name: "empty field falls through", props: map[string]any{"githubUsername": "", "github_login": "legacy-user"}, want: "legacy-user"},
+		{name: "non-string field falls through", props: map[string]any{"githubUsername": 42, "custom": map[string]any{"github": "nested-user"}}, want: "nested-user"},
+		{name: "no field populated", props: map[string]any{"email": "user@example.com"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.githubUsername(tt.props); got != tt.want {
+				t.Errorf("githubUsername() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetGroupMembers_Pagination(t *testing.T) {
+	var pages []string
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/groups/g1/users" {
+			http.NotFound(w, r)
+			return
+		}
+		after := r.URL.Query().Get("after")
+		pages = append(pages, after)
+
+		w.Header().Set("Content-Type", "application/json")
+		switch after {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/api/v1/groups/g1/users?after=u2&limit=2>; rel="next"`, srv.URL))
+			fmt.Fprint(w, `[
+				{"id":"u1","status":"ACTIVE","profile":{"githubUsername":"alice"}},
+				{"id":"u2","status":"ACTIVE","profile":{"githubUsername":"bob"}}
+			]`)
+		case "u2":
+			fmt.Fprint(w, `[
+				{"id":"u3","status":"ACTIVE","profile":{"githubUsername":"carol"}},
+				{"id":"u4","status":"SUSPENDED","profile":{"githubUsername":"dave"}}
+			]`)
+		default:
+			t.Errorf("unexpected cursor %q", after)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	cfg, err := okta.NewConfiguration(
+		okta.WithOrgUrl(srv.URL),
+		okta.WithToken("test-token"),
+		okta.WithCache(false),
+		okta.WithHttpClientPtr(srv.Client()),
+	)
+	if err != nil {
+		t.Fatalf("NewConfiguration: %v", err)
+	}
+	cfg.Servers = okta.ServerConfigurations{{URL: srv.URL}}
+	cfg.Host = strings.TrimPrefix(srv.URL, "https://")
+
+	c := &Client{
+		apiClient:        okta.NewAPIClient(cfg),
+		ctx:              context.Background(),
+		githubUserFields: []string{"githubUsername"},
+	}
+
+	result, err := c.GetGroupMembers("g1")
+	if err != nil {
+		t.Fatalf("GetGroupMembers: %v", err)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("fetched %d pages, want 2 (cursors %q)", len(pages), pages)
+	}
+	want := []string{"alice", "bob", "carol"}
+	if fmt.Sprint(result.Members) != fmt.Sprint(want) {
+		t.Errorf("members = %v, want %v", result.Members, want)
+	}
+}
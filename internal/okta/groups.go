@@ -1,7 +1,11 @@
 package okta
 
 import (
+	"fmt"
+	"log/slog"
 	"regexp"
+	"regexp/syntax"
+	"strings"
 
 	"github.com/cockroachdb/errors"
 	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
@@ -12,12 +16,133 @@ import (
 type GroupInfo struct {
 	ID                      string
 	Name                    string
+	IsActiveDirectory       bool
 	Members                 []string
 	SkippedNoGitHubUsername []string
 }
 
+// extractGroupName extracts the group name from either profile type and
+// reports whether the group is sourced from Active Directory.
+func extractGroupName(group *okta.Group) (string, bool) {
+	if group.Profile == nil {
+		return "", false
+	}
+	if group.Profile.OktaUserGroupProfile != nil {
+		return group.Profile.OktaUserGroupProfile.GetName(), false
+	}
+	if group.Profile.OktaActiveDirectoryGroupProfile != nil {
+		return group.Profile.OktaActiveDirectoryGroupProfile.GetName(), true
+	}
+	return "", false
+}
+
+// findGroupByName returns the group with an exact name match. it also
+// returns the IDs of groups that have no profile name and so could not be
+// compared.
+func findGroupByName(groups []okta.Group, name string) (*okta.Group, []string) {
+	var unnamed []string
+	for i := range groups {
+		group := &groups[i]
+		groupName, _ := extractGroupName(group)
+		if groupName == "" {
+			unnamed = append(unnamed, group.GetId())
+			continue
+		}
+		if groupName == name {
+			return group, nil
+		}
+	}
+	return nil, unnamed
+}
+
+// CommonName returns the CN component of an Active Directory distinguished
+// name (e.g., "CN=Engineering,OU=Groups,DC=example,DC=com" -> "Engineering").
+// returns the input unchanged if it is not a DN with a CN component.
+func CommonName(dn string) string {
+	var rdns []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range dn {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			rdns = append(rdns, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	rdns = append(rdns, current.String())
+
+	for _, rdn := range rdns {
+		key, value, ok := strings.Cut(strings.TrimSpace(rdn), "=")
+		if ok && strings.EqualFold(strings.TrimSpace(key), "CN") && value != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return dn
+}
+
+// literalPrefix returns the literal text every match of a start-anchored
+// pattern begins with. returns "" when the pattern is not anchored, has no
+// literal prefix, or is case-insensitive.
+func literalPrefix(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	re = re.Simplify()
+
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 || re.Sub[0].Op != syntax.OpBeginText {
+		return ""
+	}
+
+	lit := re.Sub[1]
+	if lit.Op != syntax.OpLiteral || lit.Flags&syntax.FoldCase != 0 {
+		return ""
+	}
+
+	return string(lit.Rune)
+}
+
+// nameStartsWithSearch builds an Okta search expression matching groups
+// whose name starts with prefix.
+func nameStartsWithSearch(prefix string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(prefix)
+	return fmt.Sprintf(`profile.name sw "%s"`, escaped)
+}
+
 // GetGroupsByPattern fetches all Okta groups matching a regex pattern.
+// pages through all groups and, when the pattern is anchored with a literal
+// prefix, filters server-side before applying the regex.
 func (c *Client) GetGroupsByPattern(pattern string) ([]*GroupInfo, error) {
+	groups, err := c.GetGroupNamesByPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*GroupInfo, 0, len(groups))
+	for _, group := range groups {
+		result, err := c.GetGroupMembers(group.ID)
+		if err != nil {
+			continue
+		}
+		group.Members = result.Members
+		group.SkippedNoGitHubUsername = result.SkippedNoGitHubUsername
+		matched = append(matched, group)
+	}
+
+	return matched, nil
+}
+
+// GetGroupNamesByPattern returns the Okta groups matching a regex pattern
+// like GetGroupsByPattern, but without fetching their members.
+func (c *Client) GetGroupNamesByPattern(pattern string) ([]*GroupInfo, error) {
 	if pattern == "" {
 		return nil, internalerrors.ErrEmptyPattern
 	}
@@ -27,40 +152,34 @@ func (c *Client) GetGroupsByPattern(pattern string) ([]*GroupInfo, error) {
 		return nil, errors.Wrapf(internalerrors.ErrInvalidPattern, "'%s'", pattern)
 	}
 
-	allGroups, err := c.ListGroups()
+	// narrow server-side when the pattern has a literal prefix; the regex
+	// below still decides the final match
+	var search string
+	if prefix := literalPrefix(pattern); prefix != "" {
+		search = nameStartsWithSearch(prefix)
+	}
+
+	allGroups, err := c.listGroups(search)
 	if err != nil {
 		return nil, err
 	}
 
 	var matched []*GroupInfo
-	for _, group := range allGroups {
-		if group.Profile == nil {
+	for i := range allGroups {
+		group := &allGroups[i]
+		name, isAD := extractGroupName(group)
+		if name == "" {
+			c.logger.Warn("skipping okta group without a profile name",
+				slog.String("group_id", group.GetId()),
+				slog.String("pattern", pattern))
 			continue
 		}
 
-		// extract group name from either profile type
-		var groupName string
-		if group.Profile.OktaUserGroupProfile != nil {
-			groupName = group.Profile.OktaUserGroupProfile.GetName()
-		} else if group.Profile.OktaActiveDirectoryGroupProfile != nil {
-			groupName = group.Profile.OktaActiveDirectoryGroupProfile.GetName()
-		}
-
-		if groupName == "" {
-			continue
-		}
-
-		if re.MatchString(groupName) {
-			result, err := c.GetGroupMembers(group.GetId())
-			if err != nil {
-				continue
-			}
-
+		if re.MatchString(name) {
 			matched = append(matched, &GroupInfo{
-				ID:                      group.GetId(),
-				Name:                    groupName,
-				Members:                 result.Members,
-				SkippedNoGitHubUsername: result.SkippedNoGitHubUsername,
+				ID:                group.GetId(),
+				Name:              name,
+				IsActiveDirectory: isAD,
 			})
 		}
 	}
@@ -75,31 +194,19 @@ func (c *Client) GetGroupInfo(groupName string) (*GroupInfo, error) {
 		return nil, err
 	}
 
-	result, err := c.GetGroupMembers(group.GetId())
+	result, err := c.GetGroupMembers(group.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	// extract group name from either profile type
-	var name string
-	if group.Profile != nil {
-		if group.Profile.OktaUserGroupProfile != nil {
-			name = group.Profile.OktaUserGroupProfile.GetName()
-		} else if group.Profile.OktaActiveDirectoryGroupProfile != nil {
-			name = group.Profile.OktaActiveDirectoryGroupProfile.GetName()
-		}
-	}
-
-	return &GroupInfo{
-		ID:                      group.GetId(),
-		Name:                    name,
-		Members:                 result.Members,
-		SkippedNoGitHubUsername: result.SkippedNoGitHubUsername,
-	}, nil
+	group.Members = result.Members
+	group.SkippedNoGitHubUsername = result.SkippedNoGitHubUsername
+	return group, nil
 }
 
 // FilterEnabledGroups filters Okta groups to only those in the enabled list.
-// returns all groups if enabled list is empty.
+// returns all groups if enabled list is empty. groups without a profile name
+// never match an enabled name and are dropped.
 func FilterEnabledGroups(groups []okta.Group, enabledNames []string) []okta.Group {
 	if len(enabledNames) == 0 {
 		return groups
@@ -112,17 +219,8 @@ func FilterEnabledGroups(groups []okta.Group, enabledNames []string) []okta.Grou
 
 	var filtered []okta.Group
 	for _, group := range groups {
-		if group.Profile != nil {
-			var groupName string
-			if group.Profile.OktaUserGroupProfile != nil {
-				groupName = group.Profile.OktaUserGroupProfile.GetName()
-			} else if group.Profile.OktaActiveDirectoryGroupProfile != nil {
-				groupName = group.Profile.OktaActiveDirectoryGroupProfile.GetName()
-			}
-
-			if groupName != "" && enabledMap[groupName] {
-				filtered = append(filtered, group)
-			}
+		if name, _ := extractGroupName(&group); name != "" && enabledMap[name] {
+			filtered = append(filtered, group)
 		}
 	}
 
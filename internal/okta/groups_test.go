@@ -0,0 +1,103 @@
+package okta
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/okta/okta-sdk-golang/v6/okta"
+)
+
+func TestCommonName(t *testing.T) {
+	tests := []struct {
+		name string
+		dn   string
+		want string
+	}{
+		{name: "full dn", dn: "CN=Engineering,OU=Groups,DC=example,DC=com", want: "Engineering"},
+		{name: "lowercase key with spaces", dn: "cn = Platform Team , ou=Groups", want: "Platform Team"},
+		{name: "escaped comma", dn: `CN=Eng\, Backend,OU=Groups`, want: "Eng, Backend"},
+		{name: "cn not first", dn: "OU=Groups,CN=Security", want: "Security"},
+		{name: "no cn component", dn: "OU=Groups,DC=example", want: "OU=Groups,DC=example"},
+		{name: "plain name", dn: "Engineering", want: "Engineering"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CommonName(tt.dn); got != tt.want {
+				t.Errorf("CommonName(%q) = %q, want %q", tt.dn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLiteralPrefix(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{pattern: "^github-eng-.*", want: "github-eng-"},
+		{pattern: "^gh-(a|b)$", want: "gh-"},
+		{pattern: `^team\.x`, want: "team.x"},
+		{pattern: `\Aplatform`, want: "platform"},
+		{pattern: "^platform$", want: "platform"},
+		{pattern: "github-eng-.*", want: ""},
+		{pattern: "^(?i)gh-.*", want: ""},
+		{pattern: "^a|^b", want: ""},
+		{pattern: "^.*-eng$", want: ""},
+		{pattern: "^(", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := literalPrefix(tt.pattern); got != tt.want {
+			t.Errorf("literalPrefix(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestNameStartsWithSearch(t *testing.T) {
+	if got, want := nameStartsWithSearch("github-eng-"), `profile.name sw "github-eng-"`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := nameStartsWithSearch(`a"b\c`), `profile.name sw "a\"b\\c"`; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// testGroup builds an okta group, leaving the profile nil when name is empty.
+func testGroup(id, name string) okta.Group {
+	group := okta.Group{Id: &id}
+	if name != "" {
+		profile := okta.NewOktaUserGroupProfile()
+		profile.SetName(name)
+		group.Profile = &okta.GroupProfile{OktaUserGroupProfile: profile}
+	}
+	return group
+}
+
+func TestFindGroupByName(t *testing.T) {
+	groups := []okta.Group{
+		testGroup("g1", "github-eng"),
+		testGroup("g2", ""),
+		testGroup("g3", "github-eng-platform"),
+	}
+
+	group, unnamed := findGroupByName(groups, "github-eng-platform")
+	if group == nil || group.GetId() != "g3" {
+		t.Errorf("expected group g3, got %v", group)
+	}
+	if unnamed != nil {
+		t.Errorf("expected no unnamed groups on a match, got %v", unnamed)
+	}
+
+	group, unnamed = findGroupByName(groups, "github-ops")
+	if group != nil {
+		t.Errorf("expected no match, got %s", group.GetId())
+	}
+	if !slices.Equal(unnamed, []string{"g2"}) {
+		t.Errorf("expected unnamed [g2], got %v", unnamed)
+	}
+
+	if _, unnamed := findGroupByName(groups[:1], "github-ops"); len(unnamed) != 0 {
+		t.Errorf("expected no unnamed groups, got %v", unnamed)
+	}
+}
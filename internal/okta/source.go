@@ -0,0 +1,30 @@
+package okta
+
+import (
+	"github.com/cruxstack/github-ops-app/internal/types"
+)
+
+// GroupSource is an identity provider that sync rules read groups and their
+// members from. *Client is the Okta implementation; other providers, such as
+// Azure AD, can be synced to GitHub teams by implementing it.
+type GroupSource interface {
+	// GetGroupsByPattern returns the groups whose names match the regex
+	// pattern, with their members.
+	GetGroupsByPattern(pattern string) ([]*GroupInfo, error)
+	// GetGroupNamesByPattern returns the groups whose names match the regex
+	// pattern without fetching their members.
+	GetGroupNamesByPattern(pattern string) ([]*GroupInfo, error)
+	// GetGroupInfo returns the group with the exact name, with its members.
+	GetGroupInfo(groupName string) (*GroupInfo, error)
+	// GetGroupByName returns the group with the exact name without fetching
+	// its members.
+	GetGroupByName(name string) (*GroupInfo, error)
+}
+
+// quotaReporter is implemented by group sources that track their API rate
+// limit, so it can be included in sync results.
+type quotaReporter interface {
+	APIQuota() (types.APIQuota, bool)
+}
+
+var _ GroupSource = (*Client)(nil)
@@ -1,15 +1,19 @@
 package okta
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
-	"regexp"
+	"slices"
 	"strings"
+	"sync"
 
 	"github.com/cockroachdb/errors"
+	internalerrors "github.com/cruxstack/github-ops-app/internal/errors"
 	"github.com/cruxstack/github-ops-app/internal/github/client"
 	"github.com/cruxstack/github-ops-app/internal/types"
+	"github.com/google/go-github/v79/github"
 )
 
 // SyncRule is an alias to types.SyncRule for convenience.
@@ -18,20 +22,199 @@ type SyncRule = types.SyncRule
 // SyncReport contains the results of syncing a single Okta group to GitHub
 // team.
 type SyncReport struct {
-	Rule                       string
-	OktaGroup                  string
-	GitHubTeam                 string
-	MembersAdded               []string
-	MembersRemoved             []string
-	MembersSkippedExternal     []string
-	MembersSkippedNoGHUsername []string
-	Errors                     []string
+	Rule                       string   `json:"rule"`
+	OktaGroup                  string   `json:"okta_group"`
+	GitHubTeam                 string   `json:"github_team"`
+	MembersAdded               []string `json:"members_added"`
+	MembersRemoved             []string `json:"members_removed"`
+	MembersSkippedExternal     []string `json:"members_skipped_external"`
+	MembersSkippedNoGHUsername []string `json:"members_skipped_no_github_username"`
+	MembersSkippedNotOrgMember []string `json:"members_skipped_not_org_member"`
+	// MembersResolvedViaSCIM are GitHub logins resolved from the email of
+	// Okta users without a GitHub username, using the org's SAML/SCIM
+	// identities.
+	MembersResolvedViaSCIM []string `json:"members_resolved_via_scim,omitempty"`
+	Errors                 []string `json:"errors"`
+	// MembersPreserved are team members not in the Okta group that were kept
+	// because they match the rule's preserve_members patterns.
+	MembersPreserved []string `json:"members_preserved,omitempty"`
+	// MembersForceIncluded are desired members not in the Okta group that
+	// were added by the rule's always_include_members list.
+	MembersForceIncluded []string `json:"members_force_included,omitempty"`
+	// MembersPending are desired members that are not yet active team
+	// members, usually pending org invitations. only set when membership
+	// verification or pending invitation handling is enabled.
+	MembersPending []string `json:"members_pending,omitempty"`
+	// SafetyThresholdBlocked is true when removals from the team were skipped
+	// because they exceeded the safety threshold.
+	SafetyThresholdBlocked bool `json:"safety_threshold_blocked,omitempty"`
+	// DescriptionUpdated is true when the team's description was set to the
+	// rule's team_description.
+	DescriptionUpdated bool `json:"description_updated,omitempty"`
+	// NoOktaMembers is true when the Okta group had no members with a GitHub
+	// username, so the team's desired members came only from the rule.
+	NoOktaMembers bool `json:"no_okta_members,omitempty"`
+	// DryRun is true when the report lists planned changes that were not
+	// applied, either from global dry-run mode or the rule's override.
+	DryRun bool `json:"dry_run"`
+	// SincePrevious compares this run with the previous sync of the team.
+	// nil when no previous sync is known.
+	SincePrevious *SyncTrend `json:"since_previous,omitempty"`
+}
+
+// SyncTrend compares a team's membership changes with the previous sync.
+type SyncTrend struct {
+	// NewlyAdded are members added this run that were not removed by the
+	// previous run.
+	NewlyAdded []string `json:"newly_added"`
+	// Churned are members whose change this run reverses the previous run
+	// (added then removed, or removed then added). repeated churn usually
+	// points to misconfigured rules or groups.
+	Churned []string `json:"churned"`
+	// NetChange is members added minus members removed this run.
+	NetChange int `json:"net_change"`
+}
+
+// reasons a rule synced nothing.
+const (
+	// EmptyRuleNoGroups means the rule matched no Okta groups, e.g. because
+	// groups were renamed and its pattern no longer matches.
+	EmptyRuleNoGroups = "no_groups"
+	// EmptyRuleNoMembers means every group the rule matched had no members
+	// with a GitHub username.
+	EmptyRuleNoMembers = "no_members"
+)
+
+// EmptyRule is an enabled rule that ran without errors but synced nothing.
+type EmptyRule struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+	// OktaGroups are the matched groups without members.
+	OktaGroups []string `json:"okta_groups,omitempty"`
+}
+
+// reasons members were skipped by a sync.
+const (
+	// SkipReasonNoGitHubUsername means the Okta user has no GitHub username
+	// and none was resolved via SCIM. a rising count points to gaps in Okta
+	// provisioning.
+	SkipReasonNoGitHubUsername = "no_github_username"
+	// SkipReasonNotOrgMember means the user is not an org member and
+	// APP_OKTA_SYNC_ORG_MEMBERS_ONLY is enabled.
+	SkipReasonNotOrgMember = "not_org_member"
+	// SkipReasonExternal means the team member is an outside collaborator,
+	// so was not removed.
+	SkipReasonExternal = "external_collaborator"
+)
+
+// SkipReasons lists every skip reason in the order they are reported.
+var SkipReasons = []string{SkipReasonNoGitHubUsername, SkipReasonNotOrgMember, SkipReasonExternal}
+
+// SkipCount is the number of distinct users skipped for one reason in a
+// sync.
+type SkipCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+	// Previous is the count from the previous full sync. nil when no
+	// previous count is known.
+	Previous *int `json:"previous,omitempty"`
+}
+
+// countSkippedMembers counts the distinct users skipped for each reason
+// across reports. a user skipped in several teams is counted once. every
+// reason is included, with a zero count if nothing was skipped for it.
+func countSkippedMembers(reports []*SyncReport) []SkipCount {
+	seen := make(map[string]map[string]bool, len(SkipReasons))
+	for _, reason := range SkipReasons {
+		seen[reason] = make(map[string]bool)
+	}
+	for _, report := range reports {
+		for reason, users := range map[string][]string{
+			SkipReasonNoGitHubUsername: report.MembersSkippedNoGHUsername,
+			SkipReasonNotOrgMember:     report.MembersSkippedNotOrgMember,
+			SkipReasonExternal:         report.MembersSkippedExternal,
+		} {
+			for _, user := range users {
+				seen[reason][strings.ToLower(user)] = true
+			}
+		}
+	}
+
+	counts := make([]SkipCount, 0, len(SkipReasons))
+	for _, reason := range SkipReasons {
+		counts = append(counts, SkipCount{Reason: reason, Count: len(seen[reason])})
+	}
+	return counts
 }
 
 // OrphanedUsersReport contains users who are org members but not in any synced
 // teams.
 type OrphanedUsersReport struct {
-	OrphanedUsers []string
+	OrphanedUsers []string `json:"orphaned_users"`
+}
+
+// MissingUsername is an active Okta user in a synced group whose profile has
+// no GitHub username.
+type MissingUsername struct {
+	Email  string   `json:"email"`
+	Groups []string `json:"groups"`
+}
+
+// MissingUsernamesReport lists active Okta users across all synced groups
+// that lack a GitHub username, deduplicated by email.
+type MissingUsernamesReport struct {
+	Users []MissingUsername `json:"users"`
+	// Errors lists rules whose groups could not be read.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// rule diagnosis statuses.
+const (
+	// RuleStatusInSync means the team exists and a sync would not change its
+	// members.
+	RuleStatusInSync = "in_sync"
+	// RuleStatusOutOfSync means the team exists and a sync would add or
+	// remove members.
+	RuleStatusOutOfSync = "out_of_sync"
+	// RuleStatusWouldCreate means the team does not exist and the next sync
+	// would create it, e.g. because it was deleted by hand.
+	RuleStatusWouldCreate = "would_create"
+	// RuleStatusTeamNotFound means the rule's github_team_slug names a team
+	// that does not exist, so syncing the rule fails.
+	RuleStatusTeamNotFound = "team_not_found"
+	// RuleStatusError means the rule could not be checked.
+	RuleStatusError = "error"
+)
+
+// RuleDiagnosis is the state of the GitHub team one Okta group maps to.
+type RuleDiagnosis struct {
+	Rule       string `json:"rule"`
+	OktaGroup  string `json:"okta_group,omitempty"`
+	GitHubTeam string `json:"github_team,omitempty"`
+	Status     string `json:"status"`
+	// MembersToAdd and MembersToRemove are the changes a sync would make to
+	// an existing team. they are not computed for rules that do not sync
+	// members.
+	MembersToAdd    []string `json:"members_to_add,omitempty"`
+	MembersToRemove []string `json:"members_to_remove,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// RuleDiagnosticsReport lists the diagnosis of every Okta group matched by
+// an enabled rule.
+type RuleDiagnosticsReport struct {
+	Rules []RuleDiagnosis `json:"rules"`
+}
+
+// Count returns the number of diagnoses with the given status.
+func (r *RuleDiagnosticsReport) Count(status string) int {
+	count := 0
+	for _, diagnosis := range r.Rules {
+		if diagnosis.Status == status {
+			count++
+		}
+	}
+	return count
 }
 
 // HasErrors returns true if any errors occurred during sync.
@@ -41,47 +224,203 @@ func (r *SyncReport) HasErrors() bool {
 
 // HasChanges returns true if members were added or removed.
 func (r *SyncReport) HasChanges() bool {
-	return len(r.MembersAdded) > 0 || len(r.MembersRemoved) > 0
+	return len(r.MembersAdded) > 0 || len(r.MembersRemoved) > 0 || len(r.MembersPending) > 0
+}
+
+// CompareWithPrevious sets SincePrevious from the members added and removed
+// by the previous sync of the same team.
+func (r *SyncReport) CompareWithPrevious(prevAdded, prevRemoved []string) {
+	prevAddedSet := make(map[string]bool, len(prevAdded))
+	for _, member := range prevAdded {
+		prevAddedSet[strings.ToLower(member)] = true
+	}
+	prevRemovedSet := make(map[string]bool, len(prevRemoved))
+	for _, member := range prevRemoved {
+		prevRemovedSet[strings.ToLower(member)] = true
+	}
+
+	trend := &SyncTrend{
+		NewlyAdded: []string{},
+		Churned:    []string{},
+		NetChange:  len(r.MembersAdded) - len(r.MembersRemoved),
+	}
+	for _, member := range r.MembersAdded {
+		if prevRemovedSet[strings.ToLower(member)] {
+			trend.Churned = append(trend.Churned, member)
+		} else {
+			trend.NewlyAdded = append(trend.NewlyAdded, member)
+		}
+	}
+	for _, member := range r.MembersRemoved {
+		if prevAddedSet[strings.ToLower(member)] {
+			trend.Churned = append(trend.Churned, member)
+		}
+	}
+
+	r.SincePrevious = trend
+}
+
+// SyncOptions controls how the syncer applies changes.
+type SyncOptions struct {
+	// SafetyThreshold is the max ratio of team members that may be removed.
+	SafetyThreshold float64
+	// OrgMembersOnly restricts desired members to existing org members so
+	// sync never generates org invitations.
+	OrgMembersOnly bool
+	// ADUseCommonName derives team names from the CN component of Active
+	// Directory group DNs instead of the full DN.
+	ADUseCommonName bool
+	// ResolveUsernamesViaSCIM resolves Okta users without a GitHub username
+	// by matching their email against the org's SAML/SCIM identities.
+	ResolveUsernamesViaSCIM bool
+	// DryRun reports planned changes for every rule without applying them,
+	// regardless of per-rule dry_run settings.
+	DryRun bool
+	// Parallelism is how many rules Sync processes at once. rules with
+	// different priorities still run in priority order. values below 2 sync
+	// rules one at a time.
+	Parallelism int
+	// VerifyMembership re-lists each team's members after syncing and
+	// reports adds that are not yet active as pending.
+	VerifyMembership bool
+	// PendingInvitesAsMembers treats users with a pending team invitation as
+	// already added, so they are reported as pending instead of being added
+	// again on every sync until they accept.
+	PendingInvitesAsMembers bool
+	// RemoveMembersFirst applies member removals for every team before any
+	// adds, so a user moving between teams never holds both. by default adds
+	// are applied first, so the user never briefly loses access.
+	RemoveMembersFirst bool
+	// OrphanExemptTeams are GitHub team slugs whose members are never
+	// reported as orphaned, e.g. teams managed outside of okta sync.
+	OrphanExemptTeams []string
+	// OrphanExemptLogins are GitHub logins never reported as orphaned, e.g.
+	// service accounts.
+	OrphanExemptLogins []string
 }
 
 // Syncer coordinates synchronization of Okta groups to GitHub teams.
 type Syncer struct {
-	oktaClient      *Client
-	githubClient    *client.Client
-	rules           []SyncRule
-	safetyThreshold float64
-	logger          *slog.Logger
+	source       GroupSource
+	githubClient *client.Client
+	rules        []SyncRule
+	opts         SyncOptions
+	logger       *slog.Logger
+
+	// connections are the group sources of additional Okta orgs keyed by
+	// lowercase connection name.
+	connections map[string]GroupSource
+
+	orgMembers map[string]bool
+	scimLogins map[string]string
+
+	// pending are the member syncs whose second phase runs once every rule
+	// has applied its first phase.
+	pendingMu sync.Mutex
+	pending   []*memberSync
+}
+
+// memberSync is a team's planned member changes with the report they are
+// recorded in.
+type memberSync struct {
+	plan    *client.TeamMemberPlan
+	report  *SyncReport
+	dryRun  bool
+	invited []string
 }
 
-// NewSyncer creates a new Okta to GitHub syncer.
-func NewSyncer(oktaClient *Client, githubClient *client.Client, rules []SyncRule, safetyThreshold float64, logger *slog.Logger) *Syncer {
+// NewSyncer creates a new syncer of groups from source, usually an Okta
+// client, to GitHub teams. rules are executed in priority order.
+func NewSyncer(source GroupSource, githubClient *client.Client, rules []SyncRule, opts SyncOptions, logger *slog.Logger) *Syncer {
 	return &Syncer{
-		oktaClient:      oktaClient,
-		githubClient:    githubClient,
-		rules:           rules,
-		safetyThreshold: safetyThreshold,
-		logger:          logger,
+		source:       source,
+		githubClient: githubClient,
+		rules:        sortRulesByPriority(rules),
+		opts:         opts,
+		logger:       logger,
+	}
+}
+
+// SetConnections sets the group sources of additional Okta orgs, keyed by
+// connection name, that rules select with okta_connection.
+func (s *Syncer) SetConnections(sources map[string]GroupSource) {
+	s.connections = make(map[string]GroupSource, len(sources))
+	for name, source := range sources {
+		s.connections[strings.ToLower(name)] = source
+	}
+}
+
+// sourceFor returns the group source that rule reads groups from. rules
+// without a connection use the syncer's source.
+func (s *Syncer) sourceFor(rule SyncRule) (GroupSource, error) {
+	if rule.OktaConnection == "" {
+		return s.source, nil
+	}
+	source, ok := s.connections[strings.ToLower(rule.OktaConnection)]
+	if !ok || source == nil {
+		return nil, errors.Newf("unknown okta connection '%s' in rule '%s'", rule.OktaConnection, rule.GetName())
 	}
+	return source, nil
+}
+
+// sortRulesByPriority returns a copy of rules ordered by descending
+// priority. the sort is stable so rules with equal priority keep config
+// order, e.g. a parent team's rule can be given a higher priority than its
+// child teams' rules to guarantee it is synced first.
+func sortRulesByPriority(rules []SyncRule) []SyncRule {
+	sorted := slices.Clone(rules)
+	slices.SortStableFunc(sorted, func(a, b SyncRule) int {
+		return cmp.Compare(b.Priority, a.Priority)
+	})
+	return sorted
 }
 
 // SyncResult contains all sync reports and orphaned users report.
 type SyncResult struct {
+	// Reports are sorted by rule name. reports of one rule keep the order
+	// its groups were synced in.
 	Reports       []*SyncReport
 	OrphanedUsers *OrphanedUsersReport
+	DisabledRules []string
+	// EmptyRules are enabled rules that matched no groups or only groups
+	// without members.
+	EmptyRules []EmptyRule
+	// APIQuotas are the GitHub and Okta rate limits observed at the end of
+	// the sync.
+	APIQuotas []types.APIQuota
+	// Skipped counts the distinct users skipped per reason, in SkipReasons
+	// order.
+	Skipped []SkipCount
 }
 
-// Sync executes all enabled sync rules and returns reports.
-// continues processing remaining rules even if some fail.
+// Sync executes all enabled sync rules and returns reports sorted by rule
+// name. continues processing remaining rules even if some fail.
 func (s *Syncer) Sync(ctx context.Context) (*SyncResult, error) {
-	var reports []*SyncReport
-	var failedRuleCount int
+	if err := s.loadOrgMembers(ctx); err != nil {
+		return nil, err
+	}
+	s.loadSCIMLogins(ctx)
 
+	var enabledRules []SyncRule
+	var disabledRules []string
 	for _, rule := range s.rules {
 		if !rule.IsEnabled() {
+			disabledRules = append(disabledRules, rule.GetName())
 			continue
 		}
+		enabledRules = append(enabledRules, rule)
+	}
+
+	outcomes := s.syncRules(enabledRules, func(rule SyncRule) ([]*SyncReport, error) {
+		return s.syncRule(ctx, rule, "")
+	})
+	s.finishMemberSyncs(ctx)
 
-		ruleReports, err := s.syncRule(ctx, rule)
+	var reports []*SyncReport
+	var failedRuleCount int
+
+	for i, rule := range enabledRules {
+		ruleReports, err := outcomes[i].reports, outcomes[i].err
 		if err != nil {
 			failedRuleCount++
 			s.logger.Error("sync rule failed",
@@ -92,7 +431,7 @@ func (s *Syncer) Sync(ctx context.Context) (*SyncResult, error) {
 			reports = append(reports, &SyncReport{
 				Rule:       rule.GetName(),
 				OktaGroup:  rule.OktaGroupName,
-				GitHubTeam: rule.GitHubTeamName,
+				GitHubTeam: cmp.Or(rule.GitHubTeamSlug, rule.GitHubTeamName),
 				Errors:     []string{err.Error()},
 			})
 			continue
@@ -105,22 +444,298 @@ func (s *Syncer) Sync(ctx context.Context) (*SyncResult, error) {
 		return nil, errors.Newf("all sync rules failed: %d errors", failedRuleCount)
 	}
 
+	// rules run in priority order, possibly concurrently, so reports are
+	// sorted by rule name to keep the output deterministic
+	slices.SortStableFunc(reports, func(a, b *SyncReport) int {
+		return strings.Compare(a.Rule, b.Rule)
+	})
+
+	emptyRules := findEmptyRules(enabledRules, outcomes)
+	for _, empty := range emptyRules {
+		s.logger.Warn("sync rule synced nothing",
+			slog.String("rule", empty.Rule),
+			slog.String("reason", empty.Reason))
+	}
+
 	return &SyncResult{
 		Reports:       reports,
 		OrphanedUsers: nil,
+		DisabledRules: disabledRules,
+		EmptyRules:    emptyRules,
+		APIQuotas:     s.apiQuotas(),
+		Skipped:       countSkippedMembers(reports),
 	}, nil
 }
 
-// DetectOrphanedUsers finds organization members not in any synced teams.
-// excludes external collaborators.
-func (s *Syncer) DetectOrphanedUsers(ctx context.Context, syncedTeams []string) (*OrphanedUsersReport, error) {
-	orgMembers, err := s.githubClient.ListOrgMembers(ctx)
+// findEmptyRules returns the rules whose outcome matched no groups, or only
+// groups without members. failed rules are reported as errors instead, and
+// rules that do not sync members are only checked for groups.
+func findEmptyRules(rules []SyncRule, outcomes []ruleOutcome) []EmptyRule {
+	var empty []EmptyRule
+	for i, rule := range rules {
+		reports, err := outcomes[i].reports, outcomes[i].err
+		if err != nil {
+			continue
+		}
+		if len(reports) == 0 {
+			empty = append(empty, EmptyRule{Rule: rule.GetName(), Reason: EmptyRuleNoGroups})
+			continue
+		}
+		if !rule.ShouldSyncMembers() {
+			continue
+		}
+
+		var groups []string
+		for _, report := range reports {
+			if !report.NoOktaMembers || report.HasErrors() {
+				groups = nil
+				break
+			}
+			groups = append(groups, report.OktaGroup)
+		}
+		if len(groups) > 0 {
+			empty = append(empty, EmptyRule{Rule: rule.GetName(), Reason: EmptyRuleNoMembers, OktaGroups: groups})
+		}
+	}
+	return empty
+}
+
+// ruleOutcome is the result of syncing one rule.
+type ruleOutcome struct {
+	reports []*SyncReport
+	err     error
+}
+
+// syncRules runs syncOne for each rule and returns the outcomes in rule
+// order. with Parallelism above 1, rules of equal priority run concurrently
+// on a bounded pool, and each priority tier finishes before the next starts
+// so parent teams are still synced before their children.
+func (s *Syncer) syncRules(rules []SyncRule, syncOne func(SyncRule) ([]*SyncReport, error)) []ruleOutcome {
+	outcomes := make([]ruleOutcome, len(rules))
+
+	if s.opts.Parallelism < 2 {
+		for i, rule := range rules {
+			outcomes[i].reports, outcomes[i].err = syncOne(rule)
+		}
+		return outcomes
+	}
+
+	sem := make(chan struct{}, s.opts.Parallelism)
+	for start := 0; start < len(rules); {
+		end := start + 1
+		for end < len(rules) && rules[end].Priority == rules[start].Priority {
+			end++
+		}
+
+		var wg sync.WaitGroup
+		for i := start; i < end; i++ {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				outcomes[i].reports, outcomes[i].err = syncOne(rules[i])
+			}()
+		}
+		wg.Wait()
+
+		start = end
+	}
+
+	return outcomes
+}
+
+// SyncTeam executes only the enabled rules that manage the given GitHub team.
+// used for targeted reconciliation after an external membership change.
+func (s *Syncer) SyncTeam(ctx context.Context, teamSlug string) (*SyncResult, error) {
+	if err := s.loadOrgMembers(ctx); err != nil {
+		return nil, err
+	}
+	s.loadSCIMLogins(ctx)
+
+	var reports []*SyncReport
+
+	for _, rule := range s.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+
+		ruleReports, err := s.syncRule(ctx, rule, teamSlug)
+		if err != nil {
+			s.finishMemberSyncs(ctx)
+			return nil, errors.Wrapf(err, "failed to sync rule '%s' for team '%s'", rule.GetName(), teamSlug)
+		}
+
+		reports = append(reports, ruleReports...)
+	}
+	s.finishMemberSyncs(ctx)
+
+	return &SyncResult{
+		Reports:   reports,
+		APIQuotas: s.apiQuotas(),
+		Skipped:   countSkippedMembers(reports),
+	}, nil
+}
+
+// apiQuotas returns the most recent GitHub and Okta rate limits observed by
+// the clients, omitting services with no tracked responses.
+func (s *Syncer) apiQuotas() []types.APIQuota {
+	var quotas []types.APIQuota
+	if s.githubClient != nil {
+		if q, ok := s.githubClient.APIQuota(); ok {
+			quotas = append(quotas, q)
+		}
+	}
+	if reporter, ok := s.source.(quotaReporter); ok {
+		if q, ok := reporter.APIQuota(); ok {
+			quotas = append(quotas, q)
+		}
+	}
+	return quotas
+}
+
+// loadOrgMembers precomputes the org member set when sync is restricted to
+// existing org members.
+func (s *Syncer) loadOrgMembers(ctx context.Context) error {
+	if !s.opts.OrgMembersOnly {
+		return nil
+	}
+
+	members := make(map[string]bool)
+	err := s.githubClient.ForEachOrgMember(ctx, func(login string) error {
+		members[strings.ToLower(login)] = true
+		return nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to list organization members")
+		return errors.Wrap(err, "failed to load organization members")
 	}
 
-	syncedUsers := make(map[string]bool)
-	for _, teamSlug := range syncedTeams {
+	s.orgMembers = members
+	return nil
+}
+
+// loadSCIMLogins precomputes the email to login map from the org's SAML/SCIM
+// identities when username resolution is enabled. failures are logged and
+// leave users without a GitHub username skipped.
+func (s *Syncer) loadSCIMLogins(ctx context.Context) {
+	s.scimLogins = nil
+	if !s.opts.ResolveUsernamesViaSCIM {
+		return
+	}
+
+	logins, err := s.githubClient.GetExternalIdentityLogins(ctx)
+	if err != nil {
+		s.logger.Warn("failed to load scim identities, users without a github username will be skipped",
+			slog.String("error", err.Error()))
+		return
+	}
+
+	s.scimLogins = logins
+}
+
+// resolveSCIMUsernames splits emails of users without a GitHub username into
+// logins resolved via SAML/SCIM identities and emails that remain unresolved.
+func (s *Syncer) resolveSCIMUsernames(emails []string) (resolved, unresolved []string) {
+	if s.scimLogins == nil {
+		return nil, emails
+	}
+
+	for _, email := range emails {
+		if login, ok := s.scimLogins[strings.ToLower(email)]; ok {
+			resolved = append(resolved, login)
+		} else {
+			unresolved = append(unresolved, email)
+		}
+	}
+	return resolved, unresolved
+}
+
+// filterOrgMembers splits desired members into current org members and those
+// not in the org. returns members unchanged when not restricted.
+func (s *Syncer) filterOrgMembers(members []string) (inOrg, notInOrg []string) {
+	if s.orgMembers == nil {
+		return members, nil
+	}
+
+	inOrg = make([]string, 0, len(members))
+	for _, member := range members {
+		if s.orgMembers[strings.ToLower(member)] {
+			inOrg = append(inOrg, member)
+		} else {
+			notInOrg = append(notInOrg, member)
+		}
+	}
+	return inOrg, notInOrg
+}
+
+// RuleTeams returns the GitHub teams the enabled sync rules map to, computed
+// from the rules and the current Okta group names without syncing or
+// fetching group members. teams are deduplicated case-insensitively.
+func (s *Syncer) RuleTeams() ([]string, error) {
+	var teams []string
+	seen := make(map[string]bool)
+	add := func(team string) {
+		if !seen[strings.ToLower(team)] {
+			seen[strings.ToLower(team)] = true
+			teams = append(teams, team)
+		}
+	}
+
+	for _, rule := range s.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+
+		// fixed teams do not depend on the group name
+		if team := cmp.Or(rule.GitHubTeamSlug, rule.GitHubTeamName); team != "" {
+			add(team)
+			continue
+		}
+
+		source, err := s.sourceFor(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		var groups []*GroupInfo
+		if rule.OktaGroupPattern != "" {
+			matched, err := source.GetGroupNamesByPattern(rule.OktaGroupPattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to match groups for rule '%s'", rule.GetName())
+			}
+			groups = matched
+		} else if rule.OktaGroupName != "" {
+			group, err := source.GetGroupByName(rule.OktaGroupName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to fetch group for rule '%s'", rule.GetName())
+			}
+			groups = []*GroupInfo{group}
+		}
+
+		for _, group := range groups {
+			team, err := s.computeTeamName(s.teamSourceName(group), rule)
+			if err != nil {
+				return nil, err
+			}
+			add(team)
+		}
+	}
+
+	return teams, nil
+}
+
+// DetectOrphanedUsers finds organization members not in any synced teams.
+// members of the OrphanExemptTeams and the OrphanExemptLogins are covered
+// like synced team members. outside collaborators are listed once up front
+// rather than checked per member; the per-member check is only used if that
+// listing fails. org members are streamed page by page to keep memory
+// bounded for large organizations.
+func (s *Syncer) DetectOrphanedUsers(ctx context.Context, syncedTeams []string) (*OrphanedUsersReport, error) {
+	coveredUsers := make(map[string]bool)
+	for _, login := range s.opts.OrphanExemptLogins {
+		coveredUsers[strings.ToLower(login)] = true
+	}
+	for _, teamSlug := range slices.Concat(syncedTeams, s.opts.OrphanExemptTeams) {
 		members, err := s.githubClient.GetTeamMembers(ctx, teamSlug)
 		if err != nil {
 			s.logger.Warn("failed to get team members for orphaned user check",
@@ -129,25 +744,44 @@ func (s *Syncer) DetectOrphanedUsers(ctx context.Context, syncedTeams []string)
 			continue
 		}
 		for _, member := range members {
-			syncedUsers[member] = true
+			coveredUsers[strings.ToLower(member)] = true
 		}
 	}
 
+	outsideCollaborators, err := s.outsideCollaborators(ctx)
+	if err != nil {
+		s.logger.Warn("failed to list outside collaborators, checking each user instead",
+			slog.String("error", err.Error()))
+	}
+
 	var orphanedUsers []string
-	for _, member := range orgMembers {
-		if !syncedUsers[member] {
-			isExternal, err := s.githubClient.IsExternalCollaborator(ctx, member)
-			if err != nil {
-				s.logger.Warn("failed to check if user is external for orphaned user check",
-					slog.String("user", member),
-					slog.String("error", err.Error()))
-				continue
-			}
+	err = s.githubClient.ForEachOrgMember(ctx, func(member string) error {
+		if coveredUsers[strings.ToLower(member)] {
+			return nil
+		}
 
-			if !isExternal {
+		if outsideCollaborators != nil {
+			if !outsideCollaborators[strings.ToLower(member)] {
 				orphanedUsers = append(orphanedUsers, member)
 			}
+			return nil
+		}
+
+		isExternal, err := s.githubClient.IsExternalCollaborator(ctx, member)
+		if err != nil {
+			s.logger.Warn("failed to check if user is external for orphaned user check",
+				slog.String("user", member),
+				slog.String("error", err.Error()))
+			return nil
+		}
+
+		if !isExternal {
+			orphanedUsers = append(orphanedUsers, member)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list organization members")
 	}
 
 	return &OrphanedUsersReport{
@@ -155,29 +789,266 @@ func (s *Syncer) DetectOrphanedUsers(ctx context.Context, syncedTeams []string)
 	}, nil
 }
 
+// outsideCollaborators returns the lowercased logins of the org's outside
+// collaborators as a set, fetched once per orphaned user check.
+func (s *Syncer) outsideCollaborators(ctx context.Context) (map[string]bool, error) {
+	logins, err := s.githubClient.ListOutsideCollaborators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]bool, len(logins))
+	for _, login := range logins {
+		set[strings.ToLower(login)] = true
+	}
+	return set, nil
+}
+
+// DetectMissingUsernames collects active members of every enabled rule's
+// Okta groups that have no GitHub username. users resolved via SAML/SCIM
+// identities, when enabled, are not reported. a rule whose groups cannot be
+// read is recorded in the report's errors and the rest are still checked.
+func (s *Syncer) DetectMissingUsernames(ctx context.Context) (*MissingUsernamesReport, error) {
+	s.loadSCIMLogins(ctx)
+
+	report := &MissingUsernamesReport{}
+	var groups []*GroupInfo
+	for _, rule := range s.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+
+		source, err := s.sourceFor(rule)
+		if err == nil && rule.OktaGroupPattern != "" {
+			var matched []*GroupInfo
+			matched, err = source.GetGroupsByPattern(rule.OktaGroupPattern)
+			groups = append(groups, matched...)
+		} else if err == nil && rule.OktaGroupName != "" {
+			var group *GroupInfo
+			group, err = source.GetGroupInfo(rule.OktaGroupName)
+			if group != nil {
+				groups = append(groups, group)
+			}
+		}
+		if err != nil {
+			s.logger.Warn("failed to read okta groups for missing username check",
+				slog.String("rule", rule.GetName()),
+				slog.String("error", err.Error()))
+			report.Errors = append(report.Errors, fmt.Sprintf("rule '%s': %s", rule.GetName(), err))
+		}
+	}
+
+	if len(groups) == 0 && len(report.Errors) > 0 {
+		return nil, errors.Newf("failed to read okta groups for all rules: %s", strings.Join(report.Errors, "; "))
+	}
+
+	for _, group := range groups {
+		_, group.SkippedNoGitHubUsername = s.resolveSCIMUsernames(group.SkippedNoGitHubUsername)
+	}
+	report.Users = aggregateMissingUsernames(groups)
+	return report, nil
+}
+
+// aggregateMissingUsernames merges the users without a GitHub username of
+// each group into one list sorted by email. emails are compared
+// case-insensitively and each user lists every group they belong to.
+func aggregateMissingUsernames(groups []*GroupInfo) []MissingUsername {
+	byEmail := make(map[string]*MissingUsername)
+	for _, group := range groups {
+		for _, email := range group.SkippedNoGitHubUsername {
+			key := strings.ToLower(email)
+			user, ok := byEmail[key]
+			if !ok {
+				user = &MissingUsername{Email: email}
+				byEmail[key] = user
+			}
+			if !slices.Contains(user.Groups, group.Name) {
+				user.Groups = append(user.Groups, group.Name)
+			}
+		}
+	}
+
+	users := make([]MissingUsername, 0, len(byEmail))
+	for _, user := range byEmail {
+		slices.Sort(user.Groups)
+		users = append(users, *user)
+	}
+	slices.SortFunc(users, func(a, b MissingUsername) int {
+		return strings.Compare(strings.ToLower(a.Email), strings.ToLower(b.Email))
+	})
+	return users
+}
+
+// DiagnoseRules checks the team each enabled rule's Okta groups map to
+// without changing anything. a missing team is reported as would_create for
+// rules that create teams by name, so a team deleted by hand is not silently
+// recreated, and as team_not_found for rules bound to a github_team_slug.
+// for existing teams of rules that sync members, the members a sync would
+// add or remove are listed; pending invitations are not considered.
+func (s *Syncer) DiagnoseRules(ctx context.Context) (*RuleDiagnosticsReport, error) {
+	if err := s.loadOrgMembers(ctx); err != nil {
+		return nil, err
+	}
+	s.loadSCIMLogins(ctx)
+
+	report := &RuleDiagnosticsReport{Rules: []RuleDiagnosis{}}
+	for _, rule := range s.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+
+		var groups []*GroupInfo
+		source, err := s.sourceFor(rule)
+		if err == nil && rule.OktaGroupPattern != "" {
+			groups, err = source.GetGroupsByPattern(rule.OktaGroupPattern)
+			err = errors.Wrapf(err, "failed to match groups with pattern '%s'", rule.OktaGroupPattern)
+		} else if err == nil && rule.OktaGroupName != "" {
+			var group *GroupInfo
+			group, err = source.GetGroupInfo(rule.OktaGroupName)
+			err = errors.Wrapf(err, "failed to fetch group '%s'", rule.OktaGroupName)
+			if group != nil {
+				groups = append(groups, group)
+			}
+		}
+		if err != nil {
+			report.Rules = append(report.Rules, RuleDiagnosis{
+				Rule:   rule.GetName(),
+				Status: RuleStatusError,
+				Error:  err.Error(),
+			})
+			continue
+		}
+
+		for _, group := range groups {
+			report.Rules = append(report.Rules, s.diagnoseGroup(ctx, rule, group))
+		}
+	}
+	return report, nil
+}
+
+// diagnoseGroup checks the team a single Okta group maps to under rule.
+func (s *Syncer) diagnoseGroup(ctx context.Context, rule SyncRule, group *GroupInfo) RuleDiagnosis {
+	diagnosis := RuleDiagnosis{Rule: rule.GetName(), OktaGroup: group.Name}
+	fail := func(err error) RuleDiagnosis {
+		diagnosis.Status = RuleStatusError
+		diagnosis.Error = err.Error()
+		return diagnosis
+	}
+
+	teamName, err := s.computeTeamName(s.teamSourceName(group), rule)
+	if err != nil {
+		return fail(err)
+	}
+	diagnosis.GitHubTeam = teamName
+
+	var team *github.Team
+	if rule.GitHubTeamSlug != "" {
+		team, err = s.githubClient.GetTeamBySlug(ctx, rule.GitHubTeamSlug)
+		if errors.Is(err, internalerrors.ErrTeamNotFound) {
+			diagnosis.Status = RuleStatusTeamNotFound
+			return diagnosis
+		}
+	} else {
+		team, err = s.githubClient.FindTeam(ctx, teamName)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if team == nil {
+		diagnosis.Status = RuleStatusWouldCreate
+		return diagnosis
+	}
+
+	diagnosis.Status = RuleStatusInSync
+	if !rule.ShouldSyncMembers() {
+		return diagnosis
+	}
+
+	current, err := s.githubClient.GetTeamMembers(ctx, team.GetSlug())
+	if err != nil {
+		return fail(err)
+	}
+
+	resolved, _ := s.resolveSCIMUsernames(group.SkippedNoGitHubUsername)
+	desired, _ := rule.ApplyMemberOverrides(append(slices.Clone(group.Members), resolved...))
+	desired, _ = s.filterOrgMembers(desired)
+
+	diagnosis.MembersToAdd, diagnosis.MembersToRemove = memberDrift(desired, current, rule.IsPreservedMember)
+	if len(diagnosis.MembersToAdd) > 0 || len(diagnosis.MembersToRemove) > 0 {
+		diagnosis.Status = RuleStatusOutOfSync
+	}
+	return diagnosis
+}
+
+// memberDrift returns the desired members missing from current and the
+// current members that are not desired and not preserved. logins are
+// compared case-insensitively.
+func memberDrift(desired, current []string, isPreserved func(string) bool) (toAdd, toRemove []string) {
+	currentSet := make(map[string]bool, len(current))
+	for _, login := range current {
+		currentSet[strings.ToLower(login)] = true
+	}
+	desiredSet := make(map[string]bool, len(desired))
+	for _, login := range desired {
+		key := strings.ToLower(login)
+		if !currentSet[key] && !desiredSet[key] {
+			toAdd = append(toAdd, login)
+		}
+		desiredSet[key] = true
+	}
+	for _, login := range current {
+		if !desiredSet[strings.ToLower(login)] && !isPreserved(login) {
+			toRemove = append(toRemove, login)
+		}
+	}
+	return toAdd, toRemove
+}
+
 // syncRule executes a single sync rule.
-// supports both pattern matching and exact group name matching.
-func (s *Syncer) syncRule(ctx context.Context, rule SyncRule) ([]*SyncReport, error) {
+// supports both pattern matching and exact group name matching. when
+// onlyTeam is set to a team slug, groups mapping to other teams are skipped.
+func (s *Syncer) syncRule(ctx context.Context, rule SyncRule, onlyTeam string) ([]*SyncReport, error) {
 	var reports []*SyncReport
 
+	source, err := s.sourceFor(rule)
+	if err != nil {
+		return nil, err
+	}
+
 	if rule.OktaGroupPattern != "" {
-		groups, err := s.oktaClient.GetGroupsByPattern(rule.OktaGroupPattern)
+		groups, err := source.GetGroupsByPattern(rule.OktaGroupPattern)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to match groups with pattern '%s'", rule.OktaGroupPattern)
 		}
 
 		for _, group := range groups {
-			teamName := s.computeTeamName(group.Name, rule)
+			teamName, err := s.computeTeamName(s.teamSourceName(group), rule)
+			if err != nil {
+				return nil, err
+			}
+			if onlyTeam != "" && !isTeamSlug(teamName, onlyTeam) {
+				continue
+			}
 			report := s.syncGroupToTeam(ctx, rule, group, teamName)
 			reports = append(reports, report)
 		}
 	} else if rule.OktaGroupName != "" {
-		group, err := s.oktaClient.GetGroupInfo(rule.OktaGroupName)
+		if onlyTeam != "" && rule.GitHubTeamSlug == "" && rule.GitHubTeamName != "" && !isTeamSlug(rule.GitHubTeamName, onlyTeam) {
+			return nil, nil
+		}
+
+		group, err := source.GetGroupInfo(rule.OktaGroupName)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to fetch group '%s'", rule.OktaGroupName)
 		}
 
-		teamName := s.computeTeamName(group.Name, rule)
+		teamName, err := s.computeTeamName(s.teamSourceName(group), rule)
+		if err != nil {
+			return nil, err
+		}
+		if onlyTeam != "" && !isTeamSlug(teamName, onlyTeam) {
+			return nil, nil
+		}
 		report := s.syncGroupToTeam(ctx, rule, group, teamName)
 		reports = append(reports, report)
 	}
@@ -185,55 +1056,101 @@ func (s *Syncer) syncRule(ctx context.Context, rule SyncRule) ([]*SyncReport, er
 	return reports, nil
 }
 
+// isTeamSlug reports whether teamName, a team name or slug, has the given
+// team slug, ignoring case. rule team names such as "Platform Eng" are
+// compared by their slug, since webhooks identify teams by slug.
+func isTeamSlug(teamName, slug string) bool {
+	return strings.EqualFold(client.TeamSlug(teamName), slug)
+}
+
+// teamSourceName returns the group name used to compute the team name.
+// uses the CN of Active Directory group DNs when enabled.
+func (s *Syncer) teamSourceName(group *GroupInfo) string {
+	if s.opts.ADUseCommonName && group.IsActiveDirectory {
+		return CommonName(group.Name)
+	}
+	return group.Name
+}
+
 // computeTeamName generates GitHub team name from Okta group name.
-// applies prefix stripping, prefix addition, and normalization.
-func (s *Syncer) computeTeamName(oktaGroupName string, rule SyncRule) string {
+// an existing team slug takes precedence, then an exact team name, then the
+// team name template, then strip/prefix rules. the result is normalized to a
+// valid team slug.
+func (s *Syncer) computeTeamName(oktaGroupName string, rule SyncRule) (string, error) {
+	if rule.GitHubTeamSlug != "" {
+		return rule.GitHubTeamSlug, nil
+	}
 	if rule.GitHubTeamName != "" {
-		return rule.GitHubTeamName
+		return rule.GitHubTeamName, nil
 	}
 
-	teamName := oktaGroupName
+	var teamName string
+	if rule.TeamNameTemplate != "" {
+		rendered, err := rule.ExecuteTeamNameTemplate(oktaGroupName)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to render team name template for group '%s'", oktaGroupName)
+		}
+		teamName = rendered
+	} else {
+		teamName = oktaGroupName
 
-	if rule.StripPrefix != "" {
-		teamName = strings.TrimPrefix(teamName, rule.StripPrefix)
-	}
+		if rule.StripPrefix != "" {
+			teamName = strings.TrimPrefix(teamName, rule.StripPrefix)
+		}
 
-	if rule.GitHubTeamPrefix != "" {
-		teamName = rule.GitHubTeamPrefix + teamName
+		if rule.GitHubTeamPrefix != "" {
+			teamName = rule.GitHubTeamPrefix + teamName
+		}
 	}
 
-	teamName = strings.ToLower(teamName)
-	teamName = regexp.MustCompile(`[^a-z0-9-]`).ReplaceAllString(teamName, "-")
-
-	return teamName
+	return client.TeamSlug(teamName), nil
 }
 
 // syncGroupToTeam synchronizes a single Okta group to a GitHub team.
-// creates team if missing and syncs members if enabled.
+// creates team if missing and syncs members if enabled, applying the first
+// member phase and leaving the second to finishMemberSyncs. the rule's dry_run
+// setting can put the rule in dry-run, but the syncer's DryRun option and the
+// GitHub client's dry-run mode always win over a rule's false.
 func (s *Syncer) syncGroupToTeam(ctx context.Context, rule SyncRule, group *GroupInfo, teamName string) *SyncReport {
+	resolved, skippedNoGHUsername := s.resolveSCIMUsernames(group.SkippedNoGitHubUsername)
+
+	globalDryRun := s.opts.DryRun || s.githubClient.DryRun()
+	dryRun := rule.IsDryRun(globalDryRun)
+	ctx = client.WithDryRun(ctx, dryRun)
+
 	report := &SyncReport{
 		Rule:                       rule.GetName(),
 		OktaGroup:                  group.Name,
 		GitHubTeam:                 teamName,
-		MembersSkippedNoGHUsername: group.SkippedNoGitHubUsername,
+		MembersSkippedNoGHUsername: skippedNoGHUsername,
+		MembersResolvedViaSCIM:     resolved,
 		Errors:                     []string{},
+		DryRun:                     dryRun,
+		NoOktaMembers:              len(group.Members) == 0 && len(resolved) == 0,
 	}
 
-	if len(group.SkippedNoGitHubUsername) > 0 {
-		s.logger.Warn("okta users skipped due to missing github username",
+	if dryRun && !globalDryRun {
+		s.logger.Info("sync rule runs in dry-run mode",
+			slog.String("rule", rule.GetName()),
+			slog.String("team", teamName),
+			slog.Bool("dry_run", dryRun))
+	}
+
+	if len(resolved) > 0 {
+		s.logger.Info("okta users without github username resolved via scim",
 			slog.String("group", group.Name),
-			slog.Int("count", len(group.SkippedNoGitHubUsername)))
+			slog.Int("count", len(resolved)))
 	}
 
-	privacy := "closed"
-	if rule.TeamPrivacy != "" {
-		privacy = rule.TeamPrivacy
+	if len(skippedNoGHUsername) > 0 {
+		s.logger.Warn("okta users skipped due to missing github username",
+			slog.String("group", group.Name),
+			slog.Int("count", len(skippedNoGHUsername)))
 	}
 
-	team, err := s.githubClient.GetOrCreateTeam(ctx, teamName, privacy)
+	team, err := s.resolveTeam(ctx, rule, teamName)
 	if err != nil {
-		errMsg := fmt.Sprintf("failed to get/create team '%s': %v", teamName, err)
-		report.Errors = append(report.Errors, errMsg)
+		report.Errors = append(report.Errors, err.Error())
 		return report
 	}
 
@@ -243,6 +1160,18 @@ func (s *Syncer) syncGroupToTeam(ctx context.Context, rule SyncRule, group *Grou
 		return report
 	}
 
+	if rule.TeamDescription != "" {
+		updated, err := s.githubClient.SetTeamDescription(ctx, team, rule.TeamDescription)
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else if updated {
+			report.DescriptionUpdated = true
+			s.logger.Info("team description updated from sync rule",
+				slog.String("rule", rule.GetName()),
+				slog.String("team", teamName))
+		}
+	}
+
 	if !rule.ShouldSyncMembers() {
 		return report
 	}
@@ -251,17 +1180,183 @@ func (s *Syncer) syncGroupToTeam(ctx context.Context, rule SyncRule, group *Grou
 	if team.Slug != nil {
 		teamSlug = *team.Slug
 	}
+	s.warnTeamSlugMismatch(rule, teamName, teamSlug)
+
+	members := group.Members
+	if len(resolved) > 0 {
+		members = append(slices.Clone(group.Members), resolved...)
+	}
 
-	syncResult, err := s.githubClient.SyncTeamMembers(ctx, teamSlug, group.Members, s.safetyThreshold)
+	members, forced := rule.ApplyMemberOverrides(members)
+	if len(forced) > 0 {
+		report.MembersForceIncluded = forced
+		s.logger.Info("members always included by sync rule",
+			slog.String("rule", rule.GetName()),
+			slog.String("team", teamName),
+			slog.Int("count", len(forced)))
+	}
+
+	desiredMembers, notInOrg := s.filterOrgMembers(members)
+	if len(notInOrg) > 0 {
+		report.MembersSkippedNotOrgMember = notInOrg
+		s.logger.Info("okta users skipped because they are not org members",
+			slog.String("group", group.Name),
+			slog.Int("count", len(notInOrg)))
+	}
+
+	var invited []string
+	if s.opts.PendingInvitesAsMembers {
+		desiredMembers, invited = s.excludePendingInvitations(ctx, teamSlug, desiredMembers)
+	}
+
+	plan, err := s.githubClient.PlanTeamMembers(ctx, teamSlug, desiredMembers, s.opts.SafetyThreshold, rule.IsPreservedMember)
 	if err != nil {
 		report.Errors = append(report.Errors, fmt.Sprintf("failed to sync members for team '%s': %v", teamSlug, err))
 		return report
 	}
 
+	s.warnTeamSlugMismatch(rule, teamSlug, plan.TeamSlug)
+
+	if s.opts.RemoveMembersFirst {
+		s.githubClient.ApplyTeamRemovals(ctx, plan)
+	} else {
+		s.githubClient.ApplyTeamAdds(ctx, plan)
+	}
+
+	s.pendingMu.Lock()
+	s.pending = append(s.pending, &memberSync{plan: plan, report: report, dryRun: dryRun, invited: invited})
+	s.pendingMu.Unlock()
+
+	return report
+}
+
+// finishMemberSyncs applies the second phase of every pending member sync,
+// removals by default or adds with RemoveMembersFirst, and records the
+// outcomes in their reports. teams are finished up to Parallelism at once.
+func (s *Syncer) finishMemberSyncs(ctx context.Context) {
+	s.pendingMu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.pendingMu.Unlock()
+
+	if s.opts.Parallelism < 2 {
+		for _, m := range pending {
+			s.finishMemberSync(ctx, m)
+		}
+		return
+	}
+
+	sem := make(chan struct{}, s.opts.Parallelism)
+	var wg sync.WaitGroup
+	for _, m := range pending {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.finishMemberSync(ctx, m)
+		}()
+	}
+	wg.Wait()
+}
+
+// finishMemberSync applies the second phase of m, verifies adds when
+// enabled, and copies the result into m's report.
+func (s *Syncer) finishMemberSync(ctx context.Context, m *memberSync) {
+	ctx = client.WithDryRun(ctx, m.dryRun)
+	if s.opts.RemoveMembersFirst {
+		s.githubClient.ApplyTeamAdds(ctx, m.plan)
+	} else {
+		s.githubClient.ApplyTeamRemovals(ctx, m.plan)
+	}
+
+	report, syncResult := m.report, m.plan.Result
+
+	if s.opts.VerifyMembership {
+		if err := s.githubClient.VerifyTeamMembers(ctx, syncResult); err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		} else if len(syncResult.MembersPending) > 0 {
+			s.logger.Info("team memberships pending after sync",
+				slog.String("team", syncResult.TeamName),
+				slog.Int("count", len(syncResult.MembersPending)))
+		}
+	}
+
 	report.MembersAdded = syncResult.MembersAdded
 	report.MembersRemoved = syncResult.MembersRemoved
 	report.MembersSkippedExternal = syncResult.MembersSkippedExternal
+	report.SafetyThresholdBlocked = syncResult.SafetyThresholdBlocked
+	report.MembersPreserved = syncResult.MembersPreserved
+	report.MembersPending = append(m.invited, syncResult.MembersPending...)
 	report.Errors = append(report.Errors, syncResult.Errors...)
+}
 
-	return report
+// excludePendingInvitations splits desired members into those to sync and
+// those already invited to the team. if invitations cannot be listed, all
+// members are synced so a lookup failure never blocks adds.
+func (s *Syncer) excludePendingInvitations(ctx context.Context, teamSlug string, desired []string) (remaining, invited []string) {
+	pending, err := s.githubClient.ListPendingTeamInvitations(ctx, teamSlug)
+	if err != nil {
+		s.logger.Warn("failed to list pending team invitations, syncing without them",
+			slog.String("team", teamSlug),
+			slog.String("error", err.Error()))
+		return desired, nil
+	}
+	if len(pending) == 0 {
+		return desired, nil
+	}
+
+	pendingSet := make(map[string]bool, len(pending))
+	for _, login := range pending {
+		pendingSet[strings.ToLower(login)] = true
+	}
+
+	for _, member := range desired {
+		if pendingSet[strings.ToLower(member)] {
+			invited = append(invited, member)
+		} else {
+			remaining = append(remaining, member)
+		}
+	}
+	if len(invited) > 0 {
+		s.logger.Info("desired members have pending team invitations",
+			slog.String("team", teamSlug),
+			slog.Int("count", len(invited)))
+	}
+	return remaining, invited
+}
+
+// resolveTeam returns the team a rule syncs into. rules bound to an existing
+// team slug only look the team up; other rules create the team if missing.
+func (s *Syncer) resolveTeam(ctx context.Context, rule SyncRule, teamName string) (*github.Team, error) {
+	if rule.GitHubTeamSlug != "" {
+		team, err := s.githubClient.GetTeamBySlug(ctx, rule.GitHubTeamSlug)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve team slug '%s'", rule.GitHubTeamSlug)
+		}
+		return team, nil
+	}
+
+	privacy := "closed"
+	if rule.TeamPrivacy != "" {
+		privacy = rule.TeamPrivacy
+	}
+
+	team, err := s.githubClient.GetOrCreateTeam(ctx, teamName, privacy, rule.ParentTeamSlug)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get/create team '%s'", teamName)
+	}
+	return team, nil
+}
+
+// warnTeamSlugMismatch logs a warning when GitHub's slug for a team differs
+// only in case from the slug the rule computed, so the rule can be fixed.
+func (s *Syncer) warnTeamSlugMismatch(rule SyncRule, computed, actual string) {
+	if actual == computed || !strings.EqualFold(actual, computed) {
+		return
+	}
+	s.logger.Warn("github team slug differs in case from sync rule, using github's slug",
+		slog.String("rule", rule.GetName()),
+		slog.String("team", computed),
+		slog.String("github_slug", actual))
 }
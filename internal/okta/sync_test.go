@@ -0,0 +1,883 @@
+package okta
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/errors"
+	"github.com/cruxstack/github-ops-app/internal/github/client"
+)
+
+func TestFilterOrgMembers(t *testing.T) {
+	members := []string{"alice", "Bob", "carol"}
+
+	t.Run("unrestricted returns members unchanged", func(t *testing.T) {
+		s := &Syncer{}
+		inOrg, notInOrg := s.filterOrgMembers(members)
+		if !reflect.DeepEqual(inOrg, members) {
+			t.Errorf("inOrg = %v, want %v", inOrg, members)
+		}
+		if len(notInOrg) != 0 {
+			t.Errorf("notInOrg = %v, want empty", notInOrg)
+		}
+	})
+
+	t.Run("restricted splits by org membership case-insensitively", func(t *testing.T) {
+		s := &Syncer{orgMembers: map[string]bool{"alice": true, "bob": true}}
+		inOrg, notInOrg := s.filterOrgMembers(members)
+		if want := []string{"alice", "Bob"}; !reflect.DeepEqual(inOrg, want) {
+			t.Errorf("inOrg = %v, want %v", inOrg, want)
+		}
+		if want := []string{"carol"}; !reflect.DeepEqual(notInOrg, want) {
+			t.Errorf("notInOrg = %v, want %v", notInOrg, want)
+		}
+	})
+}
+
+func TestComputeTeamName_ActiveDirectory(t *testing.T) {
+	group := &GroupInfo{
+		Name:              "CN=Platform Eng,OU=Groups,DC=example,DC=com",
+		IsActiveDirectory: true,
+	}
+	rule := SyncRule{GitHubTeamPrefix: "ad-"}
+
+	s := &Syncer{opts: SyncOptions{ADUseCommonName: true}}
+	if got, _ := s.computeTeamName(s.teamSourceName(group), rule); got != "ad-platform-eng" {
+		t.Errorf("team name = %q, want %q", got, "ad-platform-eng")
+	}
+
+	s = &Syncer{}
+	if got := s.teamSourceName(group); got != group.Name {
+		t.Errorf("expected full dn when disabled, got %q", got)
+	}
+
+	s = &Syncer{opts: SyncOptions{ADUseCommonName: true}}
+	oktaGroup := &GroupInfo{Name: "CN=not-ad"}
+	if got := s.teamSourceName(oktaGroup); got != oktaGroup.Name {
+		t.Errorf("expected okta group name unchanged, got %q", got)
+	}
+}
+
+func TestComputeTeamName_Slug(t *testing.T) {
+	rule := SyncRule{GitHubTeamSlug: "platform-eng", GitHubTeamPrefix: "okta-"}
+
+	s := &Syncer{}
+	if got, _ := s.computeTeamName("Platform Engineering", rule); got != "platform-eng" {
+		t.Errorf("team name = %q, want %q", got, "platform-eng")
+	}
+	if got := rule.GetName(); got != "platform-eng" {
+		t.Errorf("rule name = %q, want slug", got)
+	}
+}
+
+func TestRuleTeams_FixedTeams(t *testing.T) {
+	disabled := false
+	s := &Syncer{rules: []SyncRule{
+		{OktaGroupName: "Platform Engineering", GitHubTeamSlug: "platform-eng"},
+		{OktaGroupPattern: "^eng-", GitHubTeamName: "Engineering"},
+		{OktaGroupName: "eng-oncall", GitHubTeamName: "engineering"},
+		{OktaGroupName: "legacy", GitHubTeamName: "legacy", Enabled: &disabled},
+	}}
+
+	teams, err := s.RuleTeams()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(teams, []string{"platform-eng", "Engineering"}) {
+		t.Errorf("expected deduplicated enabled teams, got %v", teams)
+	}
+}
+
+func TestComputeTeamName_Template(t *testing.T) {
+	s := &Syncer{}
+
+	tests := []struct {
+		name  string
+		group string
+		rule  SyncRule
+		want  string
+	}{
+		{
+			name:  "template with group",
+			group: "Platform",
+			rule:  SyncRule{TeamNameTemplate: "team-{{.Group}}-prod"},
+			want:  "team-platform-prod",
+		},
+		{
+			name:  "template with stripped group takes precedence over prefix",
+			group: "github-eng-Backend API",
+			rule: SyncRule{
+				StripPrefix:      "github-eng-",
+				GitHubTeamPrefix: "eng-",
+				TeamNameTemplate: "{{.StrippedGroup}}",
+			},
+			want: "backend-api",
+		},
+		{
+			name:  "exact team name takes precedence over template",
+			group: "Platform",
+			rule:  SyncRule{GitHubTeamName: "platform", TeamNameTemplate: "team-{{.Group}}"},
+			want:  "platform",
+		},
+		{
+			name:  "no template uses strip and prefix",
+			group: "github-eng-frontend",
+			rule:  SyncRule{StripPrefix: "github-eng-", GitHubTeamPrefix: "eng-"},
+			want:  "eng-frontend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.computeTeamName(tt.group, tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("team name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := s.computeTeamName("x", SyncRule{TeamNameTemplate: "{{.Unknown}}"}); err == nil {
+		t.Error("expected error for unknown template field")
+	}
+}
+
+func TestSyncReportCompareWithPrevious(t *testing.T) {
+	report := &SyncReport{
+		MembersAdded:   []string{"alice", "Bob"},
+		MembersRemoved: []string{"carol", "dave", "erin"},
+	}
+	report.CompareWithPrevious([]string{"carol"}, []string{"bob"})
+
+	trend := report.SincePrevious
+	if trend == nil {
+		t.Fatal("expected trend to be set")
+	}
+	if want := []string{"alice"}; !reflect.DeepEqual(trend.NewlyAdded, want) {
+		t.Errorf("NewlyAdded = %v, want %v", trend.NewlyAdded, want)
+	}
+	if want := []string{"Bob", "carol"}; !reflect.DeepEqual(trend.Churned, want) {
+		t.Errorf("Churned = %v, want %v", trend.Churned, want)
+	}
+	if trend.NetChange != -1 {
+		t.Errorf("NetChange = %d, want -1", trend.NetChange)
+	}
+}
+
+func TestResolveSCIMUsernames(t *testing.T) {
+	emails := []string{"Alice@example.com", "bob@example.com"}
+
+	s := &Syncer{}
+	resolved, unresolved := s.resolveSCIMUsernames(emails)
+	if len(resolved) != 0 || !reflect.DeepEqual(unresolved, emails) {
+		t.Errorf("expected no resolution when disabled, got %v, %v", resolved, unresolved)
+	}
+
+	s = &Syncer{scimLogins: map[string]string{"alice@example.com": "alice-gh"}}
+	resolved, unresolved = s.resolveSCIMUsernames(emails)
+	if want := []string{"alice-gh"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("resolved = %v, want %v", resolved, want)
+	}
+	if want := []string{"bob@example.com"}; !reflect.DeepEqual(unresolved, want) {
+		t.Errorf("unresolved = %v, want %v", unresolved, want)
+	}
+}
+
+func TestAggregateMissingUsernames(t *testing.T) {
+	groups := []*GroupInfo{
+		{Name: "platform", SkippedNoGitHubUsername: []string{"carol@example.com", "Alice@example.com"}},
+		{Name: "eng", SkippedNoGitHubUsername: []string{"alice@example.com", "bob@example.com"}},
+		{Name: "eng", SkippedNoGitHubUsername: []string{"bob@example.com"}},
+		{Name: "empty"},
+	}
+
+	want := []MissingUsername{
+		{Email: "Alice@example.com", Groups: []string{"eng", "platform"}},
+		{Email: "bob@example.com", Groups: []string{"eng"}},
+		{Email: "carol@example.com", Groups: []string{"platform"}},
+	}
+	if got := aggregateMissingUsernames(groups); !reflect.DeepEqual(got, want) {
+		t.Errorf("aggregateMissingUsernames() = %+v, want %+v", got, want)
+	}
+
+	if got := aggregateMissingUsernames(nil); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty non-nil list, got %#v", got)
+	}
+}
+
+func TestDetectMissingUsernames(t *testing.T) {
+	source := fakeGroupSource{
+		"github-eng":      {Name: "github-eng", SkippedNoGitHubUsername: []string{"bob@example.com", "alice@example.com"}},
+		"github-platform": {Name: "github-platform", SkippedNoGitHubUsername: []string{"alice@example.com"}},
+		"sales":           {Name: "sales", SkippedNoGitHubUsername: []string{"dave@example.com"}},
+	}
+	disabled := false
+
+	t.Run("reports enabled rules and unreadable groups", func(t *testing.T) {
+		s := NewSyncer(source, nil, []SyncRule{
+			{Name: "eng", OktaGroupPattern: "^github-"},
+			{Name: "sales", OktaGroupName: "sales", Enabled: &disabled},
+			{Name: "missing", OktaGroupName: "missing"},
+		}, SyncOptions{}, slog.New(slog.DiscardHandler))
+
+		report, err := s.DetectMissingUsernames(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []MissingUsername{
+			{Email: "alice@example.com", Groups: []string{"github-eng", "github-platform"}},
+			{Email: "bob@example.com", Groups: []string{"github-eng"}},
+		}
+		if !reflect.DeepEqual(report.Users, want) {
+			t.Errorf("expected users %+v, got %+v", want, report.Users)
+		}
+		if len(report.Errors) != 1 || !strings.Contains(report.Errors[0], "rule 'missing'") {
+			t.Errorf("expected an error for the missing rule, got %v", report.Errors)
+		}
+	})
+
+	t.Run("fails when no group can be read", func(t *testing.T) {
+		s := NewSyncer(source, nil, []SyncRule{{Name: "missing", OktaGroupName: "missing"}},
+			SyncOptions{}, slog.New(slog.DiscardHandler))
+
+		if _, err := s.DetectMissingUsernames(context.Background()); err == nil {
+			t.Error("expected an error when every rule fails")
+		}
+	})
+}
+
+func TestMemberDrift(t *testing.T) {
+	rule := SyncRule{PreserveMembers: []string{"svc-*"}}
+	desired := []string{"Alice", "bob", "carol", "carol"}
+	current := []string{"alice", "dave", "svc-ci"}
+
+	toAdd, toRemove := memberDrift(desired, current, rule.IsPreservedMember)
+	if want := []string{"bob", "carol"}; !reflect.DeepEqual(toAdd, want) {
+		t.Errorf("toAdd = %v, want %v", toAdd, want)
+	}
+	if want := []string{"dave"}; !reflect.DeepEqual(toRemove, want) {
+		t.Errorf("toRemove = %v, want %v", toRemove, want)
+	}
+
+	toAdd, toRemove = memberDrift(current, current, rule.IsPreservedMember)
+	if toAdd != nil || toRemove != nil {
+		t.Errorf("expected no drift for matching members, got %v and %v", toAdd, toRemove)
+	}
+}
+
+func TestSyncRuleIsPreservedMember(t *testing.T) {
+	rule := SyncRule{PreserveMembers: []string{"svc-*", "deploy-bot[bot]", "Release-Manager"}}
+
+	tests := map[string]bool{
+		"svc-ci":          true,
+		"SVC-Backup":      true,
+		"release-manager": true,
+		"deploy-bot[bot]": true,
+		"alice":           false,
+		"my-svc-ci":       false,
+	}
+	for login, want := range tests {
+		if got := rule.IsPreservedMember(login); got != want {
+			t.Errorf("IsPreservedMember(%q) = %v, want %v", login, got, want)
+		}
+	}
+
+	if (SyncRule{}).IsPreservedMember("svc-ci") {
+		t.Error("expected no members preserved without patterns")
+	}
+}
+
+func TestSyncRuleApplyMemberOverrides(t *testing.T) {
+	rule := SyncRule{
+		ExcludeMembers:       []string{"SVC-Deploy", "contractor-1", "Breakglass-Admin"},
+		AlwaysIncludeMembers: []string{"breakglass-admin", "Alice"},
+	}
+
+	desired, forced := rule.ApplyMemberOverrides([]string{"alice", "svc-deploy", "bob", "Contractor-1"})
+
+	if want := []string{"alice", "bob", "breakglass-admin"}; !reflect.DeepEqual(desired, want) {
+		t.Errorf("desired = %v, want %v", desired, want)
+	}
+	if want := []string{"breakglass-admin"}; !reflect.DeepEqual(forced, want) {
+		t.Errorf("forced = %v, want %v", forced, want)
+	}
+
+	desired, forced = (SyncRule{}).ApplyMemberOverrides([]string{"alice"})
+	if !reflect.DeepEqual(desired, []string{"alice"}) || forced != nil {
+		t.Errorf("expected members unchanged without overrides, got %v, %v", desired, forced)
+	}
+}
+
+func TestSortRulesByPriority(t *testing.T) {
+	rules := []SyncRule{
+		{Name: "child-a"},
+		{Name: "parent", Priority: 10},
+		{Name: "child-b"},
+		{Name: "cleanup", Priority: -1},
+		{Name: "platform", Priority: 10},
+	}
+
+	var got []string
+	for _, rule := range sortRulesByPriority(rules) {
+		got = append(got, rule.Name)
+	}
+
+	want := []string{"parent", "platform", "child-a", "child-b", "cleanup"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sortRulesByPriority() = %v, want %v", got, want)
+	}
+	if rules[0].Name != "child-a" {
+		t.Error("expected input rules to be left unchanged")
+	}
+}
+
+func TestSyncRules_Parallel(t *testing.T) {
+	rules := sortRulesByPriority([]SyncRule{
+		{Name: "parent", Priority: 10},
+		{Name: "a"},
+		{Name: "b"},
+		{Name: "c"},
+		{Name: "d"},
+		{Name: "cleanup", Priority: -1},
+	})
+
+	var mu sync.Mutex
+	var running, maxRunning int
+	started := map[string]int{}
+	ended := map[string]int{}
+	step := 0
+
+	s := &Syncer{opts: SyncOptions{Parallelism: 2}}
+	outcomes := s.syncRules(rules, func(rule SyncRule) ([]*SyncReport, error) {
+		mu.Lock()
+		step++
+		started[rule.Name] = step
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		step++
+		ended[rule.Name] = step
+		running--
+		mu.Unlock()
+
+		if rule.Name == "b" {
+			return nil, errors.New("boom")
+		}
+		return []*SyncReport{{Rule: rule.Name}}, nil
+	})
+
+	if maxRunning != 2 {
+		t.Errorf("expected up to 2 rules running at once, got %d", maxRunning)
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if started[name] < ended["parent"] || ended[name] > started["cleanup"] {
+			t.Errorf("rule %s overlapped a different priority tier", name)
+		}
+	}
+
+	for i, rule := range rules {
+		if rule.Name == "b" {
+			if outcomes[i].err == nil {
+				t.Error("expected error outcome for rule b")
+			}
+			continue
+		}
+		if outcomes[i].err != nil || len(outcomes[i].reports) != 1 || outcomes[i].reports[0].Rule != rule.Name {
+			t.Errorf("outcome %d does not match rule %s: %+v", i, rule.Name, outcomes[i])
+		}
+	}
+}
+
+func TestSyncerSourceFor(t *testing.T) {
+	global, acquired := &Client{}, &Client{}
+	s := &Syncer{source: global}
+	s.SetConnections(map[string]GroupSource{"Acquired": acquired})
+
+	tests := []struct {
+		name       string
+		connection string
+		want       GroupSource
+		wantErr    bool
+	}{
+		{name: "global by default", want: global},
+		{name: "named connection", connection: "acquired", want: acquired},
+		{name: "case-insensitive", connection: "ACQUIRED", want: acquired},
+		{name: "unknown connection", connection: "other", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.sourceFor(SyncRule{Name: "rule", OktaConnection: tt.connection})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sourceFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("sourceFor() returned the wrong source")
+			}
+		})
+	}
+}
+
+func TestFindEmptyRules(t *testing.T) {
+	noSync := false
+	rules := []SyncRule{
+		{Name: "renamed", OktaGroupPattern: "^old-"},
+		{Name: "empty-group", OktaGroupName: "interns"},
+		{Name: "partly-empty", OktaGroupPattern: "^eng-"},
+		{Name: "failed", OktaGroupName: "missing"},
+		{Name: "teams-only", OktaGroupName: "ops", SyncMembers: &noSync},
+	}
+	outcomes := []ruleOutcome{
+		{},
+		{reports: []*SyncReport{{OktaGroup: "interns", NoOktaMembers: true}}},
+		{reports: []*SyncReport{{OktaGroup: "eng-a", NoOktaMembers: true}, {OktaGroup: "eng-b"}}},
+		{err: errors.New("group not found")},
+		{reports: []*SyncReport{{OktaGroup: "ops", NoOktaMembers: true}}},
+	}
+
+	got := findEmptyRules(rules, outcomes)
+	want := []EmptyRule{
+		{Rule: "renamed", Reason: EmptyRuleNoGroups},
+		{Rule: "empty-group", Reason: EmptyRuleNoMembers, OktaGroups: []string{"interns"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+// fakeGroupSource is an in-memory GroupSource keyed by group name.
+type fakeGroupSource map[string]*GroupInfo
+
+func (f fakeGroupSource) GetGroupsByPattern(pattern string) ([]*GroupInfo, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var matched []*GroupInfo
+	for _, name := range slices.Sorted(maps.Keys(f)) {
+		if re.MatchString(name) {
+			matched = append(matched, f[name])
+		}
+	}
+	return matched, nil
+}
+
+func (f fakeGroupSource) GetGroupNamesByPattern(pattern string) ([]*GroupInfo, error) {
+	return f.GetGroupsByPattern(pattern)
+}
+
+func (f fakeGroupSource) GetGroupInfo(groupName string) (*GroupInfo, error) {
+	group, ok := f[groupName]
+	if !ok {
+		return nil, errors.Newf("group '%s' not found", groupName)
+	}
+	return group, nil
+}
+
+func (f fakeGroupSource) GetGroupByName(name string) (*GroupInfo, error) {
+	return f.GetGroupInfo(name)
+}
+
+func TestRuleTeams_GroupSource(t *testing.T) {
+	source := fakeGroupSource{
+		"github-eng":      {ID: "1", Name: "github-eng"},
+		"github-platform": {ID: "2", Name: "github-platform"},
+		"sales":           {ID: "3", Name: "sales"},
+	}
+	s := NewSyncer(source, nil, []SyncRule{
+		{Name: "eng", OktaGroupPattern: "^github-", StripPrefix: "github-"},
+		{Name: "sales", OktaGroupName: "sales"},
+	}, SyncOptions{}, nil)
+
+	teams, err := s.RuleTeams()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(teams, []string{"eng", "platform", "sales"}) {
+		t.Errorf("expected teams from the fake source, got %v", teams)
+	}
+	if quotas := s.apiQuotas(); len(quotas) != 0 {
+		t.Errorf("expected no quotas from a source without them, got %v", quotas)
+	}
+}
+
+// newRecordingGitHubClient returns a GitHub client for org "acme" whose
+// teams start with members and whose org has orgMembers and the outside
+// collaborators outside. with a nil members every team exists, otherwise only
+// the teams in members do. a nil outside makes the outside collaborator
+// listing fail. it records each membership change as "add team/login" or
+// "remove team/login" and each org membership check as "check login".
+func newRecordingGitHubClient(t *testing.T, members map[string][]string, orgMembers, outside []string) (*client.Client, func() []string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var mu sync.Mutex
+	var ops []string
+	record := func(op string) {
+		mu.Lock()
+		defer mu.Unlock()
+		ops = append(ops, op)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /app/installations/1/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"token":"test-token","expires_at":%q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+	})
+	mux.HandleFunc("GET /orgs/acme/teams", func(w http.ResponseWriter, r *http.Request) {
+		teams := []map[string]string{}
+		for _, slug := range slices.Sorted(maps.Keys(members)) {
+			teams = append(teams, map[string]string{"name": slug, "slug": slug})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(teams)
+	})
+	mux.HandleFunc("GET /orgs/acme/teams/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := members[r.PathValue("slug")]; members != nil && !ok {
+			http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":1,"name":%[1]q,"slug":%[1]q}`, r.PathValue("slug"))
+	})
+	mux.HandleFunc("GET /orgs/acme/teams/{slug}/members", func(w http.ResponseWriter, r *http.Request) {
+		var users []map[string]string
+		for _, login := range members[r.PathValue("slug")] {
+			users = append(users, map[string]string{"login": login})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users)
+	})
+	mux.HandleFunc("GET /orgs/acme/members", func(w http.ResponseWriter, r *http.Request) {
+		var users []map[string]string
+		for _, login := range orgMembers {
+			users = append(users, map[string]string{"login": login})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users)
+	})
+	mux.HandleFunc("GET /orgs/acme/outside_collaborators", func(w http.ResponseWriter, r *http.Request) {
+		if outside == nil {
+			http.Error(w, `{"message":"server error"}`, http.StatusInternalServerError)
+			return
+		}
+		users := []map[string]string{}
+		for _, login := range outside {
+			users = append(users, map[string]string{"login": login})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(users)
+	})
+	mux.HandleFunc("GET /orgs/acme/memberships/{user}", func(w http.ResponseWriter, r *http.Request) {
+		record("check " + r.PathValue("user"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"state":"active","role":"member"}`)
+	})
+	mux.HandleFunc("PUT /orgs/acme/teams/{slug}/memberships/{user}", func(w http.ResponseWriter, r *http.Request) {
+		record("add " + r.PathValue("slug") + "/" + r.PathValue("user"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"state":"active"}`)
+	})
+	mux.HandleFunc("DELETE /orgs/acme/teams/{slug}/memberships/{user}", func(w http.ResponseWriter, r *http.Request) {
+		record("remove " + r.PathValue("slug") + "/" + r.PathValue("user"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	c, err := client.NewAppClientWithBaseURL(1, 1, keyPEM, "acme", srv.URL+"/")
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(ops)
+	}
+}
+
+func TestDiagnoseRules(t *testing.T) {
+	source := fakeGroupSource{
+		"eng":      {Name: "eng", Members: []string{"alice", "bob"}},
+		"platform": {Name: "platform", Members: []string{"carol"}},
+		"data":     {Name: "data", Members: []string{"dave"}},
+		"infra":    {Name: "infra", Members: []string{"erin"}},
+	}
+	disabled := false
+	rules := []SyncRule{
+		{Name: "eng", OktaGroupName: "eng"},
+		{Name: "platform", OktaGroupName: "platform"},
+		{Name: "data", OktaGroupName: "data"},
+		{Name: "infra", OktaGroupName: "infra", GitHubTeamSlug: "infra"},
+		{Name: "sales", OktaGroupName: "sales"},
+		{Name: "off", OktaGroupName: "eng", Enabled: &disabled},
+	}
+	teams := map[string][]string{"eng": {"alice", "mallory"}, "platform": {"carol"}}
+	org := []string{"alice", "bob", "carol", "dave", "erin", "mallory"}
+	gh, ops := newRecordingGitHubClient(t, teams, org, []string{})
+	s := NewSyncer(source, gh, rules, SyncOptions{}, slog.New(slog.DiscardHandler))
+
+	report, err := s.DiagnoseRules(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byRule := make(map[string]RuleDiagnosis)
+	for _, d := range report.Rules {
+		byRule[d.Rule] = d
+	}
+	if len(report.Rules) != 5 {
+		t.Errorf("expected a diagnosis for each enabled rule, got %+v", report.Rules)
+	}
+	if d := byRule["eng"]; d.Status != RuleStatusOutOfSync || !slices.Equal(d.MembersToAdd, []string{"bob"}) || !slices.Equal(d.MembersToRemove, []string{"mallory"}) {
+		t.Errorf("expected eng out of sync adding bob and removing mallory, got %+v", d)
+	}
+	if d := byRule["platform"]; d.Status != RuleStatusInSync || d.GitHubTeam != "platform" {
+		t.Errorf("expected platform in sync, got %+v", d)
+	}
+	if d := byRule["data"]; d.Status != RuleStatusWouldCreate {
+		t.Errorf("expected the missing data team to be created, got %+v", d)
+	}
+	if d := byRule["infra"]; d.Status != RuleStatusTeamNotFound {
+		t.Errorf("expected the missing infra slug to be not found, got %+v", d)
+	}
+	if d := byRule["sales"]; d.Status != RuleStatusError || !strings.Contains(d.Error, "sales") {
+		t.Errorf("expected an error for the missing sales group, got %+v", d)
+	}
+	if got := ops(); len(got) != 0 {
+		t.Errorf("expected no changes, got %v", got)
+	}
+}
+
+func TestSync_MemberOrder(t *testing.T) {
+	// alice moves from team "a", which syncs first, to team "b".
+	source := fakeGroupSource{
+		"team-a": {ID: "1", Name: "team-a", Members: []string{"carol"}},
+		"team-b": {ID: "2", Name: "team-b", Members: []string{"alice", "bob"}},
+	}
+	rules := []SyncRule{{Name: "teams", OktaGroupPattern: "^team-", StripPrefix: "team-"}}
+	current := map[string][]string{"a": {"alice", "carol"}, "b": {"bob"}}
+
+	tests := []struct {
+		name        string
+		removeFirst bool
+		want        []string
+	}{
+		{name: "add first", want: []string{"add b/alice", "check alice", "remove a/alice"}},
+		{name: "remove first", removeFirst: true, want: []string{"check alice", "remove a/alice", "add b/alice"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh, ops := newRecordingGitHubClient(t, current, nil, nil)
+			opts := SyncOptions{SafetyThreshold: 1, RemoveMembersFirst: tt.removeFirst}
+			s := NewSyncer(source, gh, rules, opts, slog.New(slog.DiscardHandler))
+
+			result, err := s.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := ops(); !slices.Equal(got, tt.want) {
+				t.Errorf("expected changes %v, got %v", tt.want, got)
+			}
+
+			a, b := result.Reports[0], result.Reports[1]
+			if !slices.Equal(a.MembersRemoved, []string{"alice"}) || !slices.Equal(b.MembersAdded, []string{"alice"}) {
+				t.Errorf("expected alice moved from a to b, got removed %v and added %v", a.MembersRemoved, b.MembersAdded)
+			}
+			if len(s.pending) != 0 {
+				t.Errorf("expected no pending member syncs, got %d", len(s.pending))
+			}
+		})
+	}
+}
+
+func TestSync_GlobalDryRunWinsOverRule(t *testing.T) {
+	source := fakeGroupSource{
+		"eng": {ID: "1", Name: "eng", Members: []string{"alice"}},
+	}
+	enforce := false
+	rules := []SyncRule{{Name: "eng", OktaGroupName: "eng", GitHubTeamName: "eng", DryRun: &enforce}}
+	current := map[string][]string{"eng": {"bob"}}
+
+	tests := []struct {
+		name         string
+		syncerDryRun bool
+		clientDryRun bool
+		wantDryRun   bool
+	}{
+		{name: "syncer dry-run", syncerDryRun: true, wantDryRun: true},
+		{name: "client dry-run", clientDryRun: true, wantDryRun: true},
+		{name: "enforcing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gh, ops := newRecordingGitHubClient(t, current, nil, nil)
+			gh.SetDryRun(tt.clientDryRun)
+			opts := SyncOptions{SafetyThreshold: 1, DryRun: tt.syncerDryRun}
+			s := NewSyncer(source, gh, rules, opts, slog.New(slog.DiscardHandler))
+
+			result, err := s.Sync(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			report := result.Reports[0]
+			if report.DryRun != tt.wantDryRun {
+				t.Errorf("expected report dry_run %v, got %v", tt.wantDryRun, report.DryRun)
+			}
+			if !slices.Equal(report.MembersAdded, []string{"alice"}) || !slices.Equal(report.MembersRemoved, []string{"bob"}) {
+				t.Errorf("expected alice added and bob removed, got added %v and removed %v", report.MembersAdded, report.MembersRemoved)
+			}
+
+			mutated := slices.ContainsFunc(ops(), func(op string) bool {
+				return strings.HasPrefix(op, "add ") || strings.HasPrefix(op, "remove ")
+			})
+			if mutated == tt.wantDryRun {
+				t.Errorf("expected mutations %v, got %v", !tt.wantDryRun, ops())
+			}
+		})
+	}
+}
+
+func TestSync_ReportsSortedByRuleName(t *testing.T) {
+	source := fakeGroupSource{
+		"g1": {ID: "1", Name: "g1", Members: []string{"alice"}},
+		"g2": {ID: "2", Name: "g2", Members: []string{"bob"}},
+		"g3": {ID: "3", Name: "g3", Members: []string{"carol"}},
+	}
+	rules := []SyncRule{
+		{Name: "zeta", OktaGroupName: "g1", GitHubTeamName: "zeta", Priority: 10},
+		{Name: "mid", OktaGroupName: "g2", GitHubTeamName: "mid"},
+		{Name: "alpha", OktaGroupName: "g3", GitHubTeamName: "alpha"},
+	}
+
+	gh, _ := newRecordingGitHubClient(t, nil, nil, nil)
+	opts := SyncOptions{SafetyThreshold: 1, Parallelism: 2}
+	s := NewSyncer(source, gh, rules, opts, slog.New(slog.DiscardHandler))
+
+	result, err := s.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, report := range result.Reports {
+		got = append(got, report.Rule)
+	}
+	if want := []string{"alpha", "mid", "zeta"}; !slices.Equal(got, want) {
+		t.Errorf("expected reports sorted by rule name %v, got %v", want, got)
+	}
+}
+
+func TestSyncTeam_MultiWordTeamName(t *testing.T) {
+	source := fakeGroupSource{
+		"platform": {ID: "1", Name: "platform", Members: []string{"alice"}},
+		"security": {ID: "2", Name: "security", Members: []string{"bob"}},
+	}
+	rules := []SyncRule{
+		{Name: "platform", OktaGroupName: "platform", GitHubTeamName: "Platform Eng"},
+		{Name: "security", OktaGroupName: "security", GitHubTeamName: "Security Eng"},
+		{Name: "pattern", OktaGroupPattern: "^platform$", TeamNameTemplate: "{{.Group}} Eng"},
+	}
+
+	gh, ops := newRecordingGitHubClient(t, nil, nil, nil)
+	s := NewSyncer(source, gh, rules, SyncOptions{SafetyThreshold: 1}, slog.New(slog.DiscardHandler))
+
+	result, err := s.SyncTeam(context.Background(), "platform-eng")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var teams []string
+	for _, report := range result.Reports {
+		teams = append(teams, report.Rule+"="+report.GitHubTeam)
+	}
+	if want := []string{"platform=Platform Eng", "pattern=platform-eng"}; !slices.Equal(teams, want) {
+		t.Errorf("expected only the platform-eng team synced %v, got %v", want, teams)
+	}
+	if got := ops(); !slices.Contains(got, "add platform-eng/alice") || slices.Contains(got, "add security-eng/bob") {
+		t.Errorf("expected alice added to platform-eng only, got %v", got)
+	}
+}
+
+func TestDetectOrphanedUsers_Exemptions(t *testing.T) {
+	teams := map[string][]string{"eng": {"alice"}, "bots": {"deploy-bot"}}
+	org := []string{"alice", "deploy-bot", "CI-Runner", "mallory"}
+	gh, ops := newRecordingGitHubClient(t, teams, org, nil)
+
+	opts := SyncOptions{OrphanExemptTeams: []string{"bots"}, OrphanExemptLogins: []string{"ci-runner"}}
+	s := NewSyncer(nil, gh, nil, opts, slog.New(slog.DiscardHandler))
+
+	report, err := s.DetectOrphanedUsers(context.Background(), []string{"eng"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.OrphanedUsers, []string{"mallory"}) {
+		t.Errorf("expected only mallory orphaned, got %v", report.OrphanedUsers)
+	}
+	if got := ops(); !slices.Equal(got, []string{"check mallory"}) {
+		t.Errorf("expected only mallory checked for external status, got %v", got)
+	}
+}
+
+func TestDetectOrphanedUsers_OutsideCollaborators(t *testing.T) {
+	org := []string{"alice", "Vendor", "mallory"}
+	gh, ops := newRecordingGitHubClient(t, nil, org, []string{"vendor"})
+	s := NewSyncer(nil, gh, nil, SyncOptions{}, slog.New(slog.DiscardHandler))
+
+	report, err := s.DetectOrphanedUsers(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.OrphanedUsers, []string{"alice", "mallory"}) {
+		t.Errorf("expected alice and mallory orphaned, got %v", report.OrphanedUsers)
+	}
+	if got := ops(); len(got) != 0 {
+		t.Errorf("expected no per-user membership checks, got %v", got)
+	}
+}
+
+func TestCountSkippedMembers(t *testing.T) {
+	reports := []*SyncReport{
+		{MembersSkippedNoGHUsername: []string{"a@example.com", "b@example.com"}, MembersSkippedExternal: []string{"vendor"}},
+		{MembersSkippedNoGHUsername: []string{"A@example.com"}},
+		{Errors: []string{"rule failed"}},
+	}
+
+	want := []SkipCount{
+		{Reason: SkipReasonNoGitHubUsername, Count: 2},
+		{Reason: SkipReasonNotOrgMember, Count: 0},
+		{Reason: SkipReasonExternal, Count: 1},
+	}
+	if got := countSkippedMembers(reports); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
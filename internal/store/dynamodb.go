@@ -0,0 +1,198 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/cockroachdb/errors"
+)
+
+// DynamoDB item attributes. the table's partition key must be a string
+// attribute named "key"; enable TTL on "expires_at" so expired items are
+// removed. expiry is also checked on read since TTL deletion is delayed.
+const (
+	dynamoDBKeyAttr     = "key"
+	dynamoDBValueAttr   = "value"
+	dynamoDBExpiresAttr = "expires_at"
+)
+
+// DynamoDBAPI is the subset of the DynamoDB client used by DynamoDBStore.
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBStore is a Store backed by a DynamoDB table, so state is shared
+// across Lambda instances and survives cold starts.
+type DynamoDBStore struct {
+	client DynamoDBAPI
+	table  string
+	now    func() time.Time
+}
+
+// NewDynamoDBStore creates a store using the given table.
+func NewDynamoDBStore(client DynamoDBAPI, table string) *DynamoDBStore {
+	return &DynamoDBStore{
+		client: client,
+		table:  table,
+		now:    time.Now,
+	}
+}
+
+// Get returns the value stored for key.
+func (s *DynamoDBStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	out, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            dynamoDBKey(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to get '%s' from dynamodb table '%s'", key, s.table)
+	}
+	if out.Item == nil || s.itemExpired(out.Item) {
+		return nil, false, nil
+	}
+
+	value, ok := out.Item[dynamoDBValueAttr].(*ddbtypes.AttributeValueMemberB)
+	if !ok {
+		return nil, false, errors.Newf("item '%s' in dynamodb table '%s' has no binary value", key, s.table)
+	}
+	return value.Value, true, nil
+}
+
+// Set stores value for key.
+func (s *DynamoDBStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      s.newItem(key, value, ttl),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to put '%s' in dynamodb table '%s'", key, s.table)
+	}
+	return nil
+}
+
+// Delete removes key.
+func (s *DynamoDBStore) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key:       dynamoDBKey(key),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete '%s' from dynamodb table '%s'", key, s.table)
+	}
+	return nil
+}
+
+// PutIfAbsent stores value only if key is missing or expired, using a
+// conditional write so concurrent callers cannot both succeed.
+func (s *DynamoDBStore) PutIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                s.newItem(key, value, ttl),
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR (attribute_exists(#expires) AND #expires <= :now)"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":     dynamoDBKeyAttr,
+			"#expires": dynamoDBExpiresAttr,
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":now": dynamoDBNumber(s.now().Unix()),
+		},
+	})
+	if err != nil {
+		var conditionFailed *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to put '%s' in dynamodb table '%s'", key, s.table)
+	}
+	return true, nil
+}
+
+// DeleteIfValue removes key only if it holds value, using a conditional
+// delete so a value replaced by another caller is left alone.
+func (s *DynamoDBStore) DeleteIfValue(ctx context.Context, key string, value []byte) (bool, error) {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.table),
+		Key:                 dynamoDBKey(key),
+		ConditionExpression: aws.String("#value = :value"),
+		ExpressionAttributeNames: map[string]string{
+			"#value": dynamoDBValueAttr,
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":value": &ddbtypes.AttributeValueMemberB{Value: value},
+		},
+	})
+	if err != nil {
+		var conditionFailed *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to delete '%s' from dynamodb table '%s'", key, s.table)
+	}
+	return true, nil
+}
+
+// RefreshIfValue resets the ttl of key only if it still holds value and has
+// not expired, using a conditional write.
+func (s *DynamoDBStore) RefreshIfValue(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.table),
+		Item:                s.newItem(key, value, ttl),
+		ConditionExpression: aws.String("#value = :value AND (attribute_not_exists(#expires) OR #expires > :now)"),
+		ExpressionAttributeNames: map[string]string{
+			"#value":   dynamoDBValueAttr,
+			"#expires": dynamoDBExpiresAttr,
+		},
+		ExpressionAttributeValues: map[string]ddbtypes.AttributeValue{
+			":value": &ddbtypes.AttributeValueMemberB{Value: value},
+			":now":   dynamoDBNumber(s.now().Unix()),
+		},
+	})
+	if err != nil {
+		var conditionFailed *ddbtypes.ConditionalCheckFailedException
+		if errors.As(err, &conditionFailed) {
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to refresh '%s' in dynamodb table '%s'", key, s.table)
+	}
+	return true, nil
+}
+
+// newItem builds the item stored for key.
+func (s *DynamoDBStore) newItem(key string, value []byte, ttl time.Duration) map[string]ddbtypes.AttributeValue {
+	item := dynamoDBKey(key)
+	item[dynamoDBValueAttr] = &ddbtypes.AttributeValueMemberB{Value: value}
+	if ttl > 0 {
+		// ttl attributes are whole seconds, so round up to avoid expiring early
+		item[dynamoDBExpiresAttr] = dynamoDBNumber(s.now().Add(ttl + time.Second - 1).Unix())
+	}
+	return item
+}
+
+// itemExpired returns true if the item has an expiry that has passed.
+func (s *DynamoDBStore) itemExpired(item map[string]ddbtypes.AttributeValue) bool {
+	attr, ok := item[dynamoDBExpiresAttr].(*ddbtypes.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(attr.Value, 10, 64)
+	return err == nil && s.now().Unix() >= expiresAt
+}
+
+// dynamoDBKey returns the primary key attributes for key.
+func dynamoDBKey(key string) map[string]ddbtypes.AttributeValue {
+	return map[string]ddbtypes.AttributeValue{
+		dynamoDBKeyAttr: &ddbtypes.AttributeValueMemberS{Value: key},
+	}
+}
+
+// dynamoDBNumber returns a number attribute value.
+func dynamoDBNumber(n int64) *ddbtypes.AttributeValueMemberN {
+	return &ddbtypes.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	ddbtypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeDynamoDB is an in-memory DynamoDBAPI that evaluates the conditional
+// put used by DynamoDBStore.
+type fakeDynamoDB struct {
+	items map[string]map[string]ddbtypes.AttributeValue
+	now   int64
+}
+
+func (f *fakeDynamoDB) GetItem(_ context.Context, in *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	return &dynamodb.GetItemOutput{Item: f.items[fakeKey(in.Key)]}, nil
+}
+
+func (f *fakeDynamoDB) PutItem(_ context.Context, in *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	key := fakeKey(in.Item)
+	existing, exists := f.items[key]
+	if in.ConditionExpression != nil {
+		if want, ok := in.ExpressionAttributeValues[":value"]; ok {
+			// refresh: the item must hold the value and be live
+			if !f.holds(existing, want) || f.expired(existing) {
+				return nil, &ddbtypes.ConditionalCheckFailedException{}
+			}
+		} else if exists && !f.expired(existing) {
+			// put if absent: the item must be missing or expired
+			return nil, &ddbtypes.ConditionalCheckFailedException{}
+		}
+	}
+	f.items[key] = in.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(_ context.Context, in *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	key := fakeKey(in.Key)
+	if want, ok := in.ExpressionAttributeValues[":value"]; ok && !f.holds(f.items[key], want) {
+		return nil, &ddbtypes.ConditionalCheckFailedException{}
+	}
+	delete(f.items, key)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// holds returns true if item has the binary value of want.
+func (f *fakeDynamoDB) holds(item map[string]ddbtypes.AttributeValue, want ddbtypes.AttributeValue) bool {
+	got, ok := item[dynamoDBValueAttr].(*ddbtypes.AttributeValueMemberB)
+	return ok && bytes.Equal(got.Value, want.(*ddbtypes.AttributeValueMemberB).Value)
+}
+
+// expired returns true if item has an expiry at or before now. items
+// without one never expire.
+func (f *fakeDynamoDB) expired(item map[string]ddbtypes.AttributeValue) bool {
+	expires, ok := item[dynamoDBExpiresAttr].(*ddbtypes.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	at, _ := strconv.ParseInt(expires.Value, 10, 64)
+	return at <= f.now
+}
+
+func fakeKey(item map[string]ddbtypes.AttributeValue) string {
+	return item[dynamoDBKeyAttr].(*ddbtypes.AttributeValueMemberS).Value
+}
+
+func TestDynamoDBStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	fake := &fakeDynamoDB{items: map[string]map[string]ddbtypes.AttributeValue{}, now: now.Unix()}
+	s := NewDynamoDBStore(fake, "github-ops-app")
+	s.now = func() time.Time { return now }
+
+	acquired, err := s.PutIfAbsent(ctx, "lock/sync", []byte("a"), time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected first put to succeed, got %t err=%v", acquired, err)
+	}
+	if acquired, err := s.PutIfAbsent(ctx, "lock/sync", []byte("b"), time.Minute); err != nil || acquired {
+		t.Fatalf("expected put on a live key to fail, got %t err=%v", acquired, err)
+	}
+
+	// expired items may linger until dynamodb removes them
+	now = now.Add(time.Minute)
+	fake.now = now.Unix()
+	if _, found, _ := s.Get(ctx, "lock/sync"); found {
+		t.Error("expected expired item to be treated as missing")
+	}
+	if acquired, _ := s.PutIfAbsent(ctx, "lock/sync", []byte("b"), time.Minute); !acquired {
+		t.Error("expected put on an expired key to succeed")
+	}
+
+	if ok, _ := s.RefreshIfValue(ctx, "lock/sync", []byte("a"), time.Minute); ok {
+		t.Error("expected refresh with a replaced value to fail")
+	}
+	now = now.Add(30 * time.Second)
+	fake.now = now.Unix()
+	if ok, err := s.RefreshIfValue(ctx, "lock/sync", []byte("b"), time.Minute); err != nil || !ok {
+		t.Errorf("expected refresh with the held value to succeed, got %t err=%v", ok, err)
+	}
+	now = now.Add(45 * time.Second)
+	fake.now = now.Unix()
+	if _, found, _ := s.Get(ctx, "lock/sync"); !found {
+		t.Error("expected refresh to extend the expiry")
+	}
+	if ok, _ := s.DeleteIfValue(ctx, "lock/sync", []byte("a")); ok {
+		t.Error("expected delete with another value to fail")
+	}
+	if ok, err := s.DeleteIfValue(ctx, "lock/sync", []byte("b")); err != nil || !ok {
+		t.Errorf("expected delete with the held value to succeed, got %t err=%v", ok, err)
+	}
+	if _, found, _ := s.Get(ctx, "lock/sync"); found {
+		t.Error("expected conditional delete to remove the item")
+	}
+
+	if err := s.Set(ctx, "okta-sync/last-sync", []byte("{}"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, found, _ := s.Get(ctx, "okta-sync/last-sync"); !found || string(got) != "{}" {
+		t.Errorf("unexpected value %q found=%t", got, found)
+	}
+	if err := s.Delete(ctx, "okta-sync/last-sync"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, found, _ := s.Get(ctx, "okta-sync/last-sync"); found {
+		t.Error("expected item to be deleted")
+	}
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// TeamSyncChanges records the membership changes applied to a team.
+type TeamSyncChanges struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// SyncSnapshot records the membership changes from the most recent okta sync
+// of each team.
+type SyncSnapshot struct {
+	Timestamp time.Time                  `json:"timestamp"`
+	Teams     map[string]TeamSyncChanges `json:"teams"`
+	// Skipped maps each skip reason to the distinct users skipped for it by
+	// the most recent full sync.
+	Skipped map[string]int `json:"skipped,omitempty"`
+}
+
+// LastSyncStore keeps the most recent okta sync snapshot.
+type LastSyncStore interface {
+	// Get returns the stored snapshot, or nil if no sync has been recorded.
+	Get(ctx context.Context) (*SyncSnapshot, error)
+	// Put replaces the stored snapshot.
+	Put(ctx context.Context, snapshot SyncSnapshot) error
+}
+
+// MemoryLastSyncStore keeps the last sync snapshot in memory. contents are
+// lost when the process exits.
+type MemoryLastSyncStore struct {
+	mu       sync.Mutex
+	snapshot *SyncSnapshot
+}
+
+// NewMemoryLastSyncStore creates an empty in-memory last sync store.
+func NewMemoryLastSyncStore() *MemoryLastSyncStore {
+	return &MemoryLastSyncStore{}
+}
+
+// Get returns a copy of the stored snapshot, or nil if none exists.
+func (s *MemoryLastSyncStore) Get(_ context.Context) (*SyncSnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.snapshot == nil {
+		return nil, nil
+	}
+
+	snapshot := *s.snapshot
+	snapshot.Teams = maps.Clone(s.snapshot.Teams)
+	snapshot.Skipped = maps.Clone(s.snapshot.Skipped)
+	return &snapshot, nil
+}
+
+// Put replaces the stored snapshot.
+func (s *MemoryLastSyncStore) Put(_ context.Context, snapshot SyncSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot.Teams = maps.Clone(snapshot.Teams)
+	snapshot.Skipped = maps.Clone(snapshot.Skipped)
+	s.snapshot = &snapshot
+	return nil
+}
+
+// lastSyncKey is the Store key holding the last sync snapshot.
+const lastSyncKey = "okta-sync/last-sync"
+
+// PersistentLastSyncStore keeps the last sync snapshot in a Store.
+type PersistentLastSyncStore struct {
+	store Store
+}
+
+// NewPersistentLastSyncStore creates a last sync store backed by s.
+func NewPersistentLastSyncStore(s Store) *PersistentLastSyncStore {
+	return &PersistentLastSyncStore{store: s}
+}
+
+// Get returns the stored snapshot, or nil if none exists.
+func (s *PersistentLastSyncStore) Get(ctx context.Context) (*SyncSnapshot, error) {
+	data, found, err := s.store.Get(ctx, lastSyncKey)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	var snapshot SyncSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to decode last sync snapshot")
+	}
+	return &snapshot, nil
+}
+
+// Put replaces the stored snapshot.
+func (s *PersistentLastSyncStore) Put(ctx context.Context, snapshot SyncSnapshot) error {
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode last sync snapshot")
+	}
+	return s.store.Set(ctx, lastSyncKey, data, 0)
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLastSyncStore(t *testing.T) {
+	stores := map[string]LastSyncStore{
+		"memory":     NewMemoryLastSyncStore(),
+		"persistent": NewPersistentLastSyncStore(NewMemoryStore()),
+	}
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			testLastSyncStore(t, s)
+		})
+	}
+}
+
+// testLastSyncStore checks that a stored snapshot round trips and is not
+// affected by later changes to the caller's map.
+func testLastSyncStore(t *testing.T, s LastSyncStore) {
+	ctx := context.Background()
+
+	snapshot, err := s.Get(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot != nil {
+		t.Fatalf("expected no snapshot, got %+v", snapshot)
+	}
+
+	teams := map[string]TeamSyncChanges{
+		"eng": {Added: []string{"alice"}},
+	}
+	if err := s.Put(ctx, SyncSnapshot{Teams: teams}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// mutating the caller's map must not affect the stored snapshot
+	teams["ops"] = TeamSyncChanges{}
+
+	snapshot, err = s.Get(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if snapshot == nil || len(snapshot.Teams) != 1 {
+		t.Fatalf("expected snapshot with 1 team, got %+v", snapshot)
+	}
+	if got := snapshot.Teams["eng"].Added; len(got) != 1 || got[0] != "alice" {
+		t.Errorf("unexpected added members: %v", got)
+	}
+}
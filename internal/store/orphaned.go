@@ -0,0 +1,136 @@
+// Package store provides pluggable storage for stateful features. in-memory
+// implementations are used by default; features can instead be backed by a
+// shared Store, such as DynamoDB, for runtimes without long-lived processes.
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// OrphanedUsersSnapshot is the result of a single orphaned user detection
+// run.
+type OrphanedUsersSnapshot struct {
+	Timestamp time.Time `json:"timestamp"`
+	Count     int       `json:"count"`
+	Users     []string  `json:"users"`
+}
+
+// OrphanedUsersHistory records orphaned user detection results over time.
+type OrphanedUsersHistory interface {
+	// Record stores a snapshot.
+	Record(ctx context.Context, snapshot OrphanedUsersSnapshot) error
+	// List returns stored snapshots, newest first.
+	List(ctx context.Context) ([]OrphanedUsersSnapshot, error)
+}
+
+// MemoryOrphanedUsersHistory keeps the most recent snapshots in a fixed-size
+// ring buffer. contents are lost when the process exits.
+type MemoryOrphanedUsersHistory struct {
+	mu        sync.Mutex
+	snapshots []OrphanedUsersSnapshot
+	next      int
+	full      bool
+}
+
+// NewMemoryOrphanedUsersHistory creates an in-memory history holding up to
+// capacity snapshots.
+func NewMemoryOrphanedUsersHistory(capacity int) *MemoryOrphanedUsersHistory {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &MemoryOrphanedUsersHistory{
+		snapshots: make([]OrphanedUsersSnapshot, capacity),
+	}
+}
+
+// Record stores a snapshot, evicting the oldest when full.
+func (h *MemoryOrphanedUsersHistory) Record(_ context.Context, snapshot OrphanedUsersSnapshot) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.snapshots[h.next] = snapshot
+	h.next = (h.next + 1) % len(h.snapshots)
+	if h.next == 0 {
+		h.full = true
+	}
+	return nil
+}
+
+// List returns stored snapshots, newest first.
+func (h *MemoryOrphanedUsersHistory) List(_ context.Context) ([]OrphanedUsersSnapshot, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := h.next
+	if h.full {
+		count = len(h.snapshots)
+	}
+
+	result := make([]OrphanedUsersSnapshot, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (h.next - i + len(h.snapshots)) % len(h.snapshots)
+		result = append(result, h.snapshots[idx])
+	}
+	return result, nil
+}
+
+// orphanedHistoryKey is the Store key holding orphaned user snapshots.
+const orphanedHistoryKey = "okta-sync/orphaned-history"
+
+// PersistentOrphanedUsersHistory keeps the most recent snapshots in a Store
+// as a single newest-first list. records read and rewrite the whole list, so
+// concurrent records may drop a snapshot; okta syncs are serialized by a lock.
+type PersistentOrphanedUsersHistory struct {
+	store    Store
+	capacity int
+}
+
+// NewPersistentOrphanedUsersHistory creates a history backed by s holding up
+// to capacity snapshots.
+func NewPersistentOrphanedUsersHistory(s Store, capacity int) *PersistentOrphanedUsersHistory {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &PersistentOrphanedUsersHistory{store: s, capacity: capacity}
+}
+
+// Record stores a snapshot, evicting the oldest when full.
+func (h *PersistentOrphanedUsersHistory) Record(ctx context.Context, snapshot OrphanedUsersSnapshot) error {
+	snapshots, err := h.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	snapshots = append([]OrphanedUsersSnapshot{snapshot}, snapshots...)
+	if len(snapshots) > h.capacity {
+		snapshots = snapshots[:h.capacity]
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode orphaned user history")
+	}
+	return h.store.Set(ctx, orphanedHistoryKey, data, 0)
+}
+
+// List returns stored snapshots, newest first.
+func (h *PersistentOrphanedUsersHistory) List(ctx context.Context) ([]OrphanedUsersSnapshot, error) {
+	data, found, err := h.store.Get(ctx, orphanedHistoryKey)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return []OrphanedUsersSnapshot{}, nil
+	}
+
+	var snapshots []OrphanedUsersSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, errors.Wrap(err, "failed to decode orphaned user history")
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOrphanedUsersHistory(t *testing.T) {
+	histories := map[string]OrphanedUsersHistory{
+		"memory":     NewMemoryOrphanedUsersHistory(3),
+		"persistent": NewPersistentOrphanedUsersHistory(NewMemoryStore(), 3),
+	}
+	for name, h := range histories {
+		t.Run(name, func(t *testing.T) {
+			testOrphanedUsersHistory(t, h)
+		})
+	}
+}
+
+// testOrphanedUsersHistory checks that a history holding 3 snapshots keeps
+// the newest, newest first.
+func testOrphanedUsersHistory(t *testing.T, h OrphanedUsersHistory) {
+	ctx := context.Background()
+
+	snapshots, err := h.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(snapshots) != 0 {
+		t.Fatalf("expected empty history, got %d snapshots", len(snapshots))
+	}
+
+	for i := 1; i <= 5; i++ {
+		if err := h.Record(ctx, OrphanedUsersSnapshot{Count: i}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	snapshots, err = h.List(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []int{5, 4, 3}
+	if len(snapshots) != len(want) {
+		t.Fatalf("expected %d snapshots, got %d", len(want), len(snapshots))
+	}
+	for i, count := range want {
+		if snapshots[i].Count != count {
+			t.Errorf("snapshot %d count = %d, want %d", i, snapshots[i].Count, count)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// backends selectable with APP_STORE_BACKEND.
+const (
+	BackendMemory   = "memory"
+	BackendDynamoDB = "dynamodb"
+)
+
+// Store is a key-value store shared by stateful features. values are opaque
+// bytes; a ttl of 0 keeps an entry until it is deleted.
+type Store interface {
+	// Get returns the value stored for key. found is false if the key is
+	// missing or expired.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores value for key, replacing any existing entry.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete removes key. deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+	// PutIfAbsent stores value only if key is missing or expired. returns
+	// false without changing the entry if key is present.
+	PutIfAbsent(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	// DeleteIfValue removes key only if it holds value. returns false
+	// without changing the entry if key is missing or holds another value.
+	DeleteIfValue(ctx context.Context, key string, value []byte) (bool, error)
+	// RefreshIfValue resets the ttl of key only if it still holds value.
+	// returns false if key is missing, expired, or holds another value.
+	RefreshIfValue(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+}
+
+// memoryEntry is a stored value and when it expires. a zero expiry never
+// expires.
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// expired returns true if the entry has expired at now.
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// MemoryStore is an in-process Store. contents are lost when the process
+// exits.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	now     func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: make(map[string]memoryEntry),
+		now:     time.Now,
+	}
+}
+
+// Get returns a copy of the value stored for key.
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if entry.expired(s.now()) {
+		delete(s.entries, key)
+		return nil, false, nil
+	}
+	return slices.Clone(entry.value), true, nil
+}
+
+// Set stores a copy of value for key.
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = s.newEntry(value, ttl)
+	return nil
+}
+
+// Delete removes key.
+func (s *MemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// PutIfAbsent stores a copy of value only if key is missing or expired.
+func (s *MemoryStore) PutIfAbsent(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && !entry.expired(s.now()) {
+		return false, nil
+	}
+	s.entries[key] = s.newEntry(value, ttl)
+	return true, nil
+}
+
+// DeleteIfValue removes key only if it holds value.
+func (s *MemoryStore) DeleteIfValue(_ context.Context, key string, value []byte) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !bytes.Equal(entry.value, value) {
+		return false, nil
+	}
+	delete(s.entries, key)
+	return true, nil
+}
+
+// RefreshIfValue resets the ttl of key only if it still holds value.
+func (s *MemoryStore) RefreshIfValue(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || entry.expired(s.now()) || !bytes.Equal(entry.value, value) {
+		return false, nil
+	}
+	s.entries[key] = s.newEntry(value, ttl)
+	return true, nil
+}
+
+// newEntry creates an entry for value expiring after ttl.
+func (s *MemoryStore) newEntry(value []byte, ttl time.Duration) memoryEntry {
+	entry := memoryEntry{value: slices.Clone(value)}
+	if ttl > 0 {
+		entry.expiresAt = s.now().Add(ttl)
+	}
+	return entry
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	if _, found, err := s.Get(ctx, "missing"); err != nil || found {
+		t.Fatalf("expected missing key, got found=%t err=%v", found, err)
+	}
+
+	value := []byte("v1")
+	if err := s.Set(ctx, "key", value, time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	value[0] = 'x' // the store keeps its own copy
+
+	got, found, err := s.Get(ctx, "key")
+	if err != nil || !found || string(got) != "v1" {
+		t.Fatalf("expected v1, got %q found=%t err=%v", got, found, err)
+	}
+
+	if ok, _ := s.PutIfAbsent(ctx, "key", []byte("v2"), time.Minute); ok {
+		t.Error("expected PutIfAbsent to fail while the key is present")
+	}
+
+	now = now.Add(time.Minute)
+	if _, found, _ := s.Get(ctx, "key"); found {
+		t.Error("expected key to expire after its ttl")
+	}
+	if ok, _ := s.PutIfAbsent(ctx, "key", []byte("v2"), 0); !ok {
+		t.Error("expected PutIfAbsent to replace an expired key")
+	}
+
+	now = now.Add(24 * time.Hour)
+	if got, found, _ := s.Get(ctx, "key"); !found || string(got) != "v2" {
+		t.Errorf("expected entry without ttl to persist, got %q found=%t", got, found)
+	}
+
+	if err := s.Delete(ctx, "key"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, found, _ := s.Get(ctx, "key"); found {
+		t.Error("expected key to be deleted")
+	}
+}
+
+func TestMemoryStore_IfValue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	_ = s.Set(ctx, "key", []byte("a"), time.Minute)
+
+	if ok, _ := s.RefreshIfValue(ctx, "key", []byte("b"), time.Minute); ok {
+		t.Error("expected refresh with another value to fail")
+	}
+	now = now.Add(30 * time.Second)
+	if ok, _ := s.RefreshIfValue(ctx, "key", []byte("a"), time.Minute); !ok {
+		t.Error("expected refresh with the held value to succeed")
+	}
+	now = now.Add(45 * time.Second)
+	if _, found, _ := s.Get(ctx, "key"); !found {
+		t.Error("expected refresh to extend the ttl")
+	}
+
+	if ok, _ := s.DeleteIfValue(ctx, "key", []byte("b")); ok {
+		t.Error("expected delete with another value to fail")
+	}
+	if _, found, _ := s.Get(ctx, "key"); !found {
+		t.Error("expected key to survive a mismatched delete")
+	}
+	if ok, _ := s.DeleteIfValue(ctx, "key", []byte("a")); !ok {
+		t.Error("expected delete with the held value to succeed")
+	}
+
+	_ = s.Set(ctx, "key", []byte("a"), time.Minute)
+	now = now.Add(time.Minute)
+	if ok, _ := s.RefreshIfValue(ctx, "key", []byte("a"), time.Minute); ok {
+		t.Error("expected refresh of an expired key to fail")
+	}
+}
@@ -0,0 +1,70 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RefNameCondition is the ref_name condition of a branch ruleset. patterns
+// use GitHub's fnmatch syntax and may carry the refs/heads/ prefix. ~ALL
+// matches every branch; ~DEFAULT_BRANCH must be resolved to the default
+// branch before matching.
+type RefNameCondition struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// Matches returns true if the branch matches an include pattern and no
+// exclude pattern.
+func (c RefNameCondition) Matches(branch string) bool {
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+
+	included := false
+	for _, pattern := range c.Include {
+		if matchRefPattern(pattern, branch) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+
+	for _, pattern := range c.Exclude {
+		if matchRefPattern(pattern, branch) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRefPattern matches a branch name against a ruleset ref pattern. `*`
+// does not match `/`, while `**` matches across path segments.
+func matchRefPattern(pattern, branch string) bool {
+	if pattern == "~ALL" {
+		return true
+	}
+	pattern = strings.TrimPrefix(pattern, "refs/heads/")
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch ch := pattern[i]; ch {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+
+	matched, _ := regexp.MatchString(b.String(), branch)
+	return matched
+}
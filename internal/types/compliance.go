@@ -0,0 +1,21 @@
+package types
+
+// violation types reported by PR compliance checks.
+const (
+	ViolationInsufficientReviews       = "insufficient_reviews"
+	ViolationMissingTeamReview         = "missing_team_review"
+	ViolationMissingStatusCheck        = "missing_status_check"
+	ViolationUnsignedCommits           = "unsigned_commits"
+	ViolationSelfApproval              = "self_approval"
+	ViolationMergedWhileDraft          = "merged_while_draft"
+	ViolationMissingRequiredDeployment = "missing_required_deployment"
+)
+
+// RepoRoles are GitHub's built-in repository roles, from most to least
+// privileged.
+var RepoRoles = []string{"admin", "maintain", "write", "triage", "read"}
+
+// DefaultBypassPermissions are the repository roles whose merges are
+// recognized as a bypass by a privileged user when no bypass permissions are
+// configured.
+var DefaultBypassPermissions = []string{"admin", "maintain"}
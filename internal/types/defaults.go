@@ -0,0 +1,19 @@
+package types
+
+import "time"
+
+// DefaultGitHubMaxRPS is the default client-side limit on GitHub API
+// requests per second. high enough that typical workloads are not slowed
+// down.
+const DefaultGitHubMaxRPS = 25.0
+
+// DefaultProtectionCacheTTL is the default lifetime of cached branch
+// protection and ruleset lookups.
+const DefaultProtectionCacheTTL = time.Minute
+
+// default slack retry settings. a notification survives a brief rate limit
+// or outage without holding up a webhook response for long.
+const (
+	DefaultSlackMaxAttempts  = 4
+	DefaultSlackRetryTimeout = 30 * time.Second
+)
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// APIQuotaLowRatio is the fraction of quota remaining at or below which an
+// APIQuota is considered low.
+const APIQuotaLowRatio = 0.1
+
+// APIQuota is an API rate limit snapshot taken from response headers.
+type APIQuota struct {
+	Service   string    `json:"service"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	Reset     time.Time `json:"reset"`
+}
+
+// IsLow returns true if the remaining quota is at or below APIQuotaLowRatio
+// of the limit.
+func (q APIQuota) IsLow() bool {
+	return q.Limit > 0 && float64(q.Remaining) <= float64(q.Limit)*APIQuotaLowRatio
+}
+
+// ResetsIn returns the time until the quota resets, rounded to the second.
+// zero if the reset time has passed.
+func (q APIQuota) ResetsIn() time.Duration {
+	return max(time.Until(q.Reset).Round(time.Second), 0)
+}
+
+// String formats the quota as "API quota remaining: X/Y, resets in Z".
+func (q APIQuota) String() string {
+	return fmt.Sprintf("API quota remaining: %d/%d, resets in %s", q.Remaining, q.Limit, q.ResetsIn())
+}
+
+// QuotaTracker records the most recent rate limit observed in API responses.
+// safe for concurrent use.
+type QuotaTracker struct {
+	latest atomic.Pointer[APIQuota]
+}
+
+// Observe records the quota from the limit, remaining, and reset (unix
+// seconds) headers named by prefix, e.g. "X-RateLimit-". responses without
+// all three headers are ignored.
+func (t *QuotaTracker) Observe(service, prefix string, header http.Header) {
+	limit, err := strconv.Atoi(header.Get(prefix + "Limit"))
+	if err != nil {
+		return
+	}
+	remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+
+	t.latest.Store(&APIQuota{
+		Service:   service,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Unix(reset, 0),
+	})
+}
+
+// Latest returns the most recently observed quota, if any.
+func (t *QuotaTracker) Latest() (APIQuota, bool) {
+	q := t.latest.Load()
+	if q == nil {
+		return APIQuota{}, false
+	}
+	return *q, true
+}
@@ -1,18 +1,50 @@
 // Package types provides shared type definitions used across packages.
 package types
 
+import (
+	"path"
+	"strings"
+	"text/template"
+)
+
 // SyncRule defines how to sync Okta groups to GitHub teams.
 type SyncRule struct {
-	Name                string `json:"name"`
-	Enabled             *bool  `json:"enabled,omitempty"`
-	OktaGroupPattern    string `json:"okta_group_pattern,omitempty"`
-	OktaGroupName       string `json:"okta_group_name,omitempty"`
-	GitHubTeamPrefix    string `json:"github_team_prefix,omitempty"`
-	GitHubTeamName      string `json:"github_team_name,omitempty"`
-	StripPrefix         string `json:"strip_prefix,omitempty"`
-	SyncMembers         *bool  `json:"sync_members,omitempty"`
-	CreateTeamIfMissing bool   `json:"create_team_if_missing"`
-	TeamPrivacy         string `json:"team_privacy,omitempty"`
+	Name                 string   `json:"name"`
+	Enabled              *bool    `json:"enabled,omitempty"`
+	OktaGroupPattern     string   `json:"okta_group_pattern,omitempty"`
+	OktaGroupName        string   `json:"okta_group_name,omitempty"`
+	GitHubTeamPrefix     string   `json:"github_team_prefix,omitempty"`
+	GitHubTeamName       string   `json:"github_team_name,omitempty"`
+	StripPrefix          string   `json:"strip_prefix,omitempty"`
+	SyncMembers          *bool    `json:"sync_members,omitempty"`
+	CreateTeamIfMissing  bool     `json:"create_team_if_missing"`
+	TeamPrivacy          string   `json:"team_privacy,omitempty"`
+	TeamNameTemplate     string   `json:"team_name_template,omitempty"`
+	DryRun               *bool    `json:"dry_run,omitempty"`
+	PreserveMembers      []string `json:"preserve_members,omitempty"`
+	Priority             int      `json:"priority,omitempty"`
+	ExcludeMembers       []string `json:"exclude_members,omitempty"`
+	AlwaysIncludeMembers []string `json:"always_include_members,omitempty"`
+	ParentTeamSlug       string   `json:"parent_team_slug,omitempty"`
+	// GitHubTeamSlug binds the rule to an existing team by slug. the team is
+	// never created, renamed, or moved, and the rule fails if it is missing.
+	GitHubTeamSlug string `json:"github_team_slug,omitempty"`
+	// TeamDescription is set as the team's description when it differs.
+	// empty leaves the description untouched.
+	TeamDescription string `json:"team_description,omitempty"`
+	// OktaConnection names the Okta org, from APP_OKTA_CONNECTIONS, that the
+	// rule's groups are read from. empty uses the global Okta org.
+	OktaConnection string `json:"okta_connection,omitempty"`
+}
+
+// TeamNameData holds the variables available to TeamNameTemplate.
+type TeamNameData struct {
+	// Group is the Okta group name.
+	Group string
+	// StrippedGroup is the group name with StripPrefix removed.
+	StrippedGroup string
+	// Prefix is the configured GitHubTeamPrefix.
+	Prefix string
 }
 
 // IsEnabled returns true if the rule is enabled (defaults to true).
@@ -26,7 +58,62 @@ func (r SyncRule) ShouldSyncMembers() bool {
 	return r.SyncMembers == nil || *r.SyncMembers
 }
 
-// GetName returns the rule name, defaulting to GitHubTeamName if not set.
+// IsDryRun returns true if the global setting or the rule requests dry-run.
+// a rule's "dry_run": false never overrides a global dry-run.
+func (r SyncRule) IsDryRun(globalDryRun bool) bool {
+	return globalDryRun || (r.DryRun != nil && *r.DryRun)
+}
+
+// IsPreservedMember returns true if the GitHub login equals or matches any
+// PreserveMembers glob pattern. preserved members are never removed from the
+// team by sync. matching is case-insensitive.
+func (r SyncRule) IsPreservedMember(login string) bool {
+	login = strings.ToLower(login)
+	for _, pattern := range r.PreserveMembers {
+		pattern = strings.ToLower(pattern)
+		if pattern == login {
+			return true
+		}
+		if ok, _ := path.Match(pattern, login); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyMemberOverrides drops ExcludeMembers from the GitHub logins resolved
+// from Okta and adds AlwaysIncludeMembers. forced lists the always-included
+// logins that were not already members. logins are compared
+// case-insensitively, and always-included logins win over exclusions.
+func (r SyncRule) ApplyMemberOverrides(members []string) (desired, forced []string) {
+	excluded := make(map[string]bool, len(r.ExcludeMembers))
+	for _, login := range r.ExcludeMembers {
+		excluded[strings.ToLower(login)] = true
+	}
+
+	seen := make(map[string]bool, len(members))
+	for _, login := range members {
+		if excluded[strings.ToLower(login)] {
+			continue
+		}
+		desired = append(desired, login)
+		seen[strings.ToLower(login)] = true
+	}
+
+	for _, login := range r.AlwaysIncludeMembers {
+		if seen[strings.ToLower(login)] {
+			continue
+		}
+		desired = append(desired, login)
+		forced = append(forced, login)
+		seen[strings.ToLower(login)] = true
+	}
+
+	return desired, forced
+}
+
+// GetName returns the rule name, defaulting to GitHubTeamName or
+// GitHubTeamSlug if not set.
 func (r SyncRule) GetName() string {
 	if r.Name != "" {
 		return r.Name
@@ -34,5 +121,38 @@ func (r SyncRule) GetName() string {
 	if r.GitHubTeamName != "" {
 		return r.GitHubTeamName
 	}
+	if r.GitHubTeamSlug != "" {
+		return r.GitHubTeamSlug
+	}
 	return r.OktaGroupName
 }
+
+// ParseTeamNameTemplate parses TeamNameTemplate as a Go text/template.
+// returns nil if no template is configured.
+func (r SyncRule) ParseTeamNameTemplate() (*template.Template, error) {
+	if r.TeamNameTemplate == "" {
+		return nil, nil
+	}
+	return template.New("team_name").Parse(r.TeamNameTemplate)
+}
+
+// ExecuteTeamNameTemplate renders TeamNameTemplate for an Okta group name.
+// the result is not normalized into a team slug.
+func (r SyncRule) ExecuteTeamNameTemplate(groupName string) (string, error) {
+	tmpl, err := r.ParseTeamNameTemplate()
+	if err != nil || tmpl == nil {
+		return "", err
+	}
+
+	data := TeamNameData{
+		Group:         groupName,
+		StrippedGroup: strings.TrimPrefix(groupName, r.StripPrefix),
+		Prefix:        r.GitHubTeamPrefix,
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}